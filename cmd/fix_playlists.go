@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/navidrome/navidrome/core/auth"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(fixPlaylistsCmd)
+}
+
+var fixPlaylistsCmd = &cobra.Command{
+	Use:   "fix-playlists",
+	Short: "Fix playlists pointing at missing tracks",
+	Long:  "Re-point playlist entries referencing missing tracks to the matching track currently in the library",
+	Run: func(cmd *cobra.Command, args []string) {
+		runFixPlaylists()
+	},
+}
+
+func runFixPlaylists() {
+	ds := persistence.New(db.Db())
+	ctx := auth.WithAdminUser(context.Background(), ds)
+	resolved, err := ds.ResolvePlaylistTracks(ctx)
+	if err != nil {
+		log.Fatal("Error fixing playlists", err)
+	}
+	log.Info("Finished fixing playlists", "resolvedTracks", resolved)
+}
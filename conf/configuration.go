@@ -45,17 +45,22 @@ type configOptions struct {
 	TranscodingCacheSize         string
 	ImageCacheSize               string
 	AlbumPlayCountMode           string
+	InProgressThreshold          float64 // Fraction of a track's duration after which it counts as fully played (used by the "continue listening" endpoint)
+	ClearCompletedBookmarks      bool    // Remove bookmarks past InProgressThreshold during DB clean-up
 	EnableArtworkPrecache        bool
 	AutoImportPlaylists          bool
 	PlaylistsPath                string
 	AutoTranscodeDownload        bool
+	AdditionalMimeTypes          map[string]string
 	DefaultDownsamplingFormat    string
 	SearchFullString             bool
 	RecentlyAddedByModTime       bool
 	PreferSortTags               bool
+	SortLanguage                 string // Language tag tailoring the UNICODE sort collation (ex: "ja", "sv"). Empty uses the language-agnostic Unicode tables
 	IgnoredArticles              string
 	IndexGroups                  string
 	SubsonicArtistParticipations bool
+	MaxContributors              int
 	FFmpegPath                   string
 	MPVPath                      string
 	MPVCmdTemplate               string
@@ -109,9 +114,68 @@ type configOptions struct {
 }
 
 type scannerOptions struct {
-	Extractor          string
-	GenreSeparators    string
-	GroupAlbumReleases bool
+	Extractor              string
+	ExtractorFallback      string // Extractor retried on files the primary fails to parse. Empty disables the fallback chain
+	GenreSeparators        string
+	GroupAlbumReleases     bool
+	ArtistFallback         string // Fallback for missing track artist: "" (use Unknown Artist) or "albumartist"
+	AdditionalRoles        []string
+	AdditiveTags           []string // Tags aggregated in albums as the union of all track values, instead of only when all tracks agree
+	PurgeOrphanAnnotations bool     // Remove annotations pointing to deleted items after a full scan
+	NormalizeArtistNames   bool     // Fold artist names differing only in case or diacritics into a single artist. Opt-in, as some users intentionally distinguish such names
+	ArtistFolderMetadata   bool     // Pick up artist.jpg/artist.nfo files from folders that directly contain the album folders of a single album artist
+
+	// TimeBudget limits the wall-clock duration of a single scan pass. When exceeded, the scan
+	// finishes the folder being processed and resumes on the next run. 0 disables the limit
+	TimeBudget time.Duration
+
+	// Albums without a consistent album artist and at least this many distinct track artists are
+	// treated as Various Artists compilations. 0 disables the heuristic
+	VariousArtistsThreshold int
+
+	// CaseInsensitivePaths matches file paths ignoring case, for case-preserving but
+	// case-insensitive filesystems (ex: APFS, NTFS)
+	CaseInsensitivePaths bool
+
+	// SoftDeleteAnnotations preserves play counts, ratings, stars and bookmarks of deleted
+	// tracks in a tombstone table, restoring them when a matching file is re-imported later
+	// (ex: after a library path change)
+	SoftDeleteAnnotations bool
+
+	// SoftDeleteRetention is how long tombstoned annotations are kept before being purged.
+	// 0 keeps them forever
+	SoftDeleteRetention time.Duration
+
+	// ScanHistoryLength is how many scan summary rows are kept in the scan history. Older
+	// entries are pruned after each scan
+	ScanHistoryLength int
+
+	// AllowedAudioTypes restricts the import to the listed file extensions or MIME types.
+	// Empty imports every recognized audio file (see AdditionalMimeTypes to recognize
+	// extensions not in the built-in list)
+	AllowedAudioTypes []string
+
+	// ExcludedAudioTypes skips the listed file extensions or MIME types during import.
+	// Already imported files matching a new entry are marked missing on the next full scan
+	ExcludedAudioTypes []string
+
+	// FollowSymlinks makes the scanner descend into symlinked folders. Links that loop back
+	// into a folder already being walked are detected and skipped
+	FollowSymlinks bool
+
+	// FollowExternalSymlinks allows symlinked folders whose target lives outside the library
+	// root. Only consulted when FollowSymlinks is enabled
+	FollowExternalSymlinks bool
+
+	// WriteTrackID embeds a NAVIDROME_ID tag into each file the first time it is imported,
+	// and uses it as the track ID from then on, so a track keeps its identity (annotations,
+	// playlist entries) when the file is renamed within its folder. Opt-in, as it modifies
+	// the library files. Requires an extractor that can write tags (taglib)
+	WriteTrackID bool
+
+	// WriteTrackIDDryRun only logs the files that would receive a NAVIDROME_ID tag, without
+	// modifying them. Only consulted when WriteTrackID is enabled
+	WriteTrackIDDryRun bool
 }
 
 type lastfmOptions struct {
@@ -297,6 +361,8 @@ func init() {
 	viper.SetDefault("transcodingcachesize", "100MB")
 	viper.SetDefault("imagecachesize", "100MB")
 	viper.SetDefault("albumplaycountmode", consts.AlbumPlayCountModeAbsolute)
+	viper.SetDefault("inprogressthreshold", 0.95)
+	viper.SetDefault("clearcompletedbookmarks", false)
 	viper.SetDefault("enableartworkprecache", true)
 	viper.SetDefault("autoimportplaylists", true)
 	viper.SetDefault("playlistspath", consts.DefaultPlaylistsPath)
@@ -304,13 +370,16 @@ func init() {
 	viper.SetDefault("enableexternalservices", true)
 	viper.SetDefault("enablemediafilecoverart", true)
 	viper.SetDefault("autotranscodedownload", false)
+	viper.SetDefault("additionalmimetypes", map[string]string{})
 	viper.SetDefault("defaultdownsamplingformat", consts.DefaultDownsamplingFormat)
 	viper.SetDefault("searchfullstring", false)
 	viper.SetDefault("recentlyaddedbymodtime", false)
 	viper.SetDefault("prefersorttags", false)
+	viper.SetDefault("sortlanguage", "")
 	viper.SetDefault("ignoredarticles", "The El La Los Las Le Les Os As O A")
 	viper.SetDefault("indexgroups", "A B C D E F G H I J K L M N O P Q R S T U V W X-Z(XYZ) [Unknown]([)")
 	viper.SetDefault("subsonicartistparticipations", false)
+	viper.SetDefault("maxcontributors", 50)
 	viper.SetDefault("ffmpegpath", "")
 	viper.SetDefault("mpvcmdtemplate", "mpv --audio-device=%d --no-audio-display --pause %f --input-ipc-server=%s")
 
@@ -344,8 +413,27 @@ func init() {
 	viper.SetDefault("jukebox.adminonly", true)
 
 	viper.SetDefault("scanner.extractor", consts.DefaultScannerExtractor)
+	viper.SetDefault("scanner.extractorfallback", "")
 	viper.SetDefault("scanner.genreseparators", ";/,")
 	viper.SetDefault("scanner.groupalbumreleases", false)
+	viper.SetDefault("scanner.artistfallback", "")
+	viper.SetDefault("scanner.additionalroles", []string{})
+	viper.SetDefault("scanner.additivetags", []string{})
+	viper.SetDefault("scanner.purgeorphanannotations", false)
+	viper.SetDefault("scanner.normalizeartistnames", false)
+	viper.SetDefault("scanner.artistfoldermetadata", true)
+	viper.SetDefault("scanner.timebudget", 0)
+	viper.SetDefault("scanner.variousartiststhreshold", 4)
+	viper.SetDefault("scanner.caseinsensitivepaths", false)
+	viper.SetDefault("scanner.softdeleteannotations", false)
+	viper.SetDefault("scanner.softdeleteretention", "2160h") // 90 days
+	viper.SetDefault("scanner.scanhistorylength", 100)
+	viper.SetDefault("scanner.allowedaudiotypes", []string{})
+	viper.SetDefault("scanner.excludedaudiotypes", []string{})
+	viper.SetDefault("scanner.followsymlinks", true)
+	viper.SetDefault("scanner.followexternalsymlinks", true)
+	viper.SetDefault("scanner.writetrackid", false)
+	viper.SetDefault("scanner.writetrackiddryrun", false)
 
 	viper.SetDefault("agents", "lastfm,spotify")
 	viper.SetDefault("lastfm.enabled", true)
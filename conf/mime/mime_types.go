@@ -3,6 +3,7 @@ package mime
 import (
 	"mime"
 	"strings"
+	"sync"
 
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/log"
@@ -40,6 +41,33 @@ func initMimeTypes() {
 	for _, ext := range mimeConf.Lossless {
 		LosslessFormats = append(LosslessFormats, strings.TrimPrefix(ext, "."))
 	}
+
+	AddCustomTypes(conf.Server.AdditionalMimeTypes)
+}
+
+// AddCustomTypes registers user-defined suffix mappings (the AdditionalMimeTypes config option)
+// on top of the defaults shipped in mime_types.yaml
+func AddCustomTypes(types map[string]string) {
+	for ext, typ := range types {
+		ext = "." + strings.TrimPrefix(strings.ToLower(ext), ".")
+		_ = mime.AddExtensionType(ext, typ)
+	}
+}
+
+var unknownSuffixes sync.Map
+
+// TypeByExtension resolves the MIME type to serve files with, falling back to
+// application/octet-stream for suffixes missing from the mapping table. Each unknown suffix is
+// only logged once
+func TypeByExtension(ext string) string {
+	if typ := mime.TypeByExtension(ext); typ != "" {
+		return typ
+	}
+	if _, logged := unknownSuffixes.LoadOrStore(ext, struct{}{}); !logged {
+		log.Warn("Unknown media suffix, serving as application/octet-stream. "+
+			"Use the AdditionalMimeTypes config option to map it", "suffix", ext)
+	}
+	return "application/octet-stream"
 }
 
 func init() {
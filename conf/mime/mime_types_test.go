@@ -0,0 +1,36 @@
+package mime_test
+
+import (
+	"testing"
+
+	"github.com/navidrome/navidrome/conf/mime"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMimeTypes(t *testing.T) {
+	log.SetLevel(log.LevelFatal)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "MimeTypes Suite")
+}
+
+var _ = Describe("MimeTypes", func() {
+	Describe("AddCustomTypes", func() {
+		It("makes a custom suffix usable from scan classification to MIME lookup", func() {
+			Expect(model.IsAudioFile("file.nvx")).To(BeFalse())
+
+			mime.AddCustomTypes(map[string]string{"nvx": "audio/x-nvx"})
+
+			Expect(model.IsAudioFile("file.nvx")).To(BeTrue())
+			Expect(mime.TypeByExtension(".nvx")).To(Equal("audio/x-nvx"))
+		})
+	})
+
+	Describe("TypeByExtension", func() {
+		It("falls back to application/octet-stream for unknown suffixes", func() {
+			Expect(mime.TypeByExtension(".definitely-unknown")).To(Equal("application/octet-stream"))
+		})
+	})
+})
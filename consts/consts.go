@@ -56,6 +56,20 @@ const (
 	I18nFolder   = "i18n"
 	SkipScanFile = ".ndignore"
 
+	// Property keys for the tag persistence allowlist, managed through the native API
+	TagAllowlistKey = "TagAllowlist"
+	TagUsageKey     = "TagUsage"
+	PartialScanKey  = "PartialScan"
+
+	// Property key for the tag value merges re-applied by the scanner (see model.TagMappings)
+	TagMappingsKey = "TagMappings"
+
+	// Property key for the cross-instance scan claim (see scanner.scanClaim)
+	ScanClaimKey = "ScanInProgress"
+
+	// Property key for the gain analysis job checkpoint (last processed media file id)
+	GainAnalysisKey = "GainAnalysisLastId"
+
 	PlaceholderArtistArt = "artist-placeholder.webp"
 	PlaceholderAlbumArt  = "placeholder.png"
 	PlaceholderAvatar    = "logo-192x192.png"
@@ -258,7 +258,7 @@ func (l *lastfmAgent) NowPlaying(ctx context.Context, userId string, track *mode
 		album:       track.Album,
 		trackNumber: track.TrackNumber,
 		mbid:        track.MbzRecordingID,
-		duration:    int(track.Duration),
+		duration:    int(track.Duration()),
 		albumArtist: track.AlbumArtist,
 	})
 	if err != nil {
@@ -274,8 +274,8 @@ func (l *lastfmAgent) Scrobble(ctx context.Context, userId string, s scrobbler.S
 		return scrobbler.ErrNotAuthorized
 	}
 
-	if s.Duration <= 30 {
-		log.Debug(ctx, "Skipping Last.fm scrobble for short song", "track", s.Title, "duration", s.Duration)
+	if s.Duration() <= 30 {
+		log.Debug(ctx, "Skipping Last.fm scrobble for short song", "track", s.Title, "duration", s.Duration())
 		return nil
 	}
 	err = l.client.scrobble(ctx, sk, ScrobbleInfo{
@@ -284,7 +284,7 @@ func (l *lastfmAgent) Scrobble(ctx context.Context, userId string, s scrobbler.S
 		album:       s.Album,
 		trackNumber: s.TrackNumber,
 		mbid:        s.MbzRecordingID,
-		duration:    int(s.Duration),
+		duration:    int(s.Duration()),
 		albumArtist: s.AlbumArtist,
 		timestamp:   s.TimeStamp,
 	})
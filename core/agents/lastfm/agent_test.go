@@ -240,7 +240,7 @@ var _ = Describe("lastfmAgent", func() {
 				Artist:         "Track Artist",
 				AlbumArtist:    "Track AlbumArtist",
 				TrackNumber:    1,
-				Duration:       180,
+				DurationMs:     180000,
 				MbzRecordingID: "mbz-123",
 			}
 		})
@@ -261,7 +261,7 @@ var _ = Describe("lastfmAgent", func() {
 				Expect(sentParams.Get("artist")).To(Equal(track.Artist))
 				Expect(sentParams.Get("albumArtist")).To(Equal(track.AlbumArtist))
 				Expect(sentParams.Get("trackNumber")).To(Equal(strconv.Itoa(track.TrackNumber)))
-				Expect(sentParams.Get("duration")).To(Equal(strconv.FormatFloat(float64(track.Duration), 'G', -1, 32)))
+				Expect(sentParams.Get("duration")).To(Equal(strconv.FormatFloat(float64(track.Duration()), 'G', -1, 32)))
 				Expect(sentParams.Get("mbid")).To(Equal(track.MbzRecordingID))
 			})
 
@@ -288,13 +288,13 @@ var _ = Describe("lastfmAgent", func() {
 				Expect(sentParams.Get("artist")).To(Equal(track.Artist))
 				Expect(sentParams.Get("albumArtist")).To(Equal(track.AlbumArtist))
 				Expect(sentParams.Get("trackNumber")).To(Equal(strconv.Itoa(track.TrackNumber)))
-				Expect(sentParams.Get("duration")).To(Equal(strconv.FormatFloat(float64(track.Duration), 'G', -1, 32)))
+				Expect(sentParams.Get("duration")).To(Equal(strconv.FormatFloat(float64(track.Duration()), 'G', -1, 32)))
 				Expect(sentParams.Get("mbid")).To(Equal(track.MbzRecordingID))
 				Expect(sentParams.Get("timestamp")).To(Equal(strconv.FormatInt(ts.Unix(), 10)))
 			})
 
 			It("skips songs with less than 31 seconds", func() {
-				track.Duration = 29
+				track.DurationMs = 29000
 				httpClient.Res = http.Response{Body: io.NopCloser(bytes.NewBufferString("{}")), StatusCode: 200}
 
 				err := agent.Scrobble(ctx, "user-1", scrobbler.Scrobble{MediaFile: *track, TimeStamp: time.Now()})
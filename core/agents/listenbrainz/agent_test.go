@@ -40,7 +40,7 @@ var _ = Describe("listenBrainzAgent", func() {
 			MbzRecordingID: "mbz-123",
 			MbzAlbumID:     "mbz-456",
 			MbzArtistID:    "mbz-789",
-			Duration:       142.2,
+			DurationMs:     142200,
 		}
 	})
 
@@ -3,12 +3,14 @@ package artwork
 import (
 	"context"
 	"errors"
+	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/conf/configtest"
@@ -110,6 +112,36 @@ var _ = Describe("Artwork", func() {
 				Expect(err).To(MatchError(ErrUnavailable))
 			})
 		})
+		Context("Cover metadata persisted by the scanner", func() {
+			var al model.Album
+			BeforeEach(func() {
+				al = model.Album{
+					ID:                "888",
+					Name:              "Scanned cover",
+					CoverArtPath:      "tests/fixtures/NON_EXISTENT/cover.jpg",
+					CoverArtSize:      1234,
+					CoverArtUpdatedAt: time.Now(),
+					UpdatedAt:         time.Now().Add(-time.Hour),
+				}
+				ds.Album(ctx).(*tests.MockAlbumRepo).SetData(model.Albums{al})
+			})
+			It("builds the cache key and last update from the stored metadata, without touching the file", func() {
+				r, err := newAlbumArtworkReader(ctx, aw, al.CoverArtID(), nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(r.LastUpdated().Unix()).To(Equal(al.CoverArtUpdatedAt.Unix()))
+				Expect(r.Key()).To(ContainSubstring(fmt.Sprintf(".%d", al.CoverArtSize)))
+			})
+			It("changes the key when a rescan picks a different image", func() {
+				r1, err := newAlbumArtworkReader(ctx, aw, al.CoverArtID(), nil)
+				Expect(err).ToNot(HaveOccurred())
+				al.CoverArtPath = "tests/fixtures/NON_EXISTENT/folder.png"
+				al.CoverArtSize = 4321
+				ds.Album(ctx).(*tests.MockAlbumRepo).SetData(model.Albums{al})
+				r2, err := newAlbumArtworkReader(ctx, aw, al.CoverArtID(), nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(r2.Key()).ToNot(Equal(r1.Key()))
+			})
+		})
 		Context("Multiple covers", func() {
 			BeforeEach(func() {
 				ds.Album(ctx).(*tests.MockAlbumRepo).SetData(model.Albums{
@@ -32,7 +32,12 @@ func newAlbumArtworkReader(ctx context.Context, artwork *artwork, artID model.Ar
 		album: *al,
 	}
 	a.cacheKey.artID = artID
+	// The cover file's modification time is persisted at scan time, so cache keys and
+	// conditional requests follow the image without a filesystem stat per request
 	a.cacheKey.lastUpdate = al.UpdatedAt
+	if al.CoverArtUpdatedAt.After(a.cacheKey.lastUpdate) {
+		a.cacheKey.lastUpdate = al.CoverArtUpdatedAt
+	}
 	return a, nil
 }
 
@@ -41,19 +46,33 @@ func (a *albumArtworkReader) Key() string {
 	if conf.Server.EnableExternalServices {
 		hash = md5.Sum([]byte(conf.Server.Agents + conf.Server.CoverArtPriority))
 	}
+	// The selected cover's path and size are part of the key, so a different image picked by a
+	// rescan invalidates the cached entry even when the album row keeps its timestamps
 	return fmt.Sprintf(
-		"%s.%x.%t",
+		"%s.%x.%t.%x.%d",
 		a.cacheKey.Key(),
 		hash,
 		conf.Server.EnableExternalServices,
+		md5.Sum([]byte(a.album.CoverArtPath)),
+		a.album.CoverArtSize,
 	)
 }
 func (a *albumArtworkReader) LastUpdated() time.Time {
-	return a.album.UpdatedAt
+	return a.cacheKey.lastUpdate
 }
 
 func (a *albumArtworkReader) Reader(ctx context.Context) (io.ReadCloser, string, error) {
-	var ff = a.fromCoverArtPriority(ctx, a.a.ffmpeg, conf.Server.CoverArtPriority)
+	var ff []sourceFunc
+	// The cover selected by the scanner (or overridden through the API) takes precedence;
+	// the priority list below stays as a fallback for albums not yet re-scanned
+	if a.album.CoverArtPath != "" {
+		if a.album.CoverArtPath == a.album.EmbedArtPath {
+			ff = append(ff, fromTag(a.album.CoverArtPath), fromFFmpegTag(ctx, a.a.ffmpeg, a.album.CoverArtPath))
+		} else {
+			ff = append(ff, fromExternalFile(ctx, a.album.CoverArtPath, "*"))
+		}
+	}
+	ff = append(ff, a.fromCoverArtPriority(ctx, a.a.ffmpeg, conf.Server.CoverArtPriority)...)
 	return selectImageReader(ctx, a.artID, ff...)
 }
 
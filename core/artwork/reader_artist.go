@@ -60,6 +60,13 @@ func newArtistReader(ctx context.Context, artwork *artwork, artID model.ArtworkI
 	if !strings.HasSuffix(a.artistFolder, string(filepath.Separator)) {
 		a.artistFolder, _ = filepath.Split(a.artistFolder)
 	}
+	// Stat the image recorded by the scanner, so a replaced artist.jpg invalidates the cache
+	// even when none of the artist's albums changed
+	if ar.ImagePath != "" {
+		if st, err := os.Stat(ar.ImagePath); err == nil && a.cacheKey.lastUpdate.Before(st.ModTime()) {
+			a.cacheKey.lastUpdate = st.ModTime()
+		}
+	}
 	a.cacheKey.artID = artID
 	return a, nil
 }
@@ -79,7 +86,13 @@ func (a *artistReader) LastUpdated() time.Time {
 }
 
 func (a *artistReader) Reader(ctx context.Context) (io.ReadCloser, string, error) {
-	var ff = a.fromArtistArtPriority(ctx, conf.Server.ArtistArtPriority)
+	var ff []sourceFunc
+	// An image file recorded from the artist's folder during the scan takes precedence over
+	// the configured priority list
+	if a.artist.ImagePath != "" {
+		ff = append(ff, fromArtistImagePath(ctx, a.artist.ImagePath))
+	}
+	ff = append(ff, a.fromArtistArtPriority(ctx, conf.Server.ArtistArtPriority)...)
 	return selectImageReader(ctx, a.artID, ff...)
 }
 
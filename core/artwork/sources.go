@@ -79,6 +79,19 @@ func fromExternalFile(ctx context.Context, files string, pattern string) sourceF
 	}
 }
 
+// fromArtistImagePath serves the artist image file recorded on the artist row by the scanner
+// (ex: artist.jpg found in the artist's folder)
+func fromArtistImagePath(ctx context.Context, path string) sourceFunc {
+	return func() (io.ReadCloser, string, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Warn(ctx, "Could not open artist image file", "file", path, err)
+			return nil, "", err
+		}
+		return f, path, nil
+	}
+}
+
 func fromTag(path string) sourceFunc {
 	return func() (io.ReadCloser, string, error) {
 		if path == "" {
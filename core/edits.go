@@ -0,0 +1,175 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/server/events"
+	"github.com/navidrome/navidrome/utils/slice"
+	"github.com/navidrome/navidrome/utils/str"
+	"golang.org/x/exp/maps"
+)
+
+// ErrNotEditable is returned when a bulk edit request contains a field outside the allowlist
+var ErrNotEditable = errors.New("field is not editable")
+
+type Edits interface {
+	// BulkEdit applies the field/value changes to all given media files in a single transaction,
+	// and re-aggregates the affected albums. Partial failures roll back entirely.
+	BulkEdit(ctx context.Context, ids []string, fields map[string]interface{}) error
+}
+
+func NewEdits(ds model.DataStore, broker events.Broker) Edits {
+	return &editService{ds: ds, broker: broker}
+}
+
+type editService struct {
+	ds     model.DataStore
+	broker events.Broker
+}
+
+type fieldSetter func(ctx context.Context, tx model.DataStore, mf *model.MediaFile, value interface{}) error
+
+// editableFields is the allowlist of fields that can be changed through the bulk edit API
+var editableFields = map[string]fieldSetter{
+	"title": func(_ context.Context, _ model.DataStore, mf *model.MediaFile, value interface{}) error {
+		title, err := asString("title", value)
+		if err != nil {
+			return err
+		}
+		mf.Title = title
+		mf.OrderTitle = str.SanitizeFieldForSorting(title)
+		return nil
+	},
+	"comment": func(_ context.Context, _ model.DataStore, mf *model.MediaFile, value interface{}) error {
+		comment, err := asString("comment", value)
+		if err != nil {
+			return err
+		}
+		mf.Comment = comment
+		return nil
+	},
+	"year": func(_ context.Context, _ model.DataStore, mf *model.MediaFile, value interface{}) error {
+		year, err := asInt("year", value)
+		if err != nil {
+			return err
+		}
+		mf.Year = year
+		mf.Date = strconv.Itoa(year)
+		return nil
+	},
+	"bpm": func(_ context.Context, _ model.DataStore, mf *model.MediaFile, value interface{}) error {
+		bpm, err := asInt("bpm", value)
+		if err != nil {
+			return err
+		}
+		mf.Bpm = bpm
+		return nil
+	},
+	"genre": func(ctx context.Context, tx model.DataStore, mf *model.MediaFile, value interface{}) error {
+		name, err := asString("genre", value)
+		if err != nil {
+			return err
+		}
+		g := model.Genre{Name: name}
+		if err := tx.Genre(ctx).Put(&g); err != nil {
+			return err
+		}
+		mf.Genre = g.Name
+		mf.Genres = model.Genres{g}
+		return nil
+	},
+}
+
+func asString(field string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field %q requires a string value, got %v", field, value)
+	}
+	return s, nil
+}
+
+func asInt(field string, value interface{}) (int, error) {
+	// JSON numbers are decoded as float64
+	f, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("field %q requires a numeric value, got %v", field, value)
+	}
+	return int(f), nil
+}
+
+func (e *editService) BulkEdit(ctx context.Context, ids []string, fields map[string]interface{}) error {
+	setters := make(map[string]fieldSetter, len(fields))
+	for name := range fields {
+		setter, ok := editableFields[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrNotEditable, name)
+		}
+		setters[name] = setter
+	}
+	albumIDs := map[string]struct{}{}
+	err := e.ds.WithTx(func(tx model.DataStore) error {
+		repo := tx.MediaFile(ctx)
+		for _, id := range ids {
+			mf, err := repo.Get(id)
+			if err != nil {
+				return fmt.Errorf("retrieving track %s: %w", id, err)
+			}
+			for name, setter := range setters {
+				if err := setter(ctx, tx, mf, fields[name]); err != nil {
+					return err
+				}
+			}
+			// Edited fields take precedence over the file tags on subsequent scans, until
+			// explicitly unlocked
+			for name := range setters {
+				mf.LockedFields = mf.LockedFields.Lock(name)
+			}
+			if err := repo.Put(mf); err != nil {
+				return err
+			}
+			if mf.AlbumID != "" {
+				albumIDs[mf.AlbumID] = struct{}{}
+			}
+		}
+		return refreshAlbums(ctx, tx, albumIDs)
+	})
+	if err != nil {
+		return err
+	}
+	event := &events.RefreshResource{}
+	e.broker.SendMessage(ctx, event.With("song", ids...).With("album", maps.Keys(albumIDs)...))
+	return nil
+}
+
+// refreshAlbums re-aggregates the albums of the edited tracks, the same way the scanner does
+func refreshAlbums(ctx context.Context, tx model.DataStore, albumIDs map[string]struct{}) error {
+	if len(albumIDs) == 0 {
+		return nil
+	}
+	mfs, err := tx.MediaFile(ctx).GetAll(model.QueryOptions{Filters: squirrel.Eq{"album_id": maps.Keys(albumIDs)}})
+	if err != nil {
+		return err
+	}
+	grouped := slice.Group(mfs, func(mf model.MediaFile) string { return mf.AlbumID })
+	for id, tracks := range grouped {
+		if _, ok := albumIDs[id]; !ok {
+			continue
+		}
+		al := model.MediaFiles(tracks).ToAlbum()
+		// Locked album fields keep their current values through the re-aggregation
+		if cur, err := tx.Album(ctx).Get(id); err == nil {
+			al.ApplyFieldLocks(*cur)
+		} else if !errors.Is(err, model.ErrNotFound) {
+			return err
+		}
+		if err := tx.Album(ctx).Put(&al); err != nil {
+			return err
+		}
+	}
+	return nil
+}
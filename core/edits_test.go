@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/server/events"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Edits", func() {
+	var ds *tests.MockDataStore
+	var service Edits
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ds = &tests.MockDataStore{}
+		service = NewEdits(ds, events.GetBroker())
+
+		var mfs model.MediaFiles
+		var ids []string
+		for i := 0; i < 20; i++ {
+			albumID := "al-1"
+			if i >= 10 {
+				albumID = "al-2"
+			}
+			id := fmt.Sprintf("song-%d", i)
+			ids = append(ids, id)
+			mfs = append(mfs, model.MediaFile{ID: id, AlbumID: albumID, Title: "Track",
+				Genre: "Rock", Genres: model.Genres{{ID: "gn-rock", Name: "Rock"}}})
+		}
+		ds.MockedMediaFile = tests.CreateMockMediaFileRepo()
+		ds.MockedMediaFile.(*tests.MockMediaFileRepo).SetData(mfs)
+		ds.MockedAlbum = tests.CreateMockAlbumRepo()
+		ds.MockedAlbum.(*tests.MockAlbumRepo).SetData(model.Albums{
+			{ID: "al-1", Name: "First", Genre: "Rock"},
+			{ID: "al-2", Name: "Second", Genre: "Rock"},
+		})
+	})
+
+	allIDs := func() []string {
+		var ids []string
+		for i := 0; i < 20; i++ {
+			ids = append(ids, fmt.Sprintf("song-%d", i))
+		}
+		return ids
+	}
+
+	It("applies the change to all tracks and re-aggregates both albums", func() {
+		Expect(service.BulkEdit(ctx, allIDs(), map[string]interface{}{"genre": "Jazz"})).To(Succeed())
+
+		for _, id := range allIDs() {
+			mf, err := ds.MediaFile(ctx).Get(id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mf.Genre).To(Equal("Jazz"))
+			Expect(mf.LockedFields.IsLocked("genre")).To(BeTrue())
+		}
+		for _, albumID := range []string{"al-1", "al-2"} {
+			al, err := ds.Album(ctx).Get(albumID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(al.Genre).To(Equal("Jazz"))
+			Expect(al.SongCount).To(Equal(10))
+		}
+	})
+
+	It("rejects fields outside the allowlist", func() {
+		err := service.BulkEdit(ctx, allIDs(), map[string]interface{}{"path": "/etc/passwd"})
+		Expect(err).To(MatchError(ErrNotEditable))
+	})
+
+	It("rejects values of the wrong type", func() {
+		err := service.BulkEdit(ctx, allIDs(), map[string]interface{}{"year": "not a year"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails the whole batch when a track does not exist", func() {
+		err := service.BulkEdit(ctx, append(allIDs(), "missing"), map[string]interface{}{"genre": "Jazz"})
+		Expect(err).To(MatchError(model.ErrNotFound))
+	})
+})
@@ -225,7 +225,10 @@ func (e *externalMetadata) populateArtistInfo(ctx context.Context, artist *auxAr
 	g := errgroup.Group{}
 	g.SetLimit(2)
 	g.Go(func() error { e.callGetImage(ctx, e.ag, artist); return nil })
-	g.Go(func() error { e.callGetBiography(ctx, e.ag, artist); return nil })
+	// A biography parsed from the artist's folder (artist.nfo) takes precedence over the agents
+	if artist.NfoPath == "" {
+		g.Go(func() error { e.callGetBiography(ctx, e.ag, artist); return nil })
+	}
 	g.Go(func() error { e.callGetURL(ctx, e.ag, artist); return nil })
 	g.Go(func() error { e.callGetSimilar(ctx, e.ag, artist, maxSimilarArtists, true); return nil })
 	_ = g.Wait()
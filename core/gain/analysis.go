@@ -0,0 +1,173 @@
+package gain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/core/ffmpeg"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/utils/singleton"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	batchSize = 20
+	// numWorkers bounds the concurrent ffmpeg decodes, so the analysis doesn't starve
+	// playback transcodes
+	numWorkers = 2
+)
+
+var ErrAlreadyRunning = errors.New("gain analysis already running")
+
+// Analysis is the background job that computes ReplayGain values for files without gain
+// tags. It checkpoints the last processed id in the property table, so a stopped or
+// interrupted job resumes from where it left off instead of re-decoding the whole library
+type Analysis interface {
+	Start(ctx context.Context) error
+	Stop()
+	Status(ctx context.Context) (*Status, error)
+}
+
+type Status struct {
+	Running   bool   `json:"running"`
+	Processed int64  `json:"processed"`
+	Remaining int64  `json:"remaining"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+func GetAnalysis(ds model.DataStore, ffmpeg ffmpeg.FFmpeg) Analysis {
+	return singleton.GetInstance(func() *analysis {
+		return newAnalysis(ds, &ffmpegAnalyzer{ffmpeg: ffmpeg})
+	})
+}
+
+func newAnalysis(ds model.DataStore, analyzer Analyzer) *analysis {
+	return &analysis{ds: ds, analyzer: analyzer}
+}
+
+type analysis struct {
+	ds        model.DataStore
+	analyzer  Analyzer
+	running   atomic.Bool
+	processed atomic.Int64
+	lock      sync.Mutex // protects cancel and lastErr
+	cancel    context.CancelFunc
+	lastErr   error
+}
+
+func (a *analysis) Start(ctx context.Context) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if !a.running.CompareAndSwap(false, true) {
+		return ErrAlreadyRunning
+	}
+	// Detached from the request context: the job outlives the request that started it
+	jobCtx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.lastErr = nil
+	a.processed.Store(0)
+	go a.run(jobCtx)
+	return nil
+}
+
+func (a *analysis) Stop() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.cancel != nil {
+		a.cancel()
+	}
+}
+
+func (a *analysis) Status(ctx context.Context) (*Status, error) {
+	remaining, err := a.ds.MediaFile(ctx).CountMissingGain()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{
+		Running:   a.running.Load(),
+		Processed: a.processed.Load(),
+		Remaining: remaining,
+	}
+	a.lock.Lock()
+	if a.lastErr != nil {
+		status.LastError = a.lastErr.Error()
+	}
+	a.lock.Unlock()
+	return status, nil
+}
+
+func (a *analysis) run(ctx context.Context) {
+	defer a.running.Store(false)
+	props := a.ds.Property(ctx)
+	lastID, err := props.DefaultGet(consts.GainAnalysisKey, "")
+	if err != nil {
+		a.setLastError(err)
+		return
+	}
+	if lastID != "" {
+		log.Info(ctx, "Resuming gain analysis", "fromId", lastID)
+	} else {
+		log.Info(ctx, "Starting gain analysis")
+	}
+	for {
+		if ctx.Err() != nil {
+			log.Info(ctx, "Gain analysis stopped", "processed", a.processed.Load())
+			return
+		}
+		batch, err := a.ds.MediaFile(ctx).GetMissingGain(lastID, batchSize)
+		if err != nil {
+			a.setLastError(err)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+		a.processBatch(ctx, batch)
+		lastID = batch[len(batch)-1].ID
+		if err := props.Put(consts.GainAnalysisKey, lastID); err != nil {
+			a.setLastError(err)
+			return
+		}
+	}
+	// Done: drop the checkpoint, so the next run starts from the beginning and retries any
+	// files that failed to analyze
+	_ = props.Delete(consts.GainAnalysisKey)
+	log.Info(ctx, "Gain analysis finished", "processed", a.processed.Load())
+}
+
+func (a *analysis) processBatch(ctx context.Context, batch model.MediaFiles) {
+	var g errgroup.Group
+	g.SetLimit(numWorkers)
+	for i := range batch {
+		mf := batch[i]
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+			m, err := a.analyzer.Measure(ctx, mf.Path)
+			if err != nil {
+				log.Warn(ctx, "Error measuring loudness", "path", mf.Path, err)
+				a.setLastError(err)
+				return nil
+			}
+			if err := a.ds.MediaFile(ctx).StoreComputedGain(mf.ID, m.Gain, m.Peak); err != nil {
+				log.Error(ctx, "Error storing computed gain", "id", mf.ID, err)
+				a.setLastError(err)
+				return nil
+			}
+			a.processed.Add(1)
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
+
+func (a *analysis) setLastError(err error) {
+	a.lock.Lock()
+	a.lastErr = err
+	a.lock.Unlock()
+}
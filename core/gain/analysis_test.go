@@ -0,0 +1,122 @@
+package gain
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeAnalyzer struct {
+	mu       sync.Mutex
+	measured []string
+	failFor  string
+	result   Measurement
+}
+
+func (f *fakeAnalyzer) Measure(_ context.Context, path string) (Measurement, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if path == f.failFor {
+		return Measurement{}, errors.New("decode error")
+	}
+	f.measured = append(f.measured, path)
+	return f.result, nil
+}
+
+func (f *fakeAnalyzer) paths() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.measured...)
+}
+
+var _ = Describe("Analysis", func() {
+	var ds *tests.MockDataStore
+	var mfRepo *tests.MockMediaFileRepo
+	var analyzer *fakeAnalyzer
+	var job *analysis
+	ctx := context.Background()
+
+	waitForCompletion := func() {
+		Eventually(func() bool { return job.running.Load() }).Should(BeFalse())
+	}
+
+	BeforeEach(func() {
+		mfRepo = tests.CreateMockMediaFileRepo()
+		mfRepo.SetData(model.MediaFiles{
+			{ID: "1", Path: "/music/one.mp3"},
+			{ID: "2", Path: "/music/two.mp3", RgTrackGain: -2.1, RgTrackPeak: 0.98},
+			{ID: "3", Path: "/music/three.mp3", Missing: true},
+			{ID: "4", Path: "/music/four.mp3"},
+		})
+		ds = &tests.MockDataStore{MockedMediaFile: mfRepo}
+		analyzer = &fakeAnalyzer{result: Measurement{Gain: -1.5, Peak: 0.9}}
+		job = newAnalysis(ds, analyzer)
+	})
+
+	It("computes gain for files without ReplayGain values only", func() {
+		Expect(job.Start(ctx)).To(Succeed())
+		waitForCompletion()
+
+		Expect(analyzer.paths()).To(ConsistOf("/music/one.mp3", "/music/four.mp3"))
+		mf, _ := mfRepo.Get("1")
+		Expect(mf.RgTrackGainComputed).To(Equal(-1.5))
+		Expect(mf.RgTrackPeakComputed).To(Equal(0.9))
+		mf, _ = mfRepo.Get("2")
+		Expect(mf.RgTrackGainComputed).To(BeZero())
+	})
+
+	It("clears the checkpoint when it finishes", func() {
+		Expect(job.Start(ctx)).To(Succeed())
+		waitForCompletion()
+
+		_, err := ds.Property(ctx).Get(consts.GainAnalysisKey)
+		Expect(err).To(MatchError(model.ErrNotFound))
+	})
+
+	It("resumes from the last processed id", func() {
+		Expect(ds.Property(ctx).Put(consts.GainAnalysisKey, "1")).To(Succeed())
+
+		Expect(job.Start(ctx)).To(Succeed())
+		waitForCompletion()
+
+		Expect(analyzer.paths()).To(ConsistOf("/music/four.mp3"))
+	})
+
+	It("keeps going when a file fails to analyze, and reports the error", func() {
+		analyzer.failFor = "/music/one.mp3"
+
+		Expect(job.Start(ctx)).To(Succeed())
+		waitForCompletion()
+
+		Expect(analyzer.paths()).To(ConsistOf("/music/four.mp3"))
+		status, err := job.Status(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.Running).To(BeFalse())
+		Expect(status.Processed).To(Equal(int64(1)))
+		Expect(status.LastError).To(ContainSubstring("decode error"))
+	})
+
+	It("does not start a second run while one is in progress", func() {
+		job.running.Store(true)
+		Expect(job.Start(ctx)).To(MatchError(ErrAlreadyRunning))
+	})
+
+	It("reports the number of files still missing gain values", func() {
+		status, err := job.Status(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.Remaining).To(Equal(int64(2)))
+
+		Expect(job.Start(ctx)).To(Succeed())
+		waitForCompletion()
+
+		status, err = job.Status(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.Remaining).To(BeZero())
+	})
+})
@@ -0,0 +1,76 @@
+package gain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+
+	"github.com/navidrome/navidrome/core/ffmpeg"
+	"github.com/navidrome/navidrome/log"
+)
+
+// Measurement is the result of analyzing one file: the ReplayGain 2.0 track gain in dB
+// (relative to the -18 LUFS reference) and the sample peak in linear scale
+type Measurement struct {
+	Gain float64
+	Peak float64
+}
+
+// Analyzer measures the loudness of a single audio file. The default implementation decodes
+// the file with ffmpeg; tests plug a fake to avoid the external dependency
+type Analyzer interface {
+	Measure(ctx context.Context, path string) (Measurement, error)
+}
+
+// replayGainReference is the target loudness of the ReplayGain 2.0 spec, in LUFS
+const replayGainReference = -18.0
+
+type ffmpegAnalyzer struct {
+	ffmpeg ffmpeg.FFmpeg
+}
+
+func (a *ffmpegAnalyzer) Measure(ctx context.Context, path string) (Measurement, error) {
+	cmdPath, err := a.ffmpeg.CmdPath()
+	if err != nil {
+		return Measurement{}, err
+	}
+	args := []string{cmdPath, "-hide_banner", "-nostats", "-i", path,
+		"-map", "0:a:0", "-af", "ebur128=peak=sample", "-f", "null", "-"}
+	log.Trace(ctx, "Executing ffmpeg command", "args", args)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) // #nosec
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return Measurement{}, fmt.Errorf("analyzing %s: %w: %s", path, err, string(output))
+	}
+	return parseEBUR128(string(output))
+}
+
+var (
+	integratedRx = regexp.MustCompile(`I:\s+(-?[\d.]+) LUFS`)
+	samplePeakRx = regexp.MustCompile(`Peak:\s+(-?[\d.]+) dBFS`)
+)
+
+// parseEBUR128 extracts the integrated loudness and sample peak from the summary the ebur128
+// filter prints when the stream ends, and converts them to ReplayGain values
+func parseEBUR128(output string) (Measurement, error) {
+	integrated := integratedRx.FindAllStringSubmatch(output, -1)
+	peak := samplePeakRx.FindAllStringSubmatch(output, -1)
+	if len(integrated) == 0 || len(peak) == 0 {
+		return Measurement{}, fmt.Errorf("no ebur128 summary in ffmpeg output")
+	}
+	lufs, err := strconv.ParseFloat(integrated[len(integrated)-1][1], 64)
+	if err != nil {
+		return Measurement{}, fmt.Errorf("parsing integrated loudness: %w", err)
+	}
+	peakDb, err := strconv.ParseFloat(peak[len(peak)-1][1], 64)
+	if err != nil {
+		return Measurement{}, fmt.Errorf("parsing sample peak: %w", err)
+	}
+	return Measurement{
+		Gain: replayGainReference - lufs,
+		Peak: math.Pow(10, peakDb/20),
+	}, nil
+}
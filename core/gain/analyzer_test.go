@@ -0,0 +1,44 @@
+package gain
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const ebur128Output = `
+[Parsed_ebur128_0 @ 0x55e9d1234560] Summary:
+
+  Integrated loudness:
+    I:         -23.4 LUFS
+    Threshold: -33.7 LUFS
+
+  Loudness range:
+    LRA:         6.7 LU
+    Threshold: -43.6 LUFS
+    LRA low:   -27.8 LUFS
+    LRA high:  -21.1 LUFS
+
+  Sample peak:
+    Peak:       -1.4 dBFS
+`
+
+var _ = Describe("parseEBUR128", func() {
+	It("converts the summary to ReplayGain values", func() {
+		m, err := parseEBUR128(ebur128Output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m.Gain).To(BeNumerically("~", 5.4, 0.001))
+		Expect(m.Peak).To(BeNumerically("~", 0.851, 0.001))
+	})
+
+	It("uses the last summary when the output contains intermediate frame logs", func() {
+		output := "  I:         -10.0 LUFS\n  Peak:        0.0 dBFS\n" + ebur128Output
+		m, err := parseEBUR128(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m.Gain).To(BeNumerically("~", 5.4, 0.001))
+	})
+
+	It("fails when the output has no summary", func() {
+		_, err := parseEBUR128("some unrelated ffmpeg error")
+		Expect(err).To(MatchError(ContainSubstring("no ebur128 summary")))
+	})
+})
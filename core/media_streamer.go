@@ -2,14 +2,17 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"mime"
+	"io/fs"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/conf/mime"
 	"github.com/navidrome/navidrome/consts"
 	"github.com/navidrome/navidrome/core/ffmpeg"
 	"github.com/navidrome/navidrome/log"
@@ -21,6 +24,7 @@ import (
 type MediaStreamer interface {
 	NewStream(ctx context.Context, id string, reqFormat string, reqBitRate int, offset int) (*Stream, error)
 	DoStream(ctx context.Context, mf *model.MediaFile, reqFormat string, reqBitRate int, reqOffset int) (*Stream, error)
+	ResolveStreamSource(ctx context.Context, mediaFileID string) (string, error)
 }
 
 type TranscodingCache cache.FileCache
@@ -56,6 +60,22 @@ func (ms *mediaStreamer) NewStream(ctx context.Context, id string, reqFormat str
 	return ms.DoStream(ctx, mf, reqFormat, reqBitRate, reqOffset)
 }
 
+// ResolveStreamSource returns the path to be used as the source of a stream for the given media file.
+// If the file referenced by the DB is not accessible anymore (ex: the library was moved or the file
+// was deleted outside Navidrome), it returns model.ErrSourceMissing, so callers can map it to a
+// friendlier error instead of a raw "file not found".
+func (ms *mediaStreamer) ResolveStreamSource(ctx context.Context, mediaFileID string) (string, error) {
+	mf, err := ms.ds.MediaFile(ctx).Get(mediaFileID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(mf.Path); err != nil {
+		log.Warn(ctx, "Media file path not accessible", "id", mf.ID, "path", mf.Path, err)
+		return "", fmt.Errorf("%w: %s", model.ErrSourceMissing, mf.Path)
+	}
+	return mf.Path, nil
+}
+
 func (ms *mediaStreamer) DoStream(ctx context.Context, mf *model.MediaFile, reqFormat string, reqBitRate int, reqOffset int) (*Stream, error) {
 	var format string
 	var bitRate int
@@ -75,6 +95,9 @@ func (ms *mediaStreamer) DoStream(ctx context.Context, mf *model.MediaFile, reqF
 			"originalBitrate", mf.BitRate, "originalFormat", mf.Suffix,
 			"selectedBitrate", bitRate, "selectedFormat", format)
 		f, err := os.Open(mf.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", model.ErrSourceMissing, mf.Path)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -119,12 +142,12 @@ type Stream struct {
 }
 
 func (s *Stream) Seekable() bool      { return s.Seeker != nil }
-func (s *Stream) Duration() float32   { return s.mf.Duration }
+func (s *Stream) Duration() float32   { return s.mf.Duration() }
 func (s *Stream) ContentType() string { return mime.TypeByExtension("." + s.format) }
 func (s *Stream) Name() string        { return s.mf.Title + "." + s.format }
 func (s *Stream) ModTime() time.Time  { return s.mf.UpdatedAt }
 func (s *Stream) EstimatedContentLength() int {
-	return int(s.mf.Duration * float32(s.bitRate) / 8 * 1024)
+	return int(s.mf.Duration() * float32(s.bitRate) / 8 * 1024)
 }
 
 // TODO This function deserves some love (refactoring)
@@ -133,7 +156,7 @@ func selectTranscodingOptions(ctx context.Context, ds model.DataStore, mf *model
 	if reqFormat == "raw" {
 		return format, 0
 	}
-	if reqFormat == mf.Suffix && reqBitRate == 0 {
+	if canStreamRaw(mf, reqFormat) && reqBitRate == 0 {
 		bitRate = mf.BitRate
 		return format, bitRate
 	}
@@ -172,13 +195,35 @@ func selectTranscodingOptions(ctx context.Context, ds model.DataStore, mf *model
 			bitRate = t.DefaultBitRate
 		}
 	}
-	if format == mf.Suffix && bitRate >= mf.BitRate {
+	if canStreamRaw(mf, format) && bitRate >= mf.BitRate {
 		format = "raw"
 		bitRate = 0
 	}
 	return format, bitRate
 }
 
+// containerCodecs lists, for each container suffix that can hold more than one codec, the
+// codecs clients asking for that format are assumed to handle
+var containerCodecs = map[string][]string{
+	"m4a": {"aac"},
+	"mp4": {"aac"},
+}
+
+// canStreamRaw reports whether the file can be sent as-is when the client asks for the given
+// format. Matching the suffix alone is not enough for ambiguous containers: a client asking
+// for m4a expects AAC, but the same suffix also carries lossless ALAC, which many players
+// cannot decode. Files whose codec could not be determined keep the suffix-only behavior
+func canStreamRaw(mf *model.MediaFile, format string) bool {
+	if format != mf.Suffix {
+		return false
+	}
+	expected, ambiguous := containerCodecs[mf.Suffix]
+	if !ambiguous || mf.Codec == "" {
+		return true
+	}
+	return slices.Contains(expected, mf.Codec)
+}
+
 var (
 	onceTranscodingCache     sync.Once
 	instanceTranscodingCache TranscodingCache
@@ -20,6 +20,20 @@ var _ = Describe("MediaStreamer", func() {
 		ds = &tests.MockDataStore{MockedTranscoding: &tests.MockTranscodingRepo{}}
 	})
 
+	Describe("canStreamRaw", func() {
+		DescribeTable("returns whether the file can be streamed as-is",
+			func(suffix, codec, format string, expected bool) {
+				mf := &model.MediaFile{Suffix: suffix, Codec: codec}
+				Expect(canStreamRaw(mf, format)).To(Equal(expected))
+			},
+			Entry("matching suffix", "mp3", "", "mp3", true),
+			Entry("different suffix", "flac", "", "mp3", false),
+			Entry("AAC in an m4a container", "m4a", "aac", "m4a", true),
+			Entry("ALAC in an m4a container", "m4a", "alac", "m4a", false),
+			Entry("m4a container with unknown codec", "m4a", "", "m4a", true),
+		)
+	})
+
 	Context("selectTranscodingOptions", func() {
 		mf := &model.MediaFile{}
 		Context("player is not configured", func() {
@@ -27,7 +27,7 @@ var _ = Describe("MediaStreamer", func() {
 		conf.Server.TranscodingCacheSize = "100MB"
 		ds = &tests.MockDataStore{MockedTranscoding: &tests.MockTranscodingRepo{}}
 		ds.MediaFile(ctx).(*tests.MockMediaFileRepo).SetData(model.MediaFiles{
-			{ID: "123", Path: "tests/fixtures/test.mp3", Suffix: "mp3", BitRate: 128, Duration: 257.0},
+			{ID: "123", Path: "tests/fixtures/test.mp3", Suffix: "mp3", BitRate: 128, DurationMs: 257000},
 		})
 		testCache := core.NewTranscodingCache()
 		Eventually(func() bool { return testCache.Available(context.TODO()) }).Should(BeTrue())
@@ -37,6 +37,23 @@ var _ = Describe("MediaStreamer", func() {
 		_ = os.RemoveAll(conf.Server.CacheFolder)
 	})
 
+	Context("ResolveStreamSource", func() {
+		It("returns the path when the file is accessible", func() {
+			Expect(streamer.ResolveStreamSource(ctx, "123")).To(Equal("tests/fixtures/test.mp3"))
+		})
+		It("returns ErrSourceMissing when the path is stale", func() {
+			ds.MediaFile(ctx).(*tests.MockMediaFileRepo).SetData(model.MediaFiles{
+				{ID: "124", Path: "tests/fixtures/DOES_NOT_EXIST.mp3", Suffix: "mp3"},
+			})
+			_, err := streamer.ResolveStreamSource(ctx, "124")
+			Expect(err).To(MatchError(model.ErrSourceMissing))
+		})
+		It("returns ErrNotFound when the media file is not in the DB", func() {
+			_, err := streamer.ResolveStreamSource(ctx, "666")
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+	})
+
 	Context("NewStream", func() {
 		It("returns a seekable stream if format is 'raw'", func() {
 			s, err := streamer.NewStream(ctx, "123", "raw", 0, 0)
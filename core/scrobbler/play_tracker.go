@@ -79,7 +79,7 @@ func (p *playTracker) NowPlaying(ctx context.Context, playerId string, playerNam
 		PlayerName: playerName,
 	}
 
-	ttl := time.Duration(int(mf.Duration)+5) * time.Second
+	ttl := time.Duration(mf.DurationMs)*time.Millisecond + 5*time.Second
 	_ = p.playMap.AddWithTTL(playerId, info, ttl)
 	player, _ := request.PlayerFrom(ctx)
 	if player.ScrobbleEnabled {
@@ -159,7 +159,10 @@ func (p *playTracker) incPlay(ctx context.Context, track *model.MediaFile, times
 			return err
 		}
 		err = tx.Artist(ctx).IncPlayCount(track.ArtistID, timestamp)
-		return err
+		if err != nil {
+			return err
+		}
+		return tx.PlayEvent(ctx).Record(track.ID, timestamp)
 	})
 }
 
@@ -48,7 +48,7 @@ var _ = Describe("PlayTracker", func() {
 			ArtistID:       "ar-1",
 			AlbumArtist:    "Track AlbumArtist",
 			TrackNumber:    1,
-			Duration:       180,
+			DurationMs:     180000,
 			MbzRecordingID: "mbz-123",
 		}
 		_ = ds.MediaFile(ctx).Put(&track)
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+// Optional capabilities a MusicFS may support, discovered via type assertion. Backends
+// only implement what their underlying storage can actually deliver: local disk can
+// watch for changes and report birth times, object stores expose content hashes (ETags),
+// and a bare SFTP mount supports neither
+
+// Watcher is implemented by backends that can push change notifications, so the scanner
+// doesn't need to traverse the whole tree to find what changed
+type Watcher interface {
+	// Watch emits the paths of changed files/folders until ctx is cancelled
+	Watch(ctx context.Context) (<-chan string, error)
+}
+
+// ContentHasher is implemented by backends that expose a content hash (ex: an ETag)
+// cheaper or more reliable than mtime comparison
+type ContentHasher interface {
+	ContentHash(path string) (string, error)
+}
+
+// BirthTimer is implemented by backends that may know the on-disk creation time of
+// files, letting callers (and the UI) hide birth times when the storage can't track them
+type BirthTimer interface {
+	BirthTimeSupported() bool
+}
+
+// ChangeStrategy is how the scanner decides whether an entry changed since the last scan
+type ChangeStrategy string
+
+const (
+	StrategyWatch ChangeStrategy = "watch"
+	StrategyHash  ChangeStrategy = "hash"
+	StrategyMTime ChangeStrategy = "mtime"
+)
+
+// ChangeStrategyFor picks the best change-detection strategy the backend supports:
+// watch events when available, content hashes next, plain mtime comparison otherwise.
+// The choice is logged once per library, at scan start
+func ChangeStrategyFor(fsys MusicFS, libraryPath string) ChangeStrategy {
+	strategy := StrategyMTime
+	if _, ok := fsys.(Watcher); ok {
+		strategy = StrategyWatch
+	} else if _, ok := fsys.(ContentHasher); ok {
+		strategy = StrategyHash
+	}
+	log.Info("Storage change detection strategy", "library", libraryPath, "strategy", strategy)
+	return strategy
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"testing/fstest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeStorage implements every optional capability, so each strategy path can be
+// exercised without a real backend
+type fakeStorage struct {
+	fstest.MapFS
+	watched chan string
+}
+
+func (f *fakeStorage) ChangeSignal(string) (string, error) { return "signal", nil }
+func (f *fakeStorage) ContentHash(string) (string, error)  { return "hash", nil }
+func (f *fakeStorage) BirthTimeSupported() bool            { return true }
+func (f *fakeStorage) Watch(context.Context) (<-chan string, error) {
+	return f.watched, nil
+}
+
+// hashOnlyStorage has hashes but no watch support, like an object store
+type hashOnlyStorage struct {
+	fstest.MapFS
+}
+
+func (hashOnlyStorage) ChangeSignal(string) (string, error) { return "signal", nil }
+func (hashOnlyStorage) ContentHash(string) (string, error)  { return "hash", nil }
+
+var _ = Describe("storage capabilities", func() {
+	Describe("ChangeStrategyFor", func() {
+		It("prefers watch events when the backend pushes them", func() {
+			Expect(ChangeStrategyFor(&fakeStorage{}, "/music")).To(Equal(StrategyWatch))
+		})
+
+		It("uses content hashes when there are no watch events", func() {
+			Expect(ChangeStrategyFor(hashOnlyStorage{}, "/music")).To(Equal(StrategyHash))
+		})
+
+		It("falls back to mtime comparison for plain backends", func() {
+			fsys, err := For("/music")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ChangeStrategyFor(fsys, "/music")).To(Equal(StrategyMTime))
+		})
+
+		It("treats WebDAV as hash-capable via its ETags", func() {
+			fsys, err := For("webdav://example.test/Music")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ChangeStrategyFor(fsys, "webdav://example.test/Music")).To(Equal(StrategyHash))
+		})
+	})
+
+	Describe("BirthTimer", func() {
+		It("is implemented by the local backend", func() {
+			fsys, err := For(GinkgoT().TempDir())
+			Expect(err).ToNot(HaveOccurred())
+			bt, ok := fsys.(BirthTimer)
+			Expect(ok).To(BeTrue())
+			// The answer depends on the filesystem the suite runs on, it only has to be consistent
+			Expect(bt.BirthTimeSupported()).To(Equal(bt.BirthTimeSupported()))
+		})
+
+		It("is not implemented by the WebDAV backend", func() {
+			fsys, err := For("webdav://example.test/Music")
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := fsys.(BirthTimer)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	It("delivers watch events from the fake storage", func() {
+		fake := &fakeStorage{watched: make(chan string, 1)}
+		events, err := fake.Watch(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		fake.watched <- "Album/track.mp3"
+		Expect(<-events).To(Equal("Album/track.mp3"))
+	})
+})
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/djherbis/times"
+)
+
+const LocalSchemeID = "file"
+
+func init() {
+	Register(LocalSchemeID, func(u *url.URL) (MusicFS, error) {
+		return localFS{FS: os.DirFS(u.Path), root: u.Path}, nil
+	})
+}
+
+type localFS struct {
+	fs.FS
+	root string
+}
+
+// ChangeSignal uses the mtime (with sub-second precision where the filesystem keeps it),
+// matching what the scanner already compares against the DB
+func (l localFS) ChangeSignal(path string) (string, error) {
+	info, err := fs.Stat(l.FS, path)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(info.ModTime().UnixNano(), 10), nil
+}
+
+// BirthTimeSupported reports whether the filesystem under root tracks file creation
+// times, so the UI can hide the birth time column on filesystems that don't
+func (l localFS) BirthTimeSupported() bool {
+	info, err := os.Stat(l.root)
+	if err != nil {
+		return false
+	}
+	return times.Get(info).HasBirthTime()
+}
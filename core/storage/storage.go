@@ -0,0 +1,58 @@
+// Package storage abstracts where a library's music files live. Backends register
+// themselves by URL scheme, and plain paths (no scheme) resolve to the local disk
+// backend, so existing library paths keep working unchanged. A remote backend (ex: an
+// s3:// object store) plugs in by implementing MusicFS and calling Register from an
+// init(); none ships yet, as the client SDKs are not part of the module
+package storage
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strings"
+)
+
+// MusicFS is the filesystem view a storage backend exposes to the scanner
+type MusicFS interface {
+	fs.FS
+
+	// ChangeSignal returns an opaque value that changes whenever the file at path
+	// changes: the mtime for local files, something like an ETag for remote backends
+	// where mtimes are unreliable
+	ChangeSignal(path string) (string, error)
+}
+
+// Constructor builds a MusicFS rooted at the given URL. For the local backend the root
+// is the URL path; remote backends typically use the host as a bucket/share name and
+// the path as a prefix
+type Constructor func(u *url.URL) (MusicFS, error)
+
+var registry = map[string]Constructor{}
+
+func Register(scheme string, c Constructor) {
+	registry[scheme] = c
+}
+
+// For resolves the backend for a library path. Paths without a scheme (or with a
+// single-letter one, i.e. a Windows drive) are served by the local backend
+func For(libraryPath string) (MusicFS, error) {
+	u, err := url.Parse(libraryPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid library path %q: %w", libraryPath, err)
+	}
+	scheme := u.Scheme
+	if len(scheme) <= 1 {
+		scheme = LocalSchemeID
+		u = &url.URL{Scheme: scheme, Path: libraryPath}
+	}
+	c, ok := registry[scheme]
+	if !ok {
+		var schemes []string
+		for s := range registry {
+			schemes = append(schemes, s)
+		}
+		return nil, fmt.Errorf("unsupported storage scheme %q, registered schemes: %s",
+			scheme, strings.Join(schemes, ", "))
+	}
+	return c(u)
+}
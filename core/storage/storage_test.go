@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestStorage(t *testing.T) {
+	tests.Init(t, false)
+	log.SetLevel(log.LevelFatal)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Storage Suite")
+}
+
+// fakeObjectFS stands in for a remote backend, using the object ETag as the change signal
+type fakeObjectFS struct {
+	fstest.MapFS
+	bucket string
+	prefix string
+	etags  map[string]string
+}
+
+func (f fakeObjectFS) ChangeSignal(path string) (string, error) {
+	return f.etags[path], nil
+}
+
+var _ = Describe("storage registry", func() {
+	var lastURL *url.URL
+
+	BeforeEach(func() {
+		Register("s3", func(u *url.URL) (MusicFS, error) {
+			lastURL = u
+			return fakeObjectFS{
+				MapFS:  fstest.MapFS{"song.mp3": &fstest.MapFile{Data: []byte("x")}},
+				bucket: u.Host,
+				prefix: u.Path,
+				etags:  map[string]string{"song.mp3": `"abc123"`},
+			}, nil
+		})
+		DeferCleanup(func() { delete(registry, "s3") })
+	})
+
+	It("resolves scheme-less library paths to the local backend", func() {
+		fsys, err := For("/music/library")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fsys).To(BeAssignableToTypeOf(localFS{}))
+		Expect(fsys.(localFS).root).To(Equal("/music/library"))
+	})
+
+	It("routes URLs to the backend registered for their scheme", func() {
+		fsys, err := For("s3://my-bucket/some/prefix")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lastURL.Host).To(Equal("my-bucket"))
+		Expect(lastURL.Path).To(Equal("/some/prefix"))
+
+		signal, err := fsys.ChangeSignal("song.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signal).To(Equal(`"abc123"`))
+	})
+
+	It("rejects unknown schemes, listing the registered ones", func() {
+		_, err := For("gopher://somewhere/music")
+		Expect(err).To(MatchError(ContainSubstring(`unsupported storage scheme "gopher"`)))
+		Expect(err).To(MatchError(ContainSubstring("file")))
+	})
+
+	It("uses the mtime as the local change signal", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), []byte("x"), 0600)).To(Succeed())
+
+		fsys, err := For(dir)
+		Expect(err).ToNot(HaveOccurred())
+		before, err := fsys.ChangeSignal("track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+
+		older := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(filepath.Join(dir, "track.mp3"), older, older)).To(Succeed())
+		after, err := fsys.ChangeSignal("track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(after).ToNot(Equal(before))
+	})
+})
@@ -0,0 +1,115 @@
+package storagetest
+
+import (
+	"encoding/json"
+	"io/fs"
+	"maps"
+	"testing/fstest"
+	"time"
+)
+
+// Fake audio file constructors. The file content is the JSON-encoded tag map, and the
+// special _size/_birthTime entries feed the fs.FileInfo returned by FakeFS, so tests
+// exercising size- or time-based scanner logic can declare the values they need
+
+const (
+	fakeFileInfoSize      = "_size"
+	fakeFileInfoBirthTime = "_birthTime"
+)
+
+// MP3 builds a fake mp3 file. Caller tag maps are merged over the defaults, and the
+// size is computed after the merge, so overriding duration or bitrate also changes it
+func MP3(tags ...map[string]any) *fstest.MapFile { return audioFile("mp3", 320, tags...) }
+
+// WAV builds a fake uncompressed wav file (16bit/44.1kHz stereo rate)
+func WAV(tags ...map[string]any) *fstest.MapFile { return audioFile("wav", 1411, tags...) }
+
+// FLAC builds a fake flac file, sized at a typical lossless compression rate
+func FLAC(tags ...map[string]any) *fstest.MapFile { return audioFile("flac", 1000, tags...) }
+
+// OGG builds a fake ogg file
+func OGG(tags ...map[string]any) *fstest.MapFile { return audioFile("ogg", 256, tags...) }
+
+// BirthTime declares the file creation time, for merging into a constructor call
+func BirthTime(t time.Time) map[string]any {
+	return map[string]any{fakeFileInfoBirthTime: t.Format(time.RFC3339)}
+}
+
+// Size declares an explicit file size, overriding the computed one
+func Size(size int64) map[string]any {
+	return map[string]any{fakeFileInfoSize: size}
+}
+
+func audioFile(suffix string, bitRate int, tags ...map[string]any) *fstest.MapFile {
+	merged := map[string]any{
+		"suffix":   suffix,
+		"bitrate":  bitRate,
+		"duration": 3.0,
+	}
+	for _, t := range tags {
+		maps.Copy(merged, t)
+	}
+	// Compute the size only after every tag map is merged, so caller overrides of
+	// duration/bitrate (or an explicit Size) are reflected in it
+	if _, ok := merged[fakeFileInfoSize]; !ok {
+		merged[fakeFileInfoSize] = int64(asFloat(merged["duration"]) * asFloat(merged["bitrate"]) * 1000 / 8)
+	}
+	data, _ := json.Marshal(merged)
+	return &fstest.MapFile{Data: data}
+}
+
+// fakeFileInfo derives FileInfo values from the tag map, after the JSON round trip the
+// file content goes through (which turns every number into a float64)
+type fakeFileInfo struct {
+	fs.FileInfo
+	tags map[string]any
+}
+
+func (i fakeFileInfo) Size() int64 {
+	return int64(asFloat(i.tags[fakeFileInfoSize]))
+}
+
+func (i fakeFileInfo) BirthTime() time.Time {
+	if v, ok := i.tags[fakeFileInfoBirthTime].(string); ok {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return i.ModTime()
+}
+
+// asFloat converts the numeric types a tag value can arrive as, whether it was just
+// declared (int, int64) or came back from a JSON round trip (float64)
+func asFloat(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// fakeInfoFor decodes the tag map of a fake audio file. Returns nil for files not
+// created by the constructors above
+func fakeInfoFor(file *fstest.MapFile, info fs.FileInfo) fs.FileInfo {
+	var tags map[string]any
+	if err := json.Unmarshal(file.Data, &tags); err != nil {
+		return nil
+	}
+	if _, ok := tags[fakeFileInfoSize]; !ok {
+		return nil
+	}
+	return fakeFileInfo{FileInfo: info, tags: tags}
+}
+
+// fakeFile wraps a MapFS file so Stat reports the declared size instead of the length
+// of the JSON content
+type fakeFile struct {
+	fs.File
+	info fs.FileInfo
+}
+
+func (f fakeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
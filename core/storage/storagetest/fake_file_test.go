@@ -0,0 +1,68 @@
+package storagetest
+
+import (
+	"io/fs"
+	"testing/fstest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("fake audio files", func() {
+	var fakeFS *FakeFS
+
+	statFor := func(file *fstest.MapFile) fs.FileInfo {
+		fakeFS = NewFakeFS()
+		fakeFS.SetFiles(fstest.MapFS{"album/track.mp3": file})
+		info, err := fs.Stat(fakeFS, "album/track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		return info
+	}
+
+	DescribeTable("computes a realistic size from duration and bitrate",
+		func(file *fstest.MapFile, expected int64) {
+			Expect(statFor(file).Size()).To(Equal(expected))
+		},
+		Entry("MP3", MP3(), int64(3*320*1000/8)),
+		Entry("WAV", WAV(), int64(3*1411*1000/8)),
+		Entry("FLAC", FLAC(), int64(3*1000*1000/8)),
+		Entry("OGG", OGG(), int64(3*256*1000/8)),
+	)
+
+	It("recomputes the size after the caller's tags are merged", func() {
+		info := statFor(MP3(map[string]any{"duration": 10.0, "bitrate": 128}))
+		Expect(info.Size()).To(Equal(int64(10 * 128 * 1000 / 8)))
+	})
+
+	It("keeps an explicitly declared size through the JSON round trip", func() {
+		info := statFor(MP3(Size(123456)))
+		Expect(info.Size()).To(Equal(int64(123456)))
+	})
+
+	It("reports the ModTime of the map entry", func() {
+		old := time.Now().Add(-time.Hour).Truncate(time.Second)
+		file := MP3()
+		file.ModTime = old
+		Expect(statFor(file).ModTime()).To(Equal(old))
+	})
+
+	It("reports the declared BirthTime", func() {
+		birth := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+		info := statFor(MP3(BirthTime(birth)))
+		Expect(info.(fakeFileInfo).BirthTime()).To(Equal(birth))
+	})
+
+	It("falls back to ModTime when no BirthTime was declared", func() {
+		old := time.Now().Add(-time.Hour).Truncate(time.Second)
+		file := MP3()
+		file.ModTime = old
+		info := statFor(file)
+		Expect(info.(fakeFileInfo).BirthTime()).To(Equal(old))
+	})
+
+	It("leaves plain files untouched", func() {
+		plain := &fstest.MapFile{Data: []byte("not json")}
+		Expect(statFor(plain).Size()).To(Equal(int64(len("not json"))))
+	})
+})
@@ -0,0 +1,195 @@
+// Package storagetest provides an in-memory storage.MusicFS for scanner and storage
+// tests, with helpers to mutate the tree (touch, move, remove, inject errors) while
+// keeping the directory-timestamp invariants of a real filesystem: a directory's mtime
+// is always at least as new as its latest changed child
+package storagetest
+
+import (
+	"io/fs"
+	"path"
+	"strconv"
+	"testing/fstest"
+	"time"
+
+	"github.com/navidrome/navidrome/core/storage"
+)
+
+type FakeFS struct {
+	fstest.MapFS
+	errors map[string]error
+}
+
+var _ storage.MusicFS = (*FakeFS)(nil)
+
+func NewFakeFS() *FakeFS {
+	return &FakeFS{MapFS: fstest.MapFS{}, errors: map[string]error{}}
+}
+
+// SetFiles replaces the whole tree, stamping unstamped files with the current time and
+// materializing parent directory entries with their timestamps
+func (f *FakeFS) SetFiles(files fstest.MapFS) {
+	f.MapFS = files
+	now := time.Now()
+	for _, file := range f.MapFS {
+		if file.ModTime.IsZero() {
+			file.ModTime = now
+		}
+	}
+	f.createDirTimestamps()
+}
+
+// Touch updates the mtime of an existing file (default: now) and propagates it to the
+// parent directories
+func (f *FakeFS) Touch(filePath string, when ...time.Time) error {
+	file, ok := f.MapFS[filePath]
+	if !ok {
+		return fs.ErrNotExist
+	}
+	t := time.Now()
+	if len(when) > 0 {
+		t = when[0]
+	}
+	file.ModTime = t
+	f.touchDir(path.Dir(filePath), t)
+	return nil
+}
+
+// Move renames a file preserving its content, updating the mtime of both the old and
+// the new parent directory, like a rename(2) would
+func (f *FakeFS) Move(oldPath, newPath string) error {
+	file, ok := f.MapFS[oldPath]
+	if !ok {
+		return fs.ErrNotExist
+	}
+	delete(f.MapFS, oldPath)
+	f.MapFS[newPath] = file
+	now := time.Now()
+	f.touchDir(path.Dir(oldPath), now)
+	f.touchDir(path.Dir(newPath), now)
+	return nil
+}
+
+// Remove deletes a file and refreshes the parent directory mtime
+func (f *FakeFS) Remove(filePath string) error {
+	if _, ok := f.MapFS[filePath]; !ok {
+		return fs.ErrNotExist
+	}
+	delete(f.MapFS, filePath)
+	f.touchDir(path.Dir(filePath), time.Now())
+	return nil
+}
+
+// RmGlob deletes all files matching the pattern (path.Match syntax), refreshing the
+// mtime of each affected parent directory
+func (f *FakeFS) RmGlob(pattern string) error {
+	now := time.Now()
+	for p := range f.MapFS {
+		match, err := path.Match(pattern, p)
+		if err != nil {
+			return err
+		}
+		if match {
+			delete(f.MapFS, p)
+			f.touchDir(path.Dir(p), now)
+		}
+	}
+	return nil
+}
+
+// SetError makes Open and ChangeSignal fail with err for every path matching the glob,
+// simulating transient I/O errors. A nil err clears the injection
+func (f *FakeFS) SetError(pathGlob string, err error) {
+	if err == nil {
+		delete(f.errors, pathGlob)
+		return
+	}
+	f.errors[pathGlob] = err
+}
+
+func (f *FakeFS) Open(name string) (fs.File, error) {
+	if err := f.injectedError(name); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	file, err := f.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	// Files built by the audio constructors report their declared size and times
+	if entry, ok := f.MapFS[name]; ok && !entry.Mode.IsDir() {
+		info, statErr := file.Stat()
+		if statErr == nil {
+			if fakeInfo := fakeInfoFor(entry, info); fakeInfo != nil {
+				return fakeFile{File: file, info: fakeInfo}, nil
+			}
+		}
+	}
+	return file, nil
+}
+
+// Stat mirrors Open's handling of fake audio files, as fs.Stat goes through the
+// embedded MapFS.Stat and would otherwise report the JSON content length as the size
+func (f *FakeFS) Stat(name string) (fs.FileInfo, error) {
+	if err := f.injectedError(name); err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	info, err := f.MapFS.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := f.MapFS[name]; ok && !entry.Mode.IsDir() {
+		if fakeInfo := fakeInfoFor(entry, info); fakeInfo != nil {
+			return fakeInfo, nil
+		}
+	}
+	return info, nil
+}
+
+func (f *FakeFS) ChangeSignal(name string) (string, error) {
+	if err := f.injectedError(name); err != nil {
+		return "", err
+	}
+	info, err := fs.Stat(f.MapFS, name)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(info.ModTime().UnixNano(), 10), nil
+}
+
+func (f *FakeFS) injectedError(name string) error {
+	for glob, err := range f.errors {
+		if match, _ := path.Match(glob, name); match {
+			return err
+		}
+	}
+	return nil
+}
+
+// createDirTimestamps gives every parent directory an explicit entry stamped with its
+// newest child, mirroring how mtimes end up on a freshly created real tree
+func (f *FakeFS) createDirTimestamps() {
+	for p, file := range f.MapFS {
+		if file.Mode.IsDir() {
+			continue
+		}
+		f.touchDir(path.Dir(p), file.ModTime)
+	}
+}
+
+// touchDir raises the mtime of dir and all its ancestors to t. Directory entries are
+// created on demand, so SetFiles callers don't need to declare them
+func (f *FakeFS) touchDir(dir string, t time.Time) {
+	for {
+		entry, ok := f.MapFS[dir]
+		if !ok {
+			entry = &fstest.MapFile{Mode: fs.ModeDir | 0755}
+			f.MapFS[dir] = entry
+		}
+		if entry.ModTime.Before(t) {
+			entry.ModTime = t
+		}
+		if dir == "." {
+			return
+		}
+		dir = path.Dir(dir)
+	}
+}
@@ -0,0 +1,128 @@
+package storagetest
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestStorageTest(t *testing.T) {
+	tests.Init(t, false)
+	log.SetLevel(log.LevelFatal)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "StorageTest Suite")
+}
+
+var _ = Describe("FakeFS", func() {
+	var fakeFS *FakeFS
+	var old time.Time
+
+	dirModTime := func(dir string) time.Time {
+		info, err := fs.Stat(fakeFS, dir)
+		Expect(err).ToNot(HaveOccurred())
+		return info.ModTime()
+	}
+
+	BeforeEach(func() {
+		old = time.Now().Add(-time.Hour)
+		fakeFS = NewFakeFS()
+		fakeFS.SetFiles(fstest.MapFS{
+			"artist/album/track1.mp3": &fstest.MapFile{Data: []byte("one"), ModTime: old},
+			"artist/album/track2.mp3": &fstest.MapFile{Data: []byte("two"), ModTime: old},
+			"artist/single.mp3":       &fstest.MapFile{Data: []byte("three"), ModTime: old},
+		})
+	})
+
+	It("stamps parent directories with their newest child", func() {
+		Expect(dirModTime("artist/album")).To(Equal(old))
+		Expect(dirModTime("artist")).To(Equal(old))
+		Expect(dirModTime(".")).To(Equal(old))
+	})
+
+	Describe("Touch", func() {
+		It("propagates the new mtime to the parent directories", func() {
+			now := time.Now()
+			Expect(fakeFS.Touch("artist/album/track1.mp3", now)).To(Succeed())
+			Expect(dirModTime("artist/album")).To(Equal(now))
+			Expect(dirModTime("artist")).To(Equal(now))
+		})
+	})
+
+	Describe("Move", func() {
+		It("preserves the content and refreshes both parent directories", func() {
+			Expect(fakeFS.Move("artist/album/track1.mp3", "artist/track1.mp3")).To(Succeed())
+
+			data, err := fs.ReadFile(fakeFS, "artist/track1.mp3")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("one"))
+			_, err = fakeFS.Open("artist/album/track1.mp3")
+			Expect(err).To(MatchError(fs.ErrNotExist))
+
+			Expect(dirModTime("artist/album")).To(BeTemporally(">", old))
+			Expect(dirModTime("artist")).To(BeTemporally(">", old))
+		})
+
+		It("errors on missing sources", func() {
+			Expect(fakeFS.Move("artist/nope.mp3", "artist/yes.mp3")).To(MatchError(fs.ErrNotExist))
+		})
+	})
+
+	Describe("Remove", func() {
+		It("deletes the file and refreshes the parent mtime", func() {
+			Expect(fakeFS.Remove("artist/album/track2.mp3")).To(Succeed())
+
+			_, err := fakeFS.Open("artist/album/track2.mp3")
+			Expect(err).To(MatchError(fs.ErrNotExist))
+			Expect(dirModTime("artist/album")).To(BeTemporally(">", old))
+		})
+	})
+
+	Describe("RmGlob", func() {
+		It("deletes all matches and refreshes their parents", func() {
+			Expect(fakeFS.RmGlob("artist/album/*.mp3")).To(Succeed())
+
+			entries, err := fs.ReadDir(fakeFS, "artist/album")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+			Expect(dirModTime("artist/album")).To(BeTemporally(">", old))
+
+			// Non-matching files stay put
+			_, err = fakeFS.Open("artist/single.mp3")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("SetError", func() {
+		It("injects errors for matching paths only, until cleared", func() {
+			boom := errors.New("disk on fire")
+			fakeFS.SetError("artist/album/*", boom)
+
+			_, err := fakeFS.Open("artist/album/track1.mp3")
+			Expect(err).To(MatchError(boom))
+			_, err = fakeFS.ChangeSignal("artist/album/track1.mp3")
+			Expect(err).To(MatchError(boom))
+			_, err = fakeFS.Open("artist/single.mp3")
+			Expect(err).ToNot(HaveOccurred())
+
+			fakeFS.SetError("artist/album/*", nil)
+			_, err = fakeFS.Open("artist/album/track1.mp3")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	It("reports mtime changes through ChangeSignal", func() {
+		before, err := fakeFS.ChangeSignal("artist/single.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fakeFS.Touch("artist/single.mp3")).To(Succeed())
+		after, err := fakeFS.ChangeSignal("artist/single.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(after).ToNot(Equal(before))
+	})
+})
@@ -0,0 +1,349 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+// webdavFullDownloadCap limits the fallback full download used when a server ignores
+// Range requests, so reading tags from a remote file can't pull gigabytes. Var for tests
+var webdavFullDownloadCap int64 = 256 * 1024 * 1024
+
+func init() {
+	Register("webdav", newWebdavFS("http"))
+	Register("webdavs", newWebdavFS("https"))
+}
+
+func newWebdavFS(httpScheme string) Constructor {
+	return func(u *url.URL) (MusicFS, error) {
+		base := *u
+		base.Scheme = httpScheme
+		base.User = nil
+		base.Path = strings.TrimSuffix(base.Path, "/")
+		w := &webdavFS{base: base, client: http.DefaultClient}
+		if u.User != nil {
+			w.username = u.User.Username()
+			w.password, _ = u.User.Password()
+		}
+		return w, nil
+	}
+}
+
+// webdavFS exposes a WebDAV collection (ex: a Nextcloud Music folder) as an fs.FS.
+// Directory listings come from PROPFIND, and file contents are fetched with Range
+// requests so tag extraction only streams the byte ranges it needs
+type webdavFS struct {
+	base     url.URL
+	client   *http.Client
+	username string
+	password string
+}
+
+func (w *webdavFS) urlFor(name string) string {
+	u := w.base
+	if name != "." {
+		u.Path = u.Path + "/" + name
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+func (w *webdavFS) request(method, name string, depth string) (*http.Response, error) {
+	req, err := http.NewRequest(method, w.urlFor(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	if depth != "" {
+		req.Header.Set("Depth", depth)
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	return w.client.Do(req)
+}
+
+func (w *webdavFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	infos, err := w.propfind(name, "0")
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	info := infos[0]
+	if info.IsDir() {
+		return &webdavDir{fsys: w, name: name, info: info}, nil
+	}
+	return &webdavFile{fsys: w, name: name, info: info}, nil
+}
+
+// propfind lists the resource at name (Depth 0) or the resource and its children
+// (Depth 1), mapping getlastmodified/getcontentlength to the returned FileInfos
+func (w *webdavFS) propfind(name, depth string) ([]*webdavFileInfo, error) {
+	resp, err := w.request("PROPFIND", name, depth)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fs.ErrNotExist
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("PROPFIND returned status %d", resp.StatusCode)
+	}
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("parsing PROPFIND response: %w", err)
+	}
+	if len(ms.Responses) == 0 {
+		return nil, fs.ErrNotExist
+	}
+
+	selfPath := mustPath(w.urlFor(name))
+	var infos []*webdavFileInfo
+	var self *webdavFileInfo
+	for _, r := range ms.Responses {
+		href := mustPath(r.Href)
+		info := &webdavFileInfo{
+			name:  path.Base(strings.TrimSuffix(href, "/")),
+			size:  r.Prop.ContentLength,
+			isDir: r.Prop.ResourceType.Collection != nil,
+			etag:  r.Prop.ETag,
+		}
+		if t, err := http.ParseTime(r.Prop.LastModified); err == nil {
+			info.modTime = t
+		}
+		if strings.TrimSuffix(href, "/") == strings.TrimSuffix(selfPath, "/") {
+			self = info
+		} else {
+			infos = append(infos, info)
+		}
+	}
+	if self == nil {
+		return nil, fs.ErrNotExist
+	}
+	return append([]*webdavFileInfo{self}, infos...), nil
+}
+
+// ContentHash returns the server ETag. Servers that don't expose one (rare, but the
+// property is optional) get an error, making callers fall back to mtime comparison
+func (w *webdavFS) ContentHash(name string) (string, error) {
+	infos, err := w.propfind(name, "0")
+	if err != nil {
+		return "", err
+	}
+	if infos[0].etag == "" {
+		return "", fmt.Errorf("server does not expose an ETag for %q", name)
+	}
+	return infos[0].etag, nil
+}
+
+// ChangeSignal prefers the server ETag, falling back to getlastmodified for servers
+// that don't expose one
+func (w *webdavFS) ChangeSignal(name string) (string, error) {
+	infos, err := w.propfind(name, "0")
+	if err != nil {
+		return "", err
+	}
+	if infos[0].etag != "" {
+		return infos[0].etag, nil
+	}
+	return infos[0].modTime.UTC().Format(time.RFC3339), nil
+}
+
+// Minimal DAV multistatus mapping, covering only the properties the backend needs
+type multistatus struct {
+	Responses []davResponse `xml:"response"`
+}
+
+type davResponse struct {
+	Href string  `xml:"href"`
+	Prop davProp `xml:"propstat>prop"`
+}
+
+type davProp struct {
+	LastModified  string          `xml:"getlastmodified"`
+	ContentLength int64           `xml:"getcontentlength"`
+	ETag          string          `xml:"getetag"`
+	ResourceType  davResourceType `xml:"resourcetype"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"collection"`
+}
+
+type webdavFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+	etag    string
+}
+
+func (i *webdavFileInfo) Name() string               { return i.name }
+func (i *webdavFileInfo) Size() int64                { return i.size }
+func (i *webdavFileInfo) Mode() fs.FileMode          { return i.mode() }
+func (i *webdavFileInfo) ModTime() time.Time         { return i.modTime }
+func (i *webdavFileInfo) IsDir() bool                { return i.isDir }
+func (i *webdavFileInfo) Sys() any                   { return nil }
+func (i *webdavFileInfo) Info() (fs.FileInfo, error) { return i, nil }
+func (i *webdavFileInfo) Type() fs.FileMode          { return i.mode().Type() }
+func (i *webdavFileInfo) mode() fs.FileMode {
+	if i.isDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+type webdavDir struct {
+	fsys    *webdavFS
+	name    string
+	info    *webdavFileInfo
+	entries []fs.DirEntry
+	listed  bool
+	offset  int
+}
+
+func (d *webdavDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *webdavDir) Close() error               { return nil }
+func (d *webdavDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *webdavDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		infos, err := d.fsys.propfind(d.name, "1")
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos[1:] {
+			d.entries = append(d.entries, info)
+		}
+		d.listed = true
+	}
+	if n <= 0 {
+		entries := d.entries[d.offset:]
+		d.offset = len(d.entries)
+		return entries, nil
+	}
+	if d.offset >= len(d.entries) {
+		return nil, io.EOF
+	}
+	end := min(d.offset+n, len(d.entries))
+	entries := d.entries[d.offset:end]
+	d.offset = end
+	return entries, nil
+}
+
+// webdavFile streams content with Range requests, one per Read, so consumers seeking
+// around a file (tag extractors reading headers and footers) only transfer what they
+// touch. Servers that ignore Range get a single capped full download instead
+type webdavFile struct {
+	fsys   *webdavFS
+	name   string
+	info   *webdavFileInfo
+	offset int64
+	full   *bytes.Reader // set once a server ignored a Range request
+}
+
+func (f *webdavFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *webdavFile) Close() error               { return nil }
+
+func (f *webdavFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		f.offset = offset
+	case io.SeekCurrent:
+		f.offset += offset
+	case io.SeekEnd:
+		f.offset = f.info.size + offset
+	default:
+		return 0, fs.ErrInvalid
+	}
+	if f.offset < 0 {
+		return 0, fs.ErrInvalid
+	}
+	return f.offset, nil
+}
+
+func (f *webdavFile) Read(p []byte) (int, error) {
+	if f.full != nil {
+		return f.readFromFull(p)
+	}
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+	req, err := http.NewRequest(http.MethodGet, f.fsys.urlFor(f.name), nil)
+	if err != nil {
+		return 0, err
+	}
+	if f.fsys.username != "" {
+		req.SetBasicAuth(f.fsys.username, f.fsys.password)
+	}
+	end := min(f.offset+int64(len(p)), f.info.size) - 1
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", f.offset, end))
+	resp, err := f.fsys.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		n, err := io.ReadFull(resp.Body, p[:end-f.offset+1])
+		f.offset += int64(n)
+		if err == io.ErrUnexpectedEOF {
+			err = nil
+		}
+		return n, err
+	case http.StatusOK:
+		if err := f.downloadAll(resp.Body); err != nil {
+			return 0, err
+		}
+		return f.readFromFull(p)
+	default:
+		return 0, fmt.Errorf("GET returned status %d", resp.StatusCode)
+	}
+}
+
+func (f *webdavFile) downloadAll(body io.Reader) error {
+	if f.info.size > webdavFullDownloadCap {
+		return fmt.Errorf("server does not support Range requests and %q exceeds the %d bytes download cap",
+			f.name, webdavFullDownloadCap)
+	}
+	log.Warn("WebDAV server does not support Range requests, downloading whole file", "file", f.name,
+		"size", f.info.size)
+	data, err := io.ReadAll(io.LimitReader(body, webdavFullDownloadCap))
+	if err != nil {
+		return err
+	}
+	f.full = bytes.NewReader(data)
+	return nil
+}
+
+func (f *webdavFile) readFromFull(p []byte) (int, error) {
+	n, err := f.full.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
+
+func mustPath(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Path
+	}
+	return rawURL
+}
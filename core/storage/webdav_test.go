@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/webdav"
+)
+
+var _ = Describe("webdavFS", func() {
+	var server *httptest.Server
+	var rangeRequests []string
+	var stripRange bool
+	var fsys MusicFS
+
+	write := func(memFS webdav.FileSystem, name, content string) {
+		f, err := memFS.OpenFile(context.Background(), name, os.O_CREATE|os.O_RDWR, 0644)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.Write([]byte(content))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		rangeRequests = nil
+		stripRange = false
+
+		memFS := webdav.NewMemFS()
+		Expect(memFS.Mkdir(context.Background(), "/Music", 0755)).To(Succeed())
+		Expect(memFS.Mkdir(context.Background(), "/Music/Album", 0755)).To(Succeed())
+		write(memFS, "/Music/Album/track.mp3", strings.Repeat("x", 100)+"TAIL")
+		write(memFS, "/Music/cover.jpg", "jpeg")
+
+		handler := &webdav.Handler{FileSystem: memFS, LockSystem: webdav.NewMemLS()}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rg := r.Header.Get("Range"); rg != "" {
+				rangeRequests = append(rangeRequests, rg)
+				if stripRange {
+					r.Header.Del("Range")
+				}
+			}
+			handler.ServeHTTP(w, r)
+		}))
+		DeferCleanup(server.Close)
+
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		fsys, err = For("webdav://" + serverURL.Host + "/Music")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("lists collections as directories via PROPFIND", func() {
+		entries, err := fs.ReadDir(fsys, ".")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+
+		names := []string{entries[0].Name(), entries[1].Name()}
+		Expect(names).To(ConsistOf("Album", "cover.jpg"))
+		for _, e := range entries {
+			info, err := e.Info()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.ModTime().IsZero()).To(BeFalse(), "getlastmodified should feed ModTime")
+		}
+	})
+
+	It("exposes size and mtime on file Stat", func() {
+		info, err := fs.Stat(fsys, "Album/track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Size()).To(Equal(int64(104)))
+		Expect(info.IsDir()).To(BeFalse())
+	})
+
+	It("returns fs.ErrNotExist for missing paths", func() {
+		_, err := fsys.Open("Album/nope.mp3")
+		Expect(err).To(MatchError(fs.ErrNotExist))
+	})
+
+	It("streams only the requested byte ranges", func() {
+		f, err := fsys.Open("Album/track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		// Seek to the "footer", like a tag extractor reading an ID3v1 block
+		_, err = f.(io.Seeker).Seek(-4, io.SeekEnd)
+		Expect(err).ToNot(HaveOccurred())
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(f, buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(buf)).To(Equal("TAIL"))
+		Expect(rangeRequests).To(Equal([]string{"bytes=100-103"}))
+	})
+
+	It("falls back to a single capped full download when the server ignores Range", func() {
+		stripRange = true
+
+		f, err := fsys.Open("Album/track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		_, err = f.(io.Seeker).Seek(-4, io.SeekEnd)
+		Expect(err).ToNot(HaveOccurred())
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(f, buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(buf)).To(Equal("TAIL"))
+
+		// Subsequent reads are served from the buffered download, without new requests
+		requests := len(rangeRequests)
+		_, err = f.(io.Seeker).Seek(0, io.SeekStart)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.ReadFull(f, buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(buf)).To(Equal("xxxx"))
+		Expect(rangeRequests).To(HaveLen(requests))
+	})
+
+	It("refuses the full download fallback for files over the cap", func() {
+		stripRange = true
+		originalCap := webdavFullDownloadCap
+		webdavFullDownloadCap = 10
+		DeferCleanup(func() { webdavFullDownloadCap = originalCap })
+
+		f, err := fsys.Open("Album/track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		_, err = f.Read(make([]byte, 4))
+		Expect(err).To(MatchError(ContainSubstring("download cap")))
+	})
+
+	It("uses the ETag as the change signal", func() {
+		signal, err := fsys.ChangeSignal("Album/track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signal).ToNot(BeEmpty())
+
+		unchanged, err := fsys.ChangeSignal("Album/track.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(unchanged).To(Equal(signal))
+	})
+})
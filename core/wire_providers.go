@@ -16,6 +16,7 @@ var Set = wire.NewSet(
 	NewPlayers,
 	NewShare,
 	NewPlaylists,
+	NewEdits,
 	agents.New,
 	ffmpeg.New,
 	scrobbler.GetPlayTracker,
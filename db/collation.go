@@ -0,0 +1,21 @@
+package db
+
+import (
+	"github.com/navidrome/navidrome/conf"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// unicodeCollation returns the comparison function behind the UNICODE collation, used by the
+// repositories' sort mappings. It orders strings by the Unicode collation algorithm instead of
+// byte-wise, so accented and CJK titles land where a human expects them. The ordering defaults
+// to the language-agnostic Unicode tables, and can be tailored with the SortLanguage config
+// option (ex: "ja", "sv")
+func unicodeCollation() func(a, b string) int {
+	tag := language.Und
+	if conf.Server.SortLanguage != "" {
+		tag = language.Make(conf.Server.SortLanguage)
+	}
+	c := collate.New(tag, collate.IgnoreCase)
+	return c.CompareString
+}
@@ -57,7 +57,11 @@ func Db() DB {
 	return singleton.GetInstance(func() *db {
 		sql.Register(Driver+"_custom", &sqlite3.SQLiteDriver{
 			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
-				return conn.RegisterFunc("SEEDEDRAND", hasher.HashFunc(), false)
+				if err := conn.RegisterFunc("SEEDEDRAND", hasher.HashFunc(), false); err != nil {
+					return err
+				}
+				// Collators are not safe for concurrent use, so create one per connection
+				return conn.RegisterCollation("UNICODE", unicodeCollation())
 			},
 		})
 
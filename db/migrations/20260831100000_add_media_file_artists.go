@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMediaFileArtists, downAddMediaFileArtists)
+}
+
+func upAddMediaFileArtists(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		create table if not exists media_file_artists
+		(
+			media_file_id varchar not null
+				references media_file(id)
+					on delete cascade,
+			artist_id varchar not null,
+			role varchar not null,
+			constraint media_file_artists_ux
+				unique (media_file_id, artist_id, role)
+		);
+		create index if not exists media_file_artists_role_idx
+			on media_file_artists(artist_id, role);
+
+		alter table artist add column stats varchar null default '{}';
+`)
+	if err != nil {
+		return err
+	}
+	notice(tx, "A full rescan needs to be performed to import the artist roles (participations)")
+	return forceFullRescan(tx)
+}
+
+func downAddMediaFileArtists(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMediaFileParticipations, downAddMediaFileParticipations)
+}
+
+func upAddMediaFileParticipations(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		alter table media_file add column participations varchar null default '';
+`)
+	if err != nil {
+		return err
+	}
+	notice(tx, "Backfilling the participations of existing tracks. This may take a while on large libraries")
+	return backfillParticipations(ctx, tx)
+}
+
+// backfillParticipations rewrites the serialized participations of existing tracks from the
+// media_file_artists rows, in batches, so a full rescan is not required
+func backfillParticipations(ctx context.Context, tx *sql.Tx) error {
+	const batchSize = 1000
+	for {
+		rows, err := tx.QueryContext(ctx, `
+			select distinct media_file_id from media_file_artists mfa
+			join media_file mf on mf.id = mfa.media_file_id
+			where mf.participations = '' limit ?`, batchSize)
+		if err != nil {
+			return err
+		}
+		var ids []any
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Err(); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		_ = rows.Close()
+		if len(ids) == 0 {
+			return nil
+		}
+
+		placeholders := "?"
+		for i := 1; i < len(ids); i++ {
+			placeholders += ",?"
+		}
+		_, err = tx.ExecContext(ctx, `
+			update media_file set participations = (
+				select json_object('v', 1, 'roles', json_group_object(role, json(artists)))
+				from (select mfa.role as role,
+						json_group_array(json_object('id', mfa.artist_id, 'name', coalesce(a.name, ''))) as artists
+					from media_file_artists mfa
+					left join artist a on a.id = mfa.artist_id
+					where mfa.media_file_id = media_file.id
+					group by mfa.role)
+			) where id in (`+placeholders+`)`, ids...)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func downAddMediaFileParticipations(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
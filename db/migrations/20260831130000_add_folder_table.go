@@ -0,0 +1,131 @@
+package migrations
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddFolderTable, downAddFolderTable)
+}
+
+func upAddFolderTable(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		create table if not exists folder (
+			id varchar not null primary key,
+			library_id integer not null default 1
+			   references library(id) on delete cascade,
+			path varchar not null default '',
+			parent_id varchar not null default ''
+		);
+		create index if not exists folder_parent_id on folder(parent_id);
+		alter table media_file add column folder_id varchar not null default '';
+		create index if not exists media_file_folder_id on media_file(folder_id);
+`)
+	if err != nil {
+		return err
+	}
+	notice(tx, "Building the folder tree from existing tracks. This may take a while on large libraries")
+	return backfillFolders(ctx, tx)
+}
+
+// backfillFolders creates folder rows for the directories of all existing tracks, linking each
+// folder to its parent up to the library root, and points media_file.folder_id at them
+func backfillFolders(ctx context.Context, tx *sql.Tx) error {
+	libraries := map[int]string{}
+	rows, err := tx.QueryContext(ctx, `select id, path from library`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		var path string
+		if err := rows.Scan(&id, &path); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		libraries[id] = path
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return err
+	}
+	_ = rows.Close()
+
+	type folderRow struct {
+		libraryID int
+		path      string
+		parentID  string
+	}
+	folderID := func(path string) string { return fmt.Sprintf("%x", md5.Sum([]byte(path))) }
+	folders := map[string]folderRow{}
+	dirs := map[string]string{}
+
+	rows, err = tx.QueryContext(ctx, `select distinct rtrim(path, replace(path, '/', '')), library_id from media_file`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var dir string
+		var libraryID int
+		if err := rows.Scan(&dir, &libraryID); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		key := dir
+		dir = filepath.Clean(dir)
+		dirs[key] = folderID(dir)
+		// Register the folder and all its ancestors, up to the library root
+		root := filepath.Clean(libraries[libraryID])
+		for {
+			parentID := ""
+			if dir != root && dir != filepath.Dir(dir) {
+				parentID = folderID(filepath.Dir(dir))
+			}
+			folders[folderID(dir)] = folderRow{libraryID: libraryID, path: dir, parentID: parentID}
+			if parentID == "" {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return err
+	}
+	_ = rows.Close()
+
+	ins, err := tx.PrepareContext(ctx, `insert into folder (id, library_id, path, parent_id) values (?, ?, ?, ?)
+		on conflict (id) do nothing`)
+	if err != nil {
+		return err
+	}
+	defer ins.Close()
+	for id, f := range folders {
+		if _, err := ins.ExecContext(ctx, id, f.libraryID, f.path, f.parentID); err != nil {
+			return err
+		}
+	}
+
+	upd, err := tx.PrepareContext(ctx, `update media_file set folder_id = ?
+		where rtrim(path, replace(path, '/', '')) = ?`)
+	if err != nil {
+		return err
+	}
+	defer upd.Close()
+	for dir, id := range dirs {
+		if _, err := upd.ExecContext(ctx, id, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downAddFolderTable(context.Context, *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMediaFileFingerprint, downAddMediaFileFingerprint)
+}
+
+func upAddMediaFileFingerprint(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		alter table media_file add column fingerprint varchar null default '';
+`)
+	return err
+}
+
+func downAddMediaFileFingerprint(context.Context, *sql.Tx) error {
+	return nil
+}
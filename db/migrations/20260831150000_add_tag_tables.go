@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddTagTables, downAddTagTables)
+}
+
+func upAddTagTables(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		create table if not exists tag (
+			id varchar not null primary key,
+			tag_name varchar not null default '',
+			tag_value varchar not null default ''
+		);
+		create unique index if not exists tag_name_value_ux on tag(tag_name, tag_value);
+		create table if not exists item_tags (
+			item_id varchar not null,
+			item_type varchar not null,
+			tag_id varchar not null,
+			constraint item_tags_ux unique (item_id, item_type, tag_id)
+		);
+		create index if not exists item_tags_tag_id_idx on item_tags(tag_id);
+`)
+	if err != nil {
+		return err
+	}
+	notice(tx, "A full rescan needs to be performed to import tags (mood, grouping, etc.)")
+	return forceFullRescan(tx)
+}
+
+func downAddTagTables(context.Context, *sql.Tx) error {
+	return nil
+}
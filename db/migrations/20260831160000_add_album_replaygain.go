@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAlbumReplaygain, downAddAlbumReplaygain)
+}
+
+func upAddAlbumReplaygain(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table album add
+	rg_album_gain real not null default 0;
+alter table album add
+	rg_album_peak real not null default 0;
+`)
+	if err != nil {
+		return err
+	}
+
+	notice(tx, "A full rescan needs to be performed to aggregate album gain values")
+	return forceFullRescan(tx)
+}
+
+func downAddAlbumReplaygain(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMediaFileInitialKey, downAddMediaFileInitialKey)
+}
+
+func upAddMediaFileInitialKey(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add
+	initial_key varchar(10) default '' not null;
+create index if not exists media_file_bpm
+	on media_file (bpm);
+`)
+	if err != nil {
+		return err
+	}
+
+	notice(tx, "A full rescan needs to be performed to import the initial key of your songs")
+	return forceFullRescan(tx)
+}
+
+func downAddMediaFileInitialKey(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMediaFileWorkID, downAddMediaFileWorkID)
+}
+
+func upAddMediaFileWorkID(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add
+	mbz_work_id varchar(255) default '' not null;
+create index if not exists media_file_mbz_work_id
+	on media_file (mbz_work_id);
+`)
+	if err != nil {
+		return err
+	}
+
+	notice(tx, "A full rescan needs to be performed to link recordings of the same MusicBrainz work")
+	return forceFullRescan(tx)
+}
+
+func downAddMediaFileWorkID(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
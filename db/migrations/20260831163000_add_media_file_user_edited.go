@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMediaFileUserEdited, downAddMediaFileUserEdited)
+}
+
+func upAddMediaFileUserEdited(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add
+	user_edited bool default false not null;
+`)
+	return err
+}
+
+func downAddMediaFileUserEdited(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
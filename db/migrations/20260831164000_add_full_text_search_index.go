@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddFullTextSearchIndex, downAddFullTextSearchIndex)
+}
+
+var fullTextIndexedTables = []string{"media_file", "album", "artist"}
+
+func upAddFullTextSearchIndex(_ context.Context, tx *sql.Tx) error {
+	// FTS5 is only compiled in when the `sqlite_fts5` build tag is set. Searches fall back to
+	// LIKE scans on full_text when the index is not available
+	if _, err := tx.Exec(`create virtual table fts5_probe using fts5(x)`); err != nil {
+		notice(tx, "FTS5 is not available in this build, full-text searches will keep scanning the full_text columns")
+		return nil
+	}
+	if _, err := tx.Exec(`drop table fts5_probe`); err != nil {
+		return err
+	}
+	for _, table := range fullTextIndexedTables {
+		_, err := tx.Exec(fmt.Sprintf(`
+create virtual table %[1]s_fts using fts5(id unindexed, full_text);
+insert into %[1]s_fts(id, full_text) select id, full_text from %[1]s;
+`, table))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downAddFullTextSearchIndex(_ context.Context, tx *sql.Tx) error {
+	for _, table := range fullTextIndexedTables {
+		if _, err := tx.Exec(`drop table if exists ` + table + `_fts`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
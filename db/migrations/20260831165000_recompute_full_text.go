@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upRecomputeFullText, downRecomputeFullText)
+}
+
+func upRecomputeFullText(_ context.Context, tx *sql.Tx) error {
+	notice(tx, "A full rescan needs to be performed to recompute the search index with folded punctuation")
+	return forceFullRescan(tx)
+}
+
+func downRecomputeFullText(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddPlayEventTable, downAddPlayEventTable)
+}
+
+func upAddPlayEventTable(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+create table if not exists play_event
+(
+	user_id varchar not null,
+	media_file_id varchar not null,
+	play_date datetime not null
+);
+create index if not exists play_event_user_date
+	on play_event (user_id, play_date);
+`)
+	return err
+}
+
+func downAddPlayEventTable(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`drop table if exists play_event;`)
+	return err
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddLockedFields, downAddLockedFields)
+}
+
+func upAddLockedFields(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add
+	locked_fields varchar(255) default '' not null;
+alter table album add
+	locked_fields varchar(255) default '' not null;
+`)
+	return err
+}
+
+func downAddLockedFields(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMissingFlag, downAddMissingFlag)
+}
+
+func upAddMissingFlag(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add
+	missing bool default false not null;
+alter table album add
+	missing bool default false not null;
+create index if not exists media_file_missing
+	on media_file (missing);
+`)
+	return err
+}
+
+func downAddMissingFlag(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
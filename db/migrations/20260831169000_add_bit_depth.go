@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddBitDepth, downAddBitDepth)
+}
+
+func upAddBitDepth(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add
+	bit_depth integer default 0 not null;
+`)
+	if err != nil {
+		return err
+	}
+	notice(tx, "A full rescan needs to be performed to pick up the bit depth of your tracks")
+	return forceFullRescan(tx)
+}
+
+func downAddBitDepth(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
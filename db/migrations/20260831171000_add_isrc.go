@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddIsrc, downAddIsrc)
+}
+
+func upAddIsrc(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add
+	isrc varchar default '' not null;
+create index if not exists media_file_isrc
+	on media_file (isrc);
+`)
+	if err != nil {
+		return err
+	}
+	notice(tx, "A full rescan needs to be performed to pick up the ISRC of your tracks")
+	return forceFullRescan(tx)
+}
+
+func downAddIsrc(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upDurationInMilliseconds, downDurationInMilliseconds)
+}
+
+func upDurationInMilliseconds(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+drop index if exists media_file_duration;
+alter table media_file add
+	duration_ms integer default 0 not null;
+update media_file set duration_ms = cast(round(duration * 1000) as integer);
+alter table media_file drop column duration;
+create index media_file_duration
+	on media_file (duration_ms);
+
+alter table album add
+	duration_ms integer default 0 not null;
+update album set duration_ms = cast(round(duration * 1000) as integer);
+alter table album drop column duration;
+
+alter table playlist add
+	duration_ms integer default 0 not null;
+update playlist set duration_ms = cast(round(duration * 1000) as integer);
+alter table playlist drop column duration;
+`)
+	if err != nil {
+		return err
+	}
+	notice(tx, "A full rescan needs to be performed to get the exact duration of your tracks")
+	return forceFullRescan(tx)
+}
+
+func downDurationInMilliseconds(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
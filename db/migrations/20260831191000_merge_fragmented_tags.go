@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upMergeFragmentedTags, downMergeFragmentedTags)
+}
+
+// Tag names are now always stored in their canonical (lowercase) form, but rows imported by
+// older versions may still carry mixed-case names, fragmenting filters. Re-point the items
+// linked to a non-canonical row to the canonical one, and drop the old row
+func upMergeFragmentedTags(_ context.Context, tx *sql.Tx) error {
+	rows, err := tx.Query(`select id, tag_name, tag_value from tag`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type tagRow struct {
+		id, name, value string
+	}
+	var fragmented []tagRow
+	var canonical []model.Tag
+	for rows.Next() {
+		var r tagRow
+		if err := rows.Scan(&r.id, &r.name, &r.value); err != nil {
+			return err
+		}
+		t := model.NewTag(r.name, r.value)
+		if t.ID != r.id {
+			fragmented = append(fragmented, r)
+			canonical = append(canonical, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(fragmented) == 0 {
+		return nil
+	}
+
+	for i, r := range fragmented {
+		t := canonical[i]
+		_, err = tx.Exec(`insert or ignore into tag (id, tag_name, tag_value) values (?, ?, ?)`,
+			t.ID, t.TagName, t.TagValue)
+		if err != nil {
+			return err
+		}
+		// Items already linked to the canonical row keep that link (the unique constraint
+		// makes the update a no-op for them), and their old links are deleted below
+		_, err = tx.Exec(`update or ignore item_tags set tag_id = ? where tag_id = ?`, t.ID, r.id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`delete from item_tags where tag_id = ?`, r.id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`delete from tag where id = ?`, r.id)
+		if err != nil {
+			return err
+		}
+	}
+	log.Info("Merged fragmented tag rows", "count", len(fragmented))
+	return nil
+}
+
+func downMergeFragmentedTags(context.Context, *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upNormalizePathSeparators, downNormalizePathSeparators)
+}
+
+// Paths are now stored with forward slashes regardless of the OS, so path-prefix queries keep
+// working when a library scanned on Windows is served from another OS (and vice versa). Rewrite
+// rows imported by older Windows versions, which stored backslashes
+func upNormalizePathSeparators(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`update media_file set path = replace(path, '\', '/') where path like '%\%';`)
+	return err
+}
+
+func downNormalizePathSeparators(context.Context, *sql.Tx) error {
+	return nil
+}
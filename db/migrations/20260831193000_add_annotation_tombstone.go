@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAnnotationTombstone, downAddAnnotationTombstone)
+}
+
+// annotation_tombstone preserves the user data of deleted tracks, keyed by recording identity
+// (library, title, artist, album and size) and user, so a later re-import of the same recording
+// can restore it. See the tombstone helpers in the media file repository
+func upAddAnnotationTombstone(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+create table if not exists annotation_tombstone
+(
+	user_id varchar not null,
+	library_id integer not null,
+	title varchar not null,
+	artist varchar not null,
+	album varchar not null,
+	size integer not null,
+	play_count integer default 0,
+	play_date datetime,
+	rating integer default 0,
+	starred bool default FALSE,
+	starred_at datetime,
+	bookmark_position integer default 0,
+	created_at datetime
+);
+create unique index if not exists annotation_tombstone_ux
+	on annotation_tombstone (library_id, title, artist, album, size, user_id);
+`)
+	return err
+}
+
+func downAddAnnotationTombstone(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`drop table if exists annotation_tombstone;`)
+	return err
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+	"golang.org/x/text/unicode/norm"
+)
+
+func init() {
+	goose.AddMigrationContext(upNormalizeSortKeys, downNormalizeSortKeys)
+}
+
+// The sort mappings now compare the order_* columns with the UNICODE collation. Normalize the
+// stored sort keys to NFC, so variant encodings of the same accented titles (precomposed vs
+// decomposed) group and compare consistently under it
+func upNormalizeSortKeys(ctx context.Context, tx *sql.Tx) error {
+	for _, t := range []struct {
+		table string
+		cols  []string
+	}{
+		{"media_file", []string{"order_title", "order_album_name", "order_artist_name", "order_album_artist_name"}},
+		{"album", []string{"order_album_name", "order_album_artist_name"}},
+		{"artist", []string{"order_artist_name"}},
+	} {
+		for _, col := range t.cols {
+			if err := normalizeSortColumn(ctx, tx, t.table, col); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeSortColumn(ctx context.Context, tx *sql.Tx, table, col string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`select distinct %[1]s from %[2]s where %[1]s <> ''`, col, table))
+	if err != nil {
+		return err
+	}
+	changed := map[string]string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if n := norm.NFC.String(v); n != v {
+			changed[v] = n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return err
+	}
+	_ = rows.Close()
+
+	upd, err := tx.PrepareContext(ctx, fmt.Sprintf(`update %[2]s set %[1]s = ? where %[1]s = ?`, col, table))
+	if err != nil {
+		return err
+	}
+	defer upd.Close()
+	for old, normalized := range changed {
+		if _, err := upd.ExecContext(ctx, normalized, old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downNormalizeSortKeys(context.Context, *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMediaFileBirthTime, downAddMediaFileBirthTime)
+}
+
+// Store the on-disk creation time of each file separately from the DB insertion time. Existing
+// rows are backfilled from created_at, which the scanner used to populate with the file birth
+// time where the filesystem supported it
+func upAddMediaFileBirthTime(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add column birth_time datetime;
+create index if not exists media_file_birth_time on media_file(birth_time);
+update media_file set birth_time = created_at;
+`)
+	return err
+}
+
+func downAddMediaFileBirthTime(context.Context, *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddLibraryExtractor, downAddLibraryExtractor)
+}
+
+// Allow each library to pick its own metadata extractor. Empty means the global
+// Scanner.Extractor option, preserving the previous behavior
+func upAddLibraryExtractor(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table library add column extractor varchar(255) not null default '';
+`)
+	return err
+}
+
+func downAddLibraryExtractor(context.Context, *sql.Tx) error {
+	return nil
+}
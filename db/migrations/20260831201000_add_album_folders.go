@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAlbumFolders, downAddAlbumFolders)
+}
+
+// Record which folders contribute tracks to each album, so albums spanning multiple
+// directories can be traced back to them and folder removals can adjust song counts
+// without re-aggregating every affected album. Rows are (re)built on album refresh,
+// so existing albums are populated by the next scan
+func upAddAlbumFolders(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+create table if not exists album_folders (
+	album_id varchar not null,
+	folder_id varchar not null,
+	track_count integer not null default 0,
+	primary key (album_id, folder_id)
+);
+create index if not exists album_folders_folder_id on album_folders(folder_id);
+`)
+	return err
+}
+
+func downAddAlbumFolders(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`drop table if exists album_folders;`)
+	return err
+}
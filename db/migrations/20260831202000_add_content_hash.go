@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddContentHash, downAddContentHash)
+}
+
+// Add a fingerprint of the last written values to the tables the scanner rewrites on every
+// scan, so the persist stage can skip writes that would store exactly what is already there.
+// Starting empty just means the first scan after the upgrade writes every row once
+func upAddContentHash(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add column content_hash varchar not null default '';
+alter table album add column content_hash varchar not null default '';
+alter table artist add column content_hash varchar not null default '';
+`)
+	return err
+}
+
+func downAddContentHash(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAlbumAudioProperties, downAddAlbumAudioProperties)
+}
+
+// Aggregate the tracks' audio properties on the album, so clients can badge albums as Hi-Res
+// or Mono without loading every track. Values are computed on album refresh, so existing
+// albums are populated by the next scan
+func upAddAlbumAudioProperties(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table album add column min_sample_rate integer not null default 0;
+alter table album add column max_sample_rate integer not null default 0;
+alter table album add column min_bit_depth integer not null default 0;
+alter table album add column max_bit_depth integer not null default 0;
+alter table album add column channels integer not null default 0;
+`)
+	return err
+}
+
+func downAddAlbumAudioProperties(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
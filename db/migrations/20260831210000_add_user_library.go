@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddUserLibrary, downAddUserLibrary)
+}
+
+// Per-user library access grants. Non-admin users only see content from the libraries
+// they have a row for; admins are never filtered, so the table starts empty
+func upAddUserLibrary(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		create table if not exists user_library (
+			user_id varchar not null
+				references user(id) on delete cascade,
+			library_id integer not null
+				references library(id) on delete cascade,
+			constraint user_library_ux
+				unique (user_id, library_id)
+		);`)
+	return err
+}
+
+func downAddUserLibrary(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `drop table if exists user_library;`)
+	return err
+}
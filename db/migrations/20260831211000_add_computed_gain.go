@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddComputedGain, downAddComputedGain)
+}
+
+// Store ReplayGain values computed by the gain analysis job separately from the tag-derived
+// rg_track_* columns, so a rescan of the file tags never discards the computed values and
+// tag-provided values always take precedence
+func upAddComputedGain(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add column rg_track_gain_computed real not null default 0;
+alter table media_file add column rg_track_peak_computed real not null default 0;
+`)
+	return err
+}
+
+func downAddComputedGain(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAlbumBirthTime, downAddAlbumBirthTime)
+}
+
+// Aggregate the tracks' on-disk creation times on the album, so "recently added" lists can
+// follow the real files instead of the DB insertion time. Existing rows are backfilled from
+// the tracks, falling back to the album's created_at when none carry a birth time
+func upAddAlbumBirthTime(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table album add column min_birth_time datetime;
+alter table album add column max_birth_time datetime;
+update album set
+	min_birth_time = coalesce((select min(coalesce(mf.birth_time, mf.created_at)) from media_file mf where mf.album_id = album.id), created_at),
+	max_birth_time = coalesce((select max(coalesce(mf.birth_time, mf.created_at)) from media_file mf where mf.album_id = album.id), created_at);
+create index if not exists album_max_birth_time on album(max_birth_time);
+`)
+	return err
+}
+
+func downAddAlbumBirthTime(context.Context, *sql.Tx) error {
+	return nil
+}
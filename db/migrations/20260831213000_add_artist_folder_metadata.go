@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddArtistFolderMetadata, downAddArtistFolderMetadata)
+}
+
+// Add the paths of artist-level metadata files (artist.jpg, artist.nfo) found in the artist's
+// folder during the scan. Starting empty just means they are picked up by the next scan
+func upAddArtistFolderMetadata(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table artist add column image_path varchar not null default '';
+alter table artist add column nfo_path varchar not null default '';
+`)
+	return err
+}
+
+func downAddArtistFolderMetadata(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAlbumCoverArtPath, downAddAlbumCoverArtPath)
+}
+
+// Add the image source selected for the album cover during the scan. Starting empty just
+// means the covers are picked by the next scan; until then the artwork reader falls back to
+// evaluating the priority list at serving time, as before
+func upAddAlbumCoverArtPath(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table album add column cover_art_path varchar not null default '';
+`)
+	return err
+}
+
+func downAddAlbumCoverArtPath(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
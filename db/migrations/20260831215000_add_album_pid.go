@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAlbumPid, downAddAlbumPid)
+}
+
+// Add a persistent album identifier, derived the same way regardless of the
+// GroupAlbumReleases setting, used to carry user annotations over when the album IDs
+// change after a re-grouping
+func upAddAlbumPid(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table album add column pid varchar not null default '';
+alter table media_file add column album_pid varchar not null default '';
+`)
+	if err != nil {
+		return err
+	}
+	notice(tx, "A full rescan needs to be performed to compute the persistent album identifiers")
+	return forceFullRescan(tx)
+}
+
+func downAddAlbumPid(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upRebuildAlbumSearchIndex, downRebuildAlbumSearchIndex)
+}
+
+func upRebuildAlbumSearchIndex(_ context.Context, tx *sql.Tx) error {
+	// The album full_text now also includes the participating artists and the catalog number,
+	// and it is rebuilt by the album refresh during a scan
+	notice(tx, "A full rescan needs to be performed to make your albums searchable by all their artists")
+	return forceFullRescan(tx)
+}
+
+func downRebuildAlbumSearchIndex(_ context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddScanLogTable, downAddScanLogTable)
+}
+
+func upAddScanLogTable(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+create table if not exists scan_log
+(
+	id varchar not null primary key,
+	library_path varchar not null,
+	start_time datetime not null,
+	duration_ms integer not null default 0,
+	full_scan bool not null default false,
+	partial bool not null default false,
+	added integer not null default 0,
+	updated integer not null default 0,
+	missing integer not null default 0,
+	playlists integer not null default 0,
+	error varchar not null default ''
+);
+create index if not exists scan_log_start_time
+	on scan_log (start_time);
+`)
+	return err
+}
+
+func downAddScanLogTable(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`drop table if exists scan_log;`)
+	return err
+}
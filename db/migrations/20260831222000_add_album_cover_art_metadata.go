@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAlbumCoverArtMetadata, downAddAlbumCoverArtMetadata)
+}
+
+// Add the size and modification time of the selected album cover, so serving cover art can
+// build cache keys without stating the file on every request. The columns start zeroed and are
+// filled by the next scan; until then the artwork reader falls back to the album's updated_at
+func upAddAlbumCoverArtMetadata(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table album add column cover_art_size integer not null default 0;
+alter table album add column cover_art_updated_at datetime;
+`)
+	return err
+}
+
+func downAddAlbumCoverArtMetadata(context.Context, *sql.Tx) error {
+	return nil
+}
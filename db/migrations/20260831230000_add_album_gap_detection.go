@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddAlbumGapDetection, downAddAlbumGapDetection)
+}
+
+// Add the per-track total from "x/y" track number tags, and the album-level gap detection
+// results computed from it (see model.MediaFiles.ToAlbum). Existing albums start as complete;
+// the flags are filled in when a scan next refreshes them
+func upAddAlbumGapDetection(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add column total_tracks integer not null default 0;
+alter table album add column complete bool not null default true;
+alter table album add column missing_tracks varchar not null default '';
+`)
+	return err
+}
+
+func downAddAlbumGapDetection(context.Context, *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddFolderFingerprint, downAddFolderFingerprint)
+}
+
+// Add the per-folder content fingerprint recorded by the scanner (see dirStats.fingerprint).
+// Existing folders start without one and keep using the modification time heuristic until the
+// next scan processes them
+func upAddFolderFingerprint(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table folder add column fingerprint varchar not null default '';
+`)
+	return err
+}
+
+func downAddFolderFingerprint(context.Context, *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddMediaFileCodec, downAddMediaFileCodec)
+}
+
+// Add the actual audio codec of each track, as reported by the extractor, so streaming
+// decisions don't have to rely on the file suffix alone (ex: .m4a holds both AAC and ALAC).
+// Existing rows start empty and are filled in by the next full scan
+func upAddMediaFileCodec(_ context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+alter table media_file add column codec varchar not null default '';
+`)
+	return err
+}
+
+func downAddMediaFileCodec(context.Context, *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+//go:generate go run ./schemagen
+
+// SchemaColumn describes one column of a migrated table.
+type SchemaColumn struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	NotNull    bool   `json:"notNull,omitempty"`
+	Default    string `json:"default,omitempty"`
+	PrimaryKey bool   `json:"primaryKey,omitempty"`
+}
+
+// SchemaIndex describes one index of a migrated table.
+type SchemaIndex struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique,omitempty"`
+	Columns []string `json:"columns"`
+}
+
+// SchemaForeignKey describes one foreign key of a migrated table.
+type SchemaForeignKey struct {
+	Column     string `json:"column"`
+	References string `json:"references"`
+}
+
+// SchemaTable describes one migrated table.
+type SchemaTable struct {
+	Name        string             `json:"name"`
+	Columns     []SchemaColumn     `json:"columns"`
+	Indexes     []SchemaIndex      `json:"indexes,omitempty"`
+	ForeignKeys []SchemaForeignKey `json:"foreignKeys,omitempty"`
+}
+
+// Schema is a machine-readable description of the migrated database schema, keyed by table
+// name. It is dumped to db/schema.json by `go generate ./db` (as documentation for
+// contributors) and consumed by tests that check the persistence struct mappings against it.
+type Schema map[string]SchemaTable
+
+// DumpSchema introspects the given (already migrated) database and returns its schema,
+// excluding SQLite internals, the goose bookkeeping table and the FTS5 shadow tables.
+func DumpSchema(d *sql.DB) (Schema, error) {
+	rows, err := d.Query(`select name from sqlite_master where type = 'table'
+		and name not like 'sqlite_%' and name <> 'goose_db_version'
+		and name not like '%_fts%' order by name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schema := Schema{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		table, err := dumpTable(d, name)
+		if err != nil {
+			return nil, fmt.Errorf("dumping table %s: %w", name, err)
+		}
+		schema[name] = table
+	}
+	return schema, rows.Err()
+}
+
+func dumpTable(d *sql.DB, name string) (SchemaTable, error) {
+	table := SchemaTable{Name: name}
+	rows, err := d.Query(fmt.Sprintf("pragma table_info(%q)", name))
+	if err != nil {
+		return table, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, pk int
+		var col SchemaColumn
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &col.Name, &col.Type, &notNull, &dflt, &pk); err != nil {
+			return table, err
+		}
+		col.NotNull = notNull != 0
+		col.Default = dflt.String
+		col.PrimaryKey = pk != 0
+		table.Columns = append(table.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return table, err
+	}
+	if table.Indexes, err = dumpIndexes(d, name); err != nil {
+		return table, err
+	}
+	table.ForeignKeys, err = dumpForeignKeys(d, name)
+	return table, err
+}
+
+func dumpIndexes(d *sql.DB, table string) ([]SchemaIndex, error) {
+	rows, err := d.Query(fmt.Sprintf("pragma index_list(%q)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []SchemaIndex
+	for rows.Next() {
+		var seq, unique, partial int
+		var name, origin string
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(name, "sqlite_autoindex") {
+			continue
+		}
+		idx := SchemaIndex{Name: name, Unique: unique != 0}
+		if idx.Columns, err = indexColumns(d, name); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, rows.Err()
+}
+
+func indexColumns(d *sql.DB, index string) ([]string, error) {
+	rows, err := d.Query(fmt.Sprintf("pragma index_info(%q)", index))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var seqNo, cid int
+		var name sql.NullString
+		if err := rows.Scan(&seqNo, &cid, &name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name.String)
+	}
+	return columns, rows.Err()
+}
+
+func dumpForeignKeys(d *sql.DB, table string) ([]SchemaForeignKey, error) {
+	rows, err := d.Query(fmt.Sprintf("pragma foreign_key_list(%q)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var fks []SchemaForeignKey
+	for rows.Next() {
+		var id, seq int
+		var refTable, from, onUpdate, onDelete, match string
+		var to sql.NullString
+		if err := rows.Scan(&id, &seq, &refTable, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			return nil, err
+		}
+		fks = append(fks, SchemaForeignKey{Column: from, References: refTable + "(" + to.String + ")"})
+	}
+	return fks, rows.Err()
+}
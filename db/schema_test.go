@@ -0,0 +1,27 @@
+package db
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/navidrome/navidrome/conf"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DumpSchema", func() {
+	It("matches the committed schema.json", func() {
+		conf.Server.DbPath = "file::memory:?cache=shared"
+		defer Init()()
+		schema, err := DumpSchema(Db().ReadDB())
+		Expect(err).ToNot(HaveOccurred())
+
+		// tests.Init changes the working dir to the project root
+		data, err := os.ReadFile("db/schema.json")
+		Expect(err).ToNot(HaveOccurred())
+		var committed Schema
+		Expect(json.Unmarshal(data, &committed)).To(Succeed())
+
+		Expect(schema).To(Equal(committed), "schema.json is stale, regenerate it with `go generate ./db`")
+	})
+})
@@ -0,0 +1,38 @@
+// Command schemagen migrates an in-memory database and writes its schema (tables, columns,
+// indexes and foreign keys) to schema.json, so contributors can see the current shape of the
+// DB without replaying every migration in their head. Run it with `go generate ./db`.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+)
+
+func main() {
+	output := flag.String("o", "schema.json", "output file")
+	flag.Parse()
+	log.SetLevel(log.LevelError)
+
+	conf.Server.DbPath = "file:schemagen?mode=memory&cache=shared"
+	defer db.Init()()
+	schema, err := db.DumpSchema(db.Db().ReadDB())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error dumping schema:", err)
+		os.Exit(1)
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error encoding schema:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, append(data, '\n'), 0600); err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing", *output+":", err)
+		os.Exit(1)
+	}
+}
@@ -2,53 +2,88 @@ package model
 
 import (
 	"cmp"
+	"encoding/json"
 	"slices"
 	"time"
 
 	"github.com/navidrome/navidrome/utils/slice"
+	"github.com/navidrome/navidrome/utils/str"
 )
 
 type Album struct {
 	Annotations `structs:"-"`
 
-	ID                    string     `structs:"id" json:"id"`
-	LibraryID             int        `structs:"library_id" json:"libraryId"`
-	Name                  string     `structs:"name" json:"name"`
-	EmbedArtPath          string     `structs:"embed_art_path" json:"embedArtPath"`
-	ArtistID              string     `structs:"artist_id" json:"artistId"`
-	Artist                string     `structs:"artist" json:"artist"`
-	AlbumArtistID         string     `structs:"album_artist_id" json:"albumArtistId"`
-	AlbumArtist           string     `structs:"album_artist" json:"albumArtist"`
-	AllArtistIDs          string     `structs:"all_artist_ids" json:"allArtistIds"`
-	MaxYear               int        `structs:"max_year" json:"maxYear"`
-	MinYear               int        `structs:"min_year" json:"minYear"`
-	Date                  string     `structs:"date" json:"date,omitempty"`
-	MaxOriginalYear       int        `structs:"max_original_year" json:"maxOriginalYear"`
-	MinOriginalYear       int        `structs:"min_original_year" json:"minOriginalYear"`
-	OriginalDate          string     `structs:"original_date" json:"originalDate,omitempty"`
-	ReleaseDate           string     `structs:"release_date" json:"releaseDate,omitempty"`
-	Releases              int        `structs:"releases" json:"releases"`
-	Compilation           bool       `structs:"compilation" json:"compilation"`
-	Comment               string     `structs:"comment" json:"comment,omitempty"`
-	SongCount             int        `structs:"song_count" json:"songCount"`
-	Duration              float32    `structs:"duration" json:"duration"`
-	Size                  int64      `structs:"size" json:"size"`
-	Genre                 string     `structs:"genre" json:"genre"`
-	Genres                Genres     `structs:"-" json:"genres"`
-	Discs                 Discs      `structs:"discs" json:"discs,omitempty"`
-	FullText              string     `structs:"full_text" json:"-"`
-	SortAlbumName         string     `structs:"sort_album_name" json:"sortAlbumName,omitempty"`
-	SortArtistName        string     `structs:"sort_artist_name" json:"sortArtistName,omitempty"`
-	SortAlbumArtistName   string     `structs:"sort_album_artist_name" json:"sortAlbumArtistName,omitempty"`
-	OrderAlbumName        string     `structs:"order_album_name" json:"orderAlbumName"`
-	OrderAlbumArtistName  string     `structs:"order_album_artist_name" json:"orderAlbumArtistName"`
-	CatalogNum            string     `structs:"catalog_num" json:"catalogNum,omitempty"`
-	MbzAlbumID            string     `structs:"mbz_album_id" json:"mbzAlbumId,omitempty"`
-	MbzAlbumArtistID      string     `structs:"mbz_album_artist_id" json:"mbzAlbumArtistId,omitempty"`
-	MbzAlbumType          string     `structs:"mbz_album_type" json:"mbzAlbumType,omitempty"`
-	MbzAlbumComment       string     `structs:"mbz_album_comment" json:"mbzAlbumComment,omitempty"`
-	ImageFiles            string     `structs:"image_files" json:"imageFiles,omitempty"`
-	Paths                 string     `structs:"paths" json:"paths,omitempty"`
+	ID string `structs:"id" json:"id"`
+	// PID is a persistent album identifier, derived from the same attributes as the ID but
+	// ignoring the GroupAlbumReleases setting, so it survives re-grouping. Used to carry the
+	// user annotations over when the album IDs change (see AlbumRepository.ReconcileAnnotations)
+	PID             string  `structs:"pid" json:"-"`
+	LibraryID       int     `structs:"library_id" json:"libraryId"`
+	Name            string  `structs:"name" json:"name"`
+	EmbedArtPath    string  `structs:"embed_art_path" json:"embedArtPath"`
+	ArtistID        string  `structs:"artist_id" json:"artistId"`
+	Artist          string  `structs:"artist" json:"artist"`
+	AlbumArtistID   string  `structs:"album_artist_id" json:"albumArtistId"`
+	AlbumArtist     string  `structs:"album_artist" json:"albumArtist"`
+	AllArtistIDs    string  `structs:"all_artist_ids" json:"allArtistIds"`
+	MaxYear         int     `structs:"max_year" json:"maxYear"`
+	MinYear         int     `structs:"min_year" json:"minYear"`
+	Date            string  `structs:"date" json:"date,omitempty"`
+	MaxOriginalYear int     `structs:"max_original_year" json:"maxOriginalYear"`
+	MinOriginalYear int     `structs:"min_original_year" json:"minOriginalYear"`
+	OriginalDate    string  `structs:"original_date" json:"originalDate,omitempty"`
+	ReleaseDate     string  `structs:"release_date" json:"releaseDate,omitempty"`
+	Releases        int     `structs:"releases" json:"releases"`
+	Compilation     bool    `structs:"compilation" json:"compilation"`
+	Comment         string  `structs:"comment" json:"comment,omitempty"`
+	SongCount       int     `structs:"song_count" json:"songCount"`
+	DurationMs      int64   `structs:"duration_ms" json:"-"`
+	Size            int64   `structs:"size" json:"size"`
+	RgAlbumGain     float64 `structs:"rg_album_gain" json:"rgAlbumGain"`
+	RgAlbumPeak     float64 `structs:"rg_album_peak" json:"rgAlbumPeak"`
+	// Aggregated audio properties of the tracks, so clients can badge albums as Hi-Res or
+	// Mono. Min/max carry the full range on mixed-quality albums; Channels is the dominant
+	// channel count. Zero means unknown
+	MinSampleRate        int    `structs:"min_sample_rate" json:"minSampleRate,omitempty"`
+	MaxSampleRate        int    `structs:"max_sample_rate" json:"maxSampleRate,omitempty"`
+	MinBitDepth          int    `structs:"min_bit_depth" json:"minBitDepth,omitempty"`
+	MaxBitDepth          int    `structs:"max_bit_depth" json:"maxBitDepth,omitempty"`
+	Channels             int    `structs:"channels" json:"channels,omitempty"`
+	Genre                string `structs:"genre" json:"genre"`
+	Genres               Genres `structs:"-" json:"genres"`
+	Tags                 Tags   `structs:"-" json:"tags,omitempty"`
+	Discs                Discs  `structs:"discs" json:"discs,omitempty"`
+	FullText             string `structs:"full_text" json:"-"`
+	SortAlbumName        string `structs:"sort_album_name" json:"sortAlbumName,omitempty"`
+	SortArtistName       string `structs:"sort_artist_name" json:"sortArtistName,omitempty"`
+	SortAlbumArtistName  string `structs:"sort_album_artist_name" json:"sortAlbumArtistName,omitempty"`
+	OrderAlbumName       string `structs:"order_album_name" json:"orderAlbumName"`
+	OrderAlbumArtistName string `structs:"order_album_artist_name" json:"orderAlbumArtistName"`
+	CatalogNum           string `structs:"catalog_num" json:"catalogNum,omitempty"`
+	MbzAlbumID           string `structs:"mbz_album_id" json:"mbzAlbumId,omitempty"`
+	MbzAlbumArtistID     string `structs:"mbz_album_artist_id" json:"mbzAlbumArtistId,omitempty"`
+	MbzAlbumType         string `structs:"mbz_album_type" json:"mbzAlbumType,omitempty"`
+	MbzAlbumComment      string `structs:"mbz_album_comment" json:"mbzAlbumComment,omitempty"`
+	ImageFiles           string `structs:"image_files" json:"imageFiles,omitempty"`
+	// CoverArtPath is the image source selected for the album during the scan, following the
+	// CoverArtPriority configuration. It is one of the ImageFiles or the EmbedArtPath, and can
+	// be overridden (and locked) through the native API
+	CoverArtPath string `structs:"cover_art_path" json:"coverArtPath,omitempty"`
+	// CoverArtSize and CoverArtUpdatedAt are the size and modification time of the file behind
+	// CoverArtPath, captured when the cover was selected, so cache keys and conditional requests
+	// can be answered without touching the filesystem
+	CoverArtSize      int64     `structs:"cover_art_size" json:"-"`
+	CoverArtUpdatedAt time.Time `structs:"cover_art_updated_at" json:"-"`
+	Paths             string    `structs:"paths" json:"paths,omitempty"`
+	// LockedFields lists the fields that keep their DB values when the album is re-aggregated
+	// from its tracks. See model.LockedFields
+	LockedFields LockedFields `structs:"locked_fields" json:"lockedFields,omitempty"`
+	// Missing is set when all the album's tracks are missing from disk
+	Missing bool `structs:"missing" json:"missing,omitempty"`
+	// Complete is false when gaps were detected in the album's track numbering (see
+	// MediaFiles.ToAlbum); MissingTracks summarizes the missing numbers, per disc
+	Complete              bool       `structs:"complete" json:"complete"`
+	MissingTracks         string     `structs:"missing_tracks" json:"missingTracks,omitempty"`
 	Description           string     `structs:"description" json:"description,omitempty"`
 	SmallImageUrl         string     `structs:"small_image_url" json:"smallImageUrl,omitempty"`
 	MediumImageUrl        string     `structs:"medium_image_url" json:"mediumImageUrl,omitempty"`
@@ -57,6 +92,27 @@ type Album struct {
 	ExternalInfoUpdatedAt *time.Time `structs:"external_info_updated_at" json:"externalInfoUpdatedAt"`
 	CreatedAt             time.Time  `structs:"created_at" json:"createdAt"`
 	UpdatedAt             time.Time  `structs:"updated_at" json:"updatedAt"`
+	// Min/MaxBirthTime aggregate the tracks' on-disk creation times, so "recently added"
+	// lists can follow the real files instead of the DB insertion time (see
+	// RecentlyAddedByModTime). Tracks without a birth time count with their CreatedAt
+	MinBirthTime time.Time `structs:"min_birth_time" json:"minBirthTime"`
+	MaxBirthTime time.Time `structs:"max_birth_time" json:"maxBirthTime"`
+}
+
+// Duration returns the total album length in float seconds, summed from the individual tracks'
+// integer milliseconds
+func (a Album) Duration() float32 {
+	return float32(a.DurationMs) / 1000
+}
+
+// MarshalJSON adds a `duration` attribute in float seconds, keeping the format the web API always
+// exposed for the duration
+func (a Album) MarshalJSON() ([]byte, error) {
+	type alias Album
+	return json.Marshal(struct {
+		alias
+		Duration float32 `json:"duration"`
+	}{alias(a), a.Duration()})
 }
 
 func (a Album) CoverArtID() ArtworkID {
@@ -79,6 +135,16 @@ type DiscID struct {
 	DiscNumber  int    `json:"discNumber"`
 }
 
+// AlbumFolder links an album to one directory contributing tracks to it. Albums ripped
+// into multiple folders have one entry per folder
+type AlbumFolder struct {
+	FolderID   string `json:"folderId"`
+	Path       string `json:"path"`
+	TrackCount int    `json:"trackCount"`
+}
+
+type AlbumFolders []AlbumFolder
+
 type Albums []Album
 
 // ToAlbumArtist creates an Artist object based on the attributes of this Albums collection.
@@ -86,11 +152,11 @@ type Albums []Album
 func (als Albums) ToAlbumArtist() Artist {
 	a := Artist{AlbumCount: len(als)}
 	var mbzArtistIds []string
+	var sortNames []string
 	for _, al := range als {
 		a.ID = al.AlbumArtistID
 		a.Name = al.AlbumArtist
-		a.SortArtistName = al.SortAlbumArtistName
-		a.OrderArtistName = al.OrderAlbumArtistName
+		sortNames = append(sortNames, al.SortAlbumArtistName)
 
 		a.SongCount += al.SongCount
 		a.Size += al.Size
@@ -100,6 +166,10 @@ func (als Albums) ToAlbumArtist() Artist {
 	slices.SortFunc(a.Genres, func(a, b Genre) int { return cmp.Compare(a.ID, b.ID) })
 	a.Genres = slices.Compact(a.Genres)
 	a.MbzArtistID = slice.MostFrequent(mbzArtistIds)
+	// Recompute sort/order names from the current albums, so sort tag changes picked up by a
+	// rescan are reflected in the ordering
+	a.SortArtistName = slice.MostFrequent(sortNames)
+	a.OrderArtistName = str.SanitizeFieldForSortingNoArticle(a.Name)
 
 	return a
 }
@@ -112,5 +182,29 @@ type AlbumRepository interface {
 	GetAll(...QueryOptions) (Albums, error)
 	GetAllWithoutGenres(...QueryOptions) (Albums, error)
 	Search(q string, offset int, size int) (Albums, error)
+	// SearchWithTotal returns the same page of results as Search, along with the total number
+	// of matches for the query
+	SearchWithTotal(q string, offset int, size int) (Albums, int64, error)
+
+	// GetInProgress returns albums where the user's most recently played track is not the final
+	// track of the album, ordered by the last play date
+	GetInProgress(limit int) (Albums, error)
+
+	// GetFolders returns the folders containing the album's tracks, with how many tracks
+	// each one contributes, in path order
+	GetFolders(albumID string) (AlbumFolders, error)
+	// GetAlbumArtistIDsInFolder returns the distinct album artists of the albums whose tracks
+	// live in direct sub-folders of the given folder. Used by the scanner to detect artist
+	// folders carrying artist-level metadata files (artist.jpg, artist.nfo)
+	GetAlbumArtistIDsInFolder(path string) ([]string, error)
+	// ReconcileAnnotations moves the user annotations of albums sharing the given pid over to
+	// the album with newID. When multiple old albums merge into one, play counts are combined
+	// and the earliest starred_at is kept. Used by the scanner when an album's ID changes
+	// (ex: after toggling GroupAlbumReleases) but its pid still matches
+	ReconcileAnnotations(pid string, newID string) error
+	// ReduceSongCountByFolder subtracts the given folder's contribution from the song
+	// count of every album it holds tracks for, flagging albums left with no songs as
+	// missing. Used when a folder disappears, instead of re-aggregating each album
+	ReduceSongCountByFolder(folderID string) error
 	AnnotatedRepository
 }
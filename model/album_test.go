@@ -22,7 +22,7 @@ var _ = Describe("Albums", func() {
 			Expect(artist.ID).To(Equal("11"))
 			Expect(artist.Name).To(Equal("Artist"))
 			Expect(artist.SortArtistName).To(Equal("SortAlbumArtistName"))
-			Expect(artist.OrderArtistName).To(Equal("OrderAlbumArtistName"))
+			Expect(artist.OrderArtistName).To(Equal("artist"))
 		})
 	})
 
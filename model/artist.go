@@ -22,6 +22,20 @@ type Artist struct {
 	ExternalUrl           string     `structs:"external_url" json:"externalUrl,omitempty"`
 	SimilarArtists        Artists    `structs:"similar_artists"  json:"-"`
 	ExternalInfoUpdatedAt *time.Time `structs:"external_info_updated_at" json:"externalInfoUpdatedAt"`
+	// ImagePath points to an artist image file (ex: artist.jpg) found in the artist's folder
+	// during the scan. When set, it takes precedence over images from external agents
+	ImagePath string `structs:"image_path" json:"imagePath,omitempty"`
+	// NfoPath points to the artist.nfo file the Biography and Genres were parsed from during
+	// the scan. When set, the biography is not overwritten by external agent lookups
+	NfoPath string `structs:"nfo_path" json:"nfoPath,omitempty"`
+
+	// Stats contains the number of songs and albums this artist participates in, by role
+	Stats map[string]RoleStats `structs:"-" json:"stats,omitempty"`
+}
+
+type RoleStats struct {
+	SongCount  int64 `json:"songCount"`
+	AlbumCount int64 `json:"albumCount"`
 }
 
 func (a Artist) ArtistImageUrl() string {
@@ -53,6 +67,11 @@ type ArtistRepository interface {
 	Get(id string) (*Artist, error)
 	GetAll(options ...QueryOptions) (Artists, error)
 	Search(q string, offset int, size int) (Artists, error)
-	GetIndex() (ArtistIndexes, error)
+	// SearchWithTotal returns the same page of results as Search, along with the total number
+	// of matches for the query
+	SearchWithTotal(q string, offset int, size int) (Artists, int64, error)
+	GetIndex(roles ...string) (ArtistIndexes, error)
+	// RefreshStats recomputes the per-role counters of the given artists, from the participations
+	RefreshStats(artistIDs ...string) error
 	AnnotatedRepository
 }
@@ -1,8 +1,10 @@
 package criteria
 
 import (
+	"sort"
 	"strings"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/navidrome/navidrome/log"
 )
 
@@ -36,10 +38,13 @@ var fieldMap = map[string]*mappedField{
 	"catalognumber":   {field: "media_file.catalog_num"},
 	"filepath":        {field: "media_file.path"},
 	"filetype":        {field: "media_file.suffix"},
-	"duration":        {field: "media_file.duration"},
+	"duration":        {field: "(media_file.duration_ms / 1000.0)"},
 	"bitrate":         {field: "media_file.bit_rate"},
 	"bpm":             {field: "media_file.bpm"},
 	"channels":        {field: "media_file.channels"},
+	"bitdepth":        {field: "media_file.bit_depth"},
+	"missing":         {field: "media_file.missing"},
+	"birthtime":       {field: "media_file.birth_time"},
 	"genre":           {field: "COALESCE(genre.name, '')"},
 	"loved":           {field: "COALESCE(annotation.starred, false)"},
 	"dateloved":       {field: "annotation.starred_at"},
@@ -65,3 +70,83 @@ func mapFields(expr map[string]interface{}) map[string]interface{} {
 	}
 	return m
 }
+
+// condFunc builds the comparison of a single mapped DB column against a criteria value
+type condFunc func(field string, value interface{}) squirrel.Sqlizer
+
+// mapConds translates each field of the expression into a condition, applying cmp to its
+// mapped DB column. Dynamic "tag:<name>" and "role:<name>" fields become EXISTS subqueries
+// against item_tags and media_file_artists, with cmp applied to the tag value or to the
+// participating artist's name. Tag and role names not present in the DB simply never match,
+// producing an empty result instead of a SQL error
+func mapConds(expr map[string]interface{}, cmp condFunc) squirrel.Sqlizer {
+	return buildConds(expr, cmp, cmp, false)
+}
+
+// mapNotConds is the counterpart of mapConds for negated operators. Plain fields use notCmp
+// directly, while dynamic fields wrap the positive cmp in a NOT EXISTS, so files without
+// the tag (or role) at all also match
+func mapNotConds(expr map[string]interface{}, notCmp, cmp condFunc) squirrel.Sqlizer {
+	return buildConds(expr, notCmp, cmp, true)
+}
+
+func buildConds(expr map[string]interface{}, plain, dynamic condFunc, negate bool) squirrel.Sqlizer {
+	fields := make([]string, 0, len(expr))
+	for f := range expr {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	var conds squirrel.And
+	for _, f := range fields {
+		v := expr[f]
+		name := strings.ToLower(f)
+		switch {
+		case strings.HasPrefix(name, "tag:"):
+			conds = append(conds, existsCond{negate: negate,
+				from: "item_tags it join tag on tag.id = it.tag_id",
+				cond: squirrel.And{
+					squirrel.Expr("it.item_id = media_file.id"),
+					squirrel.Eq{"it.item_type": "media_file"},
+					squirrel.Eq{"tag.tag_name": strings.TrimPrefix(name, "tag:")},
+					dynamic("tag.tag_value", v),
+				}})
+		case strings.HasPrefix(name, "role:"):
+			conds = append(conds, existsCond{negate: negate,
+				from: "media_file_artists mfa join artist on artist.id = mfa.artist_id",
+				cond: squirrel.And{
+					squirrel.Expr("mfa.media_file_id = media_file.id"),
+					squirrel.Eq{"mfa.role": strings.TrimPrefix(name, "role:")},
+					dynamic("artist.name", v),
+				}})
+		default:
+			if dbf := fieldMap[name]; dbf != nil && dbf.field != "" {
+				conds = append(conds, plain(dbf.field, v))
+			} else {
+				log.Error("Invalid field in criteria", "field", f)
+			}
+		}
+	}
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return conds
+}
+
+// existsCond renders an EXISTS (or NOT EXISTS) subquery over the given from clause
+type existsCond struct {
+	from   string
+	cond   squirrel.Sqlizer
+	negate bool
+}
+
+func (e existsCond) ToSql() (string, []interface{}, error) {
+	sql, args, err := e.cond.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	op := "exists"
+	if e.negate {
+		op = "not exists"
+	}
+	return op + " (select 1 from " + e.from + " where " + sql + ")", args, nil
+}
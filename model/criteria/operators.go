@@ -36,11 +36,15 @@ func (any Any) MarshalJSON() ([]byte, error) {
 	return marshalConjunction("any", any)
 }
 
+func eqCond(field string, value interface{}) squirrel.Sqlizer {
+	return squirrel.Eq{field: value}
+}
+
 type Is squirrel.Eq
 type Eq = Is
 
 func (is Is) ToSql() (sql string, args []interface{}, err error) {
-	return squirrel.Eq(mapFields(is)).ToSql()
+	return mapConds(is, eqCond).ToSql()
 }
 
 func (is Is) MarshalJSON() ([]byte, error) {
@@ -50,7 +54,9 @@ func (is Is) MarshalJSON() ([]byte, error) {
 type IsNot squirrel.NotEq
 
 func (in IsNot) ToSql() (sql string, args []interface{}, err error) {
-	return squirrel.NotEq(mapFields(in)).ToSql()
+	return mapNotConds(in, func(field string, value interface{}) squirrel.Sqlizer {
+		return squirrel.NotEq{field: value}
+	}, eqCond).ToSql()
 }
 
 func (in IsNot) MarshalJSON() ([]byte, error) {
@@ -60,7 +66,9 @@ func (in IsNot) MarshalJSON() ([]byte, error) {
 type Gt squirrel.Gt
 
 func (gt Gt) ToSql() (sql string, args []interface{}, err error) {
-	return squirrel.Gt(mapFields(gt)).ToSql()
+	return mapConds(gt, func(field string, value interface{}) squirrel.Sqlizer {
+		return squirrel.Gt{field: value}
+	}).ToSql()
 }
 
 func (gt Gt) MarshalJSON() ([]byte, error) {
@@ -70,7 +78,9 @@ func (gt Gt) MarshalJSON() ([]byte, error) {
 type Lt squirrel.Lt
 
 func (lt Lt) ToSql() (sql string, args []interface{}, err error) {
-	return squirrel.Lt(mapFields(lt)).ToSql()
+	return mapConds(lt, func(field string, value interface{}) squirrel.Sqlizer {
+		return squirrel.Lt{field: value}
+	}).ToSql()
 }
 
 func (lt Lt) MarshalJSON() ([]byte, error) {
@@ -80,7 +90,9 @@ func (lt Lt) MarshalJSON() ([]byte, error) {
 type Before squirrel.Lt
 
 func (bf Before) ToSql() (sql string, args []interface{}, err error) {
-	return squirrel.Lt(mapFields(bf)).ToSql()
+	return mapConds(bf, func(field string, value interface{}) squirrel.Sqlizer {
+		return squirrel.Lt{field: value}
+	}).ToSql()
 }
 
 func (bf Before) MarshalJSON() ([]byte, error) {
@@ -90,7 +102,9 @@ func (bf Before) MarshalJSON() ([]byte, error) {
 type After squirrel.Gt
 
 func (af After) ToSql() (sql string, args []interface{}, err error) {
-	return squirrel.Gt(mapFields(af)).ToSql()
+	return mapConds(af, func(field string, value interface{}) squirrel.Sqlizer {
+		return squirrel.Gt{field: value}
+	}).ToSql()
 }
 
 func (af After) MarshalJSON() ([]byte, error) {
@@ -99,12 +113,12 @@ func (af After) MarshalJSON() ([]byte, error) {
 
 type Contains map[string]interface{}
 
+func containsCond(field string, value interface{}) squirrel.Sqlizer {
+	return squirrel.Like{field: fmt.Sprintf("%%%s%%", value)}
+}
+
 func (ct Contains) ToSql() (sql string, args []interface{}, err error) {
-	lk := squirrel.Like{}
-	for f, v := range mapFields(ct) {
-		lk[f] = fmt.Sprintf("%%%s%%", v)
-	}
-	return lk.ToSql()
+	return mapConds(ct, containsCond).ToSql()
 }
 
 func (ct Contains) MarshalJSON() ([]byte, error) {
@@ -114,11 +128,9 @@ func (ct Contains) MarshalJSON() ([]byte, error) {
 type NotContains map[string]interface{}
 
 func (nct NotContains) ToSql() (sql string, args []interface{}, err error) {
-	lk := squirrel.NotLike{}
-	for f, v := range mapFields(nct) {
-		lk[f] = fmt.Sprintf("%%%s%%", v)
-	}
-	return lk.ToSql()
+	return mapNotConds(nct, func(field string, value interface{}) squirrel.Sqlizer {
+		return squirrel.NotLike{field: fmt.Sprintf("%%%s%%", value)}
+	}, containsCond).ToSql()
 }
 
 func (nct NotContains) MarshalJSON() ([]byte, error) {
@@ -128,11 +140,9 @@ func (nct NotContains) MarshalJSON() ([]byte, error) {
 type StartsWith map[string]interface{}
 
 func (sw StartsWith) ToSql() (sql string, args []interface{}, err error) {
-	lk := squirrel.Like{}
-	for f, v := range mapFields(sw) {
-		lk[f] = fmt.Sprintf("%s%%", v)
-	}
-	return lk.ToSql()
+	return mapConds(sw, func(field string, value interface{}) squirrel.Sqlizer {
+		return squirrel.Like{field: fmt.Sprintf("%s%%", value)}
+	}).ToSql()
 }
 
 func (sw StartsWith) MarshalJSON() ([]byte, error) {
@@ -142,11 +152,9 @@ func (sw StartsWith) MarshalJSON() ([]byte, error) {
 type EndsWith map[string]interface{}
 
 func (sw EndsWith) ToSql() (sql string, args []interface{}, err error) {
-	lk := squirrel.Like{}
-	for f, v := range mapFields(sw) {
-		lk[f] = fmt.Sprintf("%%%s", v)
-	}
-	return lk.ToSql()
+	return mapConds(sw, func(field string, value interface{}) squirrel.Sqlizer {
+		return squirrel.Like{field: fmt.Sprintf("%%%s", value)}
+	}).ToSql()
 }
 
 func (sw EndsWith) MarshalJSON() ([]byte, error) {
@@ -156,18 +164,22 @@ func (sw EndsWith) MarshalJSON() ([]byte, error) {
 type InTheRange map[string]interface{}
 
 func (itr InTheRange) ToSql() (sql string, args []interface{}, err error) {
-	var and squirrel.And
-	for f, v := range mapFields(itr) {
-		s := reflect.ValueOf(v)
+	var rangeErr error
+	cond := mapConds(itr, func(field string, value interface{}) squirrel.Sqlizer {
+		s := reflect.ValueOf(value)
 		if s.Kind() != reflect.Slice || s.Len() != 2 {
-			return "", nil, fmt.Errorf("invalid range for 'in' operator: %s", v)
+			rangeErr = fmt.Errorf("invalid range for 'in' operator: %s", value)
+			return squirrel.And{}
+		}
+		return squirrel.And{
+			squirrel.GtOrEq{field: s.Index(0).Interface()},
+			squirrel.LtOrEq{field: s.Index(1).Interface()},
 		}
-		and = append(and,
-			squirrel.GtOrEq{f: s.Index(0).Interface()},
-			squirrel.LtOrEq{f: s.Index(1).Interface()},
-		)
+	})
+	if rangeErr != nil {
+		return "", nil, rangeErr
 	}
-	return and.ToSql()
+	return cond.ToSql()
 }
 
 func (itr InTheRange) MarshalJSON() ([]byte, error) {
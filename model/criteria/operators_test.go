@@ -40,6 +40,29 @@ var _ = Describe("Operators", func() {
 			"(SELECT media_file_id FROM playlist_tracks pl LEFT JOIN playlist on pl.playlist_id = playlist.id WHERE (pl.playlist_id = ? AND playlist.public = ?))", "deadbeef-dead-beef", 1),
 		Entry("notInPlaylist", NotInPlaylist{"id": "deadbeef-dead-beef"}, "media_file.id NOT IN "+
 			"(SELECT media_file_id FROM playlist_tracks pl LEFT JOIN playlist on pl.playlist_id = playlist.id WHERE (pl.playlist_id = ? AND playlist.public = ?))", "deadbeef-dead-beef", 1),
+		Entry("is [new fields]", Is{"missing": false}, "media_file.missing = ?", false),
+		Entry("gt [bitDepth]", Gt{"bitDepth": 16}, "media_file.bit_depth > ?", 16),
+		Entry("after [birthTime]", After{"birthTime": rangeStart}, "media_file.birth_time > ?", rangeStart),
+		Entry("is [tag]", Is{"tag:mood": "Happy"},
+			"exists (select 1 from item_tags it join tag on tag.id = it.tag_id where "+
+				"(it.item_id = media_file.id AND it.item_type = ? AND tag.tag_name = ? AND tag.tag_value = ?))",
+			"media_file", "mood", "Happy"),
+		Entry("isNot [tag]", IsNot{"tag:mood": "Happy"},
+			"not exists (select 1 from item_tags it join tag on tag.id = it.tag_id where "+
+				"(it.item_id = media_file.id AND it.item_type = ? AND tag.tag_name = ? AND tag.tag_value = ?))",
+			"media_file", "mood", "Happy"),
+		Entry("contains [tag]", Contains{"tag:language": "por"},
+			"exists (select 1 from item_tags it join tag on tag.id = it.tag_id where "+
+				"(it.item_id = media_file.id AND it.item_type = ? AND tag.tag_name = ? AND tag.tag_value LIKE ?))",
+			"media_file", "language", "%por%"),
+		Entry("is [role]", Is{"role:composer": "Philip Glass"},
+			"exists (select 1 from media_file_artists mfa join artist on artist.id = mfa.artist_id where "+
+				"(mfa.media_file_id = media_file.id AND mfa.role = ? AND artist.name = ?))",
+			"composer", "Philip Glass"),
+		Entry("startsWith [role]", StartsWith{"role:producer": "Brian"},
+			"exists (select 1 from media_file_artists mfa join artist on artist.id = mfa.artist_id where "+
+				"(mfa.media_file_id = media_file.id AND mfa.role = ? AND artist.name LIKE ?))",
+			"producer", "Brian%"),
 	)
 
 	DescribeTable("JSON Marshaling",
@@ -72,5 +95,7 @@ var _ = Describe("Operators", func() {
 		Entry("notInTheLast", NotInTheLast{"lastPlayed": 30.0}, `{"notInTheLast":{"lastPlayed":30}}`),
 		Entry("inPlaylist", InPlaylist{"id": "deadbeef-dead-beef"}, `{"inPlaylist":{"id":"deadbeef-dead-beef"}}`),
 		Entry("notInPlaylist", NotInPlaylist{"id": "deadbeef-dead-beef"}, `{"notInPlaylist":{"id":"deadbeef-dead-beef"}}`),
+		Entry("is [tag]", Is{"tag:mood": "Happy"}, `{"is":{"tag:mood":"Happy"}}`),
+		Entry("contains [role]", Contains{"role:composer": "Glass"}, `{"contains":{"role:composer":"Glass"}}`),
 	)
 })
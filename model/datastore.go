@@ -14,6 +14,10 @@ type QueryOptions struct {
 	Offset  int
 	Filters squirrel.Sqlizer
 	Seed    string // for random sorting
+
+	// IncludeEmpty includes albums with zero tracks, a transient state that can be seen
+	// mid-scan or after partial deletes. They are hidden by default
+	IncludeEmpty bool
 }
 
 type ResourceRepository interface {
@@ -22,12 +26,16 @@ type ResourceRepository interface {
 
 type DataStore interface {
 	Library(ctx context.Context) LibraryRepository
+	Folder(ctx context.Context) FolderRepository
 	Album(ctx context.Context) AlbumRepository
 	Artist(ctx context.Context) ArtistRepository
 	MediaFile(ctx context.Context) MediaFileRepository
 	Genre(ctx context.Context) GenreRepository
+	Tag(ctx context.Context) TagRepository
 	Playlist(ctx context.Context) PlaylistRepository
 	PlayQueue(ctx context.Context) PlayQueueRepository
+	PlayEvent(ctx context.Context) PlayEventRepository
+	ScanLog(ctx context.Context) ScanLogRepository
 	Transcoding(ctx context.Context) TranscodingRepository
 	Player(ctx context.Context) PlayerRepository
 	Radio(ctx context.Context) RadioRepository
@@ -41,4 +49,47 @@ type DataStore interface {
 
 	WithTx(func(tx DataStore) error) error
 	GC(ctx context.Context, rootFolder string) error
+
+	// CleanOrphanAnnotations reports how many annotations point to media files, albums or
+	// artists that no longer exist, per item type. When fix is true, the orphans are also deleted
+	CleanOrphanAnnotations(ctx context.Context, fix bool) (map[string]int64, error)
+
+	// MergeDuplicateArtists folds artists whose names map to the same normalized ID (see
+	// ParticipantID) into a single artist, combining their annotations and re-pointing their
+	// participations. No-op unless Scanner.NormalizeArtistNames is enabled
+	MergeDuplicateArtists(ctx context.Context) (int64, error)
+
+	// MergeDuplicateFolders folds folder rows whose paths map to the same normalized ID (see
+	// FolderID) into a single folder, re-pointing their media files and child folders
+	MergeDuplicateFolders(ctx context.Context) (int64, error)
+
+	// MergeTagValues folds the given variant values of a tag (or genre, for tagName "genre")
+	// into the canonical value, re-pointing the item links, updating the denormalized genre
+	// columns and deleting the now-unreferenced rows, in a single transaction. The mapping is
+	// recorded (see TagMappings) so future scans re-apply it instead of re-creating the
+	// variants from the file tags. With dryRun, nothing is changed and only the number of
+	// affected rows is returned
+	MergeTagValues(ctx context.Context, tagName string, from []string, to string, dryRun bool) (int64, error)
+
+	// DeleteMissingFiles permanently removes the given tracks, which must be flagged as
+	// missing, together with their annotations, playlist entries and tag links, in a single
+	// transaction. Playlist positions are compacted after the removal
+	DeleteMissingFiles(ctx context.Context, ids []string) error
+
+	// DeleteLibrary permanently removes a library together with its folders, media files
+	// and albums, and any artists left orphaned by the removal, in a single transaction.
+	// The default music folder (DefaultLibraryID) cannot be removed
+	DeleteLibrary(ctx context.Context, id int) error
+
+	// RelocateLibrary rewrites the library's path prefix on its folder and media_file rows
+	// (recomputing their path-derived ids and re-pointing annotations, bookmarks, playlist
+	// entries and tag links), then updates the library row, all in a single transaction. It
+	// refuses to run when the new path overlaps another library's, and returns the number of
+	// rows rewritten
+	RelocateLibrary(ctx context.Context, id int, newPath string) (int64, error)
+
+	// ResolvePlaylistTracks re-points playlist entries referencing missing tracks to the
+	// non-missing track that represents the same recording, preserving positions and
+	// collapsing entries that become adjacent duplicates
+	ResolvePlaylistTracks(ctx context.Context) (int64, error)
 }
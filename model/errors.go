@@ -4,8 +4,10 @@ import "errors"
 
 var (
 	ErrNotFound      = errors.New("data not found")
+	ErrMultipleFound = errors.New("multiple records found")
 	ErrInvalidAuth   = errors.New("invalid authentication")
 	ErrNotAuthorized = errors.New("not authorized")
 	ErrExpired       = errors.New("access expired")
 	ErrNotAvailable  = errors.New("functionality not available")
+	ErrSourceMissing = errors.New("source file missing")
 )
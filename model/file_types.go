@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+
+	"github.com/navidrome/navidrome/conf"
 )
 
 var excludeAudioType = []string{
@@ -13,10 +15,39 @@ var excludeAudioType = []string{
 	"audio/x-scpls",
 }
 
+// IsAudioFile reports whether the file should be imported as an audio track. The decision is
+// based on the MIME type registered for the file extension (see resources/mime_types.yaml and
+// the AdditionalMimeTypes option), further restricted by the Scanner.AllowedAudioTypes and
+// Scanner.ExcludedAudioTypes lists
 func IsAudioFile(filePath string) bool {
-	extension := filepath.Ext(filePath)
+	extension := strings.ToLower(filepath.Ext(filePath))
 	mimeType := mime.TypeByExtension(extension)
-	return !slices.Contains(excludeAudioType, mimeType) && strings.HasPrefix(mimeType, "audio/")
+	if slices.Contains(excludeAudioType, mimeType) || !strings.HasPrefix(mimeType, "audio/") {
+		return false
+	}
+	if matchesAudioType(conf.Server.Scanner.ExcludedAudioTypes, extension, mimeType) {
+		return false
+	}
+	allowed := conf.Server.Scanner.AllowedAudioTypes
+	return len(allowed) == 0 || matchesAudioType(allowed, extension, mimeType)
+}
+
+// matchesAudioType checks the file extension and MIME type against an audio type list. Entries
+// can be extensions (with or without the leading dot) or MIME types, matched case-insensitively
+func matchesAudioType(list []string, extension, mimeType string) bool {
+	for _, entry := range list {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			entry = "." + strings.TrimPrefix(entry, ".")
+		}
+		if entry == extension || entry == mimeType {
+			return true
+		}
+	}
+	return false
 }
 
 func IsImageFile(filePath string) bool {
@@ -3,6 +3,7 @@ package model_test
 import (
 	"path/filepath"
 
+	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/model"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -29,6 +30,32 @@ var _ = Describe("File Types()", func() {
 		It("returns false for pls files", func() {
 			Expect(model.IsAudioFile("test.pls")).To(BeFalse())
 		})
+
+		Context("with audio type filters configured", func() {
+			AfterEach(func() {
+				conf.Server.Scanner.AllowedAudioTypes = nil
+				conf.Server.Scanner.ExcludedAudioTypes = nil
+			})
+
+			It("excludes extensions in the denylist", func() {
+				conf.Server.Scanner.ExcludedAudioTypes = []string{"mka"}
+				Expect(model.IsAudioFile("audiobook.mka")).To(BeFalse())
+				Expect(model.IsAudioFile("test.mp3")).To(BeTrue())
+			})
+
+			It("excludes MIME types in the denylist", func() {
+				conf.Server.Scanner.ExcludedAudioTypes = []string{"audio/x-wav"}
+				Expect(model.IsAudioFile("notification.wav")).To(BeFalse())
+				Expect(model.IsAudioFile("test.flac")).To(BeTrue())
+			})
+
+			It("only imports the listed types when an allowlist is set", func() {
+				conf.Server.Scanner.AllowedAudioTypes = []string{".mp3", "audio/flac"}
+				Expect(model.IsAudioFile("test.mp3")).To(BeTrue())
+				Expect(model.IsAudioFile("test.flac")).To(BeTrue())
+				Expect(model.IsAudioFile("test.ogg")).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("IsImageFile()", func() {
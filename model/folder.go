@@ -0,0 +1,41 @@
+package model
+
+import (
+	"crypto/md5"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Folder represents a directory from one of the music libraries. Folders are linked to their
+// parent through ParentID, forming a tree rooted at the library path.
+type Folder struct {
+	ID        string `structs:"id" json:"id"`
+	LibraryID int    `structs:"library_id" json:"libraryId"`
+	Path      string `structs:"path" json:"path"`
+	ParentID  string `structs:"parent_id" json:"parentId"`
+	// Fingerprint summarizes the folder contents as seen by the last scan that processed it,
+	// so unchanged folders can be skipped without loading their tracks from the DB
+	Fingerprint string `structs:"fingerprint" json:"-"`
+}
+
+// FolderID returns the deterministic ID for the folder at the given path. The path is
+// normalized (NFC, forward slashes, lower case) before hashing, so variant spellings of the
+// same directory map to the same folder
+func FolderID(path string) string {
+	path = strings.ToLower(filepath.ToSlash(norm.NFC.String(path)))
+	return fmt.Sprintf("%x", md5.Sum([]byte(path)))
+}
+
+type Folders []Folder
+
+type FolderRepository interface {
+	Get(id string) (*Folder, error)
+	GetByPath(path string) (*Folder, error)
+	Put(*Folder) error
+	GetAll(...QueryOptions) (Folders, error)
+	GetChildren(folderID string, options ...QueryOptions) (Folders, error)
+	CountAll(...QueryOptions) (int64, error)
+}
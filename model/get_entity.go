@@ -22,5 +22,9 @@ func GetEntityByID(ctx context.Context, ds DataStore, id string) (interface{}, e
 	if err == nil {
 		return mf, nil
 	}
+	f, err := ds.Folder(ctx).Get(id)
+	if err == nil {
+		return f, nil
+	}
 	return nil, err
 }
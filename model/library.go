@@ -6,11 +6,16 @@ import (
 	"time"
 )
 
+// DefaultLibraryID is the id of the library created from the MusicFolder config option. It
+// always exists and cannot be removed through the API
+const DefaultLibraryID = 1
+
 type Library struct {
 	ID         int
 	Name       string
 	Path       string
 	RemotePath string
+	Extractor  string // Metadata extractor override for this library; empty uses Scanner.Extractor
 	LastScanAt time.Time
 	UpdatedAt  time.Time
 	CreatedAt  time.Time
@@ -28,5 +33,9 @@ type LibraryRepository interface {
 	StoreMusicFolder() error
 	AddArtist(id int, artistID string) error
 	UpdateLastScan(id int, t time.Time) error
+	// GrantAccess makes the library's content visible to the user. No-op if already
+	// granted. Admins see every library and don't need grants
+	GrantAccess(id int, userID string) error
+	RevokeAccess(id int, userID string) error
 	GetAll(...QueryOptions) (Libraries, error)
 }
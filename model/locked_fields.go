@@ -0,0 +1,201 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// LockedFields is the list of fields of a media file (or album) that are locked against
+// scanner updates: their DB values take precedence over whatever is in the file tags, until
+// they are unlocked again. Fields are locked explicitly through the native API, or implicitly
+// by the bulk edit path.
+type LockedFields []string
+
+func (l LockedFields) IsLocked(field string) bool {
+	return slices.Contains(l, field)
+}
+
+// Lock returns the list with the given field added. Locking an already locked field is a no-op.
+func (l LockedFields) Lock(field string) LockedFields {
+	if l.IsLocked(field) {
+		return l
+	}
+	l = append(l, field)
+	slices.Sort(l)
+	return l
+}
+
+// Unlock returns the list with the given field removed, letting the next scan restore the
+// value from the file tags.
+func (l LockedFields) Unlock(field string) LockedFields {
+	return slices.DeleteFunc(l, func(f string) bool { return f == field })
+}
+
+// Value implements driver.Valuer, serializing the list as a JSON array
+func (l LockedFields) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "", nil
+	}
+	value, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling locked fields: %w", err)
+	}
+	return string(value), nil
+}
+
+// Scan implements sql.Scanner, parsing the serialized list
+func (l *LockedFields) Scan(value any) error {
+	var data string
+	switch v := value.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		return nil
+	}
+	if data == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(data), l)
+}
+
+// mediaFileFieldLocks maps each lockable media file field (the same names used by the bulk
+// edit API) to the function that copies its locked DB value over the freshly scanned one. It
+// reports whether the scanned value differed, i.e. whether an update was suppressed.
+var mediaFileFieldLocks = map[string]func(dst *MediaFile, locked MediaFile) bool{
+	"title": func(dst *MediaFile, locked MediaFile) bool {
+		changed := dst.Title != locked.Title
+		dst.Title = locked.Title
+		dst.SortTitle = locked.SortTitle
+		dst.OrderTitle = locked.OrderTitle
+		return changed
+	},
+	"artist": func(dst *MediaFile, locked MediaFile) bool {
+		changed := dst.Artist != locked.Artist
+		dst.Artist = locked.Artist
+		dst.ArtistID = locked.ArtistID
+		dst.SortArtistName = locked.SortArtistName
+		dst.OrderArtistName = locked.OrderArtistName
+		if dst.Participations != nil {
+			dst.Participations[RoleArtist] = []Participant{{ID: locked.ArtistID, Name: locked.Artist, SortName: locked.SortArtistName}}
+		}
+		return changed
+	},
+	"albumArtist": func(dst *MediaFile, locked MediaFile) bool {
+		changed := dst.AlbumArtist != locked.AlbumArtist
+		dst.AlbumArtist = locked.AlbumArtist
+		dst.AlbumArtistID = locked.AlbumArtistID
+		dst.SortAlbumArtistName = locked.SortAlbumArtistName
+		dst.OrderAlbumArtistName = locked.OrderAlbumArtistName
+		if dst.Participations != nil {
+			dst.Participations[RoleAlbumArtist] = []Participant{{ID: locked.AlbumArtistID, Name: locked.AlbumArtist, SortName: locked.SortAlbumArtistName}}
+		}
+		return changed
+	},
+	"genre": func(dst *MediaFile, locked MediaFile) bool {
+		changed := dst.Genre != locked.Genre
+		dst.Genre = locked.Genre
+		dst.Genres = locked.Genres
+		return changed
+	},
+	"comment": func(dst *MediaFile, locked MediaFile) bool {
+		changed := dst.Comment != locked.Comment
+		dst.Comment = locked.Comment
+		return changed
+	},
+	"year": func(dst *MediaFile, locked MediaFile) bool {
+		changed := dst.Year != locked.Year
+		dst.Year = locked.Year
+		dst.Date = locked.Date
+		return changed
+	},
+	"bpm": func(dst *MediaFile, locked MediaFile) bool {
+		changed := dst.Bpm != locked.Bpm
+		dst.Bpm = locked.Bpm
+		return changed
+	},
+}
+
+// albumFieldLocks is the equivalent of mediaFileFieldLocks for album fields, applied when the
+// scanner re-aggregates an album from its tracks
+var albumFieldLocks = map[string]func(dst *Album, locked Album) bool{
+	"name": func(dst *Album, locked Album) bool {
+		changed := dst.Name != locked.Name
+		dst.Name = locked.Name
+		dst.SortAlbumName = locked.SortAlbumName
+		dst.OrderAlbumName = locked.OrderAlbumName
+		return changed
+	},
+	"albumArtist": func(dst *Album, locked Album) bool {
+		changed := dst.AlbumArtist != locked.AlbumArtist
+		dst.AlbumArtist = locked.AlbumArtist
+		dst.AlbumArtistID = locked.AlbumArtistID
+		dst.SortAlbumArtistName = locked.SortAlbumArtistName
+		dst.OrderAlbumArtistName = locked.OrderAlbumArtistName
+		return changed
+	},
+	"genre": func(dst *Album, locked Album) bool {
+		changed := dst.Genre != locked.Genre
+		dst.Genre = locked.Genre
+		dst.Genres = locked.Genres
+		return changed
+	},
+	"comment": func(dst *Album, locked Album) bool {
+		changed := dst.Comment != locked.Comment
+		dst.Comment = locked.Comment
+		return changed
+	},
+	"coverArt": func(dst *Album, locked Album) bool {
+		changed := dst.CoverArtPath != locked.CoverArtPath
+		dst.CoverArtPath = locked.CoverArtPath
+		dst.CoverArtSize = locked.CoverArtSize
+		dst.CoverArtUpdatedAt = locked.CoverArtUpdatedAt
+		return changed
+	},
+}
+
+func IsLockableMediaFileField(field string) bool {
+	_, ok := mediaFileFieldLocks[field]
+	return ok
+}
+
+func IsLockableAlbumField(field string) bool {
+	_, ok := albumFieldLocks[field]
+	return ok
+}
+
+// ApplyFieldLocks carries the locks from the current DB row over to the freshly scanned media
+// file, copying the locked field values with them. It returns how many scanned updates were
+// suppressed, for the scan report. Unknown field names in the list are ignored.
+func (mf *MediaFile) ApplyFieldLocks(current MediaFile) int {
+	if len(current.LockedFields) == 0 {
+		return 0
+	}
+	mf.LockedFields = current.LockedFields
+	suppressed := 0
+	for _, field := range current.LockedFields {
+		if apply, ok := mediaFileFieldLocks[field]; ok && apply(mf, current) {
+			suppressed++
+		}
+	}
+	return suppressed
+}
+
+// ApplyFieldLocks is the album counterpart of MediaFile.ApplyFieldLocks, applied when an album
+// is re-aggregated from its tracks
+func (a *Album) ApplyFieldLocks(current Album) int {
+	if len(current.LockedFields) == 0 {
+		return 0
+	}
+	a.LockedFields = current.LockedFields
+	suppressed := 0
+	for _, field := range current.LockedFields {
+		if apply, ok := albumFieldLocks[field]; ok && apply(a, current) {
+			suppressed++
+		}
+	}
+	return suppressed
+}
@@ -0,0 +1,99 @@
+package model_test
+
+import (
+	. "github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LockedFields", func() {
+	Describe("Lock/Unlock", func() {
+		It("adds and removes fields, keeping the list sorted and without duplicates", func() {
+			var locks LockedFields
+			locks = locks.Lock("title")
+			locks = locks.Lock("artist")
+			locks = locks.Lock("title")
+			Expect(locks).To(Equal(LockedFields{"artist", "title"}))
+			Expect(locks.IsLocked("artist")).To(BeTrue())
+
+			locks = locks.Unlock("artist")
+			Expect(locks).To(Equal(LockedFields{"title"}))
+			Expect(locks.IsLocked("artist")).To(BeFalse())
+		})
+	})
+
+	Describe("Value/Scan", func() {
+		It("round-trips through the DB representation", func() {
+			locks := LockedFields{"artist", "title"}
+			value, err := locks.Value()
+			Expect(err).ToNot(HaveOccurred())
+
+			var read LockedFields
+			Expect(read.Scan(value)).To(Succeed())
+			Expect(read).To(Equal(locks))
+		})
+
+		It("serializes an empty list as an empty string", func() {
+			Expect(LockedFields{}.Value()).To(Equal(""))
+
+			var read LockedFields
+			Expect(read.Scan("")).To(Succeed())
+			Expect(read).To(BeEmpty())
+		})
+	})
+
+	Describe("MediaFile.ApplyFieldLocks", func() {
+		var current, scanned MediaFile
+
+		BeforeEach(func() {
+			current = MediaFile{
+				Artist: "Original Artist", ArtistID: "ar-1", OrderArtistName: "original artist",
+				Title: "Original Title", LockedFields: LockedFields{"artist"},
+			}
+			scanned = MediaFile{
+				Artist: "Retagged Artist", ArtistID: "ar-2", OrderArtistName: "retagged artist",
+				Title: "Retagged Title",
+				Participations: Participations{
+					RoleArtist: []Participant{{ID: "ar-2", Name: "Retagged Artist"}},
+				},
+			}
+		})
+
+		It("keeps the locked values and carries the locks over", func() {
+			Expect(scanned.ApplyFieldLocks(current)).To(Equal(1))
+			Expect(scanned.Artist).To(Equal("Original Artist"))
+			Expect(scanned.ArtistID).To(Equal("ar-1"))
+			Expect(scanned.OrderArtistName).To(Equal("original artist"))
+			Expect(scanned.Participations[RoleArtist]).To(HaveLen(1))
+			Expect(scanned.Participations[RoleArtist][0].Name).To(Equal("Original Artist"))
+			Expect(scanned.LockedFields).To(Equal(LockedFields{"artist"}))
+
+			// Unlocked fields still follow the tags
+			Expect(scanned.Title).To(Equal("Retagged Title"))
+		})
+
+		It("does not count suppressed updates when the values match", func() {
+			scanned.Artist = current.Artist
+			scanned.ArtistID = current.ArtistID
+			Expect(scanned.ApplyFieldLocks(current)).To(BeZero())
+		})
+
+		It("ignores unknown field names", func() {
+			current.LockedFields = LockedFields{"path"}
+			Expect(scanned.ApplyFieldLocks(current)).To(BeZero())
+			Expect(scanned.Artist).To(Equal("Retagged Artist"))
+		})
+	})
+
+	Describe("Album.ApplyFieldLocks", func() {
+		It("keeps the locked values and carries the locks over", func() {
+			current := Album{Name: "Original Name", Comment: "A comment", LockedFields: LockedFields{"name"}}
+			refreshed := Album{Name: "Aggregated Name", Comment: "New comment"}
+
+			Expect(refreshed.ApplyFieldLocks(current)).To(Equal(1))
+			Expect(refreshed.Name).To(Equal("Original Name"))
+			Expect(refreshed.Comment).To(Equal("New comment"))
+			Expect(refreshed.LockedFields).To(Equal(LockedFields{"name"}))
+		})
+	})
+})
@@ -36,6 +36,16 @@ var _ = Describe("ToLyrics", func() {
 		}))
 	})
 
+	It("treats malformed timestamps as plain text", func() {
+		a := int64(1000)
+		lyrics, err := ToLyrics("xxx", "[00:01.00]Hi there\n[ab:cd.ef] not a timestamp\n[12:34 neither")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lyrics.Synced).To(BeTrue())
+		Expect(lyrics.Line).To(Equal([]Line{
+			{Start: &a, Value: "Hi there\n[ab:cd.ef] not a timestamp\n[12:34 neither"},
+		}))
+	})
+
 	It("Should support multiple timestamps per line", func() {
 		a, b, c, d := int64(0), int64(10000), int64(13*60*1000), int64(1000*60*60*51)
 		lyrics, err := ToLyrics("xxx", "[00:00.00]  [00:10.00]Repeated\n[13:00][51:00:00.00]")
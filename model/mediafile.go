@@ -3,14 +3,17 @@ package model
 import (
 	"cmp"
 	"encoding/json"
-	"mime"
+	"fmt"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/conf/mime"
 	"github.com/navidrome/navidrome/consts"
 	"github.com/navidrome/navidrome/utils/slice"
 	"github.com/navidrome/navidrome/utils/str"
@@ -22,6 +25,7 @@ type MediaFile struct {
 
 	ID                   string  `structs:"id" json:"id"`
 	LibraryID            int     `structs:"library_id" json:"libraryId"`
+	FolderID             string  `structs:"folder_id" json:"folderId"`
 	Path                 string  `structs:"path" json:"path"`
 	Title                string  `structs:"title" json:"title"`
 	Album                string  `structs:"album" json:"album"`
@@ -30,9 +34,11 @@ type MediaFile struct {
 	AlbumArtistID        string  `structs:"album_artist_id" json:"albumArtistId"`
 	AlbumArtist          string  `structs:"album_artist" json:"albumArtist"`
 	AlbumID              string  `structs:"album_id" json:"albumId"`
+	AlbumPID             string  `structs:"album_pid" json:"-"`
 	HasCoverArt          bool    `structs:"has_cover_art" json:"hasCoverArt"`
 	TrackNumber          int     `structs:"track_number" json:"trackNumber"`
 	DiscNumber           int     `structs:"disc_number" json:"discNumber"`
+	TotalTracks          int     `structs:"total_tracks" json:"totalTracks,omitempty"`
 	DiscSubtitle         string  `structs:"disc_subtitle" json:"discSubtitle,omitempty"`
 	Year                 int     `structs:"year" json:"year"`
 	Date                 string  `structs:"date" json:"date,omitempty"`
@@ -42,12 +48,15 @@ type MediaFile struct {
 	ReleaseDate          string  `structs:"release_date" json:"releaseDate,omitempty"`
 	Size                 int64   `structs:"size" json:"size"`
 	Suffix               string  `structs:"suffix" json:"suffix"`
-	Duration             float32 `structs:"duration" json:"duration"`
+	Codec                string  `structs:"codec" json:"codec,omitempty"`
+	DurationMs           int64   `structs:"duration_ms" json:"-"`
 	BitRate              int     `structs:"bit_rate" json:"bitRate"`
 	SampleRate           int     `structs:"sample_rate" json:"sampleRate"`
+	BitDepth             int     `structs:"bit_depth" json:"bitDepth"`
 	Channels             int     `structs:"channels" json:"channels"`
 	Genre                string  `structs:"genre" json:"genre"`
 	Genres               Genres  `structs:"-" json:"genres"`
+	Tags                 Tags    `structs:"-" json:"tags,omitempty"`
 	FullText             string  `structs:"full_text" json:"-"`
 	SortTitle            string  `structs:"sort_title" json:"sortTitle,omitempty"`
 	SortAlbumName        string  `structs:"sort_album_name" json:"sortAlbumName,omitempty"`
@@ -61,10 +70,13 @@ type MediaFile struct {
 	Comment              string  `structs:"comment" json:"comment,omitempty"`
 	Lyrics               string  `structs:"lyrics" json:"lyrics"`
 	Bpm                  int     `structs:"bpm" json:"bpm,omitempty"`
+	InitialKey           string  `structs:"initial_key" json:"initialKey,omitempty"`
 	CatalogNum           string  `structs:"catalog_num" json:"catalogNum,omitempty"`
+	Isrc                 string  `structs:"isrc" json:"isrc,omitempty"`
 	MbzRecordingID       string  `structs:"mbz_recording_id" json:"mbzRecordingID,omitempty"`
 	MbzReleaseTrackID    string  `structs:"mbz_release_track_id" json:"mbzReleaseTrackId,omitempty"`
 	MbzAlbumID           string  `structs:"mbz_album_id" json:"mbzAlbumId,omitempty"`
+	MbzWorkID            string  `structs:"mbz_work_id" json:"mbzWorkId,omitempty"`
 	MbzArtistID          string  `structs:"mbz_artist_id" json:"mbzArtistId,omitempty"`
 	MbzAlbumArtistID     string  `structs:"mbz_album_artist_id" json:"mbzAlbumArtistId,omitempty"`
 	MbzAlbumType         string  `structs:"mbz_album_type" json:"mbzAlbumType,omitempty"`
@@ -73,15 +85,67 @@ type MediaFile struct {
 	RgAlbumPeak          float64 `structs:"rg_album_peak" json:"rgAlbumPeak"`
 	RgTrackGain          float64 `structs:"rg_track_gain" json:"rgTrackGain"`
 	RgTrackPeak          float64 `structs:"rg_track_peak" json:"rgTrackPeak"`
+	UserEdited           bool    `structs:"user_edited" json:"userEdited,omitempty"`
+
+	// RgTrackGainComputed/RgTrackPeakComputed hold loudness values measured by the gain
+	// analysis job, for files without ReplayGain tags. They are excluded from the mapped
+	// columns so regular puts (and therefore rescans) never overwrite them; they are only
+	// written through StoreComputedGain. Tag-provided values always take precedence
+	RgTrackGainComputed float64 `structs:"-" json:"rgTrackGainComputed,omitempty"`
+	RgTrackPeakComputed float64 `structs:"-" json:"rgTrackPeakComputed,omitempty"`
+
+	// LockedFields lists the fields that keep their DB values on subsequent scans, regardless
+	// of the file tags. See model.LockedFields
+	LockedFields LockedFields `structs:"locked_fields" json:"lockedFields,omitempty"`
+
+	// Missing is set by the scanner when the file disappears from disk. Missing tracks keep
+	// their annotations and playlist entries until they are permanently deleted
+	Missing bool `structs:"missing" json:"missing,omitempty"`
+
+	// Fingerprint is the acoustic fingerprint computed by the scanner for files without usable
+	// tags, so they can be identified externally. It is never exposed through the API.
+	Fingerprint string `structs:"fingerprint" json:"-"`
+
+	Participations Participations `structs:"participations,omitnested" json:"participations,omitempty"`
+
+	// ParticipationsTruncated indicates the participant lists above were capped at
+	// Server.MaxContributors. It is only set when serving API responses, and is never persisted.
+	ParticipationsTruncated bool `structs:"-" json:"participationsTruncated,omitempty"`
 
 	CreatedAt time.Time `structs:"created_at" json:"createdAt"` // Time this entry was created in the DB
 	UpdatedAt time.Time `structs:"updated_at" json:"updatedAt"` // Time of file last update (mtime)
+	BirthTime time.Time `structs:"birth_time" json:"birthTime"` // Time the file was created on disk; zero when the filesystem doesn't track it
 }
 
 func (mf MediaFile) ContentType() string {
 	return mime.TypeByExtension("." + mf.Suffix)
 }
 
+// TrackGain returns the ReplayGain track gain and peak to apply, preferring tag-provided
+// values and falling back to the ones computed by the gain analysis job
+func (mf MediaFile) TrackGain() (gain float64, peak float64) {
+	if mf.RgTrackGain != 0 || mf.RgTrackPeak != 0 {
+		return mf.RgTrackGain, mf.RgTrackPeak
+	}
+	return mf.RgTrackGainComputed, mf.RgTrackPeakComputed
+}
+
+// Duration returns the track length in float seconds, the unit all APIs expose. Internally it is
+// kept as integer milliseconds, so aggregations don't accumulate floating point drift
+func (mf MediaFile) Duration() float32 {
+	return float32(mf.DurationMs) / 1000
+}
+
+// MarshalJSON adds a `duration` attribute in float seconds, keeping the format the web API always
+// exposed for the duration
+func (mf MediaFile) MarshalJSON() ([]byte, error) {
+	type alias MediaFile
+	return json.Marshal(struct {
+		alias
+		Duration float32 `json:"duration"`
+	}{alias(mf), mf.Duration()})
+}
+
 func (mf MediaFile) CoverArtID() ArtworkID {
 	// If it has a cover art, return it (if feature is disabled, skip)
 	if mf.HasCoverArt && conf.Server.EnableMediaFileCoverArt {
@@ -104,6 +168,17 @@ func (mf MediaFile) StructuredLyrics() (LyricList, error) {
 	return lyrics, nil
 }
 
+// isrcRegexp matches the 12-character ISO 3901 format: country code, registrant code,
+// reference year and designation code
+var isrcRegexp = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{3}[0-9]{7}$`)
+
+// NormalizeISRC strips separators from the given ISRC and upper-cases it. Returns false if the
+// result is not a valid 12-character ISRC (ISO 3901)
+func NormalizeISRC(isrc string) (string, bool) {
+	isrc = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isrc))
+	return isrc, isrcRegexp.MatchString(isrc)
+}
+
 type MediaFiles []MediaFile
 
 // Dirs returns a deduped list of all directories from the MediaFiles' paths
@@ -121,6 +196,7 @@ func (mfs MediaFiles) Dirs() []string {
 // It assumes all mediafiles have the same Album, or else results are unpredictable.
 func (mfs MediaFiles) ToAlbum() Album {
 	a := Album{SongCount: len(mfs)}
+	var embedArtSource *MediaFile
 	var fullText []string
 	var albumArtistIds []string
 	var songArtistIds []string
@@ -131,19 +207,25 @@ func (mfs MediaFiles) ToAlbum() Album {
 	var originalYears []int
 	var originalDates []string
 	var releaseDates []string
-	for _, m := range mfs {
+	var songTags []Tags
+	var rgAlbumGains []float64
+	var rgAlbumPeaks []float64
+	var sampleRates []int
+	var bitDepths []int
+	var channels []int
+	var sortAlbumNames []string
+	var sortAlbumArtistNames []string
+	for i := range mfs {
+		m := mfs[i]
 		// We assume these attributes are all the same for all songs on an album
 		a.ID = m.AlbumID
+		a.PID = m.AlbumPID
 		a.Name = m.Album
 		a.Artist = m.Artist
 		a.ArtistID = m.ArtistID
 		a.AlbumArtist = m.AlbumArtist
 		a.AlbumArtistID = m.AlbumArtistID
-		a.SortAlbumName = m.SortAlbumName
 		a.SortArtistName = m.SortArtistName
-		a.SortAlbumArtistName = m.SortAlbumArtistName
-		a.OrderAlbumName = m.OrderAlbumName
-		a.OrderAlbumArtistName = m.OrderAlbumArtistName
 		a.MbzAlbumArtistID = m.MbzAlbumArtistID
 		a.MbzAlbumType = m.MbzAlbumType
 		a.MbzAlbumComment = m.MbzAlbumComment
@@ -151,7 +233,7 @@ func (mfs MediaFiles) ToAlbum() Album {
 		a.Compilation = m.Compilation
 
 		// Calculated attributes based on aggregations
-		a.Duration += m.Duration
+		a.DurationMs += m.DurationMs
 		a.Size += m.Size
 		years = append(years, m.Year)
 		dates = append(dates, m.Date)
@@ -160,23 +242,54 @@ func (mfs MediaFiles) ToAlbum() Album {
 		releaseDates = append(releaseDates, m.ReleaseDate)
 		a.UpdatedAt = newer(a.UpdatedAt, m.UpdatedAt)
 		a.CreatedAt = older(a.CreatedAt, m.CreatedAt)
+		// Files without a birth time (filesystem doesn't track it) count with their DB
+		// insertion time, so they don't leave the aggregate empty
+		bt := m.BirthTime
+		if bt.IsZero() {
+			bt = m.CreatedAt
+		}
+		a.MinBirthTime = older(a.MinBirthTime, bt)
+		a.MaxBirthTime = newer(a.MaxBirthTime, bt)
 		a.Genres = append(a.Genres, m.Genres...)
+		songTags = append(songTags, m.Tags)
+		rgAlbumGains = append(rgAlbumGains, m.RgAlbumGain)
+		rgAlbumPeaks = append(rgAlbumPeaks, m.RgAlbumPeak)
+		// Unknown (zero) audio properties are left out, so one untagged track does not drag
+		// the album's quality range down to zero
+		if m.SampleRate > 0 {
+			sampleRates = append(sampleRates, m.SampleRate)
+		}
+		if m.BitDepth > 0 {
+			bitDepths = append(bitDepths, m.BitDepth)
+		}
+		if m.Channels > 0 {
+			channels = append(channels, m.Channels)
+		}
 		comments = append(comments, m.Comment)
 		albumArtistIds = append(albumArtistIds, m.AlbumArtistID)
 		songArtistIds = append(songArtistIds, m.ArtistID)
+		sortAlbumNames = append(sortAlbumNames, m.SortAlbumName)
+		sortAlbumArtistNames = append(sortAlbumArtistNames, m.SortAlbumArtistName)
 		mbzAlbumIds = append(mbzAlbumIds, m.MbzAlbumID)
 		fullText = append(fullText,
 			m.Album, m.AlbumArtist, m.Artist,
 			m.SortAlbumName, m.SortAlbumArtistName, m.SortArtistName,
-			m.DiscSubtitle)
-		if m.HasCoverArt && a.EmbedArtPath == "" {
-			a.EmbedArtPath = m.Path
+			m.DiscSubtitle, m.CatalogNum)
+		// Also index every participating artist (names and sort names, deduplicated), so
+		// queries combining an album name with any of its credited artists (not just the
+		// album artist) find the album
+		fullText = append(fullText, m.Participations.AllSearchTerms()...)
+		if m.HasCoverArt && (embedArtSource == nil || compareAlbumOrder(m, *embedArtSource) < 0) {
+			embedArtSource = &mfs[i]
 		}
 		if m.DiscNumber > 0 {
 			a.Discs.Add(m.DiscNumber, m.DiscSubtitle)
 		}
 	}
 
+	if embedArtSource != nil {
+		a.EmbedArtPath = embedArtSource.Path
+	}
 	a.Paths = strings.Join(mfs.Dirs(), consts.Zwsp)
 	a.Date, _ = allOrNothing(dates)
 	a.OriginalDate, _ = allOrNothing(originalDates)
@@ -187,16 +300,121 @@ func (mfs MediaFiles) ToAlbum() Album {
 	a.Genre = slice.MostFrequent(a.Genres).Name
 	slices.SortFunc(a.Genres, func(a, b Genre) int { return cmp.Compare(a.ID, b.ID) })
 	a.Genres = slices.Compact(a.Genres)
+	a.Tags = AggregateTags(songTags)
 	a.FullText = " " + str.SanitizeStrings(fullText...)
+	a.Compilation = a.Compilation || looksLikeCompilation(albumArtistIds, songArtistIds)
 	a = fixAlbumArtist(a, albumArtistIds)
+	// Recompute sort/order names from the current tracks, so sort tag changes picked up by a
+	// rescan (and album artists replaced by "Various Artists") are reflected in the ordering
+	a.SortAlbumName = slice.MostFrequent(sortAlbumNames)
+	if a.AlbumArtistID == consts.VariousArtistsID {
+		a.SortAlbumArtistName = ""
+	} else {
+		a.SortAlbumArtistName = slice.MostFrequent(sortAlbumArtistNames)
+	}
+	a.OrderAlbumName = str.SanitizeFieldForSortingNoArticle(a.Name)
+	a.OrderAlbumArtistName = str.SanitizeFieldForSortingNoArticle(a.AlbumArtist)
 	songArtistIds = append(songArtistIds, a.AlbumArtistID, a.ArtistID)
 	slices.Sort(songArtistIds)
 	a.AllArtistIDs = strings.Join(slices.Compact(songArtistIds), " ")
 	a.MbzAlbumID = slice.MostFrequent(mbzAlbumIds)
+	a.RgAlbumGain = slice.MostFrequent(rgAlbumGains)
+	a.RgAlbumPeak = slice.MostFrequent(rgAlbumPeaks)
+	if len(sampleRates) > 0 {
+		a.MinSampleRate, a.MaxSampleRate = minMax(sampleRates)
+	}
+	if len(bitDepths) > 0 {
+		a.MinBitDepth, a.MaxBitDepth = minMax(bitDepths)
+	}
+	a.Channels = slice.MostFrequent(channels)
+	a.Complete, a.MissingTracks = detectTrackGaps(mfs)
 
 	return a
 }
 
+// detectTrackGaps finds holes in the album's track numbering, per disc. The expected number of
+// tracks comes from the "x/y" track number tag when present; without it, the highest track
+// number is used, so only gaps below it can be detected. Discs with unnumbered tracks are never
+// flagged from the heuristic, as any unnumbered track could be the one filling the hole. It
+// returns whether the album is complete, and a summary of the missing numbers
+func detectTrackGaps(mfs MediaFiles) (bool, string) {
+	type discInfo struct {
+		numbers    map[int]struct{}
+		count      int
+		expected   int
+		unnumbered bool
+	}
+	discs := map[int]*discInfo{}
+	for _, m := range mfs {
+		num := max(m.DiscNumber, 1)
+		d, ok := discs[num]
+		if !ok {
+			d = &discInfo{numbers: map[int]struct{}{}}
+			discs[num] = d
+		}
+		d.count++
+		if m.TrackNumber > 0 {
+			d.numbers[m.TrackNumber] = struct{}{}
+		} else {
+			d.unnumbered = true
+		}
+		d.expected = max(d.expected, m.TotalTracks)
+	}
+
+	complete := true
+	var parts []string
+	discNums := make([]int, 0, len(discs))
+	for num := range discs {
+		discNums = append(discNums, num)
+	}
+	slices.Sort(discNums)
+	for _, num := range discNums {
+		d := discs[num]
+		expected := d.expected
+		if expected == 0 {
+			if d.unnumbered || len(d.numbers) == 0 {
+				continue
+			}
+			for n := range d.numbers {
+				expected = max(expected, n)
+			}
+		} else if d.count >= expected {
+			// All tracks accounted for; numbering oddities (ex: merged releases) are not gaps
+			continue
+		}
+		var missing []string
+		if !d.unnumbered {
+			for n := 1; n <= expected; n++ {
+				if _, ok := d.numbers[n]; !ok {
+					missing = append(missing, strconv.Itoa(n))
+				}
+			}
+		}
+		if len(missing) == 0 {
+			if d.count >= expected {
+				continue
+			}
+			// Fewer tracks than the tag announces, but nothing pinpointable (unnumbered tracks)
+			complete = false
+			parts = append(parts, fmt.Sprintf("disc %d: %d of %d tracks", num, d.count, expected))
+			continue
+		}
+		complete = false
+		parts = append(parts, fmt.Sprintf("disc %d: missing %s", num, strings.Join(missing, ", ")))
+	}
+	return complete, strings.Join(parts, "; ")
+}
+
+// compareAlbumOrder sorts tracks by their position in the album, falling back to the path. It is
+// used to pick the embed art source deterministically, regardless of the order files were scanned
+func compareAlbumOrder(a, b MediaFile) int {
+	return cmp.Or(
+		cmp.Compare(a.DiscNumber, b.DiscNumber),
+		cmp.Compare(a.TrackNumber, b.TrackNumber),
+		cmp.Compare(a.Path, b.Path),
+	)
+}
+
 func allOrNothing(items []string) (string, int) {
 	sort.Strings(items)
 	items = slices.Compact(items)
@@ -236,6 +454,25 @@ func older(t1, t2 time.Time) time.Time {
 	return t1
 }
 
+// looksLikeCompilation detects untagged compilations: an album without a consistent album artist,
+// but with many distinct track artists, is assumed to be a compilation (ex: soundtracks, samplers).
+// Distinct counts are used so the same album duplicated in multiple folders is not misdetected.
+func looksLikeCompilation(albumArtistIds, songArtistIds []string) bool {
+	threshold := conf.Server.Scanner.VariousArtistsThreshold
+	if threshold < 1 || countDistinct(albumArtistIds) < 2 {
+		return false
+	}
+	return countDistinct(songArtistIds) >= threshold
+}
+
+func countDistinct(ids []string) int {
+	unique := map[string]struct{}{}
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	return len(unique)
+}
+
 func fixAlbumArtist(a Album, albumArtistIds []string) Album {
 	if !a.Compilation {
 		if a.AlbumArtistID == "" {
@@ -253,21 +490,105 @@ func fixAlbumArtist(a Album, albumArtistIds []string) Album {
 	return a
 }
 
+// FolderStats aggregates the tracks below one top-level folder of a library
+type FolderStats struct {
+	FolderID      string  `json:"folderId"`
+	Path          string  `json:"path"`
+	SongCount     int64   `json:"songCount"`
+	MissingCount  int64   `json:"missingCount"`
+	TotalSize     int64   `json:"totalSize"`
+	TotalDuration float64 `json:"totalDuration"`
+}
+
+// DuplicateGroup is one recording that appears to have been imported more than once: the same
+// album artist, album, track number and title at different paths. Entries carry the full
+// tracks, so callers can compare paths, bitrates and formats to decide which copy to keep
+type DuplicateGroup struct {
+	AlbumArtist string     `json:"albumArtist"`
+	Album       string     `json:"album"`
+	TrackNumber int        `json:"trackNumber"`
+	Title       string     `json:"title"`
+	Entries     MediaFiles `json:"entries"`
+}
+
+type DuplicateGroups []DuplicateGroup
+
+// LibraryStats aggregates library health numbers: totals, per-suffix counts and a breakdown
+// by top-level folder
+type LibraryStats struct {
+	SongCount     int64            `json:"songCount"`
+	MissingCount  int64            `json:"missingCount"`
+	TotalSize     int64            `json:"totalSize"`
+	TotalDuration float64          `json:"totalDuration"`
+	AvgBitRate    float64          `json:"avgBitRate"`
+	BySuffix      map[string]int64 `json:"bySuffix"`
+	Folders       []FolderStats    `json:"folders"`
+}
+
 type MediaFileRepository interface {
 	CountAll(options ...QueryOptions) (int64, error)
 	Exists(id string) (bool, error)
 	Put(m *MediaFile) error
+	// PutAll persists all the given tracks in batched statements, with the same semantics as
+	// calling Put for each one. Preferred during scans, where per-row round trips add up
+	PutAll(mfs MediaFiles) error
 	Get(id string) (*MediaFile, error)
 	GetAll(options ...QueryOptions) (MediaFiles, error)
 	Search(q string, offset int, size int) (MediaFiles, error)
+	// SearchWithTotal returns the same page of results as Search, along with the total number
+	// of matches for the query
+	SearchWithTotal(q string, offset int, size int) (MediaFiles, int64, error)
 	Delete(id string) error
 
-	// Queries by path to support the scanner, no Annotations or Bookmarks required in the response
-	FindAllByPath(path string) (MediaFiles, error)
+	// GetByWorkID returns all recordings of the same MusicBrainz work, ordered by album year
+	GetByWorkID(workID string) (MediaFiles, error)
+
+	// GetByISRC returns all tracks tagged with the given ISRC, in any of its accepted spellings
+	// (see NormalizeISRC)
+	GetByISRC(isrc string) (MediaFiles, error)
+
+	// GetInProgress returns the user's partially played tracks: those with a bookmark position
+	// past the start but before the InProgressThreshold fraction of the duration, ordered by
+	// the most recently updated bookmark
+	GetInProgress(limit int) (MediaFiles, error)
+
+	// Queries by path to support the scanner, no Annotations or Bookmarks required in the response.
+	// FindAllByPath takes optional query options, so callers can filter the result (ex: by missing state)
+	FindAllByPath(path string, options ...QueryOptions) (MediaFiles, error)
 	FindByPath(path string) (*MediaFile, error)
 	FindPathsRecursively(basePath string) ([]string, error)
 	DeleteByPath(path string) (int64, error)
 
+	// MarkMissing flags (or un-flags) the given tracks as missing from disk
+	MarkMissing(missing bool, ids ...string) error
+	// MarkMissingByPath flags all tracks directly inside the given folder as missing
+	MarkMissingByPath(path string) (int64, error)
+
+	// Stats aggregates library health numbers for the given library. It runs full-table
+	// aggregates, so callers should cache the result
+	Stats(libraryID int) (*LibraryStats, error)
+
+	// GetCursor streams every media file matching the query filters, ordered by id, fetching
+	// rows in batches so arbitrarily large libraries can be iterated (ex: for exports)
+	// without loading the whole result into memory. Sort and pagination options are ignored.
+	// Both channels are closed when the iteration ends; the error channel receives at most
+	// one error, and iteration stops early when the repository's context is canceled
+	GetCursor(options ...QueryOptions) (<-chan MediaFile, <-chan error)
+
+	// GetMissingGain pages through non-missing tracks lacking both tag-provided and computed
+	// ReplayGain values, ordered by id and starting after fromID, so the gain analysis job
+	// can resume from where it stopped
+	GetMissingGain(fromID string, limit int) (MediaFiles, error)
+	CountMissingGain() (int64, error)
+	// StoreComputedGain writes the job-computed gain columns only; regular puts never touch
+	// them (see RgTrackGainComputed)
+	StoreComputedGain(id string, gain, peak float64) error
+
+	// FindDuplicates reports groups of non-missing tracks that look like duplicate rips:
+	// same album artist, album, track number and title at more than one path, with
+	// near-equal durations
+	FindDuplicates() (DuplicateGroups, error)
+
 	AnnotatedRepository
 	BookmarkableRepository
 }
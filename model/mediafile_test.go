@@ -1,6 +1,8 @@
 package model_test
 
 import (
+	"encoding/json"
+	"slices"
 	"time"
 
 	"github.com/navidrome/navidrome/conf"
@@ -45,8 +47,8 @@ var _ = Describe("MediaFiles", func() {
 			Expect(album.SortAlbumName).To(Equal("SortAlbumName"))
 			Expect(album.SortArtistName).To(Equal("SortArtistName"))
 			Expect(album.SortAlbumArtistName).To(Equal("SortAlbumArtistName"))
-			Expect(album.OrderAlbumName).To(Equal("OrderAlbumName"))
-			Expect(album.OrderAlbumArtistName).To(Equal("OrderAlbumArtistName"))
+			Expect(album.OrderAlbumName).To(Equal("album"))
+			Expect(album.OrderAlbumArtistName).To(Equal("albumartist"))
 			Expect(album.MbzAlbumArtistID).To(Equal("MbzAlbumArtistID"))
 			Expect(album.MbzAlbumType).To(Equal("MbzAlbumType"))
 			Expect(album.MbzAlbumComment).To(Equal("MbzAlbumComment"))
@@ -60,12 +62,13 @@ var _ = Describe("MediaFiles", func() {
 		When("we have only one song", func() {
 			BeforeEach(func() {
 				mfs = MediaFiles{
-					{Duration: 100.2, Size: 1024, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:30"), CreatedAt: t("2022-12-19 08:30")},
+					{DurationMs: 100200, Size: 1024, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:30"), CreatedAt: t("2022-12-19 08:30")},
 				}
 			})
 			It("calculates the aggregates correctly", func() {
 				album := mfs.ToAlbum()
-				Expect(album.Duration).To(Equal(float32(100.2)))
+				Expect(album.DurationMs).To(Equal(int64(100200)))
+				Expect(album.Duration()).To(Equal(float32(100.2)))
 				Expect(album.Size).To(Equal(int64(1024)))
 				Expect(album.MinYear).To(Equal(1985))
 				Expect(album.MaxYear).To(Equal(1985))
@@ -78,14 +81,15 @@ var _ = Describe("MediaFiles", func() {
 		When("we have multiple songs with different dates", func() {
 			BeforeEach(func() {
 				mfs = MediaFiles{
-					{Duration: 100.2, Size: 1024, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:30"), CreatedAt: t("2022-12-19 08:30")},
-					{Duration: 200.2, Size: 2048, Year: 0, Date: "", UpdatedAt: t("2022-12-19 09:45"), CreatedAt: t("2022-12-19 08:30")},
-					{Duration: 150.6, Size: 1000, Year: 1986, Date: "1986-01-02", UpdatedAt: t("2022-12-19 09:45"), CreatedAt: t("2022-12-19 07:30")},
+					{DurationMs: 100200, Size: 1024, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:30"), CreatedAt: t("2022-12-19 08:30")},
+					{DurationMs: 200200, Size: 2048, Year: 0, Date: "", UpdatedAt: t("2022-12-19 09:45"), CreatedAt: t("2022-12-19 08:30")},
+					{DurationMs: 150600, Size: 1000, Year: 1986, Date: "1986-01-02", UpdatedAt: t("2022-12-19 09:45"), CreatedAt: t("2022-12-19 07:30")},
 				}
 			})
 			It("calculates the aggregates correctly", func() {
 				album := mfs.ToAlbum()
-				Expect(album.Duration).To(Equal(float32(451.0)))
+				Expect(album.DurationMs).To(Equal(int64(451000)))
+				Expect(album.Duration()).To(Equal(float32(451.0)))
 				Expect(album.Size).To(Equal(int64(4072)))
 				Expect(album.MinYear).To(Equal(1985))
 				Expect(album.MaxYear).To(Equal(1986))
@@ -109,9 +113,9 @@ var _ = Describe("MediaFiles", func() {
 		When("we have multiple songs with same dates", func() {
 			BeforeEach(func() {
 				mfs = MediaFiles{
-					{Duration: 100.2, Size: 1024, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:30"), CreatedAt: t("2022-12-19 08:30")},
-					{Duration: 200.2, Size: 2048, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:45"), CreatedAt: t("2022-12-19 08:30")},
-					{Duration: 150.6, Size: 1000, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:45"), CreatedAt: t("2022-12-19 07:30")},
+					{DurationMs: 100200, Size: 1024, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:30"), CreatedAt: t("2022-12-19 08:30")},
+					{DurationMs: 200200, Size: 2048, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:45"), CreatedAt: t("2022-12-19 08:30")},
+					{DurationMs: 150600, Size: 1000, Year: 1985, Date: "1985-01-02", UpdatedAt: t("2022-12-19 09:45"), CreatedAt: t("2022-12-19 07:30")},
 				}
 			})
 			It("sets the date field correctly", func() {
@@ -121,6 +125,139 @@ var _ = Describe("MediaFiles", func() {
 				Expect(album.MaxYear).To(Equal(1985))
 			})
 		})
+		When("tracks carry their on-disk creation time", func() {
+			BeforeEach(func() {
+				mfs = MediaFiles{
+					{BirthTime: t("2020-03-01 10:00"), CreatedAt: t("2022-12-19 08:30")},
+					{BirthTime: t("2021-06-15 10:00"), CreatedAt: t("2022-12-19 08:30")},
+				}
+			})
+			It("aggregates the birth time range", func() {
+				album := mfs.ToAlbum()
+				Expect(album.MinBirthTime).To(Equal(t("2020-03-01 10:00")))
+				Expect(album.MaxBirthTime).To(Equal(t("2021-06-15 10:00")))
+			})
+			It("counts files without a birth time with their creation time", func() {
+				mfs = append(mfs, MediaFile{CreatedAt: t("2022-12-19 08:30")})
+				album := mfs.ToAlbum()
+				Expect(album.MinBirthTime).To(Equal(t("2020-03-01 10:00")))
+				Expect(album.MaxBirthTime).To(Equal(t("2022-12-19 08:30")))
+			})
+		})
+
+		When("the album mixes tracks of different quality", func() {
+			BeforeEach(func() {
+				mfs = MediaFiles{
+					{SampleRate: 44100, BitDepth: 16, Channels: 2},
+					{SampleRate: 44100, BitDepth: 16, Channels: 2},
+					{SampleRate: 96000, BitDepth: 24, Channels: 1},
+				}
+			})
+			It("reports the full sample rate and bit depth range", func() {
+				album := mfs.ToAlbum()
+				Expect(album.MinSampleRate).To(Equal(44100))
+				Expect(album.MaxSampleRate).To(Equal(96000))
+				Expect(album.MinBitDepth).To(Equal(16))
+				Expect(album.MaxBitDepth).To(Equal(24))
+			})
+			It("picks the dominant channel count", func() {
+				Expect(mfs.ToAlbum().Channels).To(Equal(2))
+			})
+		})
+
+		When("some tracks have unknown audio properties", func() {
+			BeforeEach(func() {
+				mfs = MediaFiles{
+					{SampleRate: 44100, BitDepth: 0, Channels: 2},
+					{SampleRate: 0, BitDepth: 24, Channels: 0},
+				}
+			})
+			It("ignores the zero values instead of reporting them as the minimum", func() {
+				album := mfs.ToAlbum()
+				Expect(album.MinSampleRate).To(Equal(44100))
+				Expect(album.MaxSampleRate).To(Equal(44100))
+				Expect(album.MinBitDepth).To(Equal(24))
+				Expect(album.MaxBitDepth).To(Equal(24))
+				Expect(album.Channels).To(Equal(2))
+			})
+		})
+
+		When("the tracks have fractional durations", func() {
+			BeforeEach(func() {
+				mfs = MediaFiles{{DurationMs: 500}, {DurationMs: 500}, {DurationMs: 500}}
+			})
+			It("sums them without floating point drift", func() {
+				album := mfs.ToAlbum()
+				Expect(album.DurationMs).To(Equal(int64(1500)))
+				Expect(album.Duration()).To(Equal(float32(1.5)))
+			})
+		})
+
+		Context("Track gap detection", func() {
+			It("flags tracks missing from the total announced by the tags", func() {
+				mfs = MediaFiles{
+					{TrackNumber: 1, TotalTracks: 5}, {TrackNumber: 2, TotalTracks: 5},
+					{TrackNumber: 4, TotalTracks: 5}, {TrackNumber: 5, TotalTracks: 5},
+				}
+				album := mfs.ToAlbum()
+				Expect(album.Complete).To(BeFalse())
+				Expect(album.MissingTracks).To(Equal("disc 1: missing 3"))
+			})
+			It("detects holes below the highest number when there is no total", func() {
+				mfs = MediaFiles{{TrackNumber: 1}, {TrackNumber: 2}, {TrackNumber: 4}, {TrackNumber: 5}}
+				album := mfs.ToAlbum()
+				Expect(album.Complete).To(BeFalse())
+				Expect(album.MissingTracks).To(Equal("disc 1: missing 3"))
+			})
+			It("reports the track count when the missing numbers cannot be pinpointed", func() {
+				mfs = MediaFiles{{TrackNumber: 1, TotalTracks: 3}, {TrackNumber: 0, TotalTracks: 3}}
+				album := mfs.ToAlbum()
+				Expect(album.Complete).To(BeFalse())
+				Expect(album.MissingTracks).To(Equal("disc 1: 2 of 3 tracks"))
+			})
+			It("summarizes each disc separately", func() {
+				mfs = MediaFiles{
+					{DiscNumber: 1, TrackNumber: 1, TotalTracks: 2},
+					{DiscNumber: 2, TrackNumber: 2, TotalTracks: 3}, {DiscNumber: 2, TrackNumber: 3, TotalTracks: 3},
+				}
+				album := mfs.ToAlbum()
+				Expect(album.Complete).To(BeFalse())
+				Expect(album.MissingTracks).To(Equal("disc 1: missing 2; disc 2: missing 1"))
+			})
+			It("does not flag unnumbered tracks when there is no total", func() {
+				mfs = MediaFiles{{TrackNumber: 0}, {TrackNumber: 0}, {TrackNumber: 3}}
+				album := mfs.ToAlbum()
+				Expect(album.Complete).To(BeTrue())
+				Expect(album.MissingTracks).To(BeEmpty())
+			})
+			It("ignores numbering oddities when all tracks are accounted for", func() {
+				mfs = MediaFiles{
+					{TrackNumber: 1, TotalTracks: 2}, {TrackNumber: 2, TotalTracks: 2},
+					{TrackNumber: 4, TotalTracks: 2},
+				}
+				album := mfs.ToAlbum()
+				Expect(album.Complete).To(BeTrue())
+				Expect(album.MissingTracks).To(BeEmpty())
+			})
+			It("marks a contiguous album as complete", func() {
+				mfs = MediaFiles{
+					{TrackNumber: 1, TotalTracks: 3}, {TrackNumber: 2, TotalTracks: 3},
+					{TrackNumber: 3, TotalTracks: 3},
+				}
+				album := mfs.ToAlbum()
+				Expect(album.Complete).To(BeTrue())
+				Expect(album.MissingTracks).To(BeEmpty())
+			})
+		})
+	})
+	Context("Serialization", func() {
+		It("keeps exposing the duration as float seconds", func() {
+			mf := MediaFile{ID: "1", DurationMs: 120500}
+			data, err := json.Marshal(mf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"duration":120.5`))
+			Expect(string(data)).ToNot(ContainSubstring("durationMs"))
+		})
 	})
 	Context("Calculated attributes", func() {
 		Context("Discs", func() {
@@ -151,6 +288,47 @@ var _ = Describe("MediaFiles", func() {
 					Expect(album.Discs).To(Equal(Discs{1: "DiscSubtitle", 2: "DiscSubtitle2"}))
 				})
 			})
+			When("only the second disc has a subtitle", func() {
+				BeforeEach(func() {
+					mfs = MediaFiles{{DiscNumber: 1}, {DiscNumber: 2, DiscSubtitle: "Live"}}
+				})
+				It("keeps disc 1 in the map with an empty subtitle", func() {
+					album := mfs.ToAlbum()
+					Expect(album.Discs).To(Equal(Discs{1: "", 2: "Live"}))
+				})
+			})
+		})
+
+		Context("EmbedArtPath", func() {
+			When("each track has its own embedded art", func() {
+				BeforeEach(func() {
+					mfs = MediaFiles{
+						{TrackNumber: 3, HasCoverArt: true, Path: "/singles/03.mp3"},
+						{TrackNumber: 1, HasCoverArt: true, Path: "/singles/01.mp3"},
+						{TrackNumber: 2, HasCoverArt: true, Path: "/singles/02.mp3"},
+					}
+				})
+				It("picks the first track in album order, regardless of scan order", func() {
+					album := mfs.ToAlbum()
+					Expect(album.EmbedArtPath).To(Equal("/singles/01.mp3"))
+
+					slices.Reverse(mfs)
+					album = mfs.ToAlbum()
+					Expect(album.EmbedArtPath).To(Equal("/singles/01.mp3"))
+				})
+			})
+			When("the first track in album order has no embedded art", func() {
+				BeforeEach(func() {
+					mfs = MediaFiles{
+						{TrackNumber: 1, Path: "/singles/01.mp3"},
+						{TrackNumber: 2, HasCoverArt: true, Path: "/singles/02.mp3"},
+					}
+				})
+				It("picks the next one that has it", func() {
+					album := mfs.ToAlbum()
+					Expect(album.EmbedArtPath).To(Equal("/singles/02.mp3"))
+				})
+			})
 		})
 
 		Context("Genres", func() {
@@ -247,6 +425,15 @@ var _ = Describe("MediaFiles", func() {
 				album := mfs.ToAlbum()
 				Expect(album.FullText).To(Equal(" album1 albumartist1 artist1 artist2 discsubtitle1 discsubtitle2 sortalbumartistname1 sortalbumname1 sortartistname1 sortartistname2"))
 			})
+			It("also indexes the catalog number and the participating artists", func() {
+				mfs[0].CatalogNum = "CAT-0001"
+				participations := Participations{}
+				participations.Add(RoleComposer, Participant{ID: "5", Name: "Composer1"})
+				mfs[1].Participations = participations
+				album := mfs.ToAlbum()
+				Expect(album.FullText).To(ContainSubstring(" cat-0001"))
+				Expect(album.FullText).To(ContainSubstring(" composer1"))
+			})
 		})
 		Context("MbzAlbumID", func() {
 			When("we have only one MbzAlbumID", func() {
@@ -268,6 +455,96 @@ var _ = Describe("MediaFiles", func() {
 				})
 			})
 		})
+		Context("Sort and order names", func() {
+			It("follows the most frequent sort tag when tracks disagree after a partial rescan", func() {
+				mfs = MediaFiles{
+					{Album: "Das Album", SortAlbumName: "Album, Das"},
+					{Album: "Das Album", SortAlbumName: "Album, Das"},
+					{Album: "Das Album", SortAlbumName: "Old Sort Name"},
+				}
+				album := mfs.ToAlbum()
+				Expect(album.SortAlbumName).To(Equal("Album, Das"))
+				Expect(album.OrderAlbumName).To(Equal("das album"))
+			})
+			It("recomputes the order name when the album artist is replaced by Various Artists", func() {
+				mfs = MediaFiles{
+					{AlbumArtist: "Artist 1", AlbumArtistID: "ar-1", SortAlbumArtistName: "1, Artist", Compilation: true},
+					{AlbumArtist: "Artist 2", AlbumArtistID: "ar-2", SortAlbumArtistName: "2, Artist", Compilation: true},
+				}
+				album := mfs.ToAlbum()
+				Expect(album.AlbumArtist).To(Equal("Various Artists"))
+				Expect(album.OrderAlbumArtistName).To(Equal("various artists"))
+				Expect(album.SortAlbumArtistName).To(BeEmpty())
+			})
+		})
+		Context("Compilation detection", func() {
+			BeforeEach(func() {
+				DeferCleanup(configtest.SetupConfig())
+			})
+
+			multiArtistAlbum := func(numArtists int) MediaFiles {
+				var res MediaFiles
+				for i := 0; i < numArtists; i++ {
+					name := string(rune('A' + i))
+					res = append(res, MediaFile{ID: name, AlbumID: "al-1", Album: "Sampler",
+						Artist: name, ArtistID: "ar-" + name, AlbumArtist: name, AlbumArtistID: "ar-" + name})
+				}
+				return res
+			}
+
+			It("assigns albums with many distinct artists and no album artist to Various Artists", func() {
+				album := multiArtistAlbum(5).ToAlbum()
+				Expect(album.Compilation).To(BeTrue())
+				Expect(album.AlbumArtist).To(Equal(consts.VariousArtists))
+				Expect(album.AlbumArtistID).To(Equal(consts.VariousArtistsID))
+			})
+
+			It("keeps albums below the threshold with their own artist", func() {
+				album := multiArtistAlbum(2).ToAlbum()
+				Expect(album.Compilation).To(BeFalse())
+				Expect(album.AlbumArtistID).ToNot(Equal(consts.VariousArtistsID))
+			})
+
+			It("does not misdetect albums with a consistent album artist", func() {
+				mfs = multiArtistAlbum(5)
+				for i := range mfs {
+					mfs[i].AlbumArtist = "Band"
+					mfs[i].AlbumArtistID = "ar-band"
+				}
+				album := mfs.ToAlbum()
+				Expect(album.Compilation).To(BeFalse())
+				Expect(album.AlbumArtistID).To(Equal("ar-band"))
+			})
+
+			It("can be disabled by setting the threshold to 0", func() {
+				conf.Server.Scanner.VariousArtistsThreshold = 0
+				album := multiArtistAlbum(5).ToAlbum()
+				Expect(album.Compilation).To(BeFalse())
+			})
+		})
+
+		Context("ReplayGain", func() {
+			When("all tracks agree on the album gain", func() {
+				BeforeEach(func() {
+					mfs = MediaFiles{{RgAlbumGain: -5.5, RgAlbumPeak: 0.9}, {RgAlbumGain: -5.5, RgAlbumPeak: 0.9}}
+				})
+				It("sets the album gain and peak", func() {
+					album := mfs.ToAlbum()
+					Expect(album.RgAlbumGain).To(Equal(-5.5))
+					Expect(album.RgAlbumPeak).To(Equal(0.9))
+				})
+			})
+			When("tracks disagree on the album gain", func() {
+				BeforeEach(func() {
+					mfs = MediaFiles{{RgAlbumGain: -5.5, RgAlbumPeak: 0.9}, {RgAlbumGain: -1.0, RgAlbumPeak: 1.0}, {RgAlbumGain: -5.5, RgAlbumPeak: 0.9}}
+				})
+				It("uses the most frequent values", func() {
+					album := mfs.ToAlbum()
+					Expect(album.RgAlbumGain).To(Equal(-5.5))
+					Expect(album.RgAlbumPeak).To(Equal(0.9))
+				})
+			})
+		})
 	})
 })
 
@@ -299,6 +576,25 @@ var _ = Describe("MediaFile", func() {
 	})
 })
 
+var _ = Describe("NormalizeISRC", func() {
+	It("strips separators and upper-cases the value", func() {
+		isrc, ok := NormalizeISRC("us-rc1-76-00129")
+		Expect(ok).To(BeTrue())
+		Expect(isrc).To(Equal("USRC17600129"))
+	})
+	It("accepts an already normalized value", func() {
+		isrc, ok := NormalizeISRC("GBAYE0601477")
+		Expect(ok).To(BeTrue())
+		Expect(isrc).To(Equal("GBAYE0601477"))
+	})
+	It("rejects values that don't follow the 12-character format", func() {
+		_, ok := NormalizeISRC("not-an-isrc")
+		Expect(ok).To(BeFalse())
+		_, ok = NormalizeISRC("USRC176001290")
+		Expect(ok).To(BeFalse())
+	})
+})
+
 func t(v string) time.Time {
 	var timeFormats = []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05.999999999 -0700 MST"}
 	for _, f := range timeFormats {
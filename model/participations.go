@@ -0,0 +1,205 @@
+package model
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/md5"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/deluan/sanitize"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Participant is an artist taking part in a track, in a specific role.
+type Participant struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	// SortName and MbzArtistID are carried over from the tags, so artist rows created for
+	// participants not in the DB yet keep their sort order and MusicBrainz link
+	SortName    string `json:"sortName,omitempty"`
+	MbzArtistID string `json:"mbzArtistId,omitempty"`
+}
+
+// ParticipantID returns the deterministic ID used for participants extracted from tags,
+// derived from the lowercased name. When Scanner.NormalizeArtistNames is enabled, names
+// differing only in diacritics or Unicode normalization form (ex: "Sigur Rós" and "Sigur Ros")
+// also fold into the same ID
+func ParticipantID(name string) string {
+	name = strings.ToLower(name)
+	if conf.Server.Scanner.NormalizeArtistNames {
+		name = strings.ToLower(sanitize.Accents(norm.NFC.String(name)))
+	}
+	return fmt.Sprintf("%x", md5.Sum([]byte(name)))
+}
+
+// Participations maps a Role to all the artists participating in a track with that role.
+type Participations map[Role][]Participant
+
+// participationsFormatVersion is the current version of the participations serialization format.
+// Bump it when the format changes, and teach Scan how to upgrade older versions.
+const participationsFormatVersion = 1
+
+// participationsEnvelope is the versioned envelope used to (de)serialize participations to the
+// DB. Roles uses the underlying map type, to bypass the custom (ordered, response-oriented)
+// Participations marshaller.
+type participationsEnvelope struct {
+	V     int                    `json:"v"`
+	Roles map[Role][]Participant `json:"roles"`
+}
+
+// Value implements driver.Valuer, serializing participations with the current format version
+func (p Participations) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return "", nil
+	}
+	value, err := json.Marshal(participationsEnvelope{V: participationsFormatVersion, Roles: p})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling participations: %w", err)
+	}
+	return string(value), nil
+}
+
+// Scan implements sql.Scanner, parsing the serialized participations. Values persisted in the
+// legacy format (a bare map from role to participants, without the version envelope) are upgraded
+// on the fly. Malformed values are logged and result in empty Participations, instead of failing
+// the whole query.
+func (p *Participations) Scan(value any) error {
+	var data string
+	switch v := value.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		return nil
+	}
+	if data == "" {
+		return nil
+	}
+	var envelope participationsEnvelope
+	if err := json.Unmarshal([]byte(data), &envelope); err != nil {
+		log.Warn("Malformed participations. Ignoring", "value", data, err)
+		return nil
+	}
+	if envelope.V == 0 {
+		legacy := Participations{}
+		if err := json.Unmarshal([]byte(data), &legacy); err != nil {
+			log.Warn("Malformed legacy participations. Ignoring", "value", data, err)
+			return nil
+		}
+		*p = legacy
+		return nil
+	}
+	if envelope.Roles != nil {
+		*p = envelope.Roles
+	}
+	return nil
+}
+
+// SortedRoles returns the roles in a stable order: standard roles first, in display order, then
+// custom roles sorted alphabetically
+func (p Participations) SortedRoles() []Role {
+	roles := make([]Role, 0, len(p))
+	for role := range p {
+		roles = append(roles, role)
+	}
+	slices.SortFunc(roles, func(a, b Role) int {
+		if c := cmp.Compare(a.displayOrder(), b.displayOrder()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.role, b.role)
+	})
+	return roles
+}
+
+// MarshalJSON writes the roles in a stable order (see SortedRoles), keeping the artists in tag
+// order, so responses are deterministic
+func (p Participations) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, role := range p.SortedRoles() {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, err := json.Marshal(role.String())
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p[role])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// Truncated returns a copy of p with each role capped at max participants, and whether any role
+// was truncated. It is used to keep responses small for tracks with very large participant
+// lists (ex: orchestral recordings)
+func (p Participations) Truncated(max int) (Participations, bool) {
+	if max <= 0 {
+		return p, false
+	}
+	truncated := false
+	res := make(Participations, len(p))
+	for role, participants := range p {
+		if len(participants) > max {
+			participants = participants[:max]
+			truncated = true
+		}
+		res[role] = participants
+	}
+	return res, truncated
+}
+
+// AllSearchTerms returns the names and sort names of all participants, across all roles,
+// deduplicated case-insensitively. An artist holding several roles contributes their names only
+// once, so indexing the result does not bloat full_text. The roles are visited in stable order,
+// keeping the result deterministic
+func (p Participations) AllSearchTerms() []string {
+	seen := map[string]struct{}{}
+	var terms []string
+	add := func(s string) {
+		if s == "" {
+			return
+		}
+		key := strings.ToLower(s)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		terms = append(terms, s)
+	}
+	for _, role := range p.SortedRoles() {
+		for _, participant := range p[role] {
+			add(participant.Name)
+			add(participant.SortName)
+		}
+	}
+	return terms
+}
+
+// Add appends the given artists to the role, skipping names already present.
+func (p *Participations) Add(role Role, artists ...Participant) {
+	if *p == nil {
+		*p = Participations{}
+	}
+	current := (*p)[role]
+	for _, artist := range artists {
+		if slices.ContainsFunc(current, func(a Participant) bool { return a.Name == artist.Name }) {
+			continue
+		}
+		current = append(current, artist)
+	}
+	(*p)[role] = current
+}
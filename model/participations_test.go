@@ -0,0 +1,192 @@
+package model_test
+
+import (
+	"encoding/json"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/conf/configtest"
+	. "github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Participations", func() {
+	Describe("ParticipantID", func() {
+		BeforeEach(func() {
+			DeferCleanup(configtest.SetupConfig())
+		})
+
+		It("folds only case differences by default", func() {
+			Expect(ParticipantID("Sigur Rós")).To(Equal(ParticipantID("sigur rós")))
+			Expect(ParticipantID("Sigur Rós")).ToNot(Equal(ParticipantID("Sigur Ros")))
+		})
+
+		It("folds diacritics when Scanner.NormalizeArtistNames is enabled", func() {
+			conf.Server.Scanner.NormalizeArtistNames = true
+			Expect(ParticipantID("Sigur Rós")).To(Equal(ParticipantID("Sigur Ros")))
+			Expect(ParticipantID("Sigur Rós")).ToNot(Equal(ParticipantID("Another Band")))
+		})
+	})
+
+	Describe("Add", func() {
+		It("adds participants to a role, deduplicating by name", func() {
+			var p Participations
+			p.Add(RoleComposer, Participant{Name: "J.S. Bach"})
+			p.Add(RoleComposer, Participant{Name: "J.S. Bach"}, Participant{Name: "G.F. Handel"})
+			Expect(p[RoleComposer]).To(HaveLen(2))
+		})
+	})
+
+	Describe("DB serialization", func() {
+		It("serializes with the current format version", func() {
+			var p Participations
+			p.Add(RoleComposer, Participant{ID: "c-1", Name: "A Composer"})
+			value, err := p.Value()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(`{"v":1,"roles":{"composer":[{"id":"c-1","name":"A Composer"}]}}`))
+		})
+
+		It("serializes empty participations as an empty string", func() {
+			var p Participations
+			value, err := p.Value()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(""))
+		})
+
+		It("parses values in the current format", func() {
+			var p Participations
+			Expect(p.Scan(`{"v":1,"roles":{"composer":[{"id":"c-1","name":"A Composer"}]}}`)).To(Succeed())
+			Expect(p[RoleComposer]).To(ConsistOf(Participant{ID: "c-1", Name: "A Composer"}))
+		})
+
+		It("upgrades values in the legacy format", func() {
+			var p Participations
+			Expect(p.Scan(`{"composer":[{"id":"c-1","name":"A Composer"}]}`)).To(Succeed())
+			Expect(p[RoleComposer]).To(ConsistOf(Participant{ID: "c-1", Name: "A Composer"}))
+		})
+
+		It("ignores malformed values", func() {
+			var p Participations
+			Expect(p.Scan(`{"v":1,"roles":"garbage"`)).To(Succeed())
+			Expect(p).To(BeEmpty())
+			Expect(p.Scan(`[1,2,3]`)).To(Succeed())
+			Expect(p).To(BeEmpty())
+		})
+	})
+
+	Describe("MarshalJSON", func() {
+		It("writes roles in display order, with custom roles last", func() {
+			DeferCleanup(configtest.SetupConfig())
+			conf.Server.Scanner.AdditionalRoles = []string{"orchestra", "choir"}
+			var p Participations
+			p.Add(RoleFromString("orchestra"), Participant{Name: "An Orchestra"})
+			p.Add(RoleComposer, Participant{Name: "A Composer"})
+			p.Add(RoleFromString("choir"), Participant{Name: "A Choir"})
+			p.Add(RoleArtist, Participant{Name: "An Artist"})
+
+			data, err := json.Marshal(p)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"artist":[{"name":"An Artist"}],` +
+				`"composer":[{"name":"A Composer"}],` +
+				`"choir":[{"name":"A Choir"}],` +
+				`"orchestra":[{"name":"An Orchestra"}]}`))
+		})
+
+		It("keeps artists in tag order", func() {
+			var p Participations
+			p.Add(RoleComposer, Participant{Name: "Z"}, Participant{Name: "A"})
+
+			data, err := json.Marshal(p)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"composer":[{"name":"Z"},{"name":"A"}]}`))
+		})
+	})
+
+	Describe("AllSearchTerms", func() {
+		It("returns each artist once, even when they hold several roles", func() {
+			beethoven := Participant{Name: "Ludwig van Beethoven", SortName: "Beethoven, Ludwig van"}
+			p := Participations{}
+			for _, role := range []Role{RoleArtist, RoleComposer, RoleConductor, RoleArranger, RoleProducer} {
+				p.Add(role, beethoven)
+			}
+			Expect(p.AllSearchTerms()).To(Equal([]string{"Ludwig van Beethoven", "Beethoven, Ludwig van"}))
+		})
+
+		It("includes sort names, deduplicating case-insensitively", func() {
+			p := Participations{}
+			p.Add(RoleComposer, Participant{Name: "J.S. Bach", SortName: "Bach, J.S."})
+			p.Add(RoleConductor, Participant{Name: "j.s. bach"})
+			p.Add(RoleProducer, Participant{Name: "Someone Else"})
+			Expect(p.AllSearchTerms()).To(Equal([]string{"J.S. Bach", "Bach, J.S.", "Someone Else"}))
+		})
+	})
+
+	Describe("Truncated", func() {
+		var p Participations
+		BeforeEach(func() {
+			p = Participations{}
+			p.Add(RolePerformer, Participant{Name: "P1"}, Participant{Name: "P2"}, Participant{Name: "P3"})
+			p.Add(RoleComposer, Participant{Name: "C1"})
+		})
+
+		It("caps each role at the given max", func() {
+			truncated, wasTruncated := p.Truncated(2)
+			Expect(wasTruncated).To(BeTrue())
+			Expect(truncated[RolePerformer]).To(HaveLen(2))
+			Expect(truncated[RoleComposer]).To(HaveLen(1))
+		})
+
+		It("does not flag participations within the limit", func() {
+			truncated, wasTruncated := p.Truncated(3)
+			Expect(wasTruncated).To(BeFalse())
+			Expect(truncated).To(Equal(p))
+		})
+
+		It("does not truncate when max is zero", func() {
+			truncated, wasTruncated := p.Truncated(0)
+			Expect(wasTruncated).To(BeFalse())
+			Expect(truncated).To(Equal(p))
+		})
+	})
+
+	Describe("Role", func() {
+		BeforeEach(func() {
+			DeferCleanup(configtest.SetupConfig())
+		})
+
+		It("returns RoleInvalid for unknown roles", func() {
+			Expect(RoleFromString("orchestra")).To(Equal(RoleInvalid))
+		})
+
+		It("accepts roles registered in Scanner.AdditionalRoles", func() {
+			conf.Server.Scanner.AdditionalRoles = []string{"Orchestra", "choir"}
+			Expect(RoleFromString("orchestra").String()).To(Equal("orchestra"))
+			Expect(RoleFromString("CHOIR").String()).To(Equal("choir"))
+			Expect(RoleFromString("soloist")).To(Equal(RoleInvalid))
+		})
+
+		It("round trips participations with custom roles", func() {
+			conf.Server.Scanner.AdditionalRoles = []string{"orchestra"}
+			var p Participations
+			p.Add(RoleFromString("orchestra"), Participant{Name: "Berliner Philharmoniker"})
+			p.Add(RoleConductor, Participant{ID: "123", Name: "Herbert von Karajan"})
+
+			data, err := json.Marshal(p)
+			Expect(err).ToNot(HaveOccurred())
+
+			var parsed Participations
+			Expect(json.Unmarshal(data, &parsed)).To(Succeed())
+			Expect(parsed).To(Equal(p))
+		})
+
+		It("deserializes roles removed from the config as generic roles", func() {
+			data := []byte(`{"orchestra":[{"name":"Berliner Philharmoniker"}]}`)
+			var parsed Participations
+			Expect(json.Unmarshal(data, &parsed)).To(Succeed())
+			var role Role
+			Expect(role.UnmarshalText([]byte("orchestra"))).To(Succeed())
+			Expect(parsed[role]).To(HaveLen(1))
+			Expect(parsed[role][0].Name).To(Equal("Berliner Philharmoniker"))
+		})
+	})
+})
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// PlayEvent is one play of a track by a user. Events are recorded by the scrobble path going
+// forward, and power the windowed statistics rollups. Plays recorded before the table existed
+// are only reflected in the aggregate annotation counters.
+type PlayEvent struct {
+	UserID      string    `structs:"user_id"`
+	MediaFileID string    `structs:"media_file_id"`
+	PlayDate    time.Time `structs:"play_date"`
+}
+
+type PlayEventRepository interface {
+	// Record stores one play of the given track for the user in the context
+	Record(mediaFileID string, playDate time.Time) error
+
+	// GetTopArtists returns the user's most played artists in the interval, with PlayCount
+	// holding the number of plays in the window. When the user has no recorded events, it
+	// degrades to the all-time annotation counters
+	GetTopArtists(from, to time.Time, limit int) (Artists, error)
+
+	// GetTopSongs returns the user's most played tracks in the interval, with the same
+	// fallback as GetTopArtists
+	GetTopSongs(from, to time.Time, limit int) (MediaFiles, error)
+
+	// GetListeningTime returns how many seconds the user listened to in the interval, adding
+	// up the duration of each played track. Same fallback as GetTopArtists
+	GetListeningTime(from, to time.Time) (float64, error)
+}
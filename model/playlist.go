@@ -1,7 +1,9 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"slices"
 	"strconv"
 	"strings"
@@ -11,26 +13,57 @@ import (
 )
 
 type Playlist struct {
-	ID        string         `structs:"id" json:"id"`
-	Name      string         `structs:"name" json:"name"`
-	Comment   string         `structs:"comment" json:"comment"`
-	Duration  float32        `structs:"duration" json:"duration"`
-	Size      int64          `structs:"size" json:"size"`
-	SongCount int            `structs:"song_count" json:"songCount"`
-	OwnerName string         `structs:"-" json:"ownerName"`
-	OwnerID   string         `structs:"owner_id" json:"ownerId"`
-	Public    bool           `structs:"public" json:"public"`
-	Tracks    PlaylistTracks `structs:"-" json:"tracks,omitempty"`
-	Path      string         `structs:"path" json:"path"`
-	Sync      bool           `structs:"sync" json:"sync"`
-	CreatedAt time.Time      `structs:"created_at" json:"createdAt"`
-	UpdatedAt time.Time      `structs:"updated_at" json:"updatedAt"`
+	ID         string         `structs:"id" json:"id"`
+	Name       string         `structs:"name" json:"name"`
+	Comment    string         `structs:"comment" json:"comment"`
+	DurationMs int64          `structs:"duration_ms" json:"-"`
+	Size       int64          `structs:"size" json:"size"`
+	SongCount  int            `structs:"song_count" json:"songCount"`
+	OwnerName  string         `structs:"-" json:"ownerName"`
+	OwnerID    string         `structs:"owner_id" json:"ownerId"`
+	Public     bool           `structs:"public" json:"public"`
+	Tracks     PlaylistTracks `structs:"-" json:"tracks,omitempty"`
+	Path       string         `structs:"path" json:"path"`
+	Sync       bool           `structs:"sync" json:"sync"`
+	CreatedAt  time.Time      `structs:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time      `structs:"updated_at" json:"updatedAt"`
 
 	// SmartPlaylist attributes
 	Rules       *criteria.Criteria `structs:"rules" json:"rules"`
 	EvaluatedAt *time.Time         `structs:"evaluated_at" json:"evaluatedAt"`
 }
 
+// Duration returns the total playlist length in float seconds, summed from the individual
+// tracks' integer milliseconds
+func (pls Playlist) Duration() float32 {
+	return float32(pls.DurationMs) / 1000
+}
+
+// MarshalJSON adds a `duration` attribute in float seconds, keeping the format the web API always
+// exposed for the duration
+func (pls Playlist) MarshalJSON() ([]byte, error) {
+	type alias Playlist
+	return json.Marshal(struct {
+		alias
+		Duration float32 `json:"duration"`
+	}{alias(pls), pls.Duration()})
+}
+
+// UnmarshalJSON is the counterpart of MarshalJSON: playlist updates come in with the duration in
+// float seconds, and it needs to survive a full record update
+func (pls *Playlist) UnmarshalJSON(data []byte) error {
+	type alias Playlist
+	aux := struct {
+		*alias
+		Duration float32 `json:"duration"`
+	}{alias: (*alias)(pls)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	pls.DurationMs = int64(math.Round(float64(aux.Duration) * 1000))
+	return nil
+}
+
 func (pls Playlist) IsSmartPlaylist() bool {
 	return pls.Rules != nil && pls.Rules.Expression != nil
 }
@@ -60,7 +93,7 @@ func (pls *Playlist) ToM3U8() string {
 	buf.WriteString("#EXTM3U\n")
 	buf.WriteString(fmt.Sprintf("#PLAYLIST:%s\n", pls.Name))
 	for _, t := range pls.Tracks {
-		buf.WriteString(fmt.Sprintf("#EXTINF:%.f,%s - %s\n", t.Duration, t.Artist, t.Title))
+		buf.WriteString(fmt.Sprintf("#EXTINF:%.f,%s - %s\n", t.Duration(), t.Artist, t.Title))
 		buf.WriteString(t.Path + "\n")
 	}
 	return buf.String()
@@ -120,6 +153,23 @@ type PlaylistTrack struct {
 	MediaFile
 }
 
+// MarshalJSON flattens the embedded MediaFile into the track object. Without it, the MediaFile's
+// own MarshalJSON would be promoted, and the track attributes would be dropped from the output
+func (t PlaylistTrack) MarshalJSON() ([]byte, error) {
+	mf, err := json.Marshal(t.MediaFile)
+	if err != nil {
+		return nil, err
+	}
+	obj := map[string]any{}
+	if err := json.Unmarshal(mf, &obj); err != nil {
+		return nil, err
+	}
+	obj["id"] = t.ID
+	obj["mediaFileId"] = t.MediaFileID
+	obj["playlistId"] = t.PlaylistID
+	return json.Marshal(obj)
+}
+
 type PlaylistTracks []PlaylistTrack
 
 func (plt PlaylistTracks) MediaFiles() MediaFiles {
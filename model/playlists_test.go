@@ -13,13 +13,13 @@ var _ = Describe("Playlist", func() {
 			pls = model.Playlist{Name: "Mellow sunset"}
 			pls.Tracks = model.PlaylistTracks{
 				{MediaFile: model.MediaFile{Artist: "Morcheeba feat. Kurt Wagner", Title: "What New York Couples Fight About",
-					Duration: 377.84, Path: "/music/library/Morcheeba/Charango/01-06 What New York Couples Fight About.mp3"}},
+					DurationMs: 377840, Path: "/music/library/Morcheeba/Charango/01-06 What New York Couples Fight About.mp3"}},
 				{MediaFile: model.MediaFile{Artist: "A Tribe Called Quest", Title: "Description of a Fool (Groove Armada's Acoustic mix)",
-					Duration: 374.49, Path: "/music/library/Groove Armada/Back to Mine_ Groove Armada/01-01 Description of a Fool (Groove Armada's Acoustic mix).mp3"}},
+					DurationMs: 374490, Path: "/music/library/Groove Armada/Back to Mine_ Groove Armada/01-01 Description of a Fool (Groove Armada's Acoustic mix).mp3"}},
 				{MediaFile: model.MediaFile{Artist: "Lou Reed", Title: "Walk on the Wild Side",
-					Duration: 253.1, Path: "/music/library/Lou Reed/Walk on the Wild Side_ The Best of Lou Reed/01-06 Walk on the Wild Side.m4a"}},
+					DurationMs: 253100, Path: "/music/library/Lou Reed/Walk on the Wild Side_ The Best of Lou Reed/01-06 Walk on the Wild Side.m4a"}},
 				{MediaFile: model.MediaFile{Artist: "Legião Urbana", Title: "On the Way Home",
-					Duration: 163.89, Path: "/music/library/Legião Urbana/Música p_ acampamentos/02-05 On the Way Home.mp3"}},
+					DurationMs: 163890, Path: "/music/library/Legião Urbana/Música p_ acampamentos/02-05 On the Way Home.mp3"}},
 			}
 		})
 		It("generates the correct M3U format", func() {
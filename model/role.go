@@ -0,0 +1,128 @@
+package model
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/navidrome/navidrome/conf"
+)
+
+// Role represents the role of an artist in a track, ex: composer, conductor, etc.
+type Role struct {
+	role string
+}
+
+func (r Role) String() string {
+	return r.role
+}
+
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.role), nil
+}
+
+// UnmarshalText accepts any role name, even ones not in allRoles or currently registered through
+// Scanner.AdditionalRoles. This way participations persisted with a custom role that was later
+// removed from the configuration still deserialize as a generic role, instead of erroring out.
+func (r *Role) UnmarshalText(b []byte) error {
+	name := strings.ToLower(strings.TrimSpace(string(b)))
+	if role, ok := allRoles[name]; ok {
+		*r = role
+		return nil
+	}
+	*r = Role{role: name}
+	return nil
+}
+
+var (
+	RoleInvalid     = Role{"invalid"}
+	RoleArtist      = Role{"artist"}
+	RoleAlbumArtist = Role{"albumartist"}
+	RoleComposer    = Role{"composer"}
+	RoleConductor   = Role{"conductor"}
+	RoleLyricist    = Role{"lyricist"}
+	RoleArranger    = Role{"arranger"}
+	RoleProducer    = Role{"producer"}
+	RoleDirector    = Role{"director"}
+	RoleEngineer    = Role{"engineer"}
+	RoleMixer       = Role{"mixer"}
+	RoleRemixer     = Role{"remixer"}
+	RoleDJMixer     = Role{"djmixer"}
+	RolePerformer   = Role{"performer"}
+)
+
+// rolesInDisplayOrder is the order roles are serialized in responses. Custom roles come after
+// the standard ones, sorted alphabetically.
+var rolesInDisplayOrder = []Role{
+	RoleArtist,
+	RoleAlbumArtist,
+	RoleComposer,
+	RoleConductor,
+	RoleLyricist,
+	RoleArranger,
+	RoleProducer,
+	RoleDirector,
+	RoleEngineer,
+	RoleMixer,
+	RoleRemixer,
+	RoleDJMixer,
+	RolePerformer,
+}
+
+// displayOrder returns the position of the role in rolesInDisplayOrder, or its length for
+// custom roles
+func (r Role) displayOrder() int {
+	if idx := slices.Index(rolesInDisplayOrder, r); idx >= 0 {
+		return idx
+	}
+	return len(rolesInDisplayOrder)
+}
+
+var allRoles = map[string]Role{
+	RoleArtist.role:      RoleArtist,
+	RoleAlbumArtist.role: RoleAlbumArtist,
+	RoleComposer.role:    RoleComposer,
+	RoleConductor.role:   RoleConductor,
+	RoleLyricist.role:    RoleLyricist,
+	RoleArranger.role:    RoleArranger,
+	RoleProducer.role:    RoleProducer,
+	RoleDirector.role:    RoleDirector,
+	RoleEngineer.role:    RoleEngineer,
+	RoleMixer.role:       RoleMixer,
+	RoleRemixer.role:     RoleRemixer,
+	RoleDJMixer.role:     RoleDJMixer,
+	RolePerformer.role:   RolePerformer,
+}
+
+// customRoles holds role names registered at runtime, from the allowlist stored in the DB
+// (managed through the native API). When set, it takes precedence over the
+// Scanner.AdditionalRoles configuration. It is loaded by the scanner at the start of each scan.
+var customRoles []string
+
+// SetCustomRoles registers the role names from the allowlist stored in the DB. Passing an empty
+// list reverts to the Scanner.AdditionalRoles configuration.
+func SetCustomRoles(roles []string) {
+	customRoles = roles
+}
+
+func additionalRoles() []string {
+	if len(customRoles) > 0 {
+		return customRoles
+	}
+	return conf.Server.Scanner.AdditionalRoles
+}
+
+// RoleFromString returns the Role for the given name. Names not in allRoles are only accepted if
+// registered in the Scanner.AdditionalRoles configuration (or in the allowlist stored in the DB),
+// otherwise RoleInvalid is returned.
+func RoleFromString(role string) Role {
+	name := strings.ToLower(strings.TrimSpace(role))
+	if r, ok := allRoles[name]; ok {
+		return r
+	}
+	if slices.ContainsFunc(additionalRoles(), func(r string) bool {
+		return strings.ToLower(r) == name
+	}) {
+		return Role{role: name}
+	}
+	return RoleInvalid
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// ScanLog is the summary of one scanner pass over a library, recorded when the pass
+// finishes. The history is capped at Scanner.ScanHistoryLength entries per instance
+type ScanLog struct {
+	ID          string    `structs:"id" json:"id"`
+	LibraryPath string    `structs:"library_path" json:"libraryPath"`
+	StartTime   time.Time `structs:"start_time" json:"startTime"`
+	DurationMs  int64     `structs:"duration_ms" json:"durationMs"`
+	FullScan    bool      `structs:"full_scan" json:"fullScan"`
+	Partial     bool      `structs:"partial" json:"partial"`
+	Added       int64     `structs:"added" json:"added"`
+	Updated     int64     `structs:"updated" json:"updated"`
+	Missing     int64     `structs:"missing" json:"missing"`
+	Playlists   int64     `structs:"playlists" json:"playlists"`
+	Error       string    `structs:"error" json:"error,omitempty"`
+}
+
+type ScanLogs []ScanLog
+
+type ScanLogRepository interface {
+	// Add stores the summary of a finished scan, pruning the history down to the
+	// Scanner.ScanHistoryLength most recent entries
+	Add(entry *ScanLog) error
+	GetAll(options ...QueryOptions) (ScanLogs, error)
+	CountAll(options ...QueryOptions) (int64, error)
+}
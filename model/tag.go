@@ -0,0 +1,82 @@
+package model
+
+import (
+	"crypto/md5"
+	"fmt"
+	"strings"
+
+	"github.com/navidrome/navidrome/conf"
+)
+
+// Tag represents a single tag name/value pair (ex: mood=Upbeat), shared by all items tagged
+// with it.
+type Tag struct {
+	ID         string `structs:"id" json:"id"`
+	TagName    string `structs:"tag_name" json:"tagName"`
+	TagValue   string `structs:"tag_value" json:"tagValue"`
+	AlbumCount int    `structs:"-" json:"albumCount"`
+	SongCount  int    `structs:"-" json:"songCount"`
+}
+
+// NewTag returns a Tag for the given name/value pair, with its deterministic ID
+func NewTag(name, value string) Tag {
+	name = strings.ToLower(name)
+	id := fmt.Sprintf("%x", md5.Sum([]byte(name+"|"+strings.ToLower(value))))
+	return Tag{ID: id, TagName: name, TagValue: value}
+}
+
+type Tags []Tag
+
+// AggregateTags combines the tags of all items in a collection (ex: the tracks of an album) into
+// a single list. Tags whose names are listed in Scanner.AdditiveTags are aggregated as the union
+// of all values found. All other tags are considered album-level: a value is only kept when every
+// item carries it, so items disagreeing on an album-level tag leave the collection without it.
+func AggregateTags(perItem []Tags) Tags {
+	additive := map[string]bool{}
+	for _, name := range conf.Server.Scanner.AdditiveTags {
+		additive[strings.ToLower(name)] = true
+	}
+	counts := map[string]int{}
+	var order Tags // keeps the first-seen order, so the result is stable
+	for _, tags := range perItem {
+		seen := map[string]bool{}
+		for _, t := range tags {
+			if seen[t.ID] {
+				continue
+			}
+			seen[t.ID] = true
+			if counts[t.ID] == 0 {
+				order = append(order, t)
+			}
+			counts[t.ID]++
+		}
+	}
+	var result Tags
+	for _, t := range order {
+		if additive[t.TagName] || counts[t.ID] == len(perItem) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// TagMappings records the value merges applied through DataStore.MergeTagValues, keyed by tag
+// name and lowercased source value. The scanner re-applies them at mapping time, so the next
+// scan does not re-create the merged variants from the file tags. They are persisted as JSON
+// under consts.TagMappingsKey
+type TagMappings map[string]map[string]string
+
+// Canonical returns the value the given name/value pair was merged into, or the value unchanged
+// when no mapping was recorded for it
+func (m TagMappings) Canonical(name, value string) string {
+	if to, ok := m[strings.ToLower(name)][strings.ToLower(value)]; ok {
+		return to
+	}
+	return value
+}
+
+type TagRepository interface {
+	GetAll(...QueryOptions) (Tags, error)
+	Put(*Tag) error
+	PurgeUnused() error
+}
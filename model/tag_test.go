@@ -0,0 +1,50 @@
+package model_test
+
+import (
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/conf/configtest"
+	. "github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AggregateTags", func() {
+	moodDark := NewTag("mood", "Dark")
+	moodUpbeat := NewTag("mood", "Upbeat")
+	langEnglish := NewTag("language", "eng")
+
+	BeforeEach(func() {
+		DeferCleanup(configtest.SetupConfig())
+	})
+
+	It("keeps a tag shared by all items", func() {
+		tags := AggregateTags([]Tags{{moodDark, langEnglish}, {moodDark}})
+		Expect(tags).To(Equal(Tags{moodDark}))
+	})
+
+	It("drops a tag when items disagree on its value", func() {
+		tags := AggregateTags([]Tags{{moodDark}, {moodUpbeat}})
+		Expect(tags).To(BeEmpty())
+	})
+
+	It("drops a tag missing from one of the items", func() {
+		tags := AggregateTags([]Tags{{langEnglish}, {}})
+		Expect(tags).To(BeEmpty())
+	})
+
+	Context("with additive tags configured", func() {
+		BeforeEach(func() {
+			conf.Server.Scanner.AdditiveTags = []string{"Mood"}
+		})
+
+		It("unions all values of an additive tag, deduped", func() {
+			tags := AggregateTags([]Tags{{moodDark, moodUpbeat}, {moodDark, langEnglish}})
+			Expect(tags).To(Equal(Tags{moodDark, moodUpbeat}))
+		})
+
+		It("still aggregates the remaining tags at album-level", func() {
+			tags := AggregateTags([]Tags{{moodDark, langEnglish}, {moodUpbeat}})
+			Expect(tags).To(Equal(Tags{moodDark, moodUpbeat}))
+		})
+	})
+})
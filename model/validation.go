@@ -0,0 +1,119 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits applied by Normalize. Indexed text columns are capped so pathological tags don't
+// bloat the indexes; the lyrics blob gets its own, larger cap
+const (
+	maxTextFieldLength = 1024
+	maxLyricsLength    = 64 * 1024
+)
+
+// ErrValidation is wrapped by every ValidationError, so callers can detect the whole class
+// with errors.Is and quarantine the offending row
+var ErrValidation = errors.New("validation error")
+
+// ValidationError describes why a row was rejected by Normalize
+type ValidationError struct {
+	Entity string
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s validation failed: field %s: %s", e.Entity, e.Field, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error { return ErrValidation }
+
+// Normalize cleans the media file up before it is persisted: textual fields are trimmed and
+// capped at maxTextFieldLength, an empty title is substituted with the file name (sans
+// extension), numeric fields are clamped to sane ranges and oversized lyrics are dropped.
+// Truncations are recorded in Tags, so the original data is not silently lost. Rows too broken
+// to persist get a ValidationError instead
+func (mf *MediaFile) Normalize() error {
+	if mf.ID == "" && strings.TrimSpace(mf.Path) == "" {
+		return &ValidationError{Entity: "media_file", Field: "path", Reason: "empty, and no id to derive one from"}
+	}
+
+	for _, f := range []*string{
+		&mf.Title, &mf.Album, &mf.Artist, &mf.AlbumArtist, &mf.DiscSubtitle, &mf.Comment,
+		&mf.SortTitle, &mf.SortAlbumName, &mf.SortArtistName, &mf.SortAlbumArtistName,
+	} {
+		*f = strings.TrimSpace(*f)
+	}
+	for name, f := range map[string]*string{
+		"title": &mf.Title, "album": &mf.Album, "artist": &mf.Artist,
+		"albumartist": &mf.AlbumArtist, "discsubtitle": &mf.DiscSubtitle, "comment": &mf.Comment,
+	} {
+		if truncated, ok := truncateText(*f, maxTextFieldLength); ok {
+			mf.Tags = append(mf.Tags, NewTag("truncated_fields", name))
+			*f = truncated
+		}
+	}
+
+	if mf.Title == "" && mf.Path != "" {
+		base := filepath.Base(mf.Path)
+		mf.Title = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	for _, f := range []*int{&mf.TrackNumber, &mf.DiscNumber, &mf.Bpm} {
+		if *f < 0 {
+			*f = 0
+		}
+	}
+	for _, f := range []*int{&mf.Year, &mf.OriginalYear, &mf.ReleaseYear} {
+		if *f < 0 || *f > 9999 {
+			*f = 0
+		}
+	}
+
+	if len(mf.Lyrics) > maxLyricsLength {
+		// Lyrics are stored as JSON, so they cannot simply be cut at the limit
+		mf.Lyrics = ""
+		mf.Tags = append(mf.Tags, NewTag("truncated_fields", "lyrics"))
+	}
+	return nil
+}
+
+// Normalize trims and caps the album's textual fields. Albums are aggregated from
+// already-normalized tracks, so this mostly guards writes coming through the API
+func (a *Album) Normalize() {
+	for _, f := range []*string{
+		&a.Name, &a.Artist, &a.AlbumArtist, &a.Comment,
+		&a.SortAlbumName, &a.SortArtistName, &a.SortAlbumArtistName,
+	} {
+		*f = strings.TrimSpace(*f)
+		if truncated, ok := truncateText(*f, maxTextFieldLength); ok {
+			*f = truncated
+		}
+	}
+}
+
+// Normalize trims and caps the artist's textual fields
+func (a *Artist) Normalize() {
+	for _, f := range []*string{&a.Name, &a.SortArtistName} {
+		*f = strings.TrimSpace(*f)
+		if truncated, ok := truncateText(*f, maxTextFieldLength); ok {
+			*f = truncated
+		}
+	}
+}
+
+// truncateText caps s at limit bytes without splitting a multi-byte rune, reporting whether
+// anything was cut
+func truncateText(s string, limit int) (string, bool) {
+	if len(s) <= limit {
+		return s, false
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return strings.TrimSpace(s[:limit]), true
+}
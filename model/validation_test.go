@@ -0,0 +1,101 @@
+package model_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Normalize", func() {
+	Describe("MediaFile", func() {
+		var mf model.MediaFile
+
+		BeforeEach(func() {
+			mf = model.MediaFile{Path: "/music/artist/album/01 - Song.mp3", Title: "Song"}
+		})
+
+		It("trims whitespace from the textual fields", func() {
+			mf.Title = "  Song  "
+			mf.Artist = "\tThe Artist\n"
+			Expect(mf.Normalize()).To(Succeed())
+			Expect(mf.Title).To(Equal("Song"))
+			Expect(mf.Artist).To(Equal("The Artist"))
+		})
+
+		It("substitutes the file name for an empty title", func() {
+			mf.Title = "   "
+			Expect(mf.Normalize()).To(Succeed())
+			Expect(mf.Title).To(Equal("01 - Song"))
+		})
+
+		It("caps oversized text fields, recording which ones were cut", func() {
+			mf.Album = strings.Repeat("a", 5000)
+			Expect(mf.Normalize()).To(Succeed())
+			Expect(len(mf.Album)).To(Equal(1024))
+			Expect(mf.Tags).To(ContainElement(model.NewTag("truncated_fields", "album")))
+		})
+
+		It("does not split a multi-byte rune when truncating", func() {
+			mf.Comment = strings.Repeat("é", 1000)
+			Expect(mf.Normalize()).To(Succeed())
+			Expect(mf.Comment).To(Equal(strings.Repeat("é", 512)))
+		})
+
+		It("clamps negative numeric fields to zero", func() {
+			mf.TrackNumber = -1
+			mf.DiscNumber = -3
+			mf.Bpm = -120
+			Expect(mf.Normalize()).To(Succeed())
+			Expect(mf.TrackNumber).To(BeZero())
+			Expect(mf.DiscNumber).To(BeZero())
+			Expect(mf.Bpm).To(BeZero())
+		})
+
+		It("zeroes years outside a sane range", func() {
+			mf.Year = -5
+			mf.OriginalYear = 20220
+			mf.ReleaseYear = 1969
+			Expect(mf.Normalize()).To(Succeed())
+			Expect(mf.Year).To(BeZero())
+			Expect(mf.OriginalYear).To(BeZero())
+			Expect(mf.ReleaseYear).To(Equal(1969))
+		})
+
+		It("drops oversized lyrics, recording the cut", func() {
+			mf.Lyrics = strings.Repeat("x", 65*1024)
+			Expect(mf.Normalize()).To(Succeed())
+			Expect(mf.Lyrics).To(BeEmpty())
+			Expect(mf.Tags).To(ContainElement(model.NewTag("truncated_fields", "lyrics")))
+		})
+
+		It("rejects rows with neither an id nor a path", func() {
+			mf.ID = ""
+			mf.Path = "  "
+			err := mf.Normalize()
+			Expect(err).To(MatchError(model.ErrValidation))
+			var vErr *model.ValidationError
+			Expect(errors.As(err, &vErr)).To(BeTrue())
+			Expect(vErr.Field).To(Equal("path"))
+		})
+	})
+
+	Describe("Album", func() {
+		It("trims and caps the textual fields", func() {
+			al := model.Album{Name: "  The Album  ", Artist: strings.Repeat("b", 2000)}
+			al.Normalize()
+			Expect(al.Name).To(Equal("The Album"))
+			Expect(len(al.Artist)).To(Equal(1024))
+		})
+	})
+
+	Describe("Artist", func() {
+		It("trims and caps the name", func() {
+			ar := model.Artist{Name: " Someone \t"}
+			ar.Normalize()
+			Expect(ar.Name).To(Equal("Someone"))
+		})
+	})
+})
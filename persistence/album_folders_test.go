@@ -0,0 +1,102 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Album folders", func() {
+	var ar model.AlbumRepository
+	var mr model.MediaFileRepository
+	var fr model.FolderRepository
+
+	putTrack := func(id, folderID string) {
+		Expect(mr.Put(&model.MediaFile{ID: id, LibraryID: 1, AlbumID: "af-alb", Title: id,
+			FolderID: folderID, Path: "af/" + id + ".mp3"})).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		ar = NewAlbumRepository(ctx, NewDBXBuilder(db.Db()))
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+		fr = NewFolderRepository(ctx, NewDBXBuilder(db.Db()))
+
+		Expect(fr.Put(&model.Folder{ID: "af-f1", LibraryID: 1, Path: "/af/revolver"})).To(Succeed())
+		Expect(fr.Put(&model.Folder{ID: "af-f2", LibraryID: 1, Path: "/af/revolver2"})).To(Succeed())
+
+		// The classic split-album rip: same album, tracks spread over two directories
+		putTrack("af-1", "af-f1")
+		putTrack("af-2", "af-f1")
+		putTrack("af-3", "af-f2")
+		Expect(ar.Put(&model.Album{ID: "af-alb", LibraryID: 1, Name: "Revolver", SongCount: 3})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		helper := ar.(*albumRepository)
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "af-%"}),
+			squirrel.Delete("album_folders").Where(squirrel.Like{"album_id": "af-%"}),
+			squirrel.Delete("album").Where(squirrel.Like{"id": "af-%"}),
+			squirrel.Delete("folder").Where(squirrel.Like{"id": "af-%"}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("records one row per contributing folder", func() {
+		folders, err := ar.GetFolders("af-alb")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(folders).To(Equal(model.AlbumFolders{
+			{FolderID: "af-f1", Path: "/af/revolver", TrackCount: 2},
+			{FolderID: "af-f2", Path: "/af/revolver2", TrackCount: 1},
+		}))
+	})
+
+	It("follows tracks moving between folders on the next refresh", func() {
+		helper := ar.(*albumRepository)
+		_, err := helper.executeSQL(squirrel.Update("media_file").
+			Set("folder_id", "af-f1").Where(squirrel.Eq{"id": "af-3"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ar.Put(&model.Album{ID: "af-alb", LibraryID: 1, Name: "Revolver", SongCount: 3})).To(Succeed())
+
+		folders, err := ar.GetFolders("af-alb")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(folders).To(Equal(model.AlbumFolders{
+			{FolderID: "af-f1", Path: "/af/revolver", TrackCount: 3},
+		}))
+	})
+
+	Describe("ReduceSongCountByFolder", func() {
+		It("subtracts the folder's contribution without touching other albums' folders", func() {
+			Expect(ar.ReduceSongCountByFolder("af-f2")).To(Succeed())
+
+			album, err := ar.Get("af-alb")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(album.SongCount).To(Equal(2))
+			Expect(album.Missing).To(BeFalse())
+
+			folders, err := ar.GetFolders("af-alb")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(folders).To(HaveLen(1))
+			Expect(folders[0].FolderID).To(Equal("af-f1"))
+		})
+
+		It("flags the album as missing when its last folder goes away", func() {
+			Expect(ar.ReduceSongCountByFolder("af-f2")).To(Succeed())
+			Expect(ar.ReduceSongCountByFolder("af-f1")).To(Succeed())
+
+			album, err := ar.Get("af-alb")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(album.SongCount).To(Equal(0))
+			Expect(album.Missing).To(BeTrue())
+		})
+	})
+})
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	. "github.com/Masterminds/squirrel"
 	"github.com/deluan/rest"
+	"github.com/google/uuid"
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
@@ -59,42 +61,70 @@ func NewAlbumRepository(ctx context.Context, db dbx.Builder) model.AlbumReposito
 	r.ctx = ctx
 	r.db = db
 	r.tableName = "album"
+	r.hashContent = true
+	r.libraryFilter = libraryIDFilter("album")
 	r.filterMappings = map[string]filterFunc{
 		"id":              idFilter(r.tableName),
 		"name":            fullTextFilter,
 		"compilation":     booleanFilter,
+		"missing":         booleanFilter,
 		"artist_id":       artistFilter,
+		"genre_id":        eqFilter,
+		"tag_id":          tagIDFilter(r.tableName),
 		"year":            yearFilter,
 		"recently_played": recentlyPlayedFilter,
 		"starred":         booleanFilter,
 		"has_rating":      hasRatingFilter,
+		"incomplete":      incompleteFilter,
 	}
+	r.orFilterFields = map[string]struct{}{
+		"starred":         {},
+		"has_rating":      {},
+		"recently_played": {},
+		"genre_id":        {},
+		"year":            {},
+	}
+	// The annotation sorts coalesce the NULLs coming from the left join, so never-played and
+	// never-starred albums rank lowest, landing at the end of the descending lists the UI offers
 	if conf.Server.PreferSortTags {
 		r.sortMappings = map[string]string{
-			"name":           "COALESCE(NULLIF(sort_album_name,''),order_album_name)",
-			"artist":         "compilation asc, COALESCE(NULLIF(sort_album_artist_name,''),order_album_artist_name) asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) asc",
-			"albumArtist":    "compilation asc, COALESCE(NULLIF(sort_album_artist_name,''),order_album_artist_name) asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) asc",
+			"name":           "COALESCE(NULLIF(sort_album_name,''),order_album_name) collate unicode",
+			"artist":         "compilation asc, COALESCE(NULLIF(sort_album_artist_name,''),order_album_artist_name) collate unicode asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) collate unicode asc",
+			"albumArtist":    "compilation asc, COALESCE(NULLIF(sort_album_artist_name,''),order_album_artist_name) collate unicode asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) collate unicode asc",
 			"max_year":       "coalesce(nullif(original_date,''), cast(max_year as text)), release_date, name, COALESCE(NULLIF(sort_album_name,''),order_album_name) asc",
 			"random":         r.seededRandomSort(),
 			"recently_added": recentlyAddedSort(),
+			"duration":       "duration_ms",
+			"play_count":     "play_count asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) asc",
+			"play_date":      "coalesce(annotation.play_date, '') asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) asc",
+			"rating":         "rating asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) asc",
+			"starred_at":     "coalesce(annotation.starred_at, '') asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) asc",
 		}
 	} else {
 		r.sortMappings = map[string]string{
-			"name":           "order_album_name asc, order_album_artist_name asc",
-			"artist":         "compilation asc, order_album_artist_name asc, order_album_name asc",
-			"albumArtist":    "compilation asc, order_album_artist_name asc, order_album_name asc",
+			"name":           "order_album_name collate unicode asc, order_album_artist_name collate unicode asc",
+			"artist":         "compilation asc, order_album_artist_name collate unicode asc, order_album_name collate unicode asc",
+			"albumArtist":    "compilation asc, order_album_artist_name collate unicode asc, order_album_name collate unicode asc",
 			"max_year":       "coalesce(nullif(original_date,''), cast(max_year as text)), release_date, name, order_album_name asc",
 			"random":         r.seededRandomSort(),
 			"recently_added": recentlyAddedSort(),
+			"duration":       "duration_ms",
+			"play_count":     "play_count asc, order_album_name asc",
+			"play_date":      "coalesce(annotation.play_date, '') asc, order_album_name asc",
+			"rating":         "rating asc, order_album_name asc",
+			"starred_at":     "coalesce(annotation.starred_at, '') asc, order_album_name asc",
 		}
 	}
 
 	return r
 }
 
+// recentlyAddedSort ranks albums by the DB insertion time, or by the newest file on disk when
+// RecentlyAddedByModTime is set. The latter keeps the list stable across backup restores and
+// re-imports, which re-create the rows but preserve the file timestamps
 func recentlyAddedSort() string {
 	if conf.Server.RecentlyAddedByModTime {
-		return "updated_at"
+		return "coalesce(max_birth_time, created_at)"
 	}
 	return "created_at"
 }
@@ -107,6 +137,13 @@ func hasRatingFilter(string, interface{}) Sqlizer {
 	return Gt{"rating": 0}
 }
 
+// incompleteFilter matches albums flagged by the scanner's track gap detection (see
+// model.MediaFiles.ToAlbum). "incomplete=true" returns only the broken albums
+func incompleteFilter(_ string, value interface{}) Sqlizer {
+	v := strings.ToLower(value.(string))
+	return Eq{"album.complete": v != "true"}
+}
+
 func yearFilter(_ string, value interface{}) Sqlizer {
 	return Or{
 		And{
@@ -123,11 +160,21 @@ func artistFilter(_ string, value interface{}) Sqlizer {
 }
 
 func (r *albumRepository) CountAll(options ...model.QueryOptions) (int64, error) {
-	sql := r.newSelectWithAnnotation("album.id")
+	sql := r.excludeEmpty(r.newSelectWithAnnotation("album.id"), options...)
 	sql = r.withGenres(sql) // Required for filtering by genre
 	return r.count(sql, options...)
 }
 
+// excludeEmpty adds the default song_count > 0 predicate, hiding albums in the transient
+// zero-track state left behind mid-scan or after partial deletes, until the next refresh or GC
+// removes them. The IncludeEmpty option skips the predicate, for admin views
+func (r *albumRepository) excludeEmpty(sql SelectBuilder, options ...model.QueryOptions) SelectBuilder {
+	if len(options) > 0 && options[0].IncludeEmpty {
+		return sql
+	}
+	return sql.Where(Gt{"album.song_count": 0})
+}
+
 func (r *albumRepository) Exists(id string) (bool, error) {
 	return r.exists(Select().Where(Eq{"album.id": id}))
 }
@@ -163,11 +210,174 @@ func (r *albumRepository) Get(id string) (*model.Album, error) {
 }
 
 func (r *albumRepository) Put(m *model.Album) error {
+	m.Normalize()
 	_, err := r.put(m.ID, &dbAlbum{Album: m})
 	if err != nil {
 		return err
 	}
-	return r.updateGenres(m.ID, m.Genres)
+	err = r.updateFullTextIndex(m.ID, m.FullText)
+	if err != nil {
+		return err
+	}
+	err = r.updateGenres(m.ID, m.Genres)
+	if err != nil {
+		return err
+	}
+	err = r.updateTags(m.ID, m.Tags)
+	if err != nil {
+		return err
+	}
+	return r.updateFolders(m.ID)
+}
+
+// updateFolders rebuilds the album_folders association from the album's current (non
+// missing) tracks, so it stays correct when tracks move between directories
+func (r *albumRepository) updateFolders(albumID string) error {
+	del := Delete("album_folders").Where(Eq{"album_id": albumID})
+	if _, err := r.executeSQL(del); err != nil {
+		return err
+	}
+	sel := Select("album_id", "folder_id", "count(*)").From("media_file").
+		Where(And{Eq{"album_id": albumID}, Eq{"missing": false}, NotEq{"folder_id": ""}}).
+		GroupBy("folder_id")
+	ins := Insert("album_folders").Columns("album_id", "folder_id", "track_count").Select(sel)
+	_, err := r.executeSQL(ins)
+	return err
+}
+
+func (r *albumRepository) GetFolders(albumID string) (model.AlbumFolders, error) {
+	sel := Select("af.folder_id", "coalesce(f.path, '') as path", "af.track_count").
+		From("album_folders af").
+		LeftJoin("folder f on f.id = af.folder_id").
+		Where(Eq{"af.album_id": albumID}).
+		OrderBy("f.path")
+	res := model.AlbumFolders{}
+	err := r.queryAll(sel, &res)
+	return res, err
+}
+
+func (r *albumRepository) GetAlbumArtistIDsInFolder(path string) ([]string, error) {
+	sel := Select("distinct album.album_artist_id").From(r.tableName).
+		Where(Expr(`album.id in (select af.album_id from album_folders af
+			join folder f on f.id = af.folder_id where f.parent_id = ?)`, model.FolderID(path)))
+	var res []struct{ AlbumArtistID string }
+	if err := r.queryAll(sel, &res); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(res))
+	for i, a := range res {
+		ids[i] = a.AlbumArtistID
+	}
+	return ids, nil
+}
+
+// ReconcileAnnotations moves the user annotations of albums sharing the given pid over to the
+// album with newID, used when an album's ID changes but its pid still matches (ex: re-grouping
+// after toggling GroupAlbumReleases). When multiple old albums merge into one, their play
+// counts are combined and the earliest starred_at is kept
+func (r *albumRepository) ReconcileAnnotations(pid string, newID string) error {
+	// Only albums left without tracks by the re-grouping are sources: their annotations are
+	// copied (not moved), so an album split into several new ones stars all of them. The
+	// orphaned rows are removed by the garbage collection at the end of the scan
+	sel := Select("id").From(r.tableName).
+		Where(And{Eq{"pid": pid}, NotEq{"id": newID},
+			Expr("not exists (select 1 from media_file where media_file.album_id = album.id)")})
+	var old []struct{ ID string }
+	if err := r.queryAll(sel, &old); err != nil {
+		return err
+	}
+	if len(old) == 0 {
+		return nil
+	}
+	ids := []string{newID}
+	for _, al := range old {
+		ids = append(ids, al.ID)
+	}
+
+	type annRow struct {
+		UserID    string
+		PlayCount int64
+		PlayDate  *time.Time
+		Rating    int
+		Starred   bool
+		StarredAt *time.Time
+	}
+	sel = Select("user_id", "coalesce(play_count, 0) as play_count", "play_date", "coalesce(rating, 0) as rating",
+		"starred", "starred_at").From(annotationTable).Where(Eq{"item_type": r.tableName, "item_id": ids})
+	var rows []annRow
+	if err := r.queryAll(sel, &rows); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	byUser := map[string]*annRow{}
+	for i := range rows {
+		row := rows[i]
+		m, ok := byUser[row.UserID]
+		if !ok {
+			byUser[row.UserID] = &row
+			continue
+		}
+		m.PlayCount += row.PlayCount
+		if row.PlayDate != nil && (m.PlayDate == nil || row.PlayDate.After(*m.PlayDate)) {
+			m.PlayDate = row.PlayDate
+		}
+		if row.Rating > m.Rating {
+			m.Rating = row.Rating
+		}
+		if row.Starred {
+			m.Starred = true
+			if row.StarredAt != nil && (m.StarredAt == nil || row.StarredAt.Before(*m.StarredAt)) {
+				m.StarredAt = row.StarredAt
+			}
+		}
+	}
+
+	del := Delete(annotationTable).Where(Eq{"item_type": r.tableName, "item_id": newID})
+	if _, err := r.executeSQL(del); err != nil {
+		return err
+	}
+	for userID, m := range byUser {
+		ins := Insert(annotationTable).SetMap(map[string]interface{}{
+			"ann_id":     uuid.NewString(),
+			"user_id":    userID,
+			"item_id":    newID,
+			"item_type":  r.tableName,
+			"play_count": m.PlayCount,
+			"play_date":  m.PlayDate,
+			"rating":     m.Rating,
+			"starred":    m.Starred,
+			"starred_at": m.StarredAt,
+		})
+		if _, err := r.executeSQL(ins); err != nil {
+			return err
+		}
+	}
+	log.Debug(r.ctx, "Reconciled album annotations", "pid", pid, "newId", newID,
+		"oldAlbums", len(old), "users", len(byUser))
+	return nil
+}
+
+func (r *albumRepository) ReduceSongCountByFolder(folderID string) error {
+	upd := Update(r.tableName).
+		Set("song_count", Expr(`song_count - (select track_count from album_folders
+			where album_id = album.id and folder_id = ?)`, folderID)).
+		Set("content_hash", "").
+		Where(Expr("id in (select album_id from album_folders where folder_id = ?)", folderID))
+	if _, err := r.executeSQL(upd); err != nil {
+		return err
+	}
+	// Albums whose tracks all lived in the removed folder are gone from disk entirely
+	markMissing := Update(r.tableName).Set("missing", true).Set("content_hash", "").
+		Where(And{LtOrEq{"song_count": 0},
+			Expr("id in (select album_id from album_folders where folder_id = ?)", folderID)})
+	if _, err := r.executeSQL(markMissing); err != nil {
+		return err
+	}
+	_, err := r.executeSQL(Delete("album_folders").Where(Eq{"folder_id": folderID}))
+	return err
 }
 
 func (r *albumRepository) GetAll(options ...model.QueryOptions) (model.Albums, error) {
@@ -181,7 +391,7 @@ func (r *albumRepository) GetAll(options ...model.QueryOptions) (model.Albums, e
 
 func (r *albumRepository) GetAllWithoutGenres(options ...model.QueryOptions) (model.Albums, error) {
 	r.resetSeededRandom(options)
-	sq := r.selectAlbum(options...)
+	sq := r.excludeEmpty(r.selectAlbum(options...), options...)
 	var dba dbAlbums
 	err := r.queryAll(sq, &dba)
 	if err != nil {
@@ -190,6 +400,31 @@ func (r *albumRepository) GetAllWithoutGenres(options ...model.QueryOptions) (mo
 	return dba.toModels(), err
 }
 
+// GetInProgress returns albums the user has started but not finished: the track they played
+// most recently is not the last track of the album. Ordered by the album's last play date
+func (r *albumRepository) GetInProgress(limit int) (model.Albums, error) {
+	lastPlayedTrack := `(select ann.item_id from annotation ann
+		join media_file mf on mf.id = ann.item_id and mf.album_id = album.id
+		where ann.item_type = 'media_file' and ann.user_id = '` + userId(r.ctx) + `'
+		and ann.play_date is not null order by ann.play_date desc limit 1)`
+	finalTrack := `(select mf.id from media_file mf where mf.album_id = album.id
+		order by mf.disc_number desc, mf.track_number desc limit 1)`
+	sq := r.selectAlbum().
+		Where(Expr(lastPlayedTrack + " is not null")).
+		Where(Expr(lastPlayedTrack + " <> " + finalTrack)).
+		OrderBy("play_date desc")
+	if limit > 0 {
+		sq = sq.Limit(uint64(limit))
+	}
+	var dba dbAlbums
+	if err := r.queryAll(sq, &dba); err != nil {
+		return nil, err
+	}
+	res := dba.toModels()
+	err := loadAllGenres(r, res)
+	return res, err
+}
+
 func (r *albumRepository) purgeEmpty() error {
 	del := Delete(r.tableName).Where("id not in (select distinct(album_id) from media_file)")
 	c, err := r.executeSQL(del)
@@ -212,6 +447,17 @@ func (r *albumRepository) Search(q string, offset int, size int) (model.Albums,
 	return res, err
 }
 
+func (r *albumRepository) SearchWithTotal(q string, offset int, size int) (model.Albums, int64, error) {
+	var dba dbAlbums
+	total, err := r.doSearchWithTotal(q, offset, size, &dba, "name")
+	if err != nil {
+		return nil, 0, err
+	}
+	res := dba.toModels()
+	err = loadAllGenres(r, res)
+	return res, total, err
+}
+
 func (r *albumRepository) Count(options ...rest.QueryOptions) (int64, error) {
 	return r.CountAll(r.parseRestOptions(options...))
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/Masterminds/squirrel"
+	"github.com/deluan/rest"
 	"github.com/fatih/structs"
 	"github.com/google/uuid"
 	"github.com/navidrome/navidrome/conf"
@@ -20,7 +22,7 @@ var _ = Describe("AlbumRepository", func() {
 	var repo model.AlbumRepository
 
 	BeforeEach(func() {
-		ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", UserName: "johndoe"})
+		ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", UserName: "johndoe", IsAdmin: true})
 		repo = NewAlbumRepository(ctx, NewDBXBuilder(db.Db()))
 	})
 
@@ -62,6 +64,291 @@ var _ = Describe("AlbumRepository", func() {
 		})
 	})
 
+	Describe("ReadAll", func() {
+		It("filters by genre, including albums where only some tracks have it", func() {
+			// "Rock" is only tagged on one of the two "Radioactivity" tracks, but the album must
+			// still be returned
+			res, err := repo.(*albumRepository).ReadAll(rest.QueryOptions{Filters: map[string]interface{}{"genre_id": "gn-2"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.(model.Albums)).To(HaveLen(3))
+		})
+
+		It("filters by genre name regardless of case, as sent by Subsonic clients", func() {
+			// Same filter shape as filter.AlbumsByGenre
+			opts := model.QueryOptions{
+				Sort:    "genre.name asc, name asc",
+				Filters: squirrel.Expr("genre.name = ? collate nocase", "ROCK"),
+			}
+			albums, err := repo.GetAllWithoutGenres(opts)
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, a := range albums {
+				ids = append(ids, a.ID)
+			}
+			Expect(ids).To(Equal([]string{albumAbbeyRoad.ID, albumRadioactivity.ID, albumSgtPeppers.ID}))
+			Expect(repo.CountAll(opts)).To(Equal(int64(3)))
+		})
+
+		It("does not return albums without the given genre", func() {
+			res, err := repo.(*albumRepository).ReadAll(rest.QueryOptions{Filters: map[string]interface{}{"genre_id": "gn-1"}})
+			Expect(err).ToNot(HaveOccurred())
+			albums := res.(model.Albums)
+			Expect(albums).To(HaveLen(1))
+			Expect(albums[0].ID).To(Equal(albumRadioactivity.ID))
+		})
+	})
+
+	Describe("Search", func() {
+		search := func(q string) []string {
+			res, err := repo.Search(q, 0, 10)
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, al := range res {
+				ids = append(ids, al.ID)
+			}
+			return ids
+		}
+
+		BeforeEach(func() {
+			// The album is built from its tracks, the same way the scanner does, so the test
+			// covers the full_text produced by ToAlbum
+			participations := model.Participations{}
+			participations.Add(model.RoleProducer, model.Participant{ID: "as-art", Name: "George Martin"})
+			album := model.MediaFiles{{
+				AlbumID: "as-1", Album: "Revolver", AlbumArtist: "The Beatles", Artist: "The Beatles",
+				CatalogNum: "PCS 7009", Participations: participations,
+			}}.ToAlbum()
+			album.LibraryID = 1
+			Expect(repo.Put(&album)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			r := repo.(*albumRepository)
+			_, err := r.executeSQL(squirrel.Delete("album").Where(squirrel.Eq{"id": "as-1"}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.cleanFullTextIndex()).To(Succeed())
+		})
+
+		It("matches the album artist combined with the album name, in any order", func() {
+			Expect(search("beatles revolver")).To(ContainElement("as-1"))
+			Expect(search("revolver beatles")).To(ContainElement("as-1"))
+			Expect(search("revol beat")).To(ContainElement("as-1"))
+		})
+
+		It("matches participating artists and the catalog number", func() {
+			Expect(search("revolver martin")).To(ContainElement("as-1"))
+			Expect(search("pcs 7009")).To(ContainElement("as-1"))
+		})
+
+		It("requires all words to match", func() {
+			Expect(search("revolver kraftwerk")).To(BeEmpty())
+		})
+	})
+
+	Describe("Empty albums", func() {
+		// Zero-track albums are a transient state: a scan or a partial delete can leave the album
+		// row behind until the next refresh or GC removes it
+		BeforeEach(func() {
+			empty := model.Album{LibraryID: 1, ID: "ea-1", Name: "Empty Album", OrderAlbumName: "empty album", SongCount: 0}
+			Expect(repo.Put(&empty)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			r := repo.(*albumRepository)
+			_, err := r.executeSQL(squirrel.Delete("album").Where(squirrel.Eq{"id": "ea-1"}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("is hidden from GetAll and CountAll by default", func() {
+			albums, err := repo.GetAll(model.QueryOptions{Sort: "name"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(albums).To(Equal(model.Albums{
+				albumAbbeyRoad,
+				albumRadioactivity,
+				albumSgtPeppers,
+			}))
+			Expect(repo.CountAll()).To(Equal(int64(3)))
+		})
+
+		It("is returned when the IncludeEmpty option is given", func() {
+			albums, err := repo.GetAll(model.QueryOptions{Sort: "name", IncludeEmpty: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(albums).To(HaveLen(4))
+			Expect(albums[1].ID).To(Equal("ea-1"))
+			Expect(repo.CountAll(model.QueryOptions{IncludeEmpty: true})).To(Equal(int64(4)))
+		})
+
+		It("is returned by ReadAll when the include_empty filter is given", func() {
+			res, err := repo.(*albumRepository).ReadAll(rest.QueryOptions{Sort: "name",
+				Filters: map[string]interface{}{"include_empty": "true"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.(model.Albums)).To(HaveLen(4))
+		})
+
+		It("is still accessible by id", func() {
+			album, err := repo.Get("ea-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(album.Name).To(Equal("Empty Album"))
+		})
+
+		It("is removed by the GC phase", func() {
+			Expect(repo.(*albumRepository).purgeEmpty()).To(Succeed())
+			_, err := repo.Get("ea-1")
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+	})
+
+	Describe("Annotation sorts", func() {
+		BeforeEach(func() {
+			Expect(repo.IncPlayCount("103", time.Now())).To(Succeed())
+			Expect(repo.IncPlayCount("103", time.Now())).To(Succeed())
+			Expect(repo.IncPlayCount("101", time.Now())).To(Succeed())
+		})
+
+		AfterEach(func() {
+			r := repo.(*albumRepository)
+			_, err := r.executeSQL(squirrel.Delete("annotation").Where(squirrel.Eq{"item_id": []string{"101", "103"}}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("sorts by play count, with never-played albums at the end", func() {
+			albums, err := repo.GetAll(model.QueryOptions{Sort: "play_count", Order: "desc"})
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, a := range albums {
+				ids = append(ids, a.ID)
+			}
+			Expect(ids).To(Equal([]string{"103", "101", "102"}))
+		})
+	})
+
+	Describe("Recently added sort", func() {
+		var restoredRip, freshDownload model.Album
+
+		// The sort mappings are bound when the repository is created, so a new instance is
+		// needed after flipping RecentlyAddedByModTime
+		newRepo := func() model.AlbumRepository {
+			ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", UserName: "johndoe", IsAdmin: true})
+			return NewAlbumRepository(ctx, NewDBXBuilder(db.Db()))
+		}
+
+		recentlyAdded := func(r model.AlbumRepository) []string {
+			albums, err := r.GetAll(model.QueryOptions{Sort: "recently_added", Order: "desc",
+				Filters: squirrel.Like{"album.id": "za-%"}})
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, a := range albums {
+				ids = append(ids, a.ID)
+			}
+			return ids
+		}
+
+		BeforeEach(func() {
+			// A restored backup re-creates the row (recent CreatedAt) but the files on disk
+			// keep their original birth times
+			restoredRip = model.Album{LibraryID: 1, ID: "za-1", Name: "Restored Rip", SongCount: 1,
+				CreatedAt:    time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+				MinBirthTime: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+				MaxBirthTime: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+			}
+			freshDownload = model.Album{LibraryID: 1, ID: "za-2", Name: "Fresh Download", SongCount: 1,
+				CreatedAt:    time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+				MinBirthTime: time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC),
+				MaxBirthTime: time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC),
+			}
+			Expect(repo.Put(&restoredRip)).To(Succeed())
+			Expect(repo.Put(&freshDownload)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			conf.Server.RecentlyAddedByModTime = false
+			r := repo.(*albumRepository)
+			_, err := r.executeSQL(squirrel.Delete("album").Where(squirrel.Like{"id": "za-%"}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("follows the DB insertion time by default", func() {
+			Expect(recentlyAdded(newRepo())).To(Equal([]string{"za-1", "za-2"}))
+		})
+
+		It("follows the newest file on disk when RecentlyAddedByModTime is set", func() {
+			conf.Server.RecentlyAddedByModTime = true
+			Expect(recentlyAdded(newRepo())).To(Equal([]string{"za-2", "za-1"}))
+		})
+
+		It("keeps the album's position when a tag edit restamps it, in birth time mode", func() {
+			conf.Server.RecentlyAddedByModTime = true
+			r := newRepo()
+
+			restoredRip.Comment = "tags edited"
+			restoredRip.UpdatedAt = time.Now()
+			Expect(r.Put(&restoredRip)).To(Succeed())
+
+			Expect(recentlyAdded(r)).To(Equal([]string{"za-2", "za-1"}))
+		})
+	})
+
+	Describe("Tags", func() {
+		tagDark := model.NewTag("mood", "Dark")
+
+		AfterEach(func() {
+			r := repo.(*albumRepository)
+			_, err := r.executeSQL(squirrel.Delete(r.tableName).Where(squirrel.Eq{"id": "9201"}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.cleanItemTags()).To(Succeed())
+			tr := NewTagRepository(log.NewContext(context.TODO()), NewDBXBuilder(db.Db()))
+			Expect(tr.PurgeUnused()).To(Succeed())
+		})
+
+		It("persists the album tags and filters by them", func() {
+			Expect(repo.Put(&model.Album{LibraryID: 1, ID: "9201", Name: "Tagged", SongCount: 1, Tags: model.Tags{tagDark}})).To(Succeed())
+
+			res, err := repo.(*albumRepository).ReadAll(rest.QueryOptions{Filters: map[string]interface{}{"tag_id": tagDark.ID}})
+			Expect(err).ToNot(HaveOccurred())
+			albums := res.(model.Albums)
+			Expect(albums).To(HaveLen(1))
+			Expect(albums[0].ID).To(Equal("9201"))
+		})
+	})
+
+	Describe("Incomplete albums", func() {
+		AfterEach(func() {
+			r := repo.(*albumRepository)
+			_, err := r.executeSQL(squirrel.Delete(r.tableName).Where(squirrel.Eq{"id": []string{"9301", "9302"}}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("persists the gap detection flags and filters by them", func() {
+			Expect(repo.Put(&model.Album{LibraryID: 1, ID: "9301", Name: "Gappy", SongCount: 2, Complete: false, MissingTracks: "disc 1: missing 3"})).To(Succeed())
+			Expect(repo.Put(&model.Album{LibraryID: 1, ID: "9302", Name: "Whole", SongCount: 3, Complete: true})).To(Succeed())
+
+			res, err := repo.(*albumRepository).ReadAll(rest.QueryOptions{Filters: map[string]interface{}{"incomplete": "true"}})
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, a := range res.(model.Albums) {
+				ids = append(ids, a.ID)
+			}
+			Expect(ids).To(ContainElement("9301"))
+			Expect(ids).ToNot(ContainElement("9302"))
+
+			album, err := repo.Get("9301")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(album.MissingTracks).To(Equal("disc 1: missing 3"))
+		})
+	})
+
+	Describe("ReplayGain", func() {
+		It("persists the album gain and peak", func() {
+			id := uuid.NewString()
+			Expect(repo.Put(&model.Album{LibraryID: 1, ID: id, Name: "name", RgAlbumGain: -3.21, RgAlbumPeak: 0.95})).To(Succeed())
+
+			album, err := repo.Get(id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(album.RgAlbumGain).To(Equal(-3.21))
+			Expect(album.RgAlbumPeak).To(Equal(0.95))
+		})
+	})
+
 	Describe("dbAlbum mapping", func() {
 		Describe("Album.Discs", func() {
 			var a *model.Album
@@ -0,0 +1,203 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/deluan/sanitize"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/utils/str"
+	"github.com/pocketbase/dbx"
+)
+
+// MergeDuplicateArtists folds artist rows whose names map to the same ID after name
+// normalization (see model.ParticipantID) into a single row under the canonical ID, combining
+// their annotations, re-pointing their participations and removing the duplicates. It is a no-op
+// unless Scanner.NormalizeArtistNames is enabled, and is called by the scanner at the start of
+// full scans, so the merged rows are rebuilt with up-to-date stats right after
+func (s *SQLStore) MergeDuplicateArtists(ctx context.Context) (int64, error) {
+	if !conf.Server.Scanner.NormalizeArtistNames {
+		return 0, nil
+	}
+	repo := s.Artist(ctx).(*artistRepository).sqlRepository
+
+	var artists []struct{ ID, Name string }
+	if err := repo.queryAll(Select("id", "name").From("artist"), &artists); err != nil {
+		return 0, err
+	}
+	groups := map[string][]struct{ ID, Name string }{}
+	for _, a := range artists {
+		target := model.ParticipantID(a.Name)
+		groups[target] = append(groups[target], a)
+	}
+
+	var merged int64
+	for target, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		// Keep the row already under the canonical ID, or else the variant with diacritics
+		keep := group[0]
+		for _, a := range group {
+			if a.ID == target {
+				keep = a
+				break
+			}
+			if nameHasDiacritics(a.Name) && !nameHasDiacritics(keep.Name) {
+				keep = a
+			}
+		}
+		bestName := keep.Name
+		for _, a := range group {
+			if nameHasDiacritics(a.Name) {
+				bestName = a.Name
+				break
+			}
+		}
+		log.Debug(ctx, "Merging duplicate artists", "name", bestName, "canonicalId", target, "count", len(group))
+
+		if keep.ID != target {
+			if err := moveArtist(repo, keep.ID, target); err != nil {
+				return merged, err
+			}
+		}
+		for _, a := range group {
+			if a.ID == keep.ID {
+				continue
+			}
+			if err := mergeArtistInto(repo, a.ID, target); err != nil {
+				return merged, err
+			}
+		}
+		upd := Update("artist").
+			Set("name", bestName).
+			Set("order_artist_name", str.SanitizeFieldForSortingNoArticle(bestName)).
+			Set("content_hash", "").
+			Where(Eq{"id": target})
+		if _, err := repo.executeSQL(upd); err != nil {
+			return merged, err
+		}
+		merged += int64(len(group) - 1)
+	}
+	return merged, nil
+}
+
+func nameHasDiacritics(name string) bool {
+	return sanitize.Accents(name) != name
+}
+
+// moveArtist re-points the artist row (and everything hanging off it) to the canonical ID
+func moveArtist(repo sqlRepository, from, to string) error {
+	if err := mergeAnnotations(repo, from, to); err != nil {
+		return err
+	}
+	if err := repointArtistLinks(repo, from, to); err != nil {
+		return err
+	}
+	_, err := repo.executeSQL(Update("artist").Set("id", to).Set("content_hash", "").Where(Eq{"id": from}))
+	return err
+}
+
+// mergeArtistInto merges the duplicate into the canonical artist and removes its row
+func mergeArtistInto(repo sqlRepository, from, to string) error {
+	if err := mergeAnnotations(repo, from, to); err != nil {
+		return err
+	}
+	if err := repointArtistLinks(repo, from, to); err != nil {
+		return err
+	}
+	_, err := repo.executeSQL(Delete("artist").Where(Eq{"id": from}))
+	return err
+}
+
+// repointArtistLinks updates the participation and library links to the canonical artist ID,
+// dropping rows that would conflict with existing links
+func repointArtistLinks(repo sqlRepository, from, to string) error {
+	for _, table := range []string{"media_file_artists", "library_artist"} {
+		_, err := repo.db.NewQuery("update or ignore " + table + " set artist_id = {:to} where artist_id = {:from}").
+			Bind(dbx.Params{"to": to, "from": from}).Execute()
+		if err != nil {
+			return err
+		}
+		_, err = repo.db.NewQuery("delete from " + table + " where artist_id = {:from}").
+			Bind(dbx.Params{"from": from}).Execute()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type artistAnnotationRow struct {
+	AnnID     string
+	UserID    string
+	PlayCount int64
+	PlayDate  *time.Time
+	Rating    int
+	Starred   bool
+	StarredAt *time.Time
+}
+
+// mergeAnnotations combines the artist annotations of every user: play counts are summed,
+// starred wins, and the highest rating and most recent play date are kept
+func mergeAnnotations(repo sqlRepository, from, to string) error {
+	annSelect := func(itemID string, where ...Sqlizer) SelectBuilder {
+		sel := Select("ann_id", "user_id", "play_count", "play_date", "rating", "starred", "starred_at").
+			From(annotationTable).
+			Where(And{Eq{"item_type": "artist"}, Eq{"item_id": itemID}})
+		for _, w := range where {
+			sel = sel.Where(w)
+		}
+		return sel
+	}
+
+	var losers []artistAnnotationRow
+	if err := repo.queryAll(annSelect(from), &losers); err != nil {
+		return err
+	}
+	for _, l := range losers {
+		var winners []artistAnnotationRow
+		if err := repo.queryAll(annSelect(to, Eq{"user_id": l.UserID}), &winners); err != nil {
+			return err
+		}
+		if len(winners) == 0 {
+			_, err := repo.executeSQL(Update(annotationTable).Set("item_id", to).Where(Eq{"ann_id": l.AnnID}))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		w := winners[0]
+		starredAt := w.StarredAt
+		if !w.Starred {
+			starredAt = l.StarredAt
+		}
+		upd := Update(annotationTable).
+			Set("play_count", w.PlayCount+l.PlayCount).
+			Set("play_date", latestTime(w.PlayDate, l.PlayDate)).
+			Set("rating", max(w.Rating, l.Rating)).
+			Set("starred", w.Starred || l.Starred).
+			Set("starred_at", starredAt).
+			Where(Eq{"ann_id": w.AnnID})
+		if _, err := repo.executeSQL(upd); err != nil {
+			return err
+		}
+		if _, err := repo.executeSQL(Delete(annotationTable).Where(Eq{"ann_id": l.AnnID})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func latestTime(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.After(*b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MergeDuplicateArtists", func() {
+	var ds model.DataStore
+	var ctx context.Context
+	var helper sqlRepository
+	const mergeUser = "artist-merge-user"
+	const mergeSong = "artist-merge-song"
+
+	// The ID artists get when scanned without name normalization
+	legacyID := func(name string) string {
+		return fmt.Sprintf("%x", md5.Sum([]byte(strings.ToLower(name))))
+	}
+	diacriticID := legacyID("Sigur Rós")
+	plainID := legacyID("Sigur Ros")
+
+	countRows := func(table string, filters squirrel.Eq) int64 {
+		sel := squirrel.Select("count(*) as count").From(table).Where(filters)
+		var res struct{ Count int64 }
+		Expect(helper.queryOne(sel, &res)).To(Succeed())
+		return res.Count
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: mergeUser, IsAdmin: true})
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+		conf.Server.Scanner.NormalizeArtistNames = true
+
+		Expect(ds.Artist(ctx).Put(&model.Artist{ID: diacriticID, Name: "Sigur Rós"})).To(Succeed())
+		Expect(ds.Artist(ctx).Put(&model.Artist{ID: plainID, Name: "Sigur Ros"})).To(Succeed())
+
+		ar := ds.Artist(ctx)
+		Expect(ar.SetStar(true, diacriticID)).To(Succeed())
+		for i := 0; i < 3; i++ {
+			Expect(ar.IncPlayCount(diacriticID, time.Now())).To(Succeed())
+		}
+		for i := 0; i < 2; i++ {
+			Expect(ar.IncPlayCount(plainID, time.Now())).To(Succeed())
+		}
+
+		ins := squirrel.Insert("media_file_artists").
+			Columns("media_file_id", "artist_id", "role").
+			Values(mergeSong, diacriticID, "artist")
+		_, err := helper.executeSQL(ins)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		conf.Server.Scanner.NormalizeArtistNames = false
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("artist").Where(squirrel.Eq{"id": []string{diacriticID, plainID}}),
+			squirrel.Delete(annotationTable).Where(squirrel.Eq{"user_id": mergeUser}),
+			squirrel.Delete("media_file_artists").Where(squirrel.Eq{"media_file_id": mergeSong}),
+			squirrel.Delete("library_artist").Where(squirrel.Eq{"artist_id": []string{diacriticID, plainID}}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("merges the duplicates into the canonical artist", func() {
+		merged, err := ds.MergeDuplicateArtists(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(merged).To(Equal(int64(1)))
+
+		Expect(countRows("artist", squirrel.Eq{"id": diacriticID})).To(Equal(int64(0)))
+
+		// The merged artist keeps the display name with diacritics
+		winner, err := ds.Artist(ctx).Get(plainID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(winner.Name).To(Equal("Sigur Rós"))
+
+		// Annotations of both rows are combined: play counts summed, starred wins
+		Expect(winner.PlayCount).To(Equal(int64(5)))
+		Expect(winner.Starred).To(BeTrue())
+
+		// Participations are re-pointed to the canonical artist
+		Expect(countRows("media_file_artists", squirrel.Eq{"artist_id": plainID, "media_file_id": mergeSong})).To(Equal(int64(1)))
+		Expect(countRows("media_file_artists", squirrel.Eq{"artist_id": diacriticID})).To(Equal(int64(0)))
+
+		// A second run has nothing left to merge
+		merged, err = ds.MergeDuplicateArtists(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(merged).To(BeZero())
+	})
+
+	It("does nothing when Scanner.NormalizeArtistNames is disabled", func() {
+		conf.Server.Scanner.NormalizeArtistNames = false
+
+		merged, err := ds.MergeDuplicateArtists(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(merged).To(BeZero())
+		Expect(countRows("artist", squirrel.Eq{"id": []string{diacriticID, plainID}})).To(Equal(int64(2)))
+	})
+})
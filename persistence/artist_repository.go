@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"sort"
@@ -27,9 +28,15 @@ type artistRepository struct {
 type dbArtist struct {
 	*model.Artist  `structs:",flatten"`
 	SimilarArtists string `structs:"-" json:"similarArtists"`
+	Stats          string `structs:"-" json:"stats"`
 }
 
 func (a *dbArtist) PostScan() error {
+	if a.Stats != "" && a.Stats != "{}" {
+		if err := json.Unmarshal([]byte(a.Stats), &a.Artist.Stats); err != nil {
+			return fmt.Errorf("parsing artist stats from db: %w", err)
+		}
+	}
 	if a.SimilarArtists == "" {
 		return nil
 	}
@@ -52,6 +59,11 @@ func (a *dbArtist) PostMapArgs(m map[string]any) error {
 		sa = append(sa, fmt.Sprintf("%s:%s", s.ID, url.QueryEscape(s.Name)))
 	}
 	m["similar_artists"] = strings.Join(sa, ";")
+	stats, err := json.Marshal(a.Artist.Stats)
+	if err != nil {
+		return fmt.Errorf("marshalling artist stats: %w", err)
+	}
+	m["stats"] = string(stats)
 	return nil
 }
 
@@ -61,18 +73,27 @@ func NewArtistRepository(ctx context.Context, db dbx.Builder) model.ArtistReposi
 	r.db = db
 	r.indexGroups = utils.ParseIndexGroups(conf.Server.IndexGroups)
 	r.tableName = "artist"
+	r.hashContent = true
+	// Artists don't have a library_id column; they are visible when any library the user
+	// has access to references them
+	r.libraryFilter = func(userID string) Sqlizer {
+		return Expr(`artist.id in (select la.artist_id from library_artist la
+			join user_library ul on la.library_id = ul.library_id where ul.user_id = ?)`, userID)
+	}
 	r.filterMappings = map[string]filterFunc{
-		"id":      idFilter(r.tableName),
-		"name":    fullTextFilter,
-		"starred": booleanFilter,
+		"id":       idFilter(r.tableName),
+		"name":     fullTextFilter,
+		"starred":  booleanFilter,
+		"genre_id": eqFilter,
+		"role":     roleFilter,
 	}
 	if conf.Server.PreferSortTags {
 		r.sortMappings = map[string]string{
-			"name": "COALESCE(NULLIF(sort_artist_name,''),order_artist_name)",
+			"name": "COALESCE(NULLIF(sort_artist_name,''),order_artist_name) collate unicode",
 		}
 	} else {
 		r.sortMappings = map[string]string{
-			"name": "order_artist_name",
+			"name": "order_artist_name collate unicode",
 		}
 	}
 	return r
@@ -94,12 +115,16 @@ func (r *artistRepository) Exists(id string) (bool, error) {
 }
 
 func (r *artistRepository) Put(a *model.Artist, colsToUpdate ...string) error {
+	a.Normalize()
 	a.FullText = getFullText(a.Name, a.SortArtistName)
 	dba := &dbArtist{Artist: a}
 	_, err := r.put(dba.ID, dba, colsToUpdate...)
 	if err != nil {
 		return err
 	}
+	if err = r.updateFullTextIndex(a.ID, a.FullText); err != nil {
+		return err
+	}
 	if a.ID == consts.VariousArtistsID {
 		return r.updateGenres(a.ID, nil)
 	}
@@ -152,8 +177,16 @@ func (r *artistRepository) getIndexKey(a *model.Artist) string {
 }
 
 // TODO Cache the index (recalculate when there are changes to the DB)
-func (r *artistRepository) GetIndex() (model.ArtistIndexes, error) {
-	all, err := r.GetAll(model.QueryOptions{Sort: "order_artist_name"})
+func (r *artistRepository) GetIndex(roles ...string) (model.ArtistIndexes, error) {
+	options := model.QueryOptions{Sort: "order_artist_name"}
+	if len(roles) > 0 {
+		filters := Or{}
+		for _, role := range roles {
+			filters = append(filters, roleFilter("role", role))
+		}
+		options.Filters = filters
+	}
+	all, err := r.GetAll(options)
 	if err != nil {
 		return nil, err
 	}
@@ -179,8 +212,28 @@ func (r *artistRepository) GetIndex() (model.ArtistIndexes, error) {
 	return result, nil
 }
 
+// RefreshStats recomputes the per-role song/album counters of the given artists, based on the
+// rows currently in media_file_artists
+func (r *artistRepository) RefreshStats(artistIDs ...string) error {
+	upd := Update(r.tableName).
+		Set("stats", Expr(`coalesce((
+			select json_group_object(role, json_object('songCount', songs, 'albumCount', albums))
+			from (select mfa.role, count(distinct mfa.media_file_id) as songs, count(distinct mf.album_id) as albums
+				from media_file_artists mfa
+				join media_file mf on mf.id = mfa.media_file_id
+				where mfa.artist_id = artist.id
+				group by mfa.role)
+			), '{}')`)).
+		Where(Eq{"id": artistIDs})
+	_, err := r.executeSQL(upd)
+	return err
+}
+
 func (r *artistRepository) purgeEmpty() error {
-	del := Delete(r.tableName).Where("id not in (select distinct(album_artist_id) from album)")
+	del := Delete(r.tableName).Where(And{
+		ConcatExpr("id not in (select distinct(album_artist_id) from album)"),
+		ConcatExpr("id not in (select distinct(artist_id) from media_file_artists)"),
+	})
 	c, err := r.executeSQL(del)
 	if err == nil {
 		if c > 0 {
@@ -199,6 +252,15 @@ func (r *artistRepository) Search(q string, offset int, size int) (model.Artists
 	return r.toModels(dba), nil
 }
 
+func (r *artistRepository) SearchWithTotal(q string, offset int, size int) (model.Artists, int64, error) {
+	var dba []dbArtist
+	total, err := r.doSearchWithTotal(q, offset, size, &dba, "name")
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.toModels(dba), total, nil
+}
+
 func (r *artistRepository) Count(options ...rest.QueryOptions) (int64, error) {
 	return r.CountAll(r.parseRestOptions(options...))
 }
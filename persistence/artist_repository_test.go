@@ -18,7 +18,7 @@ var _ = Describe("ArtistRepository", func() {
 
 	BeforeEach(func() {
 		ctx := log.NewContext(context.TODO())
-		ctx = request.WithUser(ctx, model.User{ID: "userid"})
+		ctx = request.WithUser(ctx, model.User{ID: "userid", IsAdmin: true})
 		repo = NewArtistRepository(ctx, NewDBXBuilder(db.Db()))
 	})
 
@@ -43,6 +43,51 @@ var _ = Describe("ArtistRepository", func() {
 		})
 	})
 
+	Describe("Role filter", func() {
+		var mr model.MediaFileRepository
+
+		BeforeEach(func() {
+			ctx := log.NewContext(context.TODO())
+			ctx = request.WithUser(ctx, model.User{ID: "userid", IsAdmin: true})
+			mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+
+			Expect(repo.Put(&model.Artist{ID: "cmp-1", Name: "A Composer"})).To(Succeed())
+			mf := model.MediaFile{LibraryID: 1, ID: "8001", Title: "With Composer", Path: P("/roles/8001.mp3")}
+			mf.Participations = model.Participations{}
+			mf.Participations.Add(model.RoleAlbumArtist, model.Participant{ID: artistBeatles.ID, Name: artistBeatles.Name})
+			mf.Participations.Add(model.RoleComposer, model.Participant{ID: "cmp-1", Name: "A Composer"})
+			Expect(mr.Put(&mf)).To(Succeed())
+			Expect(repo.RefreshStats(artistBeatles.ID, "cmp-1")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(mr.Delete("8001")).To(Succeed())
+			Expect(mr.(*mediaFileRepository).cleanParticipations()).To(Succeed())
+			Expect(repo.(*artistRepository).purgeEmpty()).To(Succeed())
+			Expect(repo.RefreshStats(artistBeatles.ID)).To(Succeed())
+		})
+
+		It("only returns artists participating with the given role", func() {
+			composers, err := repo.GetAll(model.QueryOptions{Filters: roleFilter("role", "composer")})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(composers).To(HaveLen(1))
+			Expect(composers[0].ID).To(Equal("cmp-1"))
+
+			albumArtists, err := repo.GetAll(model.QueryOptions{Filters: roleFilter("role", "albumartist")})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(albumArtists).To(HaveLen(1))
+			Expect(albumArtists[0].ID).To(Equal(artistBeatles.ID))
+		})
+
+		It("refreshes the per-role counters", func() {
+			composer, err := repo.Get("cmp-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(composer.Stats).To(HaveKey("composer"))
+			Expect(composer.Stats["composer"].SongCount).To(Equal(int64(1)))
+			Expect(composer.Stats["composer"].AlbumCount).To(Equal(int64(1)))
+		})
+	})
+
 	Describe("GetIndex", func() {
 		It("returns the index", func() {
 			idx, err := repo.GetIndex()
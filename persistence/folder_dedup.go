@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MergeDuplicateFolders folds folder rows whose paths map to the same ID after path
+// normalization (see model.FolderID) into a single row under the canonical ID, re-pointing
+// media_file.folder_id and the parent_id of child folders. Rows created before the
+// normalization was introduced are simply moved to their new ID. It is called by the scanner
+// at the start of full scans, so the surviving rows are refreshed right after
+func (s *SQLStore) MergeDuplicateFolders(ctx context.Context) (int64, error) {
+	repo := s.Folder(ctx).(*folderRepository).sqlRepository
+
+	var folders []struct{ ID, Path string }
+	if err := repo.queryAll(Select("id", "path").From("folder"), &folders); err != nil {
+		return 0, err
+	}
+	groups := map[string][]struct{ ID, Path string }{}
+	for _, f := range folders {
+		target := model.FolderID(f.Path)
+		groups[target] = append(groups[target], f)
+	}
+
+	var merged int64
+	for target, group := range groups {
+		// Keep the row already under the canonical ID, or else the NFC path variant
+		keep := group[0]
+		for _, f := range group {
+			if f.ID == target {
+				keep = f
+				break
+			}
+			if norm.NFC.String(f.Path) == f.Path {
+				keep = f
+			}
+		}
+		if len(group) > 1 {
+			paths := make([]string, len(group))
+			for i, f := range group {
+				paths[i] = f.Path
+			}
+			log.Info(ctx, "Merging duplicate folders", "path", keep.Path, "canonicalId", target,
+				"mergedPaths", paths)
+			merged += int64(len(group) - 1)
+		}
+		for _, f := range group {
+			if f.ID == target {
+				continue
+			}
+			// Re-point everything under the old ID to the canonical one
+			if _, err := repo.executeSQL(Update("folder").Set("parent_id", target).Where(Eq{"parent_id": f.ID})); err != nil {
+				return merged, err
+			}
+			if _, err := repo.executeSQL(Update("media_file").Set("folder_id", target).
+				Set("content_hash", "").Where(Eq{"folder_id": f.ID})); err != nil {
+				return merged, err
+			}
+			if f.ID == keep.ID {
+				if _, err := repo.executeSQL(Update("folder").Set("id", target).Where(Eq{"id": f.ID})); err != nil {
+					return merged, err
+				}
+			} else {
+				if _, err := repo.executeSQL(Delete("folder").Where(Eq{"id": f.ID})); err != nil {
+					return merged, err
+				}
+			}
+		}
+	}
+	return merged, nil
+}
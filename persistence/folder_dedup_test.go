@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/text/unicode/norm"
+)
+
+var _ = Describe("MergeDuplicateFolders", func() {
+	var ds model.DataStore
+	var ctx context.Context
+	var helper sqlRepository
+
+	// The ID folders got before path normalization was introduced
+	legacyID := func(path string) string {
+		return fmt.Sprintf("%x", md5.Sum([]byte(path)))
+	}
+	nfcPath := norm.NFC.String("/Music/Sigur Rós")
+	nfdPath := norm.NFD.String(nfcPath)
+	childPath := nfdPath + "/Live"
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+
+		for _, f := range []struct{ id, path, parentID string }{
+			{legacyID(nfcPath), nfcPath, ""},
+			{legacyID(nfdPath), nfdPath, ""},
+			{legacyID(childPath), childPath, legacyID(nfdPath)},
+		} {
+			ins := squirrel.Insert("folder").Columns("id", "library_id", "path", "parent_id").
+				Values(f.id, 1, f.path, f.parentID)
+			_, err := helper.executeSQL(ins)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		mr := ds.MediaFile(ctx)
+		Expect(mr.Put(&model.MediaFile{ID: "fd-1", LibraryID: 1, Path: nfcPath + "/track1.mp3", FolderID: legacyID(nfcPath)})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{ID: "fd-2", LibraryID: 1, Path: nfdPath + "/track2.mp3", FolderID: legacyID(nfdPath)})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("folder").Where(squirrel.Eq{"path": []string{nfcPath, nfdPath, childPath}}),
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "fd-%"}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("merges path variants into the canonical folder", func() {
+		merged, err := ds.MergeDuplicateFolders(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(merged).To(Equal(int64(1)))
+
+		// A single row survives, under the normalized ID
+		target := model.FolderID(nfcPath)
+		folders, err := ds.Folder(ctx).GetAll(model.QueryOptions{
+			Filters: squirrel.Eq{"path": []string{nfcPath, nfdPath}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(folders).To(HaveLen(1))
+		Expect(folders[0].ID).To(Equal(target))
+
+		// Media files of both variants are intact and point at the surviving folder
+		for _, id := range []string{"fd-1", "fd-2"} {
+			mf, err := ds.MediaFile(ctx).Get(id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mf.FolderID).To(Equal(target))
+		}
+
+		// The child folder was moved to its normalized ID and re-pointed at the survivor
+		child, err := ds.Folder(ctx).Get(model.FolderID(childPath))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(child.ParentID).To(Equal(target))
+
+		// A second run has nothing left to merge
+		merged, err = ds.MergeDuplicateFolders(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(merged).To(BeZero())
+	})
+})
@@ -0,0 +1,86 @@
+package persistence
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+	"github.com/pocketbase/dbx"
+)
+
+type folderRepository struct {
+	sqlRepository
+}
+
+func NewFolderRepository(ctx context.Context, db dbx.Builder) model.FolderRepository {
+	r := &folderRepository{}
+	r.ctx = ctx
+	r.db = db
+	r.tableName = "folder"
+	return r
+}
+
+func (r *folderRepository) Get(id string) (*model.Folder, error) {
+	sq := r.newSelect().Columns("*").Where(Eq{"id": id})
+	var res model.Folder
+	err := r.queryOne(sq, &res)
+	return &res, err
+}
+
+func (r *folderRepository) GetByPath(path string) (*model.Folder, error) {
+	path = normalizePath(filepath.Clean(path))
+	sq := r.newSelect().Columns("*").Where(Eq{"path": path})
+	var res model.Folder
+	err := r.queryOne(sq, &res)
+	return &res, err
+}
+
+func (r *folderRepository) Put(f *model.Folder) error {
+	sq := Insert(r.tableName).SetMap(map[string]any{
+		"id":          f.ID,
+		"library_id":  f.LibraryID,
+		"path":        f.Path,
+		"parent_id":   f.ParentID,
+		"fingerprint": f.Fingerprint,
+	}).Suffix(`on conflict(id) do update set library_id = excluded.library_id, path = excluded.path,
+					parent_id = excluded.parent_id, fingerprint = excluded.fingerprint`)
+	_, err := r.executeSQL(sq)
+	return err
+}
+
+func (r *folderRepository) GetAll(ops ...model.QueryOptions) (model.Folders, error) {
+	sq := r.newSelect(ops...).Columns("*")
+	res := model.Folders{}
+	err := r.queryAll(sq, &res)
+	return res, err
+}
+
+// GetChildren returns the direct sub-folders of the given folder, ordered by path. Folders
+// without any audio below them are excluded, unless the IncludeEmpty option is given
+func (r *folderRepository) GetChildren(folderID string, ops ...model.QueryOptions) (model.Folders, error) {
+	sq := r.excludeEmpty(r.newSelect(ops...).Columns("*"), ops...).
+		Where(Eq{"parent_id": folderID}).OrderBy("path")
+	res := model.Folders{}
+	err := r.queryAll(sq, &res)
+	return res, err
+}
+
+func (r *folderRepository) CountAll(ops ...model.QueryOptions) (int64, error) {
+	return r.count(r.excludeEmpty(r.newSelect(), ops...), ops...)
+}
+
+// excludeEmpty adds the default predicate hiding folders with no audio anywhere below them
+// (ex: artwork-only directories, or directories whose tracks all went missing). The
+// IncludeEmpty option skips the predicate. Tracks are matched by path prefix, so folders that
+// only contain other folders are still browsable
+func (r *folderRepository) excludeEmpty(sql SelectBuilder, options ...model.QueryOptions) SelectBuilder {
+	if len(options) > 0 && options[0].IncludeEmpty {
+		return sql
+	}
+	return sql.Where(ConcatExpr(`exists (select 1 from media_file
+		where not media_file.missing
+		and substr(media_file.path, 1, length(folder.path) + 1) = folder.path || '/')`))
+}
+
+var _ model.FolderRepository = (*folderRepository)(nil)
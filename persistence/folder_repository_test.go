@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FolderRepository", func() {
+	var ctx context.Context
+	var fr model.FolderRepository
+	var mr model.MediaFileRepository
+
+	artistID := model.FolderID("/fb/artist")
+	albumID := model.FolderID("/fb/artist/album")
+	emptyID := model.FolderID("/fb/artist/empty")
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		fr = NewFolderRepository(ctx, NewDBXBuilder(db.Db()))
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+
+		Expect(fr.Put(&model.Folder{ID: artistID, LibraryID: 1, Path: "/fb/artist"})).To(Succeed())
+		Expect(fr.Put(&model.Folder{ID: albumID, LibraryID: 1, Path: "/fb/artist/album", ParentID: artistID})).To(Succeed())
+		Expect(fr.Put(&model.Folder{ID: emptyID, LibraryID: 1, Path: "/fb/artist/empty", ParentID: artistID})).To(Succeed())
+
+		Expect(mr.Put(&model.MediaFile{ID: "fb-1", LibraryID: 1, Title: "One",
+			Path: "/fb/artist/album/01.mp3", FolderID: albumID})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{ID: "fb-2", LibraryID: 1, Title: "Two",
+			Path: "/fb/artist/album/02.mp3", FolderID: albumID})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		helper := fr.(*folderRepository)
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "fb-%"}),
+			squirrel.Delete("folder").Where(squirrel.Like{"path": "/fb/%"}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	Describe("GetByPath", func() {
+		It("finds the folder, normalizing the given path", func() {
+			folder, err := fr.GetByPath("/fb/artist/album/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(folder.ID).To(Equal(albumID))
+			Expect(folder.ParentID).To(Equal(artistID))
+		})
+
+		It("returns ErrNotFound for unknown paths", func() {
+			_, err := fr.GetByPath("/fb/nowhere")
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+	})
+
+	Describe("Put", func() {
+		It("round-trips the content fingerprint recorded by the scanner", func() {
+			Expect(fr.Put(&model.Folder{ID: albumID, LibraryID: 1, Path: "/fb/artist/album",
+				ParentID: artistID, Fingerprint: "2|1234|5678"})).To(Succeed())
+
+			folder, err := fr.Get(albumID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(folder.Fingerprint).To(Equal("2|1234|5678"))
+		})
+	})
+
+	Describe("GetChildren", func() {
+		It("excludes folders without any audio below them by default", func() {
+			children, err := fr.GetChildren(artistID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(children).To(HaveLen(1))
+			Expect(children[0].ID).To(Equal(albumID))
+		})
+
+		It("includes them when the IncludeEmpty option is given", func() {
+			children, err := fr.GetChildren(artistID, model.QueryOptions{IncludeEmpty: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(children).To(HaveLen(2))
+			Expect(children[1].ID).To(Equal(emptyID))
+		})
+
+		It("treats folders whose tracks all went missing as empty", func() {
+			Expect(mr.MarkMissing(true, "fb-1", "fb-2")).To(Succeed())
+
+			children, err := fr.GetChildren(artistID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(children).To(BeEmpty())
+		})
+	})
+
+	Describe("CountAll", func() {
+		It("only counts folders with audio by default", func() {
+			scope := model.QueryOptions{Filters: squirrel.Like{"folder.path": "/fb/%"}}
+			// Both the artist and the album folder have audio below them, only "empty" hasn't
+			count, err := fr.CountAll(scope)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(int64(2)))
+
+			scope.IncludeEmpty = true
+			countAll, err := fr.CountAll(scope)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(countAll).To(Equal(int64(3)))
+		})
+	})
+
+	It("lists the same tracks FindAllByPath returns for the folder's path", func() {
+		byPath, err := mr.FindAllByPath("/fb/artist/album")
+		Expect(err).ToNot(HaveOccurred())
+		byFolder, err := mr.GetAll(model.QueryOptions{
+			Filters: squirrel.Eq{"folder_id": albumID}, Sort: "path"})
+		Expect(err).ToNot(HaveOccurred())
+
+		var pathIds, folderIds []string
+		for _, mf := range byPath {
+			pathIds = append(pathIds, mf.ID)
+		}
+		for _, mf := range byFolder {
+			folderIds = append(folderIds, mf.ID)
+		}
+		Expect(folderIds).To(Equal([]string{"fb-1", "fb-2"}))
+		Expect(folderIds).To(ConsistOf(pathIds))
+	})
+})
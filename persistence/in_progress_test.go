@@ -0,0 +1,118 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetInProgress", func() {
+	var ctx context.Context
+	var helper sqlRepository
+	const ipUser = "in-progress-user"
+
+	putTrack := func(id, albumID string, trackNumber int, duration float32) {
+		mf := model.MediaFile{LibraryID: 1, ID: id, Title: id, AlbumID: albumID, DiscNumber: 1,
+			TrackNumber: trackNumber, DurationMs: int64(duration * 1000), Path: "/in-progress/" + id + ".mp3"}
+		Expect(NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).Put(&mf)).To(Succeed())
+	}
+
+	putBookmark := func(trackID string, position int64, updatedAt time.Time) {
+		ins := squirrel.Insert(bookmarkTable).
+			Columns("user_id", "item_id", "item_type", "position", "created_at", "updated_at").
+			Values(ipUser, trackID, "media_file", position, updatedAt, updatedAt)
+		_, err := helper.executeSQL(ins)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: ipUser, IsAdmin: true})
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+		conf.Server.InProgressThreshold = 0.95
+	})
+
+	AfterEach(func() {
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "ip-%"}),
+			squirrel.Delete("album").Where(squirrel.Like{"id": "ip-%"}),
+			squirrel.Delete(bookmarkTable).Where(squirrel.Eq{"user_id": ipUser}),
+			squirrel.Delete(annotationTable).Where(squirrel.Eq{"user_id": ipUser}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	Context("tracks", func() {
+		BeforeEach(func() {
+			// All tracks are 100s long, so the 0.95 threshold sits at position 95000ms
+			for i, id := range []string{"ip-mf-1", "ip-mf-2", "ip-mf-3", "ip-mf-4"} {
+				putTrack(id, "ip-al-t", i+1, 100)
+			}
+			now := time.Now()
+			putBookmark("ip-mf-1", 50000, now.Add(-1*time.Hour))
+			putBookmark("ip-mf-2", 94999, now) // just below the threshold
+			putBookmark("ip-mf-3", 95000, now) // at the threshold: complete
+			putBookmark("ip-mf-4", 0, now)     // not started
+		})
+
+		It("only returns tracks between the start and the threshold, latest bookmark first", func() {
+			songs, err := NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).GetInProgress(10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(songs).To(HaveLen(2))
+			Expect(songs[0].ID).To(Equal("ip-mf-2"))
+			Expect(songs[1].ID).To(Equal("ip-mf-1"))
+			Expect(songs[0].BookmarkPosition).To(Equal(int64(94999)))
+		})
+
+		It("clears only the completed bookmarks", func() {
+			Expect(NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).clearCompletedBookmarks()).To(Succeed())
+			sel := squirrel.Select("item_id").From(bookmarkTable).Where(squirrel.Eq{"user_id": ipUser}).OrderBy("item_id")
+			var remaining []string
+			Expect(helper.queryAllSlice(sel, &remaining)).To(Succeed())
+			Expect(remaining).To(Equal([]string{"ip-mf-1", "ip-mf-2", "ip-mf-4"}))
+		})
+
+		It("honors the limit", func() {
+			songs, err := NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).GetInProgress(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(songs).To(HaveLen(1))
+			Expect(songs[0].ID).To(Equal("ip-mf-2"))
+		})
+	})
+
+	Context("albums", func() {
+		playTrack := func(albumID, trackID string, at time.Time) {
+			Expect(NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).IncPlayCount(trackID, at)).To(Succeed())
+			Expect(NewAlbumRepository(ctx, NewDBXBuilder(db.Db())).IncPlayCount(albumID, at)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			now := time.Now()
+			for _, albumID := range []string{"ip-al-a", "ip-al-b", "ip-al-c"} {
+				Expect(NewAlbumRepository(ctx, NewDBXBuilder(db.Db())).Put(&model.Album{LibraryID: 1, ID: albumID, Name: albumID})).To(Succeed())
+				putTrack(albumID+"-1", albumID, 1, 100)
+				putTrack(albumID+"-2", albumID, 2, 100)
+			}
+			playTrack("ip-al-a", "ip-al-a-1", now) // stopped before the last track
+			playTrack("ip-al-b", "ip-al-b-2", now) // finished the album
+			playTrack("ip-al-c", "ip-al-c-1", now.Add(-1*time.Hour))
+		})
+
+		It("only returns albums whose last played track is not the final one, latest played first", func() {
+			albums, err := NewAlbumRepository(ctx, NewDBXBuilder(db.Db())).GetInProgress(10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(albums).To(HaveLen(2))
+			Expect(albums[0].ID).To(Equal("ip-al-a"))
+			Expect(albums[1].ID).To(Equal("ip-al-c"))
+		})
+	})
+})
@@ -0,0 +1,128 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// DeleteLibrary permanently removes a library and all its folders, media files and albums,
+// together with their annotations, bookmarks, playlist entries and tag links, in a single
+// transaction. Artists that belonged only to the removed library are deleted as well;
+// artists still referenced by another library's albums or participations are kept. The
+// default music folder cannot be removed, as it is recreated from the MusicFolder config
+// option at the next startup.
+func (s *SQLStore) DeleteLibrary(ctx context.Context, id int) error {
+	if id == model.DefaultLibraryID {
+		return fmt.Errorf("library %d is the default music folder and cannot be removed", id)
+	}
+	return s.WithTx(func(tx model.DataStore) error {
+		lib, err := tx.Library(ctx).Get(id)
+		if err != nil {
+			return err
+		}
+		mr := tx.MediaFile(ctx).(*mediaFileRepository)
+
+		var mfIds []string
+		sel := Select("id").From("media_file").Where(Eq{"library_id": id})
+		if err := mr.queryAllSlice(sel, &mfIds); err != nil {
+			return err
+		}
+		var albumIds []string
+		sel = Select("id").From("album").Where(Eq{"library_id": id})
+		if err := mr.queryAllSlice(sel, &albumIds); err != nil {
+			return err
+		}
+		// Artists linked to this library, candidates for removal once its albums and
+		// participations are gone
+		var artistIds []string
+		sel = Select("artist_id").From("library_artist").Where(Eq{"library_id": id})
+		if err := mr.queryAllSlice(sel, &artistIds); err != nil {
+			return err
+		}
+
+		// Remove the playlist entries first, so the affected playlists can be renumbered
+		pr := tx.Playlist(ctx).(*playlistRepository)
+		var playlistIds []string
+		sel = Select("distinct playlist_id").From("playlist_tracks").Where(Eq{"media_file_id": mfIds})
+		if err := pr.queryAllSlice(sel, &playlistIds); err != nil {
+			return err
+		}
+		if _, err := mr.executeSQL(Delete("playlist_tracks").Where(Eq{"media_file_id": mfIds})); err != nil {
+			return err
+		}
+		for _, plsId := range playlistIds {
+			if err := pr.renumber(plsId); err != nil {
+				return err
+			}
+		}
+
+		for _, del := range []DeleteBuilder{
+			Delete(annotationTable).Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": mfIds}}),
+			Delete(bookmarkTable).Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": mfIds}}),
+			Delete("item_tags").Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": mfIds}}),
+			Delete("media_file_artists").Where(Eq{"media_file_id": mfIds}),
+			Delete("media_file_genres").Where(Eq{"media_file_id": mfIds}),
+			Delete("media_file").Where(Eq{"library_id": id}),
+			Delete(annotationTable).Where(And{Eq{"item_type": "album"}, Eq{"item_id": albumIds}}),
+			Delete("item_tags").Where(And{Eq{"item_type": "album"}, Eq{"item_id": albumIds}}),
+			Delete("album_genres").Where(Eq{"album_id": albumIds}),
+			Delete("album").Where(Eq{"library_id": id}),
+			Delete("folder").Where(Eq{"library_id": id}),
+			Delete("library_artist").Where(Eq{"library_id": id}),
+			Delete("user_library").Where(Eq{"library_id": id}),
+			Delete("annotation_tombstone").Where(Eq{"library_id": id}),
+			Delete("library").Where(Eq{"id": id}),
+		} {
+			if _, err := mr.executeSQL(del); err != nil {
+				return err
+			}
+		}
+		if mr.ftsAvailable() {
+			if _, err := mr.executeSQL(Delete("media_file_fts").Where(Eq{"id": mfIds})); err != nil {
+				return err
+			}
+		}
+		alr := tx.Album(ctx).(*albumRepository)
+		if alr.ftsAvailable() {
+			if _, err := alr.executeSQL(Delete("album_fts").Where(Eq{"id": albumIds})); err != nil {
+				return err
+			}
+		}
+		ar := tx.Artist(ctx).(*artistRepository)
+
+		// Of the artists that were linked to this library, remove the ones no remaining
+		// library references, either through an album or a participation
+		var orphanIds []string
+		sel = Select("id").From("artist").Where(And{
+			Eq{"id": artistIds},
+			ConcatExpr("id not in (select distinct(album_artist_id) from album)"),
+			ConcatExpr("id not in (select distinct(artist_id) from media_file_artists)"),
+			ConcatExpr("id not in (select distinct(artist_id) from library_artist)"),
+		})
+		if err := ar.queryAllSlice(sel, &orphanIds); err != nil {
+			return err
+		}
+		for _, del := range []DeleteBuilder{
+			Delete(annotationTable).Where(And{Eq{"item_type": "artist"}, Eq{"item_id": orphanIds}}),
+			Delete("item_tags").Where(And{Eq{"item_type": "artist"}, Eq{"item_id": orphanIds}}),
+			Delete("artist_genres").Where(Eq{"artist_id": orphanIds}),
+			Delete("artist").Where(Eq{"id": orphanIds}),
+		} {
+			if _, err := ar.executeSQL(del); err != nil {
+				return err
+			}
+		}
+		if ar.ftsAvailable() {
+			if _, err := ar.executeSQL(Delete("artist_fts").Where(Eq{"id": orphanIds})); err != nil {
+				return err
+			}
+		}
+		log.Info(ctx, "Removed library", "id", id, "name", lib.Name, "tracks", len(mfIds),
+			"albums", len(albumIds), "orphanArtists", len(orphanIds))
+		return nil
+	})
+}
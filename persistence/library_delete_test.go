@@ -0,0 +1,110 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeleteLibrary", func() {
+	var ds model.DataStore
+	var ctx, annCtx context.Context
+	var helper sqlRepository
+	var pls model.Playlist
+	const libID = 900
+	const annUser = "ld-user"
+
+	countRows := func(table string, filters squirrel.Sqlizer) int64 {
+		sel := squirrel.Select("count(*) as count").From(table).Where(filters)
+		var res struct{ Count int64 }
+		Expect(helper.queryOne(sel, &res)).To(Succeed())
+		return res.Count
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		annCtx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: annUser})
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+
+		Expect(ds.Library(ctx).Put(&model.Library{ID: libID, Name: "ld-library", Path: "/ld-library"})).To(Succeed())
+		Expect(ds.Folder(ctx).Put(&model.Folder{ID: "ld-f1", LibraryID: libID, Path: "ld"})).To(Succeed())
+
+		// ld-ar only exists in the removed library; ld-shared is also linked to the default one
+		Expect(ds.Artist(ctx).Put(&model.Artist{ID: "ld-ar", Name: "ld artist"})).To(Succeed())
+		Expect(ds.Artist(ctx).Put(&model.Artist{ID: "ld-shared", Name: "ld shared artist"})).To(Succeed())
+		Expect(ds.Library(ctx).AddArtist(libID, "ld-ar")).To(Succeed())
+		Expect(ds.Library(ctx).AddArtist(libID, "ld-shared")).To(Succeed())
+		Expect(ds.Library(ctx).AddArtist(1, "ld-shared")).To(Succeed())
+
+		Expect(ds.Album(ctx).Put(&model.Album{ID: "ld-al", LibraryID: libID, Name: "ld album", AlbumArtistID: "ld-ar"})).To(Succeed())
+		for _, id := range []string{"ld-1", "ld-2"} {
+			mf := model.MediaFile{ID: id, LibraryID: libID, AlbumID: "ld-al", Title: "Track " + id, Path: "ld/" + id + ".mp3"}
+			Expect(ds.MediaFile(ctx).Put(&mf)).To(Succeed())
+		}
+		keep := model.MediaFile{ID: "ld-keep", LibraryID: 1, Title: "Track ld-keep", Path: "ld-keep.mp3"}
+		Expect(ds.MediaFile(ctx).Put(&keep)).To(Succeed())
+
+		Expect(ds.MediaFile(annCtx).SetStar(true, "ld-1")).To(Succeed())
+		Expect(ds.Album(annCtx).SetStar(true, "ld-al")).To(Succeed())
+
+		pls = model.Playlist{Name: "ld-playlist", OwnerID: "userid"}
+		pls.AddTracks([]string{"ld-keep", "ld-1"})
+		Expect(ds.Playlist(ctx).Put(&pls)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(ds.Playlist(ctx).Delete(pls.ID)).To(Succeed())
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "ld-%"}),
+			squirrel.Delete("album").Where(squirrel.Like{"id": "ld-%"}),
+			squirrel.Delete("library_artist").Where(squirrel.Like{"artist_id": "ld-%"}),
+			squirrel.Delete("artist").Where(squirrel.Like{"id": "ld-%"}),
+			squirrel.Delete("folder").Where(squirrel.Like{"id": "ld-%"}),
+			squirrel.Delete(annotationTable).Where(squirrel.Eq{"user_id": annUser}),
+			squirrel.Delete("library").Where(squirrel.Eq{"id": libID}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("removes the library and everything belonging to it, without leaking rows", func() {
+		Expect(ds.DeleteLibrary(ctx, libID)).To(Succeed())
+
+		_, err := ds.Library(ctx).Get(libID)
+		Expect(err).To(MatchError(model.ErrNotFound))
+		Expect(countRows("media_file", squirrel.Eq{"library_id": libID})).To(BeZero())
+		Expect(countRows("album", squirrel.Eq{"library_id": libID})).To(BeZero())
+		Expect(countRows("folder", squirrel.Eq{"library_id": libID})).To(BeZero())
+		Expect(countRows("library_artist", squirrel.Eq{"library_id": libID})).To(BeZero())
+		Expect(countRows(annotationTable, squirrel.Eq{"user_id": annUser})).To(BeZero())
+
+		// The artist that only existed in the removed library is gone; the shared one survives
+		_, err = ds.Artist(ctx).Get("ld-ar")
+		Expect(err).To(MatchError(model.ErrNotFound))
+		Expect(ds.Artist(ctx).Exists("ld-shared")).To(BeTrue())
+
+		// Data from other libraries is untouched and the playlist was compacted
+		Expect(ds.MediaFile(ctx).Exists("ld-keep")).To(BeTrue())
+		saved, err := ds.Playlist(ctx).GetWithTracks(pls.ID, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.Tracks).To(HaveLen(1))
+		Expect(saved.Tracks[0].ID).To(Equal("1"))
+		Expect(saved.Tracks[0].MediaFileID).To(Equal("ld-keep"))
+	})
+
+	It("refuses to remove the default music folder", func() {
+		Expect(ds.DeleteLibrary(ctx, model.DefaultLibraryID)).To(MatchError(ContainSubstring("cannot be removed")))
+	})
+
+	It("returns ErrNotFound for an unknown library", func() {
+		Expect(ds.DeleteLibrary(ctx, 901)).To(MatchError(model.ErrNotFound))
+	})
+})
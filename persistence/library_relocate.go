@@ -0,0 +1,161 @@
+package persistence
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// RelocateLibrary rewrites the library's path prefix on its folder and media_file rows, so a
+// library moved on disk keeps its records instead of being re-imported from scratch. Media
+// file and folder ids are derived from paths, so they are recomputed the same way the
+// scanner would, and every row referencing them (annotations, bookmarks, playlist entries,
+// tag and artist links, play events) is re-pointed in the same transaction. The number of
+// rows that need rewriting is counted up front, and the rewrite is rolled back when the
+// totals don't match.
+func (s *SQLStore) RelocateLibrary(ctx context.Context, id int, newPath string) (int64, error) {
+	newPath = normalizePath(filepath.Clean(newPath))
+	var total int64
+	err := s.WithTx(func(tx model.DataStore) error {
+		lib, err := tx.Library(ctx).Get(id)
+		if err != nil {
+			return err
+		}
+		oldPath := normalizePath(filepath.Clean(lib.Path))
+		if oldPath == newPath {
+			return nil
+		}
+		// Moving a library into (or out of) its own subtree would make the rewritten paths
+		// collide with rows not yet rewritten
+		if pathsOverlap(newPath, oldPath) {
+			return fmt.Errorf("new path %q overlaps the library's current path %q", newPath, oldPath)
+		}
+		libs, err := tx.Library(ctx).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, other := range libs {
+			if other.ID != id && pathsOverlap(newPath, normalizePath(filepath.Clean(other.Path))) {
+				return fmt.Errorf("new path %q overlaps library %q (%s)", newPath, other.Name, other.Path)
+			}
+		}
+
+		mr := tx.MediaFile(ctx).(*mediaFileRepository)
+		inLibrary := And{Eq{"library_id": id}, Or{Eq{"path": oldPath}, pathStartsWith(oldPath + "/")}}
+
+		// Dry-run count first, so a rewrite that touches a different number of rows (ex: an id
+		// collision swallowed by an "on conflict" clause) is detected and rolled back
+		var expected int64
+		for _, table := range []string{"folder", "media_file"} {
+			count := Select("count(*) as count").From(table).Where(inLibrary)
+			var res struct{ Count int64 }
+			if err := mr.queryOne(count, &res); err != nil {
+				return err
+			}
+			expected += res.Count
+		}
+
+		relocate := func(path string) string { return newPath + path[len(oldPath):] }
+
+		var folders []struct{ ID, Path string }
+		sel := Select("id", "path").From("folder").Where(inLibrary)
+		if err := mr.queryAll(sel, &folders); err != nil {
+			return err
+		}
+		for _, f := range folders {
+			folderPath := relocate(f.Path)
+			folderID := model.FolderID(folderPath)
+			parentID := ""
+			if folderPath != newPath {
+				parentID = model.FolderID(filepath.Dir(folderPath))
+			}
+			c, err := mr.executeSQL(Update("folder").Set("id", folderID).Set("path", folderPath).
+				Set("parent_id", parentID).Where(Eq{"id": f.ID}))
+			if err != nil {
+				return err
+			}
+			total += c
+			if _, err := mr.executeSQL(Update("album_folders").Set("folder_id", folderID).
+				Where(Eq{"folder_id": f.ID})); err != nil {
+				return err
+			}
+		}
+
+		var files []struct{ ID, Path string }
+		sel = Select("id", "path").From("media_file").Where(inLibrary)
+		if err := mr.queryAll(sel, &files); err != nil {
+			return err
+		}
+		for _, f := range files {
+			filePath := relocate(f.Path)
+			fileID := fmt.Sprintf("%x", md5.Sum([]byte(filePath)))
+			c, err := mr.executeSQL(Update("media_file").Set("id", fileID).Set("path", filePath).
+				Set("folder_id", model.FolderID(filepath.Dir(filePath))).Where(Eq{"id": f.ID}))
+			if err != nil {
+				return err
+			}
+			total += c
+			for _, upd := range []UpdateBuilder{
+				Update(annotationTable).Set("item_id", fileID).
+					Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": f.ID}}),
+				Update(bookmarkTable).Set("item_id", fileID).
+					Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": f.ID}}),
+				Update("item_tags").Set("item_id", fileID).
+					Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": f.ID}}),
+				Update("media_file_artists").Set("media_file_id", fileID).Where(Eq{"media_file_id": f.ID}),
+				Update("media_file_genres").Set("media_file_id", fileID).Where(Eq{"media_file_id": f.ID}),
+				Update("playlist_tracks").Set("media_file_id", fileID).Where(Eq{"media_file_id": f.ID}),
+				Update("play_event").Set("media_file_id", fileID).Where(Eq{"media_file_id": f.ID}),
+				Update("scrobble_buffer").Set("media_file_id", fileID).Where(Eq{"media_file_id": f.ID}),
+			} {
+				if _, err := mr.executeSQL(upd); err != nil {
+					return err
+				}
+			}
+			if mr.ftsAvailable() {
+				if _, err := mr.executeSQL(Update("media_file_fts").Set("id", fileID).
+					Where(Eq{"id": f.ID})); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Albums are keyed by tags, not paths, so only their path-holding columns change
+		if _, err := mr.executeSQL(Update("album").
+			Set("paths", Expr("replace(paths, ?, ?)", oldPath, newPath)).
+			Set("image_files", Expr("replace(image_files, ?, ?)", oldPath, newPath)).
+			Set("embed_art_path", Expr("replace(embed_art_path, ?, ?)", oldPath, newPath)).
+			Set("cover_art_path", Expr("replace(cover_art_path, ?, ?)", oldPath, newPath)).
+			Where(Eq{"library_id": id})); err != nil {
+			return err
+		}
+
+		if _, err := mr.executeSQL(Update("library").Set("path", newPath).
+			Set("updated_at", time.Now()).Where(Eq{"id": id})); err != nil {
+			return err
+		}
+
+		if total != expected {
+			return fmt.Errorf("relocating library %d rewrote %d of %d rows", id, total, expected)
+		}
+		log.Info(ctx, "Relocated library", "id", id, "name", lib.Name, "from", oldPath,
+			"to", newPath, "rows", total)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// pathsOverlap reports whether one of the (slash-normalized) paths is the other, or contains it
+func pathsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RelocateLibrary", func() {
+	var ds model.DataStore
+	var ctx, annCtx context.Context
+	var helper sqlRepository
+	var pls model.Playlist
+	const libID = 910
+	const otherLibID = 911
+	const annUser = "rl-user"
+	const oldPath = "/rl-library"
+	const newPath = "/rl-moved"
+
+	countRows := func(table string, filters squirrel.Sqlizer) int64 {
+		sel := squirrel.Select("count(*) as count").From(table).Where(filters)
+		var res struct{ Count int64 }
+		Expect(helper.queryOne(sel, &res)).To(Succeed())
+		return res.Count
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		annCtx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: annUser})
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+
+		Expect(ds.Library(ctx).Put(&model.Library{ID: libID, Name: "rl-library", Path: oldPath})).To(Succeed())
+		Expect(ds.Library(ctx).Put(&model.Library{ID: otherLibID, Name: "rl-other", Path: "/rl-other"})).To(Succeed())
+
+		root := model.Folder{ID: model.FolderID(oldPath), LibraryID: libID, Path: oldPath}
+		Expect(ds.Folder(ctx).Put(&root)).To(Succeed())
+		album := model.Folder{ID: model.FolderID(oldPath + "/al"), LibraryID: libID, Path: oldPath + "/al",
+			ParentID: root.ID}
+		Expect(ds.Folder(ctx).Put(&album)).To(Succeed())
+
+		Expect(ds.Album(ctx).Put(&model.Album{ID: "rl-al", LibraryID: libID, Name: "rl album",
+			PID: "rl-al-pid", Paths: oldPath + "/al"})).To(Succeed())
+		for _, id := range []string{"rl-1", "rl-2"} {
+			mf := model.MediaFile{ID: id, LibraryID: libID, AlbumID: "rl-al", AlbumPID: "rl-al-pid",
+				Title: "Track " + id, Path: oldPath + "/al/" + id + ".mp3", FolderID: album.ID}
+			Expect(ds.MediaFile(ctx).Put(&mf)).To(Succeed())
+		}
+
+		Expect(ds.MediaFile(annCtx).SetStar(true, "rl-1")).To(Succeed())
+		Expect(ds.Album(annCtx).SetStar(true, "rl-al")).To(Succeed())
+
+		pls = model.Playlist{Name: "rl-playlist", OwnerID: "userid"}
+		pls.AddTracks([]string{"rl-1", "rl-2"})
+		Expect(ds.Playlist(ctx).Put(&pls)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(ds.Playlist(ctx).Delete(pls.ID)).To(Succeed())
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("media_file").Where(squirrel.Eq{"library_id": libID}),
+			squirrel.Delete("album").Where(squirrel.Eq{"library_id": libID}),
+			squirrel.Delete("folder").Where(squirrel.Eq{"library_id": libID}),
+			squirrel.Delete(annotationTable).Where(squirrel.Eq{"user_id": annUser}),
+			squirrel.Delete("library").Where(squirrel.Eq{"id": []int{libID, otherLibID}}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("rewrites the path prefix, keeping annotations, playlists and pids attached", func() {
+		count, err := ds.RelocateLibrary(ctx, libID, newPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(int64(4))) // 2 folders + 2 media files
+
+		lib, err := ds.Library(ctx).Get(libID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lib.Path).To(Equal(newPath))
+
+		// Media file ids are path-derived, so they were recomputed the way the scanner would
+		newID := fmt.Sprintf("%x", md5.Sum([]byte(newPath+"/al/rl-1.mp3")))
+		mf, err := ds.MediaFile(ctx).FindByPath(newPath + "/al/rl-1.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mf.ID).To(Equal(newID))
+		Expect(mf.Title).To(Equal("Track rl-1"))
+		Expect(mf.AlbumID).To(Equal("rl-al"))
+		Expect(mf.AlbumPID).To(Equal("rl-al-pid"))
+		Expect(countRows("media_file", squirrel.Or{squirrel.Eq{"path": oldPath},
+			pathStartsWith(oldPath + "/")})).To(BeZero())
+
+		// The star moved along with the recomputed id
+		Expect(countRows(annotationTable, squirrel.Eq{"user_id": annUser, "item_id": newID})).To(Equal(int64(1)))
+		Expect(countRows(annotationTable, squirrel.Eq{"user_id": annUser, "item_id": "rl-1"})).To(BeZero())
+
+		// Folders were rewritten into a consistent tree under the new path
+		folder, err := ds.Folder(ctx).GetByPath(newPath + "/al")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(folder.ID).To(Equal(model.FolderID(newPath + "/al")))
+		Expect(folder.ParentID).To(Equal(model.FolderID(newPath)))
+
+		// Albums are keyed by tags, so id, pid and annotation are untouched; only paths change
+		al, err := ds.Album(ctx).Get("rl-al")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(al.PID).To(Equal("rl-al-pid"))
+		Expect(al.Paths).To(Equal(newPath + "/al"))
+		Expect(countRows(annotationTable, squirrel.Eq{"user_id": annUser, "item_id": "rl-al"})).To(Equal(int64(1)))
+
+		saved, err := ds.Playlist(ctx).GetWithTracks(pls.ID, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.Tracks).To(HaveLen(2))
+		Expect(saved.Tracks[0].MediaFileID).To(Equal(newID))
+	})
+
+	It("refuses a path overlapping another library", func() {
+		_, err := ds.RelocateLibrary(ctx, libID, "/rl-other/sub")
+		Expect(err).To(MatchError(ContainSubstring("overlaps")))
+
+		// Nothing was rewritten
+		Expect(countRows("media_file", squirrel.Eq{"id": "rl-1"})).To(Equal(int64(1)))
+	})
+
+	It("refuses a path inside the library's own tree", func() {
+		_, err := ds.RelocateLibrary(ctx, libID, oldPath+"/sub")
+		Expect(err).To(MatchError(ContainSubstring("overlaps")))
+	})
+
+	It("is a no-op when the path did not change", func() {
+		count, err := ds.RelocateLibrary(ctx, libID, oldPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+
+	It("returns ErrNotFound for an unknown library", func() {
+		_, err := ds.RelocateLibrary(ctx, 912, newPath)
+		Expect(err).To(MatchError(model.ErrNotFound))
+	})
+})
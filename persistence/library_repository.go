@@ -2,11 +2,15 @@ package persistence
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	. "github.com/Masterminds/squirrel"
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner/metadata"
 	"github.com/pocketbase/dbx"
 )
 
@@ -30,10 +34,14 @@ func (r *libraryRepository) Get(id int) (*model.Library, error) {
 }
 
 func (r *libraryRepository) Put(l *model.Library) error {
+	if err := validateExtractor(l.Extractor); err != nil {
+		return err
+	}
 	cols := map[string]any{
 		"name":        l.Name,
 		"path":        l.Path,
 		"remote_path": l.RemotePath,
+		"extractor":   l.Extractor,
 		"updated_at":  time.Now(),
 	}
 	if l.ID != 0 {
@@ -41,18 +49,29 @@ func (r *libraryRepository) Put(l *model.Library) error {
 	}
 
 	sq := Insert(r.tableName).SetMap(cols).
-		Suffix(`on conflict(id) do update set name = excluded.name, path = excluded.path, 
-					remote_path = excluded.remote_path, updated_at = excluded.updated_at`)
+		Suffix(`on conflict(id) do update set name = excluded.name, path = excluded.path,
+					remote_path = excluded.remote_path, extractor = excluded.extractor, updated_at = excluded.updated_at`)
 	_, err := r.executeSQL(sq)
 	return err
 }
 
-const hardCodedMusicFolderID = 1
+// validateExtractor rejects unknown extractor names before they reach the DB, so a typo
+// doesn't surface only at the next scan. Empty means the global Scanner.Extractor default
+func validateExtractor(name string) error {
+	if name == "" {
+		return nil
+	}
+	names := metadata.ExtractorNames()
+	if !slices.Contains(names, name) {
+		return fmt.Errorf("unknown extractor %q, registered extractors: %s", name, strings.Join(names, ", "))
+	}
+	return nil
+}
 
-// TODO Remove this method when we have a proper UI to add libraries
+// StoreMusicFolder syncs the default library with the MusicFolder config option, at startup
 func (r *libraryRepository) StoreMusicFolder() error {
 	sq := Update(r.tableName).Set("path", conf.Server.MusicFolder).Set("updated_at", time.Now()).
-		Where(Eq{"id": hardCodedMusicFolderID})
+		Where(Eq{"id": model.DefaultLibraryID})
 	_, err := r.executeSQL(sq)
 	return err
 }
@@ -67,6 +86,18 @@ func (r *libraryRepository) AddArtist(id int, artistID string) error {
 	return nil
 }
 
+func (r *libraryRepository) GrantAccess(id int, userID string) error {
+	sq := Insert("user_library").Columns("user_id", "library_id").Values(userID, id).
+		Suffix(`on conflict(user_id, library_id) do nothing`)
+	_, err := r.executeSQL(sq)
+	return err
+}
+
+func (r *libraryRepository) RevokeAccess(id int, userID string) error {
+	_, err := r.executeSQL(Delete("user_library").Where(And{Eq{"library_id": id}, Eq{"user_id": userID}}))
+	return err
+}
+
 func (r *libraryRepository) UpdateLastScan(id int, t time.Time) error {
 	sq := Update(r.tableName).Set("last_scan_at", t).Where(Eq{"id": id})
 	_, err := r.executeSQL(sq)
@@ -0,0 +1,56 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	_ "github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LibraryRepository", func() {
+	var repo model.LibraryRepository
+
+	BeforeEach(func() {
+		ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		repo = NewLibraryRepository(ctx, NewDBXBuilder(db.Db()))
+	})
+
+	Describe("Put", func() {
+		AfterEach(func() {
+			helper := repo.(*libraryRepository)
+			_, err := helper.executeSQL(squirrel.Delete("library").Where(squirrel.Eq{"id": 77}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("saves a library with a registered extractor", func() {
+			lib := &model.Library{ID: 77, Name: "DSF stuff", Path: "/music/dsf", Extractor: "taglib"}
+			Expect(repo.Put(lib)).To(Succeed())
+
+			saved, err := repo.Get(77)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(saved.Extractor).To(Equal("taglib"))
+		})
+
+		It("accepts an empty extractor, meaning the global default", func() {
+			lib := &model.Library{ID: 77, Name: "Plain", Path: "/music/plain"}
+			Expect(repo.Put(lib)).To(Succeed())
+
+			saved, err := repo.Get(77)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(saved.Extractor).To(BeEmpty())
+		})
+
+		It("rejects an unknown extractor, listing the registered ones", func() {
+			lib := &model.Library{ID: 77, Name: "Typo", Path: "/music/typo", Extractor: "tag-lib"}
+			err := repo.Put(lib)
+			Expect(err).To(MatchError(ContainSubstring(`unknown extractor "tag-lib"`)))
+			Expect(err).To(MatchError(ContainSubstring("taglib")))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/deluan/rest"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MediaFile birth time", func() {
+	var mr model.MediaFileRepository
+
+	date := func(s string) time.Time {
+		t, err := time.Parse(time.DateOnly, s)
+		Expect(err).ToNot(HaveOccurred())
+		return t
+	}
+
+	put := func(id string, birthTime, createdAt time.Time) {
+		mf := &model.MediaFile{ID: id, LibraryID: 1, Title: id, Path: "bt/" + id + ".mp3",
+			BirthTime: birthTime, CreatedAt: createdAt, UpdatedAt: createdAt}
+		Expect(mr.Put(mf)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+
+		put("bt-1", date("2020-06-01"), date("2010-01-01"))
+		// Zero birth time simulates a filesystem without birth time support
+		put("bt-2", time.Time{}, date("2023-03-01"))
+		put("bt-3", date("2024-02-01"), date("2001-01-01"))
+	})
+
+	AfterEach(func() {
+		helper := mr.(*mediaFileRepository)
+		_, err := helper.executeSQL(squirrel.Delete("media_file").Where(squirrel.Like{"id": "bt-%"}))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("falls back to created_at when sorting rows with a zero birth time", func() {
+		res, err := mr.GetAll(model.QueryOptions{Sort: "birthTime",
+			Filters: squirrel.Like{"media_file.id": "bt-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		var ids []string
+		for _, mf := range res {
+			ids = append(ids, mf.ID)
+		}
+		// bt-2 has no birth time; its 2023 created_at slots it between bt-1 (2020) and bt-3 (2024)
+		Expect(ids).To(Equal([]string{"bt-1", "bt-2", "bt-3"}))
+	})
+
+	It("applies the same fallback in the birth_time_gt filter", func() {
+		res, err := mr.(*mediaFileRepository).ReadAll(rest.QueryOptions{Sort: "birthTime",
+			Filters: map[string]interface{}{"birth_time_gt": "2022-01-01", "title": "bt-"}})
+		Expect(err).ToNot(HaveOccurred())
+		var ids []string
+		for _, mf := range res.(model.MediaFiles) {
+			ids = append(ids, mf.ID)
+		}
+		Expect(ids).To(Equal([]string{"bt-2", "bt-3"}))
+	})
+
+	It("finds recently modified files with the updated_at_gt filter", func() {
+		res, err := mr.(*mediaFileRepository).ReadAll(rest.QueryOptions{Sort: "updatedAt",
+			Filters: map[string]interface{}{"updated_at_gt": "2005-01-01", "title": "bt-"}})
+		Expect(err).ToNot(HaveOccurred())
+		var ids []string
+		for _, mf := range res.(model.MediaFiles) {
+			ids = append(ids, mf.ID)
+		}
+		// bt-3 was last touched in 2001, so only the two newer rows qualify
+		Expect(ids).To(Equal([]string{"bt-1", "bt-2"}))
+	})
+})
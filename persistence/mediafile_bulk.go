@@ -0,0 +1,230 @@
+package persistence
+
+import (
+	"sort"
+	"strings"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/utils/slice"
+	"github.com/pocketbase/dbx"
+)
+
+// sqliteMaxVars caps the number of bound variables in a single statement. SQLite's historical
+// default limit is 999, so multi-row statements are chunked to stay below it
+const sqliteMaxVars = 999
+
+// withTx runs the given block on a transaction-bound copy of the repository. If the repository
+// is already inside a transaction, the block just runs on it
+func (r *mediaFileRepository) withTx(block func(tx *mediaFileRepository) error) error {
+	if t, ok := r.db.(transactional); ok {
+		return t.Transactional(func(tx *dbx.Tx) error {
+			txRepo := *r
+			txRepo.db = tx
+			return block(&txRepo)
+		})
+	}
+	return block(r)
+}
+
+// PutAll persists all the given tracks using multi-row statements, chunked to respect SQLite's
+// variable limit, with each chunk wrapped in a transaction. It is equivalent to calling Put for
+// each track, but avoids the per-row round trips that dominate large imports. Tracks are
+// upserted by id (derived from the path), so re-importing the same files keeps ids stable and
+// preserves the original created_at
+func (r *mediaFileRepository) PutAll(mfs model.MediaFiles) error {
+	if len(mfs) == 0 {
+		return nil
+	}
+	for i := range mfs {
+		m := &mfs[i]
+		if err := m.Normalize(); err != nil {
+			return err
+		}
+		m.Path = normalizePath(m.Path)
+		fullText := append([]string{m.Title, m.Album, m.Artist, m.AlbumArtist,
+			m.SortTitle, m.SortAlbumName, m.SortArtistName, m.SortAlbumArtistName, m.DiscSubtitle},
+			m.Participations.AllSearchTerms()...)
+		m.FullText = getFullText(fullText...)
+	}
+
+	args, err := toSQLArgs(&mfs[0])
+	if err != nil {
+		return err
+	}
+	// content_hash is computed from the mapped args, it is not part of the model
+	cols := make([]string, 0, len(args)+1)
+	cols = append(cols, "content_hash")
+	for col := range args {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	chunkSize := sqliteMaxVars / len(cols)
+	return slice.RangeByChunks(mfs, chunkSize, func(chunk []model.MediaFile) error {
+		return r.withTx(func(tx *mediaFileRepository) error {
+			return tx.putAllChunk(chunk, cols)
+		})
+	})
+}
+
+func (r *mediaFileRepository) putAllChunk(chunk model.MediaFiles, cols []string) error {
+	rows := make([]map[string]interface{}, len(chunk))
+	for i := range chunk {
+		args, err := toSQLArgs(&chunk[i])
+		if err != nil {
+			return err
+		}
+		args["content_hash"] = contentHash(args)
+		rows[i] = args
+	}
+	chunk, rows, err := r.dropUnchanged(chunk, rows)
+	if err != nil {
+		return err
+	}
+	if len(chunk) == 0 {
+		return nil
+	}
+
+	ins := Insert(r.tableName).Columns(cols...).Suffix(upsertByIDClause(cols))
+	ids := make([]string, 0, len(chunk))
+	for i := range chunk {
+		row := make([]interface{}, len(cols))
+		for j, col := range cols {
+			row[j] = rows[i][col]
+		}
+		ins = ins.Values(row...)
+		ids = append(ids, chunk[i].ID)
+	}
+	if _, err := r.executeSQL(ins); err != nil {
+		return err
+	}
+
+	// Rebuild the link tables of the whole chunk with batched statements, mirroring
+	// updateGenres, updateTags and updateParticipations
+	for _, del := range []DeleteBuilder{
+		Delete("media_file_genres").Where(Eq{"media_file_id": ids}),
+		Delete("item_tags").Where(And{Eq{"item_id": ids}, Eq{"item_type": r.tableName}}),
+		Delete("media_file_artists").Where(Eq{"media_file_id": ids}),
+	} {
+		if _, err := r.executeSQL(del); err != nil {
+			return err
+		}
+	}
+
+	genres := Insert("media_file_genres").Columns("genre_id", "media_file_id")
+	var numGenres int
+	tags := Insert("tag").Columns("id", "tag_name", "tag_value").
+		Suffix("on conflict (id) do nothing")
+	links := Insert("item_tags").Columns("item_id", "item_type", "tag_id").
+		Suffix("on conflict (item_id, item_type, tag_id) do nothing")
+	var numTags int
+	artists := Insert("media_file_artists").Columns("media_file_id", "artist_id", "role")
+	var numArtists int
+	for i := range chunk {
+		m := &chunk[i]
+		for _, g := range m.Genres {
+			genres = genres.Values(g.ID, m.ID)
+			numGenres++
+		}
+		for _, t := range m.Tags {
+			tags = tags.Values(t.ID, t.TagName, t.TagValue)
+			links = links.Values(m.ID, r.tableName, t.ID)
+			numTags++
+		}
+		for role, participants := range m.Participations {
+			for _, a := range participants {
+				if a.ID == "" {
+					continue
+				}
+				artists = artists.Values(m.ID, a.ID, role.String())
+				numArtists++
+			}
+		}
+	}
+	if numGenres > 0 {
+		if _, err := r.executeSQL(genres); err != nil {
+			return err
+		}
+	}
+	if numTags > 0 {
+		if _, err := r.executeSQL(tags); err != nil {
+			return err
+		}
+		if _, err := r.executeSQL(links); err != nil {
+			return err
+		}
+	}
+	if numArtists > 0 {
+		if _, err := r.executeSQL(artists); err != nil {
+			return err
+		}
+	}
+
+	if r.ftsAvailable() {
+		if _, err := r.executeSQL(Delete(r.tableName + "_fts").Where(Eq{"id": ids})); err != nil {
+			return err
+		}
+		fts := Insert(r.tableName+"_fts").Columns("id", "full_text")
+		for i := range chunk {
+			fts = fts.Values(chunk[i].ID, chunk[i].FullText)
+		}
+		if _, err := r.executeSQL(fts); err != nil {
+			return err
+		}
+	}
+
+	// Bring back tombstoned user data of re-imported recordings (no-op unless soft delete
+	// annotations is enabled)
+	for i := range chunk {
+		if err := r.restoreAnnotations(&chunk[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropUnchanged filters out the tracks whose stored row already fingerprints to the incoming
+// values, so rescanning an unchanged library writes nothing — not even the link tables, which
+// are only correct for rows put never skips
+func (r *mediaFileRepository) dropUnchanged(chunk model.MediaFiles, rows []map[string]interface{}) (model.MediaFiles, []map[string]interface{}, error) {
+	ids := make([]string, 0, len(chunk))
+	for i := range chunk {
+		ids = append(ids, chunk[i].ID)
+	}
+	sel := Select("id", "content_hash").From(r.tableName).Where(Eq{"id": ids})
+	var stored []struct{ ID, ContentHash string }
+	if err := r.queryAll(sel, &stored); err != nil {
+		return nil, nil, err
+	}
+	storedHash := make(map[string]string, len(stored))
+	for _, s := range stored {
+		storedHash[s.ID] = s.ContentHash
+	}
+
+	// The chunk is a window into the caller's slice, so filter into fresh slices
+	changed := make(model.MediaFiles, 0, len(chunk))
+	changedRows := make([]map[string]interface{}, 0, len(rows))
+	for i := range chunk {
+		hash := storedHash[chunk[i].ID]
+		if hash != "" && hash == rows[i]["content_hash"] {
+			continue
+		}
+		changed = append(changed, chunk[i])
+		changedRows = append(changedRows, rows[i])
+	}
+	return changed, changedRows, nil
+}
+
+// upsertByIDClause builds the conflict clause that makes the multi-row insert behave like put:
+// existing rows are updated in place, keeping their created_at
+func upsertByIDClause(cols []string) string {
+	var sets []string
+	for _, col := range cols {
+		if col == "id" || col == "created_at" {
+			continue
+		}
+		sets = append(sets, col+" = excluded."+col)
+	}
+	return "on conflict (id) do update set " + strings.Join(sets, ", ")
+}
@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PutAll", func() {
+	var mr model.MediaFileRepository
+	var helper *mediaFileRepository
+
+	countRows := func(table string, filters squirrel.Sqlizer) int64 {
+		sel := squirrel.Select("count(*) as count").From(table).Where(filters)
+		var res struct{ Count int64 }
+		Expect(helper.queryOne(sel, &res)).To(Succeed())
+		return res.Count
+	}
+
+	newBatch := func(size int, title string) model.MediaFiles {
+		batch := make(model.MediaFiles, 0, size)
+		for i := 0; i < size; i++ {
+			batch = append(batch, model.MediaFile{
+				ID:        fmt.Sprintf("bk-%04d", i),
+				LibraryID: 1,
+				Title:     fmt.Sprintf("%s %04d", title, i),
+				Path:      P(fmt.Sprintf("/bulk/%04d.mp3", i)),
+			})
+		}
+		return batch
+	}
+
+	BeforeEach(func() {
+		ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+		mr = helper
+	})
+
+	AfterEach(func() {
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("media_file_genres").Where(squirrel.Expr("media_file_id like 'bk-%'")),
+			squirrel.Delete("media_file_artists").Where(squirrel.Expr("media_file_id like 'bk-%'")),
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "bk-%"}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("inserts a large batch and keeps ids stable on a second run", func() {
+		Expect(mr.PutAll(newBatch(1000, "Track"))).To(Succeed())
+		Expect(countRows("media_file", squirrel.Like{"id": "bk-%"})).To(Equal(int64(1000)))
+
+		first, err := mr.Get("bk-0042")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first.Title).To(Equal("Track 0042"))
+
+		// Re-importing the same files updates the rows in place
+		Expect(mr.PutAll(newBatch(1000, "Renamed"))).To(Succeed())
+		Expect(countRows("media_file", squirrel.Like{"id": "bk-%"})).To(Equal(int64(1000)))
+
+		second, err := mr.Get("bk-0042")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(second.Title).To(Equal("Renamed 0042"))
+		Expect(second.CreatedAt).To(Equal(first.CreatedAt))
+	})
+
+	It("persists the genre and participation links", func() {
+		batch := newBatch(3, "Linked")
+		batch[0].Genres = model.Genres{genreRock, genreElectronic}
+		batch[1].Participations = model.Participations{
+			model.RoleArtist: []model.Participant{{ID: "2", Name: "Kraftwerk"}},
+		}
+		Expect(mr.PutAll(batch)).To(Succeed())
+
+		Expect(countRows("media_file_genres", squirrel.Eq{"media_file_id": "bk-0000"})).To(Equal(int64(2)))
+		Expect(countRows("media_file_artists", squirrel.Eq{"media_file_id": "bk-0001"})).To(Equal(int64(1)))
+
+		// A second run must not duplicate the links
+		Expect(mr.PutAll(batch)).To(Succeed())
+		Expect(countRows("media_file_genres", squirrel.Eq{"media_file_id": "bk-0000"})).To(Equal(int64(2)))
+		Expect(countRows("media_file_artists", squirrel.Eq{"media_file_id": "bk-0001"})).To(Equal(int64(1)))
+	})
+
+	It("matches the search index kept by Put", func() {
+		batch := newBatch(1, "Searchable Unique Bulk Title")
+		Expect(mr.PutAll(batch)).To(Succeed())
+
+		found, err := mr.Search("searchable unique bulk", 0, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(HaveLen(1))
+		Expect(found[0].ID).To(Equal("bk-0000"))
+	})
+})
@@ -0,0 +1,54 @@
+package persistence
+
+import (
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+)
+
+// cursorBatchSize is how many rows GetCursor fetches per query. Each batch is released to the
+// consumer before the next one is loaded, so this is also the upper bound on rows in memory
+const cursorBatchSize = 500
+
+// GetCursor streams every media file matching the query filters, keyed by id so each batch
+// picks up where the previous one stopped, regardless of rows changing between queries. It
+// reuses selectMediaFile, so annotations, bookmarks and participations are populated like in
+// GetAll. Sort and pagination options are ignored: the iteration order is always by id.
+func (r *mediaFileRepository) GetCursor(options ...model.QueryOptions) (<-chan model.MediaFile, <-chan error) {
+	results := make(chan model.MediaFile)
+	errC := make(chan error, 1)
+	opt := model.QueryOptions{}
+	if len(options) > 0 {
+		opt.Filters = options[0].Filters
+	}
+	go func() {
+		defer close(results)
+		defer close(errC)
+		lastID := ""
+		for {
+			sel := r.selectMediaFile(nil, opt).Where(Gt{"media_file.id": lastID}).
+				OrderBy("media_file.id").Limit(cursorBatchSize)
+			batch := model.MediaFiles{}
+			if err := r.queryAll(sel, &batch); err != nil {
+				errC <- err
+				return
+			}
+			if err := loadAllGenres(r, batch); err != nil {
+				errC <- err
+				return
+			}
+			for i := range batch {
+				select {
+				case results <- batch[i]:
+				case <-r.ctx.Done():
+					errC <- r.ctx.Err()
+					return
+				}
+			}
+			if len(batch) < cursorBatchSize {
+				return
+			}
+			lastID = batch[len(batch)-1].ID
+		}
+	}()
+	return results, errC
+}
@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetCursor", func() {
+	var ds model.DataStore
+	var ctx context.Context
+	// More rows than one cursor batch, so the iteration has to span multiple queries
+	const numTracks = cursorBatchSize + 20
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		ds = New(db.Db())
+
+		tracks := make(model.MediaFiles, 0, numTracks)
+		for i := 0; i < numTracks; i++ {
+			tracks = append(tracks, model.MediaFile{
+				ID:        fmt.Sprintf("cur-%05d", i),
+				LibraryID: 1,
+				Title:     fmt.Sprintf("Cursor Track %d", i),
+				Path:      fmt.Sprintf("/cur-lib/cur-%05d.mp3", i),
+			})
+		}
+		Expect(ds.MediaFile(ctx).PutAll(tracks)).To(Succeed())
+		DeferCleanup(func() {
+			helper := NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+			_, err := helper.executeSQL(squirrel.Delete("media_file").Where(squirrel.Like{"id": "cur-%"}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	It("streams all matching rows in id order, across batch boundaries", func() {
+		rows, errC := ds.MediaFile(ctx).GetCursor(model.QueryOptions{
+			Filters: squirrel.Like{"media_file.id": "cur-%"},
+		})
+		var ids []string
+		for mf := range rows {
+			ids = append(ids, mf.ID)
+		}
+		Expect(<-errC).ToNot(HaveOccurred())
+
+		Expect(ids).To(HaveLen(numTracks))
+		Expect(ids[0]).To(Equal("cur-00000"))
+		Expect(ids[numTracks-1]).To(Equal(fmt.Sprintf("cur-%05d", numTracks-1)))
+		for i := 1; i < len(ids); i++ {
+			Expect(ids[i] > ids[i-1]).To(BeTrue(), "ids not in ascending order")
+		}
+	})
+
+	It("stops early when the context is canceled", func() {
+		cctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		rows, errC := ds.MediaFile(cctx).GetCursor(model.QueryOptions{
+			Filters: squirrel.Like{"media_file.id": "cur-%"},
+		})
+		var count int
+		for range rows {
+			count++
+			if count == 10 {
+				cancel()
+			}
+		}
+		Expect(<-errC).To(MatchError(context.Canceled))
+		Expect(count).To(BeNumerically("<", numTracks))
+	})
+})
@@ -0,0 +1,54 @@
+package persistence
+
+import (
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+)
+
+// duplicateDurationToleranceMs is how far apart (in milliseconds) the durations within a group
+// may be while still being considered the same recording. Different encodings of the same rip
+// differ by fractions of a second; anything beyond this is likely a different take
+const duplicateDurationToleranceMs = 5000
+
+// FindDuplicates reports groups of non-missing tracks that look like the same recording
+// imported more than once: same album artist, album, track number and title at more than one
+// path, with near-equal durations. It runs a single grouped query, so it is cheap enough to
+// run after every scan
+func (r *mediaFileRepository) FindDuplicates() (model.DuplicateGroups, error) {
+	groupCols := []string{"album_artist", "album", "track_number", "title"}
+	dups := Select(groupCols...).From(r.tableName).
+		Where(Eq{"missing": false}).
+		GroupBy(groupCols...).
+		Having("count(distinct path) > 1 and max(duration_ms) - min(duration_ms) <= ?",
+			duplicateDurationToleranceMs)
+	dupsSQL, dupsArgs, err := dups.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	sel := Select("media_file.*").From(r.tableName).
+		Join("("+dupsSQL+`) dup on dup.album_artist = media_file.album_artist
+			and dup.album = media_file.album
+			and dup.track_number = media_file.track_number
+			and dup.title = media_file.title`, dupsArgs...).
+		Where(Eq{"missing": false}).
+		OrderBy("media_file.album_artist", "media_file.album", "media_file.track_number",
+			"media_file.title", "media_file.path")
+	var res model.MediaFiles
+	if err := r.queryAll(sel, &res); err != nil {
+		return nil, err
+	}
+
+	var groups model.DuplicateGroups
+	for _, mf := range res {
+		n := len(groups) - 1
+		if n < 0 || groups[n].AlbumArtist != mf.AlbumArtist || groups[n].Album != mf.Album ||
+			groups[n].TrackNumber != mf.TrackNumber || groups[n].Title != mf.Title {
+			groups = append(groups, model.DuplicateGroup{AlbumArtist: mf.AlbumArtist,
+				Album: mf.Album, TrackNumber: mf.TrackNumber, Title: mf.Title})
+			n++
+		}
+		groups[n].Entries = append(groups[n].Entries, mf)
+	}
+	return groups, nil
+}
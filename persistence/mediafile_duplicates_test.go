@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MediaFile duplicates", func() {
+	var mr model.MediaFileRepository
+
+	put := func(id, folder, suffix, title string, track int, durationMs int64, bitRate int) {
+		Expect(mr.Put(&model.MediaFile{ID: id, LibraryID: 1, Path: folder + "/" + id + "." + suffix,
+			Suffix: suffix, AlbumArtist: "The Beatles", Album: "Revolver", Title: title,
+			TrackNumber: track, DurationMs: durationMs, BitRate: bitRate})).To(Succeed())
+	}
+
+	groupFor := func(title string) *model.DuplicateGroup {
+		groups, err := mr.FindDuplicates()
+		Expect(err).ToNot(HaveOccurred())
+		for i := range groups {
+			if groups[i].Title == title {
+				return &groups[i]
+			}
+		}
+		return nil
+	}
+
+	BeforeEach(func() {
+		ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+
+		// The same rip imported twice, as MP3 and FLAC, in different folders
+		put("dd-1", "rips/mp3", "mp3", "Taxman", 1, 159000, 320)
+		put("dd-2", "rips/flac", "flac", "Taxman", 1, 159300, 1000)
+		// A track that exists only once
+		put("dd-3", "rips/mp3", "mp3", "Eleanor Rigby", 2, 127000, 320)
+		// Same tags but durations too far apart: a different take, not a duplicate
+		put("dd-4", "rips/mp3", "mp3", "Love Me Do", 3, 140000, 320)
+		put("dd-5", "rips/flac", "flac", "Love Me Do", 3, 160000, 1000)
+	})
+
+	AfterEach(func() {
+		helper := mr.(*mediaFileRepository)
+		_, err := helper.executeSQL(squirrel.Delete("media_file").Where(squirrel.Like{"id": "dd-%"}))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("groups the same recording ripped into different formats", func() {
+		group := groupFor("Taxman")
+		Expect(group).ToNot(BeNil())
+		Expect(group.AlbumArtist).To(Equal("The Beatles"))
+		Expect(group.TrackNumber).To(Equal(1))
+		Expect(group.Entries).To(HaveLen(2))
+		Expect(group.Entries[0].Suffix).To(Equal("flac"))
+		Expect(group.Entries[0].BitRate).To(Equal(1000))
+		Expect(group.Entries[1].Suffix).To(Equal("mp3"))
+		Expect(group.Entries[0].Path).ToNot(Equal(group.Entries[1].Path))
+	})
+
+	It("does not report unique tracks", func() {
+		Expect(groupFor("Eleanor Rigby")).To(BeNil())
+	})
+
+	It("does not report takes with durations too far apart", func() {
+		Expect(groupFor("Love Me Do")).To(BeNil())
+	})
+
+	It("ignores copies flagged as missing", func() {
+		Expect(mr.MarkMissing(true, "dd-2")).To(Succeed())
+		Expect(groupFor("Taxman")).To(BeNil())
+	})
+})
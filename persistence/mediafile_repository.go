@@ -3,16 +3,20 @@ package persistence
 import (
 	"context"
 	"fmt"
-	"os"
+	"math"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
 	. "github.com/Masterminds/squirrel"
 	"github.com/deluan/rest"
+	"github.com/fatih/structs"
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/utils/str"
 	"github.com/pocketbase/dbx"
 )
 
@@ -26,26 +30,72 @@ func NewMediaFileRepository(ctx context.Context, db dbx.Builder) *mediaFileRepos
 	r.ctx = ctx
 	r.db = db
 	r.tableName = "media_file"
+	r.hashContent = true
+	r.libraryFilter = libraryIDFilter("media_file")
 	r.filterMappings = map[string]filterFunc{
-		"id":      idFilter(r.tableName),
-		"title":   fullTextFilter,
-		"starred": booleanFilter,
+		"id":             idFilter(r.tableName),
+		"title":          fullTextFilter,
+		"starred":        booleanFilter,
+		"missing":        booleanFilter,
+		"genre_id":       eqFilter,
+		"tag_id":         tagIDFilter(r.tableName),
+		"folder_id":      folderFilter,
+		"suffix":         eqFilter,
+		"isrc":           isrcFilter,
+		"bpm_gt":         gtFilter,
+		"bpm_lt":         ltFilter,
+		"bit_rate_gt":    gtFilter,
+		"bit_rate_lt":    ltFilter,
+		"bit_depth_gt":   gtFilter,
+		"bit_depth_lt":   ltFilter,
+		"sample_rate_gt": gtFilter,
+		"sample_rate_lt": ltFilter,
+		"channels_gt":    gtFilter,
+		"channels_lt":    ltFilter,
+		"duration_gt":    durationGtFilter,
+		"duration_lt":    durationLtFilter,
+		"birth_time_gt":  birthTimeGtFilter,
+		"updated_at_gt":  updatedAtGtFilter,
 	}
+	r.orFilterFields = map[string]struct{}{
+		"starred":  {},
+		"title":    {},
+		"genre_id": {},
+		"suffix":   {},
+	}
+	// The annotation sorts coalesce the NULLs coming from the left join, so never-played and
+	// never-starred tracks rank lowest, landing at the end of the descending lists the UI offers
 	if conf.Server.PreferSortTags {
 		r.sortMappings = map[string]string{
-			"title":     "COALESCE(NULLIF(sort_title,''),title)",
-			"artist":    "COALESCE(NULLIF(sort_artist_name,''),order_artist_name) asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) asc, release_date asc, disc_number asc, track_number asc",
-			"album":     "COALESCE(NULLIF(sort_album_name,''),order_album_name) asc, release_date asc, disc_number asc, track_number asc, COALESCE(NULLIF(sort_artist_name,''),order_artist_name) asc, COALESCE(NULLIF(sort_title,''),title) asc",
-			"random":    r.seededRandomSort(),
-			"createdAt": "media_file.created_at",
+			"title":      "COALESCE(NULLIF(sort_title,''),title) collate unicode",
+			"artist":     "COALESCE(NULLIF(sort_artist_name,''),order_artist_name) collate unicode asc, COALESCE(NULLIF(sort_album_name,''),order_album_name) collate unicode asc, release_date asc, disc_number asc, track_number asc",
+			"album":      "COALESCE(NULLIF(sort_album_name,''),order_album_name) collate unicode asc, release_date asc, disc_number asc, track_number asc, COALESCE(NULLIF(sort_artist_name,''),order_artist_name) collate unicode asc, COALESCE(NULLIF(sort_title,''),title) collate unicode asc",
+			"random":     r.seededRandomSort(),
+			"createdAt":  "media_file.created_at",
+			"updatedAt":  "media_file.updated_at",
+			"birthTime":  birthTimeExpr,
+			"bpm":        "bpm",
+			"duration":   "duration_ms",
+			"play_count": "play_count asc, COALESCE(NULLIF(sort_title,''),title) asc",
+			"play_date":  "coalesce(annotation.play_date, '') asc, COALESCE(NULLIF(sort_title,''),title) asc",
+			"rating":     "rating asc, COALESCE(NULLIF(sort_title,''),title) asc",
+			"starred_at": "coalesce(annotation.starred_at, '') asc, COALESCE(NULLIF(sort_title,''),title) asc",
 		}
 	} else {
 		r.sortMappings = map[string]string{
-			"title":     "order_title",
-			"artist":    "order_artist_name asc, order_album_name asc, release_date asc, disc_number asc, track_number asc",
-			"album":     "order_album_name asc, release_date asc, disc_number asc, track_number asc, order_artist_name asc, title asc",
-			"random":    r.seededRandomSort(),
-			"createdAt": "media_file.created_at",
+			"title":      "order_title collate unicode",
+			"artist":     "order_artist_name collate unicode asc, order_album_name collate unicode asc, release_date asc, disc_number asc, track_number asc",
+			"album":      "order_album_name collate unicode asc, release_date asc, disc_number asc, track_number asc, order_artist_name collate unicode asc, title collate unicode asc",
+			"random":     r.seededRandomSort(),
+			"createdAt":  "media_file.created_at",
+			"updatedAt":  "media_file.updated_at",
+			"birthTime":  birthTimeExpr,
+			"bpm":        "bpm",
+			"duration":   "duration_ms",
+			"play_count": "play_count asc, order_title asc",
+			"play_date":  "coalesce(annotation.play_date, '') asc, order_title asc",
+			"rating":     "rating asc, order_title asc",
+			"starred_at": "coalesce(annotation.starred_at, '') asc, order_title asc",
 		}
 	}
 	return r
@@ -62,17 +112,59 @@ func (r *mediaFileRepository) Exists(id string) (bool, error) {
 }
 
 func (r *mediaFileRepository) Put(m *model.MediaFile) error {
-	m.FullText = getFullText(m.Title, m.Album, m.Artist, m.AlbumArtist,
-		m.SortTitle, m.SortAlbumName, m.SortArtistName, m.SortAlbumArtistName, m.DiscSubtitle)
+	if err := m.Normalize(); err != nil {
+		return err
+	}
+	m.Path = normalizePath(m.Path)
+	fullText := append([]string{m.Title, m.Album, m.Artist, m.AlbumArtist,
+		m.SortTitle, m.SortAlbumName, m.SortArtistName, m.SortAlbumArtistName, m.DiscSubtitle},
+		m.Participations.AllSearchTerms()...)
+	m.FullText = getFullText(fullText...)
 	_, err := r.put(m.ID, m)
 	if err != nil {
 		return err
 	}
-	return r.updateGenres(m.ID, m.Genres)
+	err = r.updateFullTextIndex(m.ID, m.FullText)
+	if err != nil {
+		return err
+	}
+	err = r.updateGenres(m.ID, m.Genres)
+	if err != nil {
+		return err
+	}
+	err = r.updateTags(m.ID, m.Tags)
+	if err != nil {
+		return err
+	}
+	err = r.updateParticipations(m.ID, m.Participations)
+	if err != nil {
+		return err
+	}
+	return r.restoreAnnotations(m)
+}
+
+// mediaFileCols returns all persisted media_file columns, except the given ones. It is used to
+// skip loading the lyrics blob (by far the largest column) on path-based lookups done during
+// scans and playlist imports
+func mediaFileCols(except ...string) []string {
+	var cols []string
+	for _, f := range structs.Fields(model.MediaFile{}) {
+		col, _, _ := strings.Cut(f.Tag("structs"), ",")
+		if col == "" || col == "-" || slices.Contains(except, col) {
+			continue
+		}
+		cols = append(cols, "media_file."+col)
+	}
+	return cols
 }
 
-func (r *mediaFileRepository) selectMediaFile(options ...model.QueryOptions) SelectBuilder {
-	sql := r.newSelectWithAnnotation("media_file.id", options...).Columns("media_file.*")
+// selectMediaFile builds the select for media files. When columns is empty, all columns are
+// selected; mediaFileCols can be used to restrict them
+func (r *mediaFileRepository) selectMediaFile(columns []string, options ...model.QueryOptions) SelectBuilder {
+	if len(columns) == 0 {
+		columns = []string{"media_file.*"}
+	}
+	sql := r.newSelectWithAnnotation("media_file.id", options...).Columns(columns...)
 	sql = r.withBookmark(sql, "media_file.id")
 	if len(options) > 0 && options[0].Filters != nil {
 		s, _, _ := options[0].Filters.ToSql()
@@ -89,7 +181,7 @@ func (r *mediaFileRepository) selectMediaFile(options ...model.QueryOptions) Sel
 }
 
 func (r *mediaFileRepository) Get(id string) (*model.MediaFile, error) {
-	sel := r.selectMediaFile().Where(Eq{"media_file.id": id})
+	sel := r.selectMediaFile(nil).Where(Eq{"media_file.id": id})
 	var res model.MediaFiles
 	if err := r.queryAll(sel, &res); err != nil {
 		return nil, err
@@ -103,7 +195,7 @@ func (r *mediaFileRepository) Get(id string) (*model.MediaFile, error) {
 
 func (r *mediaFileRepository) GetAll(options ...model.QueryOptions) (model.MediaFiles, error) {
 	r.resetSeededRandom(options)
-	sq := r.selectMediaFile(options...)
+	sq := r.selectMediaFile(nil, options...)
 	res := model.MediaFiles{}
 	err := r.queryAll(sq, &res, options...)
 	if err != nil {
@@ -113,22 +205,88 @@ func (r *mediaFileRepository) GetAll(options ...model.QueryOptions) (model.Media
 	return res, err
 }
 
+// GetByWorkID returns all recordings of the same MusicBrainz work, ordered by album year
+func (r *mediaFileRepository) GetByWorkID(workID string) (model.MediaFiles, error) {
+	sel := r.selectMediaFile(nil).Where(Eq{"media_file.mbz_work_id": workID}).
+		OrderBy("year", "order_album_name", "disc_number", "track_number")
+	res := model.MediaFiles{}
+	if err := r.queryAll(sel, &res); err != nil {
+		return nil, err
+	}
+	err := loadAllGenres(r, res)
+	return res, err
+}
+
+// GetByISRC returns all tracks tagged with the given ISRC. The input is normalized first, so
+// any of the accepted spellings (with or without dashes, any case) can be used
+func (r *mediaFileRepository) GetByISRC(isrc string) (model.MediaFiles, error) {
+	norm, _ := model.NormalizeISRC(isrc)
+	sel := r.selectMediaFile(nil).Where(Eq{"media_file.isrc": norm}).
+		OrderBy("order_album_name", "disc_number", "track_number")
+	res := model.MediaFiles{}
+	if err := r.queryAll(sel, &res); err != nil {
+		return nil, err
+	}
+	err := loadAllGenres(r, res)
+	return res, err
+}
+
+// GetInProgress returns tracks with a bookmark position past the start but before the
+// completion threshold, most recently updated bookmarks first
+func (r *mediaFileRepository) GetInProgress(limit int) (model.MediaFiles, error) {
+	sel := r.selectMediaFile(nil).
+		Where(Expr("bookmark.position > 0")).
+		Where(Expr("bookmark.position < media_file.duration_ms * ?", conf.Server.InProgressThreshold)).
+		OrderBy("bookmark.updated_at desc")
+	if limit > 0 {
+		sel = sel.Limit(uint64(limit))
+	}
+	res := model.MediaFiles{}
+	if err := r.queryAll(sel, &res); err != nil {
+		return nil, err
+	}
+	err := loadAllGenres(r, res)
+	return res, err
+}
+
+// FindByPath returns the track stored under exactly the given path. The match is an equality,
+// not a pattern: paths containing LIKE wildcards (%, _) only match themselves. With
+// Scanner.CaseInsensitivePaths, the comparison ignores case, for case-preserving but
+// case-insensitive filesystems. If more than one row matches, ErrMultipleFound is returned, so
+// callers can surface the inconsistency
 func (r *mediaFileRepository) FindByPath(path string) (*model.MediaFile, error) {
-	sel := r.newSelect().Columns("*").Where(Like{"path": path})
+	path = normalizePath(path)
+	sel := r.newSelect().Columns(mediaFileCols("lyrics")...)
+	if conf.Server.Scanner.CaseInsensitivePaths {
+		sel = sel.Where(Expr("path = ? collate nocase", path))
+	} else {
+		sel = sel.Where(Eq{"path": path})
+	}
 	var res model.MediaFiles
 	if err := r.queryAll(sel, &res); err != nil {
 		return nil, err
 	}
-	if len(res) == 0 {
+	switch len(res) {
+	case 0:
 		return nil, model.ErrNotFound
+	case 1:
+		return &res[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %d tracks with path %q", model.ErrMultipleFound, len(res), path)
 	}
-	return &res[0], nil
+}
+
+// normalizePath converts a path to the form stored in the DB: forward slashes, regardless of
+// the OS. This keeps the path-prefix queries working when a library is scanned on one OS and
+// served from another
+func normalizePath(path string) string {
+	return filepath.ToSlash(path)
 }
 
 func cleanPath(path string) string {
-	path = filepath.Clean(path)
-	if !strings.HasSuffix(path, string(os.PathSeparator)) {
-		path += string(os.PathSeparator)
+	path = normalizePath(filepath.Clean(path))
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
 	}
 	return path
 }
@@ -138,14 +296,16 @@ func pathStartsWith(path string) Eq {
 	return Eq{substr: path}
 }
 
-// FindAllByPath only return mediafiles that are direct children of requested path
-func (r *mediaFileRepository) FindAllByPath(path string) (model.MediaFiles, error) {
+// FindAllByPath only return mediafiles that are direct children of requested path. Filters from
+// the (optional) query options are applied on top (ex: to exclude tracks flagged missing)
+func (r *mediaFileRepository) FindAllByPath(path string, options ...model.QueryOptions) (model.MediaFiles, error) {
 	// Query by path based on https://stackoverflow.com/a/13911906/653632
 	path = cleanPath(path)
 	pathLen := utf8.RuneCountInString(path)
-	sel0 := r.newSelect().Columns("media_file.*", fmt.Sprintf("substr(path, %d) AS item", pathLen+2)).
+	sel0 := r.newSelect(options...).
+		Columns(append(mediaFileCols("lyrics"), fmt.Sprintf("substr(path, %d) AS item", pathLen+2))...).
 		Where(pathStartsWith(path))
-	sel := r.newSelect().Columns("*", "item NOT GLOB '*"+string(os.PathSeparator)+"*' AS isLast").
+	sel := r.newSelect().Columns("*", "item NOT GLOB '*/*' AS isLast").
 		Where(Eq{"isLast": 1}).FromSelect(sel0, "sel0")
 
 	res := model.MediaFiles{}
@@ -157,7 +317,7 @@ func (r *mediaFileRepository) FindAllByPath(path string) (model.MediaFiles, erro
 func (r *mediaFileRepository) FindPathsRecursively(basePath string) ([]string, error) {
 	path := cleanPath(basePath)
 	// Query based on https://stackoverflow.com/a/38330814/653632
-	sel := r.newSelect().Columns(fmt.Sprintf("distinct rtrim(path, replace(path, '%s', ''))", string(os.PathSeparator))).
+	sel := r.newSelect().Columns("distinct rtrim(path, replace(path, '/', ''))").
 		Where(pathStartsWith(path))
 	var res []string
 	err := r.queryAllSlice(sel, &res)
@@ -166,6 +326,9 @@ func (r *mediaFileRepository) FindPathsRecursively(basePath string) ([]string, e
 
 func (r *mediaFileRepository) deleteNotInPath(basePath string) error {
 	path := cleanPath(basePath)
+	if err := r.tombstoneAnnotations(NotEq(pathStartsWith(path))); err != nil {
+		return err
+	}
 	sel := Delete(r.tableName).Where(NotEq(pathStartsWith(path)))
 	c, err := r.executeSQL(sel)
 	if err == nil {
@@ -177,30 +340,96 @@ func (r *mediaFileRepository) deleteNotInPath(basePath string) error {
 }
 
 func (r *mediaFileRepository) Delete(id string) error {
+	if err := r.tombstoneAnnotations(Eq{"media_file.id": id}); err != nil {
+		return err
+	}
 	return r.delete(Eq{"id": id})
 }
 
 // DeleteByPath delete from the DB all mediafiles that are direct children of path
-func (r *mediaFileRepository) DeleteByPath(basePath string) (int64, error) {
+func (r *mediaFileRepository) MarkMissing(missing bool, ids ...string) error {
+	upd := Update(r.tableName).Set("missing", missing).Set("content_hash", "").Where(Eq{"id": ids})
+	c, err := r.executeSQL(upd)
+	if err == nil && c > 0 {
+		log.Debug(r.ctx, "Updated missing flag", "missing", missing, "totalUpdated", c)
+	}
+	return err
+}
+
+func (r *mediaFileRepository) MarkMissingByPath(basePath string) (int64, error) {
 	path := cleanPath(basePath)
 	pathLen := utf8.RuneCountInString(path)
-	del := Delete(r.tableName).
+	upd := Update(r.tableName).Set("missing", true).Set("content_hash", "").
 		Where(And{pathStartsWith(path),
-			Eq{fmt.Sprintf("substr(path, %d) glob '*%s*'", pathLen+2, string(os.PathSeparator)): 0}})
+			Eq{fmt.Sprintf("substr(path, %d) glob '*/*'", pathLen+2): 0}})
+	log.Debug(r.ctx, "Marking mediafiles missing by path", "path", path)
+	return r.executeSQL(upd)
+}
+
+func (r *mediaFileRepository) DeleteByPath(basePath string) (int64, error) {
+	path := cleanPath(basePath)
+	pathLen := utf8.RuneCountInString(path)
+	cond := And{pathStartsWith(path),
+		Eq{fmt.Sprintf("substr(path, %d) glob '*/*'", pathLen+2): 0}}
+	if err := r.tombstoneAnnotations(cond); err != nil {
+		return 0, err
+	}
+	del := Delete(r.tableName).Where(cond)
 	log.Debug(r.ctx, "Deleting mediafiles by path", "path", path)
 	return r.executeSQL(del)
 }
 
+// missingGainCond matches non-missing tracks without any ReplayGain values, either provided
+// by the file tags or computed by the gain analysis job
+var missingGainCond = And{
+	Eq{"missing": false},
+	Eq{"rg_track_gain": 0},
+	Eq{"rg_track_peak": 0},
+	Eq{"rg_track_gain_computed": 0},
+	Eq{"rg_track_peak_computed": 0},
+}
+
+func (r *mediaFileRepository) GetMissingGain(fromID string, limit int) (model.MediaFiles, error) {
+	sel := r.newSelect().Columns(mediaFileCols("lyrics")...).
+		Where(And{missingGainCond, Gt{"media_file.id": fromID}}).
+		OrderBy("media_file.id").Limit(uint64(limit))
+	res := model.MediaFiles{}
+	err := r.queryAll(sel, &res)
+	return res, err
+}
+
+func (r *mediaFileRepository) CountMissingGain() (int64, error) {
+	return r.count(r.newSelect().Where(missingGainCond))
+}
+
+func (r *mediaFileRepository) StoreComputedGain(id string, gain, peak float64) error {
+	// The computed columns are not mapped by toSQLArgs, so there is no content_hash to
+	// invalidate here: a subsequent put of the same tags can still be skipped safely
+	upd := Update(r.tableName).
+		Set("rg_track_gain_computed", gain).
+		Set("rg_track_peak_computed", peak).
+		Where(Eq{"id": id})
+	_, err := r.executeSQL(upd)
+	return err
+}
+
 func (r *mediaFileRepository) removeNonAlbumArtistIds() error {
-	upd := Update(r.tableName).Set("artist_id", "").Where(notExists("artist", ConcatExpr("id = artist_id")))
+	upd := Update(r.tableName).Set("artist_id", "").Set("content_hash", "").
+		Where(notExists("artist", ConcatExpr("id = artist_id")))
 	log.Debug(r.ctx, "Removing non-album artist_ids")
 	_, err := r.executeSQL(upd)
 	return err
 }
 
+// Search supports an optional role qualifier (ex: "composer:glass"), restricting the match to
+// the artists participating in the track with that role. Plain queries match the track's full
+// text, ranking title matches first, then artist, then the other participants.
 func (r *mediaFileRepository) Search(q string, offset int, size int) (model.MediaFiles, error) {
+	if role, query := parseRoleQualifier(q); role != model.RoleInvalid {
+		return r.searchByRole(role, query, offset, size, nil)
+	}
 	results := model.MediaFiles{}
-	err := r.doSearch(q, offset, size, &results, "title")
+	err := r.doSearch(q, offset, size, &results, searchRelevanceSort(q))
 	if err != nil {
 		return nil, err
 	}
@@ -208,16 +437,186 @@ func (r *mediaFileRepository) Search(q string, offset int, size int) (model.Medi
 	return results, err
 }
 
+func (r *mediaFileRepository) SearchWithTotal(q string, offset int, size int) (model.MediaFiles, int64, error) {
+	if role, query := parseRoleQualifier(q); role != model.RoleInvalid {
+		var total int64
+		results, err := r.searchByRole(role, query, offset, size, &total)
+		return results, total, err
+	}
+	results := model.MediaFiles{}
+	total, err := r.doSearchWithTotal(q, offset, size, &results, searchRelevanceSort(q))
+	if err != nil {
+		return nil, 0, err
+	}
+	err = loadAllGenres(r, results)
+	return results, total, err
+}
+
+// parseRoleQualifier extracts an optional "role:query" prefix from the search query. Only
+// known roles (including the ones registered in Scanner.AdditionalRoles) are treated as
+// qualifiers, so searches for titles containing a colon keep working.
+func parseRoleQualifier(q string) (model.Role, string) {
+	name, rest, found := strings.Cut(q, ":")
+	if !found {
+		return model.RoleInvalid, q
+	}
+	role := model.RoleFromString(name)
+	if role == model.RoleInvalid {
+		return model.RoleInvalid, q
+	}
+	return role, strings.TrimSpace(rest)
+}
+
+// searchRelevanceSort ranks the results by where the query matched: title first, then artist,
+// then the other participant roles (only present in the full_text blob).
+func searchRelevanceSort(q string) string {
+	var title, artist []string
+	for _, part := range strings.Split(str.SanitizeStrings(q), " ") {
+		part = `'%` + strings.ReplaceAll(escapeLike.Replace(part), `'`, `''`) + `%'`
+		title = append(title, `order_title like `+part+` escape '\'`)
+		artist = append(artist, `order_artist_name like `+part+` escape '\'`)
+	}
+	return "case when " + strings.Join(title, " and ") +
+		" then 0 when " + strings.Join(artist, " and ") + " then 1 else 2 end, title"
+}
+
+func (r *mediaFileRepository) searchByRole(role model.Role, q string, offset, size int, total *int64) (model.MediaFiles, error) {
+	q = strings.TrimSpace(q)
+	if len(q) < 2 {
+		return model.MediaFiles{}, nil
+	}
+	filter := fullTextExpr("participant.full_text", q)
+	if filter == nil {
+		return model.MediaFiles{}, nil
+	}
+	// withRole applies the role predicate, shared by the page and count queries
+	withRole := func(sel SelectBuilder) SelectBuilder {
+		return sel.
+			Join("media_file_artists mfa on mfa.media_file_id = media_file.id").
+			Join("artist participant on participant.id = mfa.artist_id").
+			Where(Eq{"mfa.role": role.String()}).
+			Where(filter)
+	}
+	if total != nil {
+		countSel := withRole(Select("count(distinct media_file.id) as count").From(r.tableName))
+		var res struct{ Count int64 }
+		if err := r.queryOne(countSel, &res); err != nil {
+			return nil, err
+		}
+		*total = res.Count
+	}
+	sel := withRole(r.selectMediaFile(nil)).
+		GroupBy("media_file.id").
+		OrderBy("title").
+		Limit(uint64(size)).Offset(uint64(offset))
+	results := model.MediaFiles{}
+	if err := r.queryAll(sel, &results); err != nil {
+		return nil, err
+	}
+	err := loadAllGenres(r, results)
+	return results, err
+}
+
 func (r *mediaFileRepository) Count(options ...rest.QueryOptions) (int64, error) {
 	return r.CountAll(r.parseRestOptions(options...))
 }
 
+// recursiveFolderPrefix flags a folder_id filter value that should match the whole folder
+// subtree. See mediaFileRepository.parseRestOptions
+const recursiveFolderPrefix = "recursive:"
+
+// folderFilter matches tracks by the folder they live in. With the recursive modifier, the
+// folder subtree is resolved through the folder parent chain, so it combines with any other
+// filter (genre, library, etc.) in a single query
+func folderFilter(_ string, value interface{}) Sqlizer {
+	id := fmt.Sprint(value)
+	if subtreeRoot, ok := strings.CutPrefix(id, recursiveFolderPrefix); ok {
+		return Expr(`media_file.folder_id in (with recursive subfolder(id) as (
+				select id from folder where id = ?
+				union all
+				select f.id from folder f join subfolder s on f.parent_id = s.id)
+				select id from subfolder)`, subtreeRoot)
+	}
+	return Eq{"media_file.folder_id": id}
+}
+
+// isrcFilter normalizes the input before comparing, so all accepted ISRC spellings
+// (with or without dashes, any case) match the value stored in the isrc column
+func isrcFilter(_ string, value interface{}) Sqlizer {
+	norm, _ := model.NormalizeISRC(fmt.Sprint(value))
+	return Eq{"media_file.isrc": norm}
+}
+
+// The duration filters keep taking float seconds, the unit the API always exposed, and compare
+// them against the integer milliseconds stored in the duration_ms column
+func durationGtFilter(_ string, value interface{}) Sqlizer {
+	return Gt{"media_file.duration_ms": secondsToMillis(value)}
+}
+
+func durationLtFilter(_ string, value interface{}) Sqlizer {
+	return Lt{"media_file.duration_ms": secondsToMillis(value)}
+}
+
+// birthTimeExpr falls back to the row's created_at when the filesystem doesn't track birth
+// times (stored as the zero time), so those files still sort and filter into a sensible
+// position instead of clustering at 0001-01-01. The literal must not look like a plain
+// number, or the column's numeric affinity coerces it and breaks the text comparison
+const birthTimeExpr = "(case when media_file.birth_time > '0002-01-01' then media_file.birth_time else media_file.created_at end)"
+
+func birthTimeGtFilter(_ string, value interface{}) Sqlizer {
+	return Gt{birthTimeExpr: value}
+}
+
+// The column needs qualifying because the joined bookmark table also has an updated_at
+func updatedAtGtFilter(_ string, value interface{}) Sqlizer {
+	return Gt{"media_file.updated_at": value}
+}
+
+func secondsToMillis(value interface{}) int64 {
+	secs, _ := strconv.ParseFloat(fmt.Sprint(value), 64)
+	return int64(math.Round(secs * 1000))
+}
+
+// parseRestOptions adds support for the `recursive` modifier: when set to true, the folder_id
+// filter matches the given folder and its whole subtree, instead of just the folder itself
+func (r *mediaFileRepository) parseRestOptions(options ...rest.QueryOptions) model.QueryOptions {
+	if len(options) > 0 {
+		if recursive, ok := options[0].Filters["recursive"]; ok {
+			delete(options[0].Filters, "recursive")
+			if strings.EqualFold(fmt.Sprint(recursive), "true") {
+				if id, ok := options[0].Filters["folder_id"]; ok {
+					options[0].Filters["folder_id"] = recursiveFolderPrefix + fmt.Sprint(id)
+				}
+			}
+		}
+	}
+	return r.sqlRestful.parseRestOptions(options...)
+}
+
 func (r *mediaFileRepository) Read(id string) (interface{}, error) {
-	return r.Get(id)
+	mf, err := r.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	r.truncateParticipations(mf)
+	return mf, nil
 }
 
 func (r *mediaFileRepository) ReadAll(options ...rest.QueryOptions) (interface{}, error) {
-	return r.GetAll(r.parseRestOptions(options...))
+	res, err := r.GetAll(r.parseRestOptions(options...))
+	if err != nil {
+		return nil, err
+	}
+	for i := range res {
+		r.truncateParticipations(&res[i])
+	}
+	return res, nil
+}
+
+// truncateParticipations caps the participant lists returned through the native API, so tracks
+// with very large participation lists don't blow up the response size
+func (r *mediaFileRepository) truncateParticipations(mf *model.MediaFile) {
+	mf.Participations, mf.ParticipationsTruncated = mf.Participations.Truncated(conf.Server.MaxContributors)
 }
 
 func (r *mediaFileRepository) EntityName() string {
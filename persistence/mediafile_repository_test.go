@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/deluan/rest"
 	"github.com/google/uuid"
+	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/db"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
@@ -19,7 +21,7 @@ var _ = Describe("MediaRepository", func() {
 
 	BeforeEach(func() {
 		ctx := log.NewContext(context.TODO())
-		ctx = request.WithUser(ctx, model.User{ID: "userid"})
+		ctx = request.WithUser(ctx, model.User{ID: "userid", IsAdmin: true})
 		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
 	})
 
@@ -32,6 +34,484 @@ var _ = Describe("MediaRepository", func() {
 		Expect(err).To(MatchError(model.ErrNotFound))
 	})
 
+	Describe("Participations serialization", func() {
+		It("round-trips participations through the DB", func() {
+			mf := model.MediaFile{LibraryID: 1, ID: "7101", Title: "Serialized", Path: P("/serialized/7101.mp3")}
+			mf.Participations = model.Participations{}
+			mf.Participations.Add(model.RoleComposer, model.Participant{ID: "c-1", Name: "A Composer"})
+			Expect(mr.Put(&mf)).To(Succeed())
+			DeferCleanup(func() { Expect(mr.Delete("7101")).To(Succeed()) })
+
+			saved, err := mr.Get("7101")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(saved.Participations).To(Equal(mf.Participations))
+		})
+
+	})
+
+	Describe("Folder filter", func() {
+		var rootFolder, compilations, jazz, singles model.Folder
+
+		BeforeEach(func() {
+			fr := NewFolderRepository(log.NewContext(context.TODO()), NewDBXBuilder(db.Db()))
+			rootFolder = model.Folder{ID: model.FolderID(P("/music")), LibraryID: 1, Path: P("/music")}
+			compilations = model.Folder{ID: model.FolderID(P("/music/compilations")), LibraryID: 1, Path: P("/music/compilations"), ParentID: rootFolder.ID}
+			jazz = model.Folder{ID: model.FolderID(P("/music/compilations/jazz")), LibraryID: 1, Path: P("/music/compilations/jazz"), ParentID: compilations.ID}
+			singles = model.Folder{ID: model.FolderID(P("/music/singles")), LibraryID: 1, Path: P("/music/singles"), ParentID: rootFolder.ID}
+			for _, f := range []model.Folder{rootFolder, compilations, jazz, singles} {
+				f := f
+				Expect(fr.Put(&f)).To(Succeed())
+			}
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "9001", FolderID: compilations.ID,
+				Path: P("/music/compilations/one.mp3"), Genres: model.Genres{genreRock}})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "9002", FolderID: jazz.ID,
+				Path: P("/music/compilations/jazz/two.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "9003", FolderID: singles.ID,
+				Path: P("/music/singles/three.mp3")})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			ids := []string{"9001", "9002", "9003"}
+			for _, id := range ids {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+			r := mr.(*mediaFileRepository)
+			_, err := r.executeSQL(squirrel.Delete("media_file_genres").Where(squirrel.Eq{"media_file_id": ids}))
+			Expect(err).ToNot(HaveOccurred())
+			folderIDs := []string{rootFolder.ID, compilations.ID, jazz.ID, singles.ID}
+			_, err = r.executeSQL(squirrel.Delete("folder").Where(squirrel.Eq{"id": folderIDs}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		readAll := func(filters map[string]interface{}) []string {
+			res, err := mr.(*mediaFileRepository).ReadAll(rest.QueryOptions{Filters: filters})
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, mf := range res.(model.MediaFiles) {
+				ids = append(ids, mf.ID)
+			}
+			return ids
+		}
+
+		It("returns the whole subtree with the recursive modifier", func() {
+			ids := readAll(map[string]interface{}{"folder_id": compilations.ID, "recursive": "true"})
+			Expect(ids).To(ConsistOf("9001", "9002"))
+		})
+
+		It("returns only the folder's own tracks without the modifier", func() {
+			ids := readAll(map[string]interface{}{"folder_id": compilations.ID})
+			Expect(ids).To(ConsistOf("9001"))
+		})
+
+		It("combines the subtree with a genre filter", func() {
+			ids := readAll(map[string]interface{}{"folder_id": compilations.ID, "recursive": "true", "genre_id": "gn-2"})
+			Expect(ids).To(ConsistOf("9001"))
+		})
+	})
+
+	Describe("GetByWorkID", func() {
+		BeforeEach(func() {
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7301", AlbumID: "al-1", Year: 1999,
+				Path: P("/works/remix.mp3"), MbzWorkID: "work-1"})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7302", AlbumID: "al-2", Year: 1970,
+				Path: P("/works/studio.mp3"), MbzWorkID: "work-1"})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7303", AlbumID: "al-3", Year: 1980,
+				Path: P("/works/unrelated.mp3")})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, id := range []string{"7301", "7302", "7303"} {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+		})
+
+		It("returns all recordings of the work, ordered by album year", func() {
+			versions, err := mr.GetByWorkID("work-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(HaveLen(2))
+			Expect(versions[0].ID).To(Equal("7302"))
+			Expect(versions[1].ID).To(Equal("7301"))
+		})
+
+		It("returns an empty list for unknown works", func() {
+			Expect(mr.GetByWorkID("work-666")).To(BeEmpty())
+		})
+	})
+
+	Describe("FindByPath", func() {
+		BeforeEach(func() {
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7601",
+				Path: P("/find/100%_sure.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7602",
+				Path: P("/find/100x_sure.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7603",
+				Path: P("/find/música/café.mp3")})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, id := range []string{"7601", "7602", "7603"} {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+		})
+
+		It("does not treat % and _ as wildcards", func() {
+			// "100%_sure" as a LIKE pattern would match "100x_sure" as well
+			found, err := mr.FindByPath(P("/find/100%_sure.mp3"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.ID).To(Equal("7601"))
+		})
+
+		It("finds paths with unicode characters", func() {
+			found, err := mr.FindByPath(P("/find/música/café.mp3"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.ID).To(Equal("7603"))
+		})
+
+		It("returns ErrNotFound when no path matches exactly", func() {
+			_, err := mr.FindByPath(P("/find/100%_sure"))
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+
+		It("is case-sensitive by default", func() {
+			_, err := mr.FindByPath(P("/find/100X_sure.mp3"))
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+
+		It("ignores case when Scanner.CaseInsensitivePaths is set", func() {
+			conf.Server.Scanner.CaseInsensitivePaths = true
+			DeferCleanup(func() { conf.Server.Scanner.CaseInsensitivePaths = false })
+
+			found, err := mr.FindByPath(P("/find/100X_sure.mp3"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.ID).To(Equal("7602"))
+		})
+
+		It("returns ErrMultipleFound when more than one row has the path", func() {
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7604",
+				Path: P("/find/100%_sure.mp3")})).To(Succeed())
+			DeferCleanup(func() { Expect(mr.Delete("7604")).To(Succeed()) })
+
+			_, err := mr.FindByPath(P("/find/100%_sure.mp3"))
+			Expect(err).To(MatchError(model.ErrMultipleFound))
+		})
+	})
+
+	Describe("Path prefix queries", func() {
+		BeforeEach(func() {
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7701",
+				Path: P("/prefix/artist/track.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7702",
+				Path: P("/prefix/artist/album/track.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7703",
+				Path: P("/prefix/artist 2/track.mp3")})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, id := range []string{"7701", "7702", "7703"} {
+				_ = mr.Delete(id)
+			}
+		})
+
+		// P() produces OS-native separators, so on Windows this asserts the conversion done at
+		// write time. Stored paths always use forward slashes, regardless of the scanning OS
+		It("stores paths with forward slashes", func() {
+			found, err := mr.Get("7701")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.Path).To(Equal("/prefix/artist/track.mp3"))
+		})
+
+		It("FindAllByPath returns only the direct children of the folder", func() {
+			found, err := mr.FindAllByPath(P("/prefix/artist"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(HaveLen(1))
+			Expect(found[0].ID).To(Equal("7701"))
+		})
+
+		It("FindPathsRecursively returns all subfolders", func() {
+			paths, err := mr.FindPathsRecursively(P("/prefix"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(paths).To(ContainElements(
+				"/prefix/artist/", "/prefix/artist/album/", "/prefix/artist 2/"))
+		})
+
+		It("MarkMissingByPath only flags the direct children", func() {
+			c, err := mr.MarkMissingByPath(P("/prefix/artist"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(int64(1)))
+			found, err := mr.Get("7701")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.Missing).To(BeTrue())
+		})
+
+		It("DeleteByPath only deletes the direct children", func() {
+			c, err := mr.DeleteByPath(P("/prefix/artist"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(int64(1)))
+			_, err = mr.Get("7701")
+			Expect(err).To(MatchError(model.ErrNotFound))
+			Expect(mr.Get("7702")).ToNot(BeNil())
+		})
+	})
+
+	Describe("GetByISRC", func() {
+		BeforeEach(func() {
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7501", Isrc: "USRC17600129",
+				Path: P("/isrc/original.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7502", Isrc: "USRC17600129",
+				Path: P("/isrc/reissue.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7503", Isrc: "GBAYE0601477",
+				Path: P("/isrc/other.mp3")})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, id := range []string{"7501", "7502", "7503"} {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+		})
+
+		It("returns all tracks carrying the ISRC", func() {
+			tracks, err := mr.GetByISRC("USRC17600129")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tracks).To(HaveLen(2))
+		})
+
+		It("accepts the dashed, lower-case spelling", func() {
+			tracks, err := mr.GetByISRC("us-rc1-76-00129")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tracks).To(HaveLen(2))
+		})
+
+		It("returns an empty list for unknown ISRCs", func() {
+			Expect(mr.GetByISRC("QZES81900001")).To(BeEmpty())
+		})
+
+		It("normalizes the isrc filter the same way", func() {
+			res, err := mr.(*mediaFileRepository).ReadAll(rest.QueryOptions{Filters: map[string]interface{}{"isrc": "gb-aye-06-01477"}})
+			Expect(err).ToNot(HaveOccurred())
+			tracks := res.(model.MediaFiles)
+			Expect(tracks).To(HaveLen(1))
+			Expect(tracks[0].ID).To(Equal("7503"))
+		})
+	})
+
+	Describe("BPM range filter", func() {
+		BeforeEach(func() {
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7201", Bpm: 90, Path: P("/bpm/slow.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7202", Bpm: 128, Path: P("/bpm/club.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7203", Bpm: 174, Path: P("/bpm/dnb.mp3")})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, id := range []string{"7201", "7202", "7203"} {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+		})
+
+		readAll := func(filters map[string]interface{}) []string {
+			res, err := mr.(*mediaFileRepository).ReadAll(rest.QueryOptions{Sort: "bpm", Filters: filters})
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, mf := range res.(model.MediaFiles) {
+				ids = append(ids, mf.ID)
+			}
+			return ids
+		}
+
+		It("returns only tracks above the lower bound", func() {
+			Expect(readAll(map[string]interface{}{"bpm_gt": 120})).To(Equal([]string{"7202", "7203"}))
+		})
+
+		It("combines both bounds into a range", func() {
+			Expect(readAll(map[string]interface{}{"bpm_gt": 90, "bpm_lt": 174})).To(Equal([]string{"7202"}))
+		})
+	})
+
+	Describe("Annotation sorts", func() {
+		ids := []string{"7801", "7802", "7803"}
+
+		BeforeEach(func() {
+			// 7801 has two genres, so the sort query must not duplicate it when the genre
+			// join is in place
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7801", Title: "Annotated A", Suffix: "dsf",
+				Genre: "Rock", Genres: model.Genres{genreRock, genreElectronic}, Path: P("/ann/a.dsf")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7802", Title: "Annotated B", Suffix: "dsf",
+				Path: P("/ann/b.dsf")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7803", Title: "Annotated C", Suffix: "dsf",
+				Path: P("/ann/c.dsf")})).To(Succeed())
+
+			Expect(mr.IncPlayCount("7801", time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC))).To(Succeed())
+			Expect(mr.IncPlayCount("7801", time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC))).To(Succeed())
+			Expect(mr.IncPlayCount("7802", time.Date(2023, 2, 1, 10, 0, 0, 0, time.UTC))).To(Succeed())
+			Expect(mr.SetRating(5, "7802")).To(Succeed())
+			Expect(mr.SetRating(3, "7801")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			r := mr.(*mediaFileRepository)
+			for _, del := range []squirrel.DeleteBuilder{
+				squirrel.Delete("media_file_genres").Where(squirrel.Eq{"media_file_id": ids}),
+				squirrel.Delete("annotation").Where(squirrel.Eq{"item_id": ids}),
+			} {
+				_, err := r.executeSQL(del)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			for _, id := range ids {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+		})
+
+		readSorted := func(sort, order string, filters squirrel.Sqlizer) []string {
+			res, err := mr.GetAll(model.QueryOptions{Sort: sort, Order: order, Filters: filters})
+			Expect(err).ToNot(HaveOccurred())
+			var found []string
+			for _, mf := range res {
+				found = append(found, mf.ID)
+			}
+			return found
+		}
+
+		It("sorts by play count, with never-played tracks at the end", func() {
+			Expect(readSorted("play_count", "desc", squirrel.Eq{"suffix": "dsf"})).
+				To(Equal([]string{"7801", "7802", "7803"}))
+		})
+
+		It("sorts by last played date", func() {
+			Expect(readSorted("play_date", "desc", squirrel.Eq{"suffix": "dsf"})).
+				To(Equal([]string{"7801", "7802", "7803"}))
+		})
+
+		It("sorts by rating, with unrated tracks at the end", func() {
+			Expect(readSorted("rating", "desc", squirrel.Eq{"suffix": "dsf"})).
+				To(Equal([]string{"7802", "7801", "7803"}))
+		})
+
+		It("does not duplicate tracks with multiple genres", func() {
+			found := readSorted("play_count", "desc", squirrel.Eq{"suffix": "dsf", "media_file.genre": "Rock"})
+			Expect(found).To(Equal([]string{"7801"}))
+		})
+	})
+
+	Describe("Random sort pagination", func() {
+		ids := []string{"7601", "7602", "7603", "7604", "7605", "7606", "7607", "7608", "7609"}
+
+		BeforeEach(func() {
+			for _, id := range ids {
+				Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: id, Suffix: "shn",
+					Path: P("/random/" + id + ".shn")})).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			for _, id := range ids {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+		})
+
+		readPage := func(seed string, offset int) []string {
+			res, err := mr.(*mediaFileRepository).ReadAll(rest.QueryOptions{
+				Sort: "random", Max: 3, Offset: offset,
+				Filters: map[string]interface{}{"suffix": "shn", "seed": seed},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			var page []string
+			for _, mf := range res.(model.MediaFiles) {
+				page = append(page, mf.ID)
+			}
+			return page
+		}
+
+		It("covers the whole list without overlaps when pages carry the same seed", func() {
+			var all []string
+			for offset := 0; offset < len(ids); offset += 3 {
+				page := readPage("pagination-seed", offset)
+				Expect(page).To(HaveLen(3))
+				all = append(all, page...)
+			}
+			Expect(all).To(ConsistOf("7601", "7602", "7603", "7604", "7605", "7606", "7607", "7608", "7609"))
+		})
+
+		It("repeats the same order for the same seed", func() {
+			Expect(readPage("stable-seed", 0)).To(Equal(readPage("stable-seed", 0)))
+		})
+	})
+
+	Describe("Format and quality filters", func() {
+		BeforeEach(func() {
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7401", Suffix: "flac", BitRate: 1411,
+				BitDepth: 24, SampleRate: 96000, Channels: 2, DurationMs: 300000,
+				Path: P("/quality/hires.flac"), Genres: model.Genres{genreRock}})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7402", Suffix: "flac", BitRate: 900,
+				BitDepth: 16, SampleRate: 44100, Channels: 2, DurationMs: 25000,
+				Path: P("/quality/snippet.flac")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7403", Suffix: "mp3", BitRate: 320,
+				SampleRate: 44100, Channels: 2, DurationMs: 180000,
+				Path: P("/quality/lossy.mp3")})).To(Succeed())
+			Expect(mr.SetStar(true, "7403")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			ids := []string{"7401", "7402", "7403"}
+			for _, id := range ids {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+			r := mr.(*mediaFileRepository)
+			for _, del := range []squirrel.DeleteBuilder{
+				squirrel.Delete("media_file_genres").Where(squirrel.Eq{"media_file_id": ids}),
+				squirrel.Delete("annotation").Where(squirrel.Eq{"item_id": ids}),
+			} {
+				_, err := r.executeSQL(del)
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+
+		readAll := func(filters map[string]interface{}) []string {
+			res, err := mr.(*mediaFileRepository).ReadAll(rest.QueryOptions{Sort: "id", Filters: filters})
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, mf := range res.(model.MediaFiles) {
+				ids = append(ids, mf.ID)
+			}
+			return ids
+		}
+
+		It("matches the suffix exactly", func() {
+			Expect(readAll(map[string]interface{}{"suffix": "flac"})).To(Equal([]string{"7401", "7402"}))
+		})
+
+		It("finds hi-res files combining suffix and bit depth", func() {
+			Expect(readAll(map[string]interface{}{"suffix": "flac", "bit_depth_gt": 16})).To(Equal([]string{"7401"}))
+		})
+
+		It("finds short tracks by duration", func() {
+			Expect(readAll(map[string]interface{}{"suffix": "flac", "duration_lt": 30})).To(Equal([]string{"7402"}))
+		})
+
+		It("bounds bitrate and sample rate into ranges", func() {
+			Expect(readAll(map[string]interface{}{"bit_rate_gt": 300, "bit_rate_lt": 1000})).To(Equal([]string{"7402", "7403"}))
+			Expect(readAll(map[string]interface{}{"sample_rate_gt": 44100})).To(Equal([]string{"7401"}))
+		})
+
+		It("composes with the genre and starred filters", func() {
+			Expect(readAll(map[string]interface{}{"suffix": "flac", "genre_id": "gn-2"})).To(Equal([]string{"7401"}))
+			Expect(readAll(map[string]interface{}{"channels_gt": 1, "starred": "true"})).To(Equal([]string{"7403"}))
+		})
+	})
+
+	It("does not load lyrics on path-based lookups", func() {
+		lyricsJSON := `[{"lang":"eng","line":[{"value":"Hello"}],"synced":false}]`
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7101", Path: P("/lyrics/song.mp3"),
+			Lyrics: lyricsJSON})).To(Succeed())
+		defer func() { Expect(mr.Delete("7101")).To(Succeed()) }()
+
+		found, err := mr.FindByPath(P("/lyrics/song.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found.Lyrics).To(BeEmpty())
+
+		full, err := mr.Get("7101")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(full.Lyrics).To(Equal(lyricsJSON))
+	})
+
 	It("counts the number of mediafiles in the DB", func() {
 		Expect(mr.CountAll()).To(Equal(int64(4)))
 	})
@@ -75,6 +555,22 @@ var _ = Describe("MediaRepository", func() {
 		Expect(found[0].ID).To(Equal("7004"))
 	})
 
+	It("filters by title with the same normalization used by search", func() {
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "7020", Title: "É Fácil", Path: P("/tests/7020.mp3")})).To(BeNil())
+
+		bySearch, err := mr.Search("fácil", 0, 10)
+		Expect(err).To(BeNil())
+		Expect(bySearch).To(HaveLen(1))
+		Expect(bySearch[0].ID).To(Equal("7020"))
+
+		for _, q := range []string{"fácil", "FaCiL", "Facil"} {
+			byFilter, err := mr.(*mediaFileRepository).ReadAll(rest.QueryOptions{Filters: map[string]interface{}{"title": q}})
+			Expect(err).To(BeNil())
+			Expect(byFilter).To(HaveLen(1))
+			Expect(byFilter.(model.MediaFiles)[0].ID).To(Equal(bySearch[0].ID))
+		}
+	})
+
 	It("delete tracks by id", func() {
 		id := uuid.NewString()
 		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: id})).To(BeNil())
@@ -144,6 +640,82 @@ var _ = Describe("MediaRepository", func() {
 		}))
 	})
 
+	It("filters by genre regardless of the name casing", func() {
+		// Subsonic clients send the genre display string back, in whatever casing they kept it
+		// (see filter.SongsByGenre)
+		Expect(mr.GetAll(model.QueryOptions{
+			Sort:    "genre.name asc, title asc",
+			Filters: squirrel.Expr("genre.name = ? collate nocase", "eLeCtRoNiC"),
+		})).To(Equal(model.MediaFiles{
+			songAntenna,
+			songRadioactivity,
+		}))
+	})
+
+	Describe("Gain analysis queries", func() {
+		var baseline int64
+
+		BeforeEach(func() {
+			var err error
+			baseline, err = mr.CountMissingGain()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "zg-1", Path: P("/gain/untagged1.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "zg-2", Path: P("/gain/tagged.mp3"),
+				RgTrackGain: -2.1, RgTrackPeak: 0.98})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "zg-3", Path: P("/gain/gone.mp3"),
+				Missing: true})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "zg-4", Path: P("/gain/untagged2.mp3")})).To(Succeed())
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "zg-5", Path: P("/gain/analyzed.mp3")})).To(Succeed())
+			Expect(mr.StoreComputedGain("zg-5", -1.5, 0.9)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, id := range []string{"zg-1", "zg-2", "zg-3", "zg-4", "zg-5"} {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+		})
+
+		It("returns only files without tag-provided or computed values, ordered by id", func() {
+			mfs, err := mr.GetMissingGain("zg-", 100)
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, mf := range mfs {
+				ids = append(ids, mf.ID)
+			}
+			Expect(ids).To(Equal([]string{"zg-1", "zg-4"}))
+		})
+
+		It("pages after the given id", func() {
+			mfs, err := mr.GetMissingGain("zg-1", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mfs).To(HaveLen(1))
+			Expect(mfs[0].ID).To(Equal("zg-4"))
+		})
+
+		It("counts the files still missing gain values", func() {
+			Expect(mr.CountMissingGain()).To(Equal(baseline + 2))
+		})
+
+		It("keeps computed values when the file is rescanned", func() {
+			mf, err := mr.Get("zg-5")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mf.RgTrackGainComputed).To(Equal(-1.5))
+			Expect(mf.RgTrackPeakComputed).To(Equal(0.9))
+
+			// A regular put, as done by the scanner, must not touch the computed columns
+			mf.Title = "Retagged"
+			mf.RgTrackGainComputed = 0
+			mf.RgTrackPeakComputed = 0
+			Expect(mr.Put(mf)).To(Succeed())
+
+			mf, err = mr.Get("zg-5")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mf.Title).To(Equal("Retagged"))
+			Expect(mf.RgTrackGainComputed).To(Equal(-1.5))
+			Expect(mf.RgTrackPeakComputed).To(Equal(0.9))
+		})
+	})
+
 	Context("Annotations", func() {
 		It("increments play count when the tracks does not have annotations", func() {
 			id := "incplay.firsttime"
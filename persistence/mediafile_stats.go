@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"sort"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+)
+
+// Stats aggregates library health numbers for the given library, grouping tracks by folder and
+// suffix in a single pass and rolling the folders up to their top-level ancestor in memory
+func (r *mediaFileRepository) Stats(libraryID int) (*model.LibraryStats, error) {
+	sel := Select("folder_id", "suffix", "count(*) as songs", "sum(missing) as missing",
+		"coalesce(sum(size), 0) as size", "coalesce(sum(duration_ms), 0) as duration_ms",
+		"coalesce(sum(bit_rate), 0) as bit_rate").
+		From(r.tableName).Where(Eq{"library_id": libraryID}).GroupBy("folder_id", "suffix")
+	var rows []struct {
+		FolderID   string
+		Suffix     string
+		Songs      int64
+		Missing    int64
+		Size       int64
+		DurationMs int64
+		BitRate    float64
+	}
+	if err := r.queryAll(sel, &rows); err != nil {
+		return nil, err
+	}
+
+	top, paths, err := r.topLevelFolders(libraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &model.LibraryStats{BySuffix: map[string]int64{}}
+	byTop := map[string]*model.FolderStats{}
+	for _, row := range rows {
+		stats.SongCount += row.Songs
+		stats.MissingCount += row.Missing
+		stats.TotalSize += row.Size
+		stats.TotalDuration += float64(row.DurationMs) / 1000
+		stats.AvgBitRate += row.BitRate
+		stats.BySuffix[row.Suffix] += row.Songs
+
+		topID, ok := top[row.FolderID]
+		if !ok {
+			continue
+		}
+		fs := byTop[topID]
+		if fs == nil {
+			fs = &model.FolderStats{FolderID: topID, Path: paths[topID]}
+			byTop[topID] = fs
+		}
+		fs.SongCount += row.Songs
+		fs.MissingCount += row.Missing
+		fs.TotalSize += row.Size
+		fs.TotalDuration += float64(row.DurationMs) / 1000
+	}
+	if stats.SongCount > 0 {
+		stats.AvgBitRate /= float64(stats.SongCount)
+	}
+	for _, fs := range byTop {
+		stats.Folders = append(stats.Folders, *fs)
+	}
+	sort.Slice(stats.Folders, func(i, j int) bool { return stats.Folders[i].Path < stats.Folders[j].Path })
+	return stats, nil
+}
+
+// topLevelFolders maps each folder of the library to its top-level ancestor (the folders right
+// under the library root; the root maps to itself), and returns the paths of those ancestors
+func (r *mediaFileRepository) topLevelFolders(libraryID int) (map[string]string, map[string]string, error) {
+	var folders []struct{ ID, ParentID, Path string }
+	sel := Select("id", "parent_id", "path").From("folder").Where(Eq{"library_id": libraryID})
+	if err := r.queryAll(sel, &folders); err != nil {
+		return nil, nil, err
+	}
+	parent := map[string]string{}
+	paths := map[string]string{}
+	for _, f := range folders {
+		parent[f.ID] = f.ParentID
+		paths[f.ID] = f.Path
+	}
+	top := map[string]string{}
+	for _, f := range folders {
+		id := f.ID
+		// Climb until the next parent is the library root (or there is none)
+		for parent[id] != "" && parent[parent[id]] != "" {
+			id = parent[id]
+		}
+		top[f.ID] = id
+	}
+	return top, paths, nil
+}
@@ -0,0 +1,97 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MediaFile Stats", func() {
+	var ctx context.Context
+	var mr model.MediaFileRepository
+
+	// A dedicated library, so the aggregates are not polluted by the shared fixtures
+	const libID = 42
+	rootID := model.FolderID("/st")
+	rockID := model.FolderID("/st/rock")
+	jazzID := model.FolderID("/st/jazz")
+	albumID := model.FolderID("/st/rock/album")
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+		helper := mr.(*mediaFileRepository)
+
+		_, err := helper.executeSQL(squirrel.Insert("library").
+			Columns("id", "name", "path").Values(libID, "stats", "/st"))
+		Expect(err).ToNot(HaveOccurred())
+
+		fr := NewFolderRepository(ctx, NewDBXBuilder(db.Db()))
+		Expect(fr.Put(&model.Folder{ID: rootID, LibraryID: libID, Path: "/st"})).To(Succeed())
+		Expect(fr.Put(&model.Folder{ID: rockID, LibraryID: libID, Path: "/st/rock", ParentID: rootID})).To(Succeed())
+		Expect(fr.Put(&model.Folder{ID: jazzID, LibraryID: libID, Path: "/st/jazz", ParentID: rootID})).To(Succeed())
+		Expect(fr.Put(&model.Folder{ID: albumID, LibraryID: libID, Path: "/st/rock/album", ParentID: rockID})).To(Succeed())
+
+		Expect(mr.Put(&model.MediaFile{ID: "st-1", LibraryID: libID, Title: "One", Suffix: "mp3",
+			Size: 1000, DurationMs: 100_000, BitRate: 320, Path: "/st/rock/album/01.mp3", FolderID: albumID})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{ID: "st-2", LibraryID: libID, Title: "Two", Suffix: "mp3",
+			Size: 2000, DurationMs: 200_000, BitRate: 128, Path: "/st/rock/album/02.mp3", FolderID: albumID})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{ID: "st-3", LibraryID: libID, Title: "Three", Suffix: "flac",
+			Size: 5000, DurationMs: 300_000, BitRate: 900, Path: "/st/jazz/03.flac", FolderID: jazzID})).To(Succeed())
+		Expect(mr.MarkMissing(true, "st-2")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		helper := mr.(*mediaFileRepository)
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "st-%"}),
+			squirrel.Delete("folder").Where(squirrel.Eq{"library_id": libID}),
+			squirrel.Delete("library").Where(squirrel.Eq{"id": libID}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("computes exact aggregates for the library", func() {
+		stats, err := mr.Stats(libID)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stats.SongCount).To(Equal(int64(3)))
+		Expect(stats.MissingCount).To(Equal(int64(1)))
+		Expect(stats.TotalSize).To(Equal(int64(8000)))
+		Expect(stats.TotalDuration).To(BeNumerically("~", 600, 0.001))
+		Expect(stats.AvgBitRate).To(BeNumerically("~", (320+128+900)/3.0, 0.001))
+		Expect(stats.BySuffix).To(Equal(map[string]int64{"mp3": 2, "flac": 1}))
+	})
+
+	It("breaks the numbers down by top-level folder", func() {
+		stats, err := mr.Stats(libID)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stats.Folders).To(HaveLen(2))
+		Expect(stats.Folders[0].Path).To(Equal("/st/jazz"))
+		Expect(stats.Folders[0].SongCount).To(Equal(int64(1)))
+		Expect(stats.Folders[0].TotalSize).To(Equal(int64(5000)))
+
+		// The album folder rolls up into its top-level ancestor
+		Expect(stats.Folders[1].FolderID).To(Equal(rockID))
+		Expect(stats.Folders[1].SongCount).To(Equal(int64(2)))
+		Expect(stats.Folders[1].MissingCount).To(Equal(int64(1)))
+		Expect(stats.Folders[1].TotalDuration).To(BeNumerically("~", 300, 0.001))
+	})
+
+	It("returns zeroed stats for an unknown library", func() {
+		stats, err := mr.Stats(99999)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.SongCount).To(BeZero())
+		Expect(stats.BySuffix).To(BeEmpty())
+		Expect(stats.Folders).To(BeEmpty())
+	})
+})
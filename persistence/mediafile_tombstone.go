@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"time"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+const annotationTombstoneTable = "annotation_tombstone"
+
+// tombstoneAnnotations copies the user data (play counts, ratings, stars and bookmark positions)
+// of the media files matched by cond into the tombstone table, keyed by user and recording
+// identity — library, title, artist, album and size, the same key canonicalTrackMapping matches
+// on — so restoreAnnotations can bring it back when the same recording is re-imported. It must
+// run before the matched rows are deleted. No-op unless Scanner.SoftDeleteAnnotations is enabled
+func (r *mediaFileRepository) tombstoneAnnotations(cond Sqlizer) error {
+	if !conf.Server.Scanner.SoftDeleteAnnotations {
+		return nil
+	}
+	sel := Select("annotation.user_id", "library_id", "title", "artist", "album", "size",
+		"play_count", "play_date", "rating", "starred", "starred_at",
+		"coalesce(bookmark.position, 0)", "datetime('now')").
+		From(r.tableName).
+		Join("annotation on annotation.item_type = 'media_file' and annotation.item_id = media_file.id").
+		LeftJoin("bookmark on bookmark.item_type = 'media_file' and bookmark.item_id = media_file.id" +
+			" and bookmark.user_id = annotation.user_id").
+		Where(cond)
+	ins := Insert(annotationTombstoneTable).Options("or replace").
+		Columns("user_id", "library_id", "title", "artist", "album", "size",
+			"play_count", "play_date", "rating", "starred", "starred_at",
+			"bookmark_position", "created_at").
+		Select(sel)
+	c, err := r.executeSQL(ins)
+	if err != nil {
+		return err
+	}
+	if c > 0 {
+		log.Debug(r.ctx, "Tombstoned annotations of deleted tracks", "count", c)
+	}
+	return r.purgeExpiredTombstones()
+}
+
+// purgeExpiredTombstones enforces the Scanner.SoftDeleteRetention limit, so the tombstone table
+// doesn't grow unbounded with recordings that are never re-imported
+func (r *mediaFileRepository) purgeExpiredTombstones() error {
+	retention := conf.Server.Scanner.SoftDeleteRetention
+	if retention <= 0 {
+		return nil
+	}
+	del := Delete(annotationTombstoneTable).Where(Lt{"created_at": time.Now().Add(-retention)})
+	c, err := r.executeSQL(del)
+	if err == nil && c > 0 {
+		log.Debug(r.ctx, "Purged expired annotation tombstones", "count", c)
+	}
+	return err
+}
+
+// restoreAnnotations restores the tombstoned user data matching the given track's recording
+// identity, then clears the used tombstone entries. Annotations and bookmarks recreated by the
+// user in the meantime are kept untouched. No-op unless Scanner.SoftDeleteAnnotations is enabled
+func (r *mediaFileRepository) restoreAnnotations(m *model.MediaFile) error {
+	if !conf.Server.Scanner.SoftDeleteAnnotations {
+		return nil
+	}
+	key := And{Eq{"library_id": m.LibraryID}, Eq{"title": m.Title}, Eq{"artist": m.Artist},
+		Eq{"album": m.Album}, Eq{"size": m.Size}}
+	sel := Select("user_id", "play_count", "play_date", "rating", "starred", "starred_at",
+		"bookmark_position").From(annotationTombstoneTable).Where(key)
+	var rows []struct {
+		UserID           string
+		PlayCount        int64
+		PlayDate         *time.Time
+		Rating           int
+		Starred          bool
+		StarredAt        *time.Time
+		BookmarkPosition int64
+	}
+	if err := r.queryAll(sel, &rows); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	for _, t := range rows {
+		ins := Insert(annotationTable).Options("or ignore").SetMap(map[string]interface{}{
+			"ann_id":     uuid.NewString(),
+			"user_id":    t.UserID,
+			"item_type":  r.tableName,
+			"item_id":    m.ID,
+			"play_count": t.PlayCount,
+			"play_date":  t.PlayDate,
+			"rating":     t.Rating,
+			"starred":    t.Starred,
+			"starred_at": t.StarredAt,
+		})
+		if _, err := r.executeSQL(ins); err != nil {
+			return err
+		}
+		if t.BookmarkPosition > 0 {
+			// The bookmark table has no uniqueness constraint, so check by hand instead of
+			// relying on `insert or ignore` like above
+			var existing struct{ Total int64 }
+			sq := Select("count(*) as total").From(bookmarkTable).Where(And{Eq{"user_id": t.UserID},
+				Eq{"item_type": r.tableName}, Eq{"item_id": m.ID}})
+			if err := r.queryOne(sq, &existing); err != nil {
+				return err
+			}
+			if existing.Total > 0 {
+				continue
+			}
+			bmk := Insert(bookmarkTable).SetMap(map[string]interface{}{
+				"user_id":    t.UserID,
+				"item_type":  r.tableName,
+				"item_id":    m.ID,
+				"comment":    "",
+				"position":   t.BookmarkPosition,
+				"changed_by": "",
+				"created_at": time.Now(),
+				"updated_at": time.Now(),
+			})
+			if _, err := r.executeSQL(bmk); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := r.executeSQL(Delete(annotationTombstoneTable).Where(key))
+	if err == nil {
+		log.Debug(r.ctx, "Restored annotations of re-imported track", "id", m.ID, "path", m.Path, "users", len(rows))
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Annotation soft delete", func() {
+	var ctx context.Context
+	var mr model.MediaFileRepository
+
+	tombstoneCount := func() int64 {
+		var res struct{ Total int64 }
+		helper := mr.(*mediaFileRepository)
+		Expect(helper.queryOne(squirrel.Select("count(*) as total").From(annotationTombstoneTable), &res)).To(Succeed())
+		return res.Total
+	}
+
+	newTrack := func(id, path string) *model.MediaFile {
+		return &model.MediaFile{ID: id, LibraryID: 1, Title: "Tomb Song", Artist: "Tomb Artist",
+			Album: "Tomb Album", Size: 4321, Path: path}
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", UserName: "johndoe", IsAdmin: true})
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+
+		conf.Server.Scanner.SoftDeleteAnnotations = true
+		DeferCleanup(func() { conf.Server.Scanner.SoftDeleteAnnotations = false })
+
+		Expect(mr.Put(newTrack("ts-1", "ts/old/track.mp3"))).To(Succeed())
+		Expect(mr.SetRating(4, "ts-1")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		helper := mr.(*mediaFileRepository)
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("annotation").Where(squirrel.Like{"item_id": "ts-%"}),
+			squirrel.Delete("bookmark").Where(squirrel.Like{"item_id": "ts-%"}),
+			squirrel.Delete(annotationTombstoneTable),
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "ts-%"}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("restores the rating when the same recording is re-imported", func() {
+		Expect(mr.Delete("ts-1")).To(Succeed())
+		Expect(tombstoneCount()).To(Equal(int64(1)))
+
+		Expect(mr.Put(newTrack("ts-2", "ts/new/track.mp3"))).To(Succeed())
+
+		restored, err := mr.Get("ts-2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Rating).To(Equal(4))
+		Expect(tombstoneCount()).To(BeZero())
+	})
+
+	It("restores bookmarks when the recording comes back through PutAll", func() {
+		Expect(mr.AddBookmark("ts-1", "", 120)).To(Succeed())
+		Expect(mr.Delete("ts-1")).To(Succeed())
+
+		Expect(mr.PutAll(model.MediaFiles{*newTrack("ts-2", "ts/new/track.mp3")})).To(Succeed())
+
+		bookmarks, err := mr.GetBookmarks()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bookmarks).To(HaveLen(1))
+		Expect(bookmarks[0].Item.ID).To(Equal("ts-2"))
+		Expect(bookmarks[0].Position).To(Equal(int64(120)))
+	})
+
+	It("deletes annotations for good when disabled", func() {
+		conf.Server.Scanner.SoftDeleteAnnotations = false
+
+		Expect(mr.Delete("ts-1")).To(Succeed())
+		Expect(tombstoneCount()).To(BeZero())
+
+		Expect(mr.Put(newTrack("ts-2", "ts/new/track.mp3"))).To(Succeed())
+		restored, err := mr.Get("ts-2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.Rating).To(BeZero())
+	})
+
+	It("purges tombstones older than the retention limit", func() {
+		conf.Server.Scanner.SoftDeleteRetention = time.Hour
+		DeferCleanup(func() { conf.Server.Scanner.SoftDeleteRetention = 0 })
+
+		Expect(mr.Delete("ts-1")).To(Succeed())
+		helper := mr.(*mediaFileRepository)
+		_, err := helper.executeSQL(squirrel.Update(annotationTombstoneTable).
+			Set("created_at", time.Now().Add(-2*time.Hour)))
+		Expect(err).ToNot(HaveOccurred())
+
+		// Any tombstoning delete triggers the purge. A different recording, so it doesn't
+		// restore ts-1's tombstone instead
+		Expect(mr.Put(&model.MediaFile{ID: "ts-other", LibraryID: 1, Title: "Other Song",
+			Artist: "Tomb Artist", Album: "Tomb Album", Size: 99, Path: "ts/other.mp3"})).To(Succeed())
+		Expect(mr.SetRating(1, "ts-other")).To(Succeed())
+		Expect(mr.Delete("ts-other")).To(Succeed())
+
+		Expect(tombstoneCount()).To(Equal(int64(1)))
+	})
+})
@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// DeleteMissingFiles permanently removes the given tracks from the DB, together with their
+// annotations, bookmarks, playlist entries and tag links, in a single transaction. Only tracks
+// flagged as missing are removed; other ids are silently skipped. Playlists that lose tracks
+// are renumbered, so positions stay contiguous.
+func (s *SQLStore) DeleteMissingFiles(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.WithTx(func(tx model.DataStore) error {
+		mr := tx.MediaFile(ctx).(*mediaFileRepository)
+		var missingIds []string
+		sel := Select("id").From("media_file").Where(And{Eq{"id": ids}, Eq{"missing": true}})
+		if err := mr.queryAllSlice(sel, &missingIds); err != nil {
+			return err
+		}
+		if len(missingIds) == 0 {
+			return nil
+		}
+
+		// Re-point entries whose track reappeared elsewhere in the library, so those playlists
+		// survive the cleanup
+		mapping, err := canonicalTrackMapping(ctx, tx, missingIds, time.Time{}, 0, 0)
+		if err != nil {
+			return err
+		}
+		if _, err := reassignPlaylistTracks(ctx, tx, mapping); err != nil {
+			return err
+		}
+
+		// Remove the remaining playlist entries first, so the affected playlists can be renumbered
+		pr := tx.Playlist(ctx).(*playlistRepository)
+		var playlistIds []string
+		sel = Select("distinct playlist_id").From("playlist_tracks").Where(Eq{"media_file_id": missingIds})
+		if err := pr.queryAllSlice(sel, &playlistIds); err != nil {
+			return err
+		}
+		if _, err := mr.executeSQL(Delete("playlist_tracks").Where(Eq{"media_file_id": missingIds})); err != nil {
+			return err
+		}
+		for _, id := range playlistIds {
+			if err := pr.renumber(id); err != nil {
+				return err
+			}
+		}
+
+		for _, del := range []DeleteBuilder{
+			Delete(annotationTable).Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": missingIds}}),
+			Delete(bookmarkTable).Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": missingIds}}),
+			Delete("item_tags").Where(And{Eq{"item_type": "media_file"}, Eq{"item_id": missingIds}}),
+			Delete("media_file_artists").Where(Eq{"media_file_id": missingIds}),
+			Delete("media_file_genres").Where(Eq{"media_file_id": missingIds}),
+			Delete("media_file").Where(Eq{"id": missingIds}),
+		} {
+			if _, err := mr.executeSQL(del); err != nil {
+				return err
+			}
+		}
+		if mr.ftsAvailable() {
+			if _, err := mr.executeSQL(Delete("media_file_fts").Where(Eq{"id": missingIds})); err != nil {
+				return err
+			}
+		}
+		log.Info(ctx, "Permanently deleted missing tracks", "requested", len(ids), "deleted", len(missingIds))
+		return nil
+	})
+}
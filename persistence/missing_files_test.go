@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeleteMissingFiles", func() {
+	var ds model.DataStore
+	var ctx, annCtx context.Context
+	var helper sqlRepository
+	var pls model.Playlist
+	const annUser = "mm-user"
+
+	countRows := func(table string, filters squirrel.Eq) int64 {
+		sel := squirrel.Select("count(*) as count").From(table).Where(filters)
+		var res struct{ Count int64 }
+		Expect(helper.queryOne(sel, &res)).To(Succeed())
+		return res.Count
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		annCtx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: annUser})
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+
+		mr := ds.MediaFile(ctx)
+		for _, id := range []string{"mm-1", "mm-2", "mm-3"} {
+			mf := model.MediaFile{ID: id, LibraryID: 1, Title: "Track " + id, Path: "mm/" + id + ".mp3"}
+			Expect(mr.Put(&mf)).To(Succeed())
+		}
+		Expect(mr.MarkMissing(true, "mm-2")).To(Succeed())
+		Expect(ds.MediaFile(annCtx).SetStar(true, "mm-2")).To(Succeed())
+
+		pls = model.Playlist{Name: "mm-playlist", OwnerID: "userid"}
+		pls.AddTracks([]string{"mm-1", "mm-2", "mm-3"})
+		Expect(ds.Playlist(ctx).Put(&pls)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(ds.Playlist(ctx).Delete(pls.ID)).To(Succeed())
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "mm-%"}),
+			squirrel.Delete(annotationTable).Where(squirrel.Eq{"user_id": annUser}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("deletes the missing tracks and compacts the affected playlists", func() {
+		// mm-3 is not flagged as missing, so it must be skipped
+		Expect(ds.DeleteMissingFiles(ctx, []string{"mm-2", "mm-3"})).To(Succeed())
+
+		_, err := ds.MediaFile(ctx).Get("mm-2")
+		Expect(err).To(MatchError(model.ErrNotFound))
+		Expect(ds.MediaFile(ctx).Exists("mm-3")).To(BeTrue())
+
+		// The annotations of the deleted track are gone
+		Expect(countRows(annotationTable, squirrel.Eq{"user_id": annUser})).To(BeZero())
+
+		// The playlist lost the deleted track and the remaining positions are contiguous
+		saved, err := ds.Playlist(ctx).GetWithTracks(pls.ID, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.Tracks).To(HaveLen(2))
+		Expect(saved.Tracks[0].ID).To(Equal("1"))
+		Expect(saved.Tracks[0].MediaFileID).To(Equal("mm-1"))
+		Expect(saved.Tracks[1].ID).To(Equal("2"))
+		Expect(saved.Tracks[1].MediaFileID).To(Equal("mm-3"))
+	})
+
+	It("does nothing for tracks that are not missing", func() {
+		Expect(ds.DeleteMissingFiles(ctx, []string{"mm-1"})).To(Succeed())
+		Expect(ds.MediaFile(ctx).Exists("mm-1")).To(BeTrue())
+
+		saved, err := ds.Playlist(ctx).GetWithTracks(pls.ID, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.Tracks).To(HaveLen(3))
+	})
+})
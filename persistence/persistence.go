@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 
+	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/db"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
@@ -34,14 +35,30 @@ func (s *SQLStore) Library(ctx context.Context) model.LibraryRepository {
 	return NewLibraryRepository(ctx, s.getDBXBuilder())
 }
 
+func (s *SQLStore) Folder(ctx context.Context) model.FolderRepository {
+	return NewFolderRepository(ctx, s.getDBXBuilder())
+}
+
 func (s *SQLStore) Genre(ctx context.Context) model.GenreRepository {
 	return NewGenreRepository(ctx, s.getDBXBuilder())
 }
 
+func (s *SQLStore) Tag(ctx context.Context) model.TagRepository {
+	return NewTagRepository(ctx, s.getDBXBuilder())
+}
+
 func (s *SQLStore) PlayQueue(ctx context.Context) model.PlayQueueRepository {
 	return NewPlayQueueRepository(ctx, s.getDBXBuilder())
 }
 
+func (s *SQLStore) PlayEvent(ctx context.Context) model.PlayEventRepository {
+	return NewPlayEventRepository(ctx, s.getDBXBuilder())
+}
+
+func (s *SQLStore) ScanLog(ctx context.Context) model.ScanLogRepository {
+	return NewScanLogRepository(ctx, s.getDBXBuilder())
+}
+
 func (s *SQLStore) Playlist(ctx context.Context) model.PlaylistRepository {
 	return NewPlaylistRepository(ctx, s.getDBXBuilder())
 }
@@ -94,6 +111,8 @@ func (s *SQLStore) Resource(ctx context.Context, m interface{}) model.ResourceRe
 		return s.MediaFile(ctx).(model.ResourceRepository)
 	case model.Genre:
 		return s.Genre(ctx).(model.ResourceRepository)
+	case model.Tag:
+		return s.Tag(ctx).(model.ResourceRepository)
 	case model.Playlist:
 		return s.Playlist(ctx).(model.ResourceRepository)
 	case model.Radio:
@@ -136,6 +155,11 @@ func (s *SQLStore) GC(ctx context.Context, rootFolder string) error {
 		log.Error(ctx, "Error removing empty albums", err)
 		return err
 	}
+	err = s.MediaFile(ctx).(*mediaFileRepository).cleanParticipations()
+	if err != nil {
+		log.Error(ctx, "Error removing orphan participations", err)
+		return err
+	}
 	err = s.Artist(ctx).(*artistRepository).purgeEmpty()
 	if err != nil {
 		log.Error(ctx, "Error removing empty artists", err)
@@ -156,11 +180,33 @@ func (s *SQLStore) GC(ctx context.Context, rootFolder string) error {
 		log.Error(ctx, "Error removing orphan artist annotations", err)
 		return err
 	}
+	err = s.MediaFile(ctx).(*mediaFileRepository).cleanFullTextIndex()
+	if err != nil {
+		log.Error(ctx, "Error removing orphan media_file full-text entries", err)
+		return err
+	}
+	err = s.Album(ctx).(*albumRepository).cleanFullTextIndex()
+	if err != nil {
+		log.Error(ctx, "Error removing orphan album full-text entries", err)
+		return err
+	}
+	err = s.Artist(ctx).(*artistRepository).cleanFullTextIndex()
+	if err != nil {
+		log.Error(ctx, "Error removing orphan artist full-text entries", err)
+		return err
+	}
 	err = s.MediaFile(ctx).(*mediaFileRepository).cleanBookmarks()
 	if err != nil {
 		log.Error(ctx, "Error removing orphan bookmarks", err)
 		return err
 	}
+	if conf.Server.ClearCompletedBookmarks {
+		err = s.MediaFile(ctx).(*mediaFileRepository).clearCompletedBookmarks()
+		if err != nil {
+			log.Error(ctx, "Error removing completed bookmarks", err)
+			return err
+		}
+	}
 	err = s.Playlist(ctx).(*playlistRepository).removeOrphans()
 	if err != nil {
 		log.Error(ctx, "Error tidying up playlists", err)
@@ -170,9 +216,52 @@ func (s *SQLStore) GC(ctx context.Context, rootFolder string) error {
 		log.Error(ctx, "Error removing unused genres", err)
 		return err
 	}
+	err = s.MediaFile(ctx).(*mediaFileRepository).cleanItemTags()
+	if err != nil {
+		log.Error(ctx, "Error removing orphan tag links", err)
+		return err
+	}
+	err = s.Album(ctx).(*albumRepository).cleanItemTags()
+	if err != nil {
+		log.Error(ctx, "Error removing orphan album tag links", err)
+		return err
+	}
+	err = s.Tag(ctx).PurgeUnused()
+	if err != nil {
+		log.Error(ctx, "Error removing unused tags", err)
+		return err
+	}
 	return err
 }
 
+// CleanOrphanAnnotations counts annotations whose item_id no longer exists in its item_type
+// table, and deletes them when fix is true. Only the types listed below are considered: rows
+// with any other item_type (including items only flagged for removal but still present in their
+// table) are never touched
+func (s *SQLStore) CleanOrphanAnnotations(ctx context.Context, fix bool) (map[string]int64, error) {
+	repos := map[string]sqlRepository{
+		"media_file": s.MediaFile(ctx).(*mediaFileRepository).sqlRepository,
+		"album":      s.Album(ctx).(*albumRepository).sqlRepository,
+		"artist":     s.Artist(ctx).(*artistRepository).sqlRepository,
+	}
+	report := map[string]int64{}
+	for itemType, repo := range repos {
+		count, err := repo.countOrphanAnnotations()
+		if err != nil {
+			log.Error(ctx, "Error counting orphan annotations", "itemType", itemType, err)
+			return nil, err
+		}
+		report[itemType] = count
+		if fix && count > 0 {
+			if err := repo.cleanAnnotations(); err != nil {
+				log.Error(ctx, "Error removing orphan annotations", "itemType", itemType, err)
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
 func (s *SQLStore) getDBXBuilder() dbx.Builder {
 	if s.db == nil {
 		return NewDBXBuilder(db.Db())
@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+	"github.com/pocketbase/dbx"
+)
+
+type playEventRepository struct {
+	sqlRepository
+}
+
+func NewPlayEventRepository(ctx context.Context, db dbx.Builder) model.PlayEventRepository {
+	r := &playEventRepository{}
+	r.ctx = ctx
+	r.db = db
+	r.tableName = "play_event"
+	return r
+}
+
+func (r *playEventRepository) Record(mediaFileID string, playDate time.Time) error {
+	ins := Insert(r.tableName).SetMap(map[string]interface{}{
+		"user_id":       userId(r.ctx),
+		"media_file_id": mediaFileID,
+		"play_date":     playDate,
+	})
+	_, err := r.executeSQL(ins)
+	return err
+}
+
+// hasEvents tells whether the user has any recorded play events. Users whose whole history
+// predates the play_event table fall back to the aggregate annotation counters
+func (r *playEventRepository) hasEvents() (bool, error) {
+	return r.exists(Select().Where(Eq{"user_id": userId(r.ctx)}))
+}
+
+func (r *playEventRepository) inWindow(from, to time.Time) And {
+	return And{
+		Eq{r.tableName + ".user_id": userId(r.ctx)},
+		GtOrEq{r.tableName + ".play_date": from},
+		Lt{r.tableName + ".play_date": to},
+	}
+}
+
+// annotated joins the given table with the user's aggregate annotations, for the fallback
+// queries
+func (r *playEventRepository) annotated(table string) SelectBuilder {
+	return Select().From(table).
+		Join("annotation on (annotation.item_id = " + table + ".id" +
+			" AND annotation.item_type = '" + table + "'" +
+			" AND annotation.user_id = '" + userId(r.ctx) + "')").
+		Where(Gt{"annotation.play_count": 0})
+}
+
+func (r *playEventRepository) GetTopArtists(from, to time.Time, limit int) (model.Artists, error) {
+	hasEvents, err := r.hasEvents()
+	if err != nil {
+		return nil, err
+	}
+	var sel SelectBuilder
+	if hasEvents {
+		sel = Select("artist.id", "artist.name", "artist.album_count", "count(*) as play_count").
+			From(r.tableName).
+			Join("media_file on media_file.id = "+r.tableName+".media_file_id").
+			Join("artist on artist.id = media_file.artist_id").
+			Where(r.inWindow(from, to)).
+			GroupBy("artist.id").
+			OrderBy("play_count desc", "artist.name")
+	} else {
+		sel = r.annotated("artist").
+			Columns("artist.id", "artist.name", "artist.album_count", "annotation.play_count").
+			OrderBy("annotation.play_count desc", "artist.name")
+	}
+	res := model.Artists{}
+	err = r.queryAll(sel.Limit(uint64(limit)), &res)
+	return res, err
+}
+
+func (r *playEventRepository) GetTopSongs(from, to time.Time, limit int) (model.MediaFiles, error) {
+	hasEvents, err := r.hasEvents()
+	if err != nil {
+		return nil, err
+	}
+	var sel SelectBuilder
+	if hasEvents {
+		sel = Select("media_file.*", "count(*) as play_count").
+			From(r.tableName).
+			Join("media_file on media_file.id = "+r.tableName+".media_file_id").
+			Where(r.inWindow(from, to)).
+			GroupBy("media_file.id").
+			OrderBy("play_count desc", "media_file.title")
+	} else {
+		sel = r.annotated("media_file").
+			Columns("media_file.*", "annotation.play_count").
+			OrderBy("annotation.play_count desc", "media_file.title")
+	}
+	res := model.MediaFiles{}
+	err = r.queryAll(sel.Limit(uint64(limit)), &res)
+	return res, err
+}
+
+func (r *playEventRepository) GetListeningTime(from, to time.Time) (float64, error) {
+	hasEvents, err := r.hasEvents()
+	if err != nil {
+		return 0, err
+	}
+	var sel SelectBuilder
+	if hasEvents {
+		sel = Select("coalesce(sum(media_file.duration_ms) / 1000.0, 0) as total").
+			From(r.tableName).
+			Join("media_file on media_file.id = " + r.tableName + ".media_file_id").
+			Where(r.inWindow(from, to))
+	} else {
+		sel = r.annotated("media_file").
+			Columns("coalesce(sum(annotation.play_count * media_file.duration_ms) / 1000.0, 0) as total")
+	}
+	var res struct{ Total float64 }
+	err = r.queryOne(sel, &res)
+	return res.Total, err
+}
+
+var _ model.PlayEventRepository = (*playEventRepository)(nil)
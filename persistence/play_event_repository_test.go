@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PlayEventRepository", func() {
+	var repo model.PlayEventRepository
+	var ctx context.Context
+	var helper sqlRepository
+	const peUser = "play-event-user"
+
+	july := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	august := time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)
+	september := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: peUser, IsAdmin: true})
+		repo = NewPlayEventRepository(ctx, NewDBXBuilder(db.Db()))
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+
+		ar := NewArtistRepository(ctx, NewDBXBuilder(db.Db()))
+		Expect(ar.Put(&model.Artist{ID: "pe-art-1", Name: "Windowed One"})).To(Succeed())
+		Expect(ar.Put(&model.Artist{ID: "pe-art-2", Name: "Windowed Two"})).To(Succeed())
+		mr := NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "pe-mf-1", Title: "One", ArtistID: "pe-art-1", DurationMs: 100000, Path: "/pe/1.mp3"})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "pe-mf-2", Title: "Two", ArtistID: "pe-art-2", DurationMs: 200000, Path: "/pe/2.mp3"})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("play_event").Where(squirrel.Eq{"user_id": peUser}),
+			squirrel.Delete(annotationTable).Where(squirrel.Eq{"user_id": peUser}),
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "pe-%"}),
+			squirrel.Delete("artist").Where(squirrel.Like{"id": "pe-%"}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	Context("with recorded events", func() {
+		BeforeEach(func() {
+			// July: track one 3x, track two 1x. August: track one 1x, track two 4x
+			for i := 0; i < 3; i++ {
+				Expect(repo.Record("pe-mf-1", july.AddDate(0, 0, i))).To(Succeed())
+			}
+			Expect(repo.Record("pe-mf-2", july.AddDate(0, 0, 10))).To(Succeed())
+			Expect(repo.Record("pe-mf-1", august.AddDate(0, 0, 5))).To(Succeed())
+			for i := 0; i < 4; i++ {
+				Expect(repo.Record("pe-mf-2", august.AddDate(0, 0, i))).To(Succeed())
+			}
+		})
+
+		It("returns the top artists of each month", func() {
+			top, err := repo.GetTopArtists(july, august, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(top).To(HaveLen(2))
+			Expect(top[0].ID).To(Equal("pe-art-1"))
+			Expect(top[0].PlayCount).To(Equal(int64(3)))
+			Expect(top[1].ID).To(Equal("pe-art-2"))
+
+			top, err = repo.GetTopArtists(august, september, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(top[0].ID).To(Equal("pe-art-2"))
+			Expect(top[0].PlayCount).To(Equal(int64(4)))
+		})
+
+		It("returns the top songs of each month, honoring the limit", func() {
+			top, err := repo.GetTopSongs(july, august, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(top).To(HaveLen(1))
+			Expect(top[0].ID).To(Equal("pe-mf-1"))
+			Expect(top[0].PlayCount).To(Equal(int64(3)))
+
+			top, err = repo.GetTopSongs(august, september, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(top[0].ID).To(Equal("pe-mf-2"))
+		})
+
+		It("adds up the listening time of the window", func() {
+			total, err := repo.GetListeningTime(july, august)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(total).To(Equal(float64(3*100 + 200)))
+		})
+	})
+
+	Context("without events", func() {
+		BeforeEach(func() {
+			mr := NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+			ar := NewArtistRepository(ctx, NewDBXBuilder(db.Db()))
+			for i := 0; i < 2; i++ {
+				Expect(mr.IncPlayCount("pe-mf-2", july)).To(Succeed())
+				Expect(ar.IncPlayCount("pe-art-2", july)).To(Succeed())
+			}
+			Expect(mr.IncPlayCount("pe-mf-1", july)).To(Succeed())
+			Expect(ar.IncPlayCount("pe-art-1", july)).To(Succeed())
+		})
+
+		It("degrades to the aggregate counters", func() {
+			top, err := repo.GetTopArtists(august, september, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(top).To(HaveLen(2))
+			Expect(top[0].ID).To(Equal("pe-art-2"))
+			Expect(top[0].PlayCount).To(Equal(int64(2)))
+
+			songs, err := repo.GetTopSongs(august, september, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(songs[0].ID).To(Equal("pe-mf-2"))
+
+			total, err := repo.GetListeningTime(august, september)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(total).To(Equal(float64(2*200 + 100)))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// missingAndMatchingSel builds the reconciliation query, matching each missing track to the
+// non-missing track that represents the same recording, by library, title, artist, album and
+// size. If ids is empty, all missing tracks are considered. A non-zero before timestamp excludes
+// tracks created after it (ex: by a scan running mid-reconciliation), so shifting pages don't
+// consider rows twice.
+func missingAndMatchingSel(ids []string, before time.Time) SelectBuilder {
+	sel := Select("m.id as old_id", "min(c.id) as new_id").From("media_file m").
+		Join(`media_file c on not c.missing
+			and c.library_id = m.library_id and c.title = m.title
+			and c.artist = m.artist and c.album = m.album and c.size = m.size`).
+		Where(Eq{"m.missing": true}).GroupBy("m.id")
+	if len(ids) > 0 {
+		sel = sel.Where(Eq{"m.id": ids})
+	}
+	if !before.IsZero() {
+		sel = sel.Where(Lt{"m.created_at": before}).Where(Lt{"c.created_at": before})
+	}
+	return sel
+}
+
+// countMissingAndMatching returns how many missing tracks have a canonical replacement, so
+// callers can size their pagination loop up-front.
+func countMissingAndMatching(ctx context.Context, tx model.DataStore, before time.Time) (int64, error) {
+	mr := tx.MediaFile(ctx).(*mediaFileRepository)
+	sel := Select("count(*) as total").FromSelect(missingAndMatchingSel(nil, before), "pairs")
+	var res struct{ Total int64 }
+	err := mr.queryOne(sel, &res)
+	return res.Total, err
+}
+
+// canonicalTrackMapping maps missing tracks to the non-missing track that represents the same
+// recording (see missingAndMatchingSel). When more than one candidate matches, the lowest id is
+// picked, so the mapping is deterministic. A non-zero limit paginates the result; the rows are
+// ordered by the canonical id with the missing id as tie-breaker, so several missing tracks
+// resolving to the same canonical one can't be skipped or repeated between pages.
+func canonicalTrackMapping(ctx context.Context, tx model.DataStore, ids []string, before time.Time, offset, limit int) (map[string]string, error) {
+	mr := tx.MediaFile(ctx).(*mediaFileRepository)
+	sel := missingAndMatchingSel(ids, before).OrderBy("new_id", "old_id")
+	if limit > 0 {
+		sel = sel.Offset(uint64(offset)).Limit(uint64(limit))
+	}
+	var pairs []struct{ OldID, NewID string }
+	if err := mr.queryAll(sel, &pairs); err != nil {
+		return nil, err
+	}
+	mapping := map[string]string{}
+	for _, p := range pairs {
+		mapping[p.OldID] = p.NewID
+	}
+	return mapping, nil
+}
+
+// reassignPlaylistTracks is the primitive behind playlist track re-resolution: it rewrites
+// playlist entries pointing at the old track ids to their canonical replacements, collapsing
+// entries that become adjacent duplicates and keeping positions contiguous. Returns the number
+// of rewritten entries.
+func reassignPlaylistTracks(ctx context.Context, tx model.DataStore, mapping map[string]string) (int64, error) {
+	if len(mapping) == 0 {
+		return 0, nil
+	}
+	pr := tx.Playlist(ctx).(*playlistRepository)
+	oldIds := make([]string, 0, len(mapping))
+	for id := range mapping {
+		oldIds = append(oldIds, id)
+	}
+	var playlistIds []string
+	sel := Select("distinct playlist_id").From("playlist_tracks").Where(Eq{"media_file_id": oldIds})
+	if err := pr.queryAllSlice(sel, &playlistIds); err != nil {
+		return 0, err
+	}
+
+	var reassigned int64
+	for old, canonical := range mapping {
+		upd := Update("playlist_tracks").Set("media_file_id", canonical).Where(Eq{"media_file_id": old})
+		c, err := pr.executeSQL(upd)
+		if err != nil {
+			return reassigned, err
+		}
+		reassigned += c
+	}
+
+	for _, id := range playlistIds {
+		var trackIds []string
+		sq := Select("media_file_id").From("playlist_tracks").Where(Eq{"playlist_id": id}).OrderBy("id")
+		if err := pr.queryAllSlice(sq, &trackIds); err != nil {
+			return reassigned, err
+		}
+		var deduped []string
+		for i, trackId := range trackIds {
+			if i > 0 && trackIds[i-1] == trackId {
+				continue
+			}
+			deduped = append(deduped, trackId)
+		}
+		if err := pr.updatePlaylist(id, deduped); err != nil {
+			return reassigned, err
+		}
+	}
+	return reassigned, nil
+}
+
+// resolveBatchSize is the number of missing/canonical pairs processed per page when resolving
+// playlist tracks, keeping memory bounded on libraries with thousands of missing tracks
+const resolveBatchSize = 1000
+
+// ResolvePlaylistTracks re-points playlist entries referencing missing tracks to the non-missing
+// track that represents the same recording (see canonicalTrackMapping), so playlists keep
+// playing after their tracks are moved around the library. It is exposed as the `fix-playlists`
+// command, and runs implicitly before missing files are permanently deleted.
+func (s *SQLStore) ResolvePlaylistTracks(ctx context.Context) (int64, error) {
+	var resolved int64
+	// Tracks created after this point are left for the next run
+	watermark := time.Now()
+	err := s.WithTx(func(tx model.DataStore) error {
+		total, err := countMissingAndMatching(ctx, tx, watermark)
+		if err != nil {
+			return err
+		}
+		for offset := 0; int64(offset) < total; offset += resolveBatchSize {
+			mapping, err := canonicalTrackMapping(ctx, tx, nil, watermark, offset, resolveBatchSize)
+			if err != nil {
+				return err
+			}
+			c, err := reassignPlaylistTracks(ctx, tx, mapping)
+			if err != nil {
+				return err
+			}
+			resolved += c
+		}
+		return nil
+	})
+	if resolved > 0 {
+		log.Info(ctx, "Re-pointed playlist entries to canonical tracks", "count", resolved)
+	}
+	return resolved, err
+}
@@ -0,0 +1,162 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResolvePlaylistTracks", func() {
+	var ds model.DataStore
+	var ctx context.Context
+	var helper sqlRepository
+	var playlists []model.Playlist
+
+	newPlaylist := func(name string, trackIds ...string) model.Playlist {
+		pls := model.Playlist{Name: name, OwnerID: "userid"}
+		pls.AddTracks(trackIds)
+		Expect(ds.Playlist(ctx).Put(&pls)).To(Succeed())
+		playlists = append(playlists, pls)
+		return pls
+	}
+
+	trackIds := func(pls model.Playlist) []string {
+		saved, err := ds.Playlist(ctx).GetWithTracks(pls.ID, false)
+		Expect(err).ToNot(HaveOccurred())
+		var ids []string
+		for _, t := range saved.Tracks {
+			ids = append(ids, t.MediaFileID)
+		}
+		return ids
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+		playlists = nil
+
+		mr := ds.MediaFile(ctx)
+		// rr-dup and rr-canon are the same recording: rr-dup went missing after the file moved
+		Expect(mr.Put(&model.MediaFile{ID: "rr-dup", LibraryID: 1, Title: "Same Song",
+			Artist: "Same Artist", Album: "Same Album", Size: 1234, Path: "rr/old/track.mp3"})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{ID: "rr-canon", LibraryID: 1, Title: "Same Song",
+			Artist: "Same Artist", Album: "Same Album", Size: 1234, Path: "rr/new/track.mp3"})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{ID: "rr-1", LibraryID: 1, Title: "First", Path: "rr/1.mp3"})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{ID: "rr-3", LibraryID: 1, Title: "Last", Path: "rr/3.mp3"})).To(Succeed())
+		Expect(mr.MarkMissing(true, "rr-dup")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, pls := range playlists {
+			Expect(ds.Playlist(ctx).Delete(pls.ID)).To(Succeed())
+		}
+		_, err := helper.executeSQL(squirrel.Delete("media_file").Where(squirrel.Like{"id": "rr-%"}))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("Pagination", func() {
+		BeforeEach(func() {
+			// A second missing copy of the same recording: both missing tracks map to rr-canon,
+			// a tie on the canonical id that only the secondary order on the missing id breaks
+			Expect(ds.MediaFile(ctx).Put(&model.MediaFile{ID: "rr-dup2", LibraryID: 1, Title: "Same Song",
+				Artist: "Same Artist", Album: "Same Album", Size: 1234, Path: "rr/older/track.mp3"})).To(Succeed())
+			Expect(ds.MediaFile(ctx).MarkMissing(true, "rr-dup2")).To(Succeed())
+		})
+
+		It("counts the pairs, so callers can size their loop", func() {
+			var total int64
+			Expect(ds.WithTx(func(tx model.DataStore) error {
+				var err error
+				total, err = countMissingAndMatching(ctx, tx, time.Time{})
+				return err
+			})).To(Succeed())
+			Expect(total).To(Equal(int64(2)))
+		})
+
+		It("does not skip or repeat pairs between pages when canonical ids tie", func() {
+			merged := map[string]string{}
+			Expect(ds.WithTx(func(tx model.DataStore) error {
+				for offset := 0; offset < 2; offset++ {
+					page, err := canonicalTrackMapping(ctx, tx, nil, time.Time{}, offset, 1)
+					if err != nil {
+						return err
+					}
+					Expect(page).To(HaveLen(1))
+					for old, canonical := range page {
+						Expect(merged).ToNot(HaveKey(old))
+						merged[old] = canonical
+					}
+				}
+				return nil
+			})).To(Succeed())
+			Expect(merged).To(Equal(map[string]string{"rr-dup": "rr-canon", "rr-dup2": "rr-canon"}))
+		})
+
+		It("ignores tracks created after the watermark", func() {
+			watermark := time.Now()
+			Expect(ds.MediaFile(ctx).Put(&model.MediaFile{ID: "rr-late", LibraryID: 1, Title: "Same Song",
+				Artist: "Same Artist", Album: "Same Album", Size: 1234, Path: "rr/late/track.mp3",
+				CreatedAt: watermark.Add(time.Hour)})).To(Succeed())
+			Expect(ds.MediaFile(ctx).MarkMissing(true, "rr-late")).To(Succeed())
+
+			var total int64
+			Expect(ds.WithTx(func(tx model.DataStore) error {
+				var err error
+				total, err = countMissingAndMatching(ctx, tx, watermark)
+				return err
+			})).To(Succeed())
+			Expect(total).To(Equal(int64(2)))
+		})
+	})
+
+	It("re-points entries to the canonical track, keeping the ordering", func() {
+		pls := newPlaylist("rr-playlist", "rr-1", "rr-dup", "rr-3")
+
+		resolved, err := ds.ResolvePlaylistTracks(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(Equal(int64(1)))
+
+		Expect(trackIds(pls)).To(Equal([]string{"rr-1", "rr-canon", "rr-3"}))
+		saved, err := ds.Playlist(ctx).GetWithTracks(pls.ID, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.Tracks[0].ID).To(Equal("1"))
+		Expect(saved.Tracks[2].ID).To(Equal("3"))
+	})
+
+	It("collapses entries that become adjacent duplicates", func() {
+		pls := newPlaylist("rr-adjacent", "rr-canon", "rr-dup", "rr-3")
+
+		_, err := ds.ResolvePlaylistTracks(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(trackIds(pls)).To(Equal([]string{"rr-canon", "rr-3"}))
+	})
+
+	It("is a no-op when the missing track has no canonical counterpart", func() {
+		Expect(ds.MediaFile(ctx).MarkMissing(true, "rr-1")).To(Succeed())
+		pls := newPlaylist("rr-unresolved", "rr-1", "rr-3")
+
+		resolved, err := ds.ResolvePlaylistTracks(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(BeZero())
+		Expect(trackIds(pls)).To(Equal([]string{"rr-1", "rr-3"}))
+	})
+
+	It("runs before missing files are permanently deleted", func() {
+		pls := newPlaylist("rr-cleanup", "rr-1", "rr-dup")
+
+		Expect(ds.DeleteMissingFiles(ctx, []string{"rr-dup"})).To(Succeed())
+
+		_, err := ds.MediaFile(ctx).Get("rr-dup")
+		Expect(err).To(MatchError(model.ErrNotFound))
+		Expect(trackIds(pls)).To(Equal([]string{"rr-1", "rr-canon"}))
+	})
+})
@@ -52,6 +52,9 @@ func NewPlaylistRepository(ctx context.Context, db dbx.Builder) model.PlaylistRe
 		"q":     playlistFilter,
 		"smart": smartPlaylistFilter,
 	}
+	r.sortMappings = map[string]string{
+		"duration": "duration_ms",
+	}
 	return r
 }
 
@@ -312,14 +315,16 @@ func (r *playlistRepository) addTracks(playlistId string, startingPos int, media
 // refreshCounters updates total playlist duration, size and count
 func (r *playlistRepository) refreshCounters(pls *model.Playlist) error {
 	statsSql := Select(
-		"coalesce(sum(duration), 0) as duration",
+		"coalesce(sum(duration_ms), 0) as duration_ms",
 		"coalesce(sum(size), 0) as size",
 		"count(*) as count",
 	).
 		From("media_file").
 		Join("playlist_tracks f on f.media_file_id = media_file.id").
 		Where(Eq{"playlist_id": pls.ID})
-	var res struct{ Duration, Size, Count float32 }
+	var res struct {
+		DurationMs, Size, Count int64
+	}
 	err := r.queryOne(statsSql, &res)
 	if err != nil {
 		return err
@@ -327,7 +332,7 @@ func (r *playlistRepository) refreshCounters(pls *model.Playlist) error {
 
 	// Update playlist's total duration, size and count
 	upd := Update("playlist").
-		Set("duration", res.Duration).
+		Set("duration_ms", res.DurationMs).
 		Set("size", res.Size).
 		Set("song_count", res.Count).
 		Set("updated_at", time.Now()).
@@ -337,8 +342,8 @@ func (r *playlistRepository) refreshCounters(pls *model.Playlist) error {
 		return err
 	}
 	pls.SongCount = int(res.Count)
-	pls.Duration = res.Duration
-	pls.Size = int64(res.Size)
+	pls.DurationMs = res.DurationMs
+	pls.Size = res.Size
 	return nil
 }
 
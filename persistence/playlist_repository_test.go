@@ -3,6 +3,7 @@ package persistence
 import (
 	"context"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/navidrome/navidrome/db"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
@@ -75,6 +76,37 @@ var _ = Describe("PlaylistRepository", func() {
 		})
 	})
 
+	Describe("Totals", func() {
+		var mr model.MediaFileRepository
+
+		BeforeEach(func() {
+			ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+			mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+			for _, id := range []string{"dms-1", "dms-2", "dms-3"} {
+				Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: id, DurationMs: 500,
+					Path: P("/totals/" + id + ".mp3")})).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			for _, id := range []string{"dms-1", "dms-2", "dms-3"} {
+				Expect(mr.Delete(id)).To(Succeed())
+			}
+		})
+
+		It("sums fractional track durations without drift", func() {
+			pls := model.Playlist{Name: "Half Seconds", OwnerID: "userid"}
+			pls.AddTracks([]string{"dms-1", "dms-2", "dms-3"})
+			Expect(repo.Put(&pls)).To(Succeed())
+			defer func() { Expect(repo.Delete(pls.ID)).To(Succeed()) }()
+
+			saved, err := repo.Get(pls.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(saved.DurationMs).To(Equal(int64(1500)))
+			Expect(saved.Duration()).To(Equal(float32(1.5)))
+		})
+	})
+
 	It("Put/Exists/Delete", func() {
 		By("saves the playlist to the DB")
 		newPls := model.Playlist{Name: "Great!", OwnerID: "userid"}
@@ -128,5 +160,92 @@ var _ = Describe("PlaylistRepository", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(savedPls.Rules).To(Equal(rules))
 		})
+
+		Context("with tag and role criteria", func() {
+			var mr model.MediaFileRepository
+			var pls model.Playlist
+
+			refresh := func(rules *criteria.Criteria) model.MediaFiles {
+				pls = model.Playlist{Name: "Tagged", OwnerID: "userid", Rules: rules}
+				Expect(repo.Put(&pls)).To(Succeed())
+				saved, err := repo.GetWithTracks(pls.ID, true)
+				Expect(err).ToNot(HaveOccurred())
+				return saved.MediaFiles()
+			}
+
+			BeforeEach(func() {
+				ctx := log.NewContext(context.TODO())
+				ctx = request.WithUser(ctx, model.User{ID: "userid", IsAdmin: true})
+				mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+				ar := NewArtistRepository(ctx, NewDBXBuilder(db.Db()))
+				Expect(ar.Put(&model.Artist{ID: "zp-ar", Name: "Philip Glass"})).To(Succeed())
+				Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "zp-1", Path: P("/smart/happy.mp3"),
+					Tags: model.Tags{model.NewTag("mood", "Happy")}})).To(Succeed())
+				Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "zp-2", Path: P("/smart/sad.mp3"),
+					Tags: model.Tags{model.NewTag("mood", "Sad")}})).To(Succeed())
+				Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "zp-3", Path: P("/smart/composed.mp3"),
+					Participations: model.Participations{
+						model.RoleComposer: []model.Participant{{ID: "zp-ar", Name: "Philip Glass"}},
+					}})).To(Succeed())
+				pls = model.Playlist{}
+			})
+
+			AfterEach(func() {
+				if pls.ID != "" {
+					Expect(repo.Delete(pls.ID)).To(Succeed())
+				}
+				for _, id := range []string{"zp-1", "zp-2", "zp-3"} {
+					Expect(mr.Delete(id)).To(Succeed())
+				}
+				helper := mr.(*mediaFileRepository)
+				for _, del := range []squirrel.DeleteBuilder{
+					squirrel.Delete("media_file_artists").Where(squirrel.Eq{"media_file_id": "zp-3"}),
+					squirrel.Delete("artist").Where(squirrel.Eq{"id": "zp-ar"}),
+					squirrel.Delete("item_tags").Where(squirrel.Eq{"item_id": []string{"zp-1", "zp-2"}}),
+					squirrel.Delete("tag").Where(squirrel.Eq{"id": []string{
+						model.NewTag("mood", "Happy").ID, model.NewTag("mood", "Sad").ID}}),
+				} {
+					_, err := helper.executeSQL(del)
+					Expect(err).ToNot(HaveOccurred())
+				}
+			})
+
+			It("matches tracks by tag value", func() {
+				tracks := refresh(&criteria.Criteria{
+					Expression: criteria.All{criteria.Is{"tag:mood": "Happy"}},
+				})
+				Expect(tracks).To(HaveLen(1))
+				Expect(tracks[0].ID).To(Equal("zp-1"))
+			})
+
+			It("matches tracks by participant role", func() {
+				tracks := refresh(&criteria.Criteria{
+					Expression: criteria.All{criteria.Contains{"role:composer": "Glass"}},
+				})
+				Expect(tracks).To(HaveLen(1))
+				Expect(tracks[0].ID).To(Equal("zp-3"))
+			})
+
+			It("excludes tracks carrying the tag with isNot, keeping untagged ones", func() {
+				tracks := refresh(&criteria.Criteria{
+					Expression: criteria.All{
+						criteria.StartsWith{"filepath": P("/smart/")},
+						criteria.IsNot{"tag:mood": "Happy"},
+					},
+				})
+				var ids []string
+				for _, t := range tracks {
+					ids = append(ids, t.ID)
+				}
+				Expect(ids).To(ConsistOf("zp-2", "zp-3"))
+			})
+
+			It("returns an empty playlist for unknown tag names", func() {
+				tracks := refresh(&criteria.Criteria{
+					Expression: criteria.All{criteria.Is{"tag:nonexistent": "whatever"}},
+				})
+				Expect(tracks).To(BeEmpty())
+			})
+		})
 	})
 })
@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/pocketbase/dbx"
+)
+
+type scanLogRepository struct {
+	sqlRepository
+}
+
+func NewScanLogRepository(ctx context.Context, db dbx.Builder) model.ScanLogRepository {
+	r := &scanLogRepository{}
+	r.ctx = ctx
+	r.db = db
+	r.tableName = "scan_log"
+	return r
+}
+
+func (r *scanLogRepository) Add(entry *model.ScanLog) error {
+	if entry.ID == "" {
+		entry.ID = uuid.NewString()
+	}
+	ins := Insert(r.tableName).SetMap(map[string]interface{}{
+		"id":           entry.ID,
+		"library_path": entry.LibraryPath,
+		"start_time":   entry.StartTime,
+		"duration_ms":  entry.DurationMs,
+		"full_scan":    entry.FullScan,
+		"partial":      entry.Partial,
+		"added":        entry.Added,
+		"updated":      entry.Updated,
+		"missing":      entry.Missing,
+		"playlists":    entry.Playlists,
+		"error":        entry.Error,
+	})
+	if _, err := r.executeSQL(ins); err != nil {
+		return err
+	}
+	return r.prune()
+}
+
+// prune keeps only the Scanner.ScanHistoryLength most recent entries. 0 (or a negative
+// value) disables pruning
+func (r *scanLogRepository) prune() error {
+	limit := conf.Server.Scanner.ScanHistoryLength
+	if limit <= 0 {
+		return nil
+	}
+	del := Delete(r.tableName).
+		Where(Expr("id not in (select id from "+r.tableName+" order by start_time desc, id desc limit ?)", limit))
+	c, err := r.executeSQL(del)
+	if err == nil && c > 0 {
+		log.Debug(r.ctx, "Pruned scan history", "totalDeleted", c, "kept", limit)
+	}
+	return err
+}
+
+func (r *scanLogRepository) GetAll(options ...model.QueryOptions) (model.ScanLogs, error) {
+	sel := r.newSelect(options...).Columns("*")
+	res := model.ScanLogs{}
+	err := r.queryAll(sel, &res)
+	return res, err
+}
+
+func (r *scanLogRepository) CountAll(options ...model.QueryOptions) (int64, error) {
+	return r.count(Select(), options...)
+}
+
+var _ model.ScanLogRepository = (*scanLogRepository)(nil)
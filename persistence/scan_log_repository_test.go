@@ -0,0 +1,62 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScanLogRepository", func() {
+	var repo model.ScanLogRepository
+
+	BeforeEach(func() {
+		ctx := log.NewContext(context.TODO())
+		repo = NewScanLogRepository(ctx, NewDBXBuilder(db.Db()))
+	})
+
+	AfterEach(func() {
+		r := repo.(*scanLogRepository)
+		_, err := r.executeSQL(squirrel.Delete("scan_log"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("stores and lists entries, most recent first", func() {
+		now := time.Now()
+		Expect(repo.Add(&model.ScanLog{LibraryPath: "/music", StartTime: now.Add(-time.Hour), FullScan: true, Added: 10})).To(Succeed())
+		Expect(repo.Add(&model.ScanLog{LibraryPath: "/music", StartTime: now, Updated: 2, Missing: 1})).To(Succeed())
+
+		entries, err := repo.GetAll(model.QueryOptions{Sort: "start_time", Order: "desc"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Updated).To(Equal(int64(2)))
+		Expect(entries[0].Missing).To(Equal(int64(1)))
+		Expect(entries[0].FullScan).To(BeFalse())
+		Expect(entries[1].Added).To(Equal(int64(10)))
+		Expect(entries[1].FullScan).To(BeTrue())
+		Expect(repo.CountAll()).To(Equal(int64(2)))
+	})
+
+	It("prunes the history down to the configured length", func() {
+		previous := conf.Server.Scanner.ScanHistoryLength
+		conf.Server.Scanner.ScanHistoryLength = 2
+		DeferCleanup(func() { conf.Server.Scanner.ScanHistoryLength = previous })
+
+		now := time.Now()
+		for i := 0; i < 3; i++ {
+			Expect(repo.Add(&model.ScanLog{LibraryPath: "/music", StartTime: now.Add(time.Duration(i) * time.Minute), Added: int64(i)})).To(Succeed())
+		}
+
+		entries, err := repo.GetAll(model.QueryOptions{Sort: "start_time", Order: "asc"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Added).To(Equal(int64(1)))
+		Expect(entries[1].Added).To(Equal(int64(2)))
+	})
+})
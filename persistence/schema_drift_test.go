@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// These specs catch the classic "added a column in a migration, forgot the struct field" bug,
+// by diffing the live (migrated) schema against the column mappings used by each Put.
+var _ = Describe("Schema mappings", func() {
+	var schema db.Schema
+
+	BeforeEach(func() {
+		var err error
+		schema, err = db.DumpSchema(db.Db().ReadDB())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// content_hash is not mapped by any struct: put computes and stores it itself
+	putManaged := map[string]bool{
+		"content_hash":           true,
+		"rg_track_gain_computed": true,
+		"rg_track_peak_computed": true,
+	}
+
+	drift := func(table string, rec interface{}) (missing []string, stale []string) {
+		mapped, err := toSQLArgs(rec)
+		Expect(err).ToNot(HaveOccurred())
+		columns := map[string]bool{}
+		for _, col := range schema[table].Columns {
+			columns[col.Name] = true
+			if _, ok := mapped[col.Name]; !ok && !putManaged[col.Name] {
+				missing = append(missing, col.Name)
+			}
+		}
+		for name := range mapped {
+			if !columns[name] {
+				stale = append(stale, name)
+			}
+		}
+		return missing, stale
+	}
+
+	DescribeTable("matches the live schema",
+		func(table string, rec interface{}) {
+			missing, stale := drift(table, rec)
+			Expect(missing).To(BeEmpty(), "%s columns not mapped by the persistence structs", table)
+			Expect(stale).To(BeEmpty(), "struct fields without a %s column", table)
+		},
+		Entry("media_file", "media_file", &model.MediaFile{}),
+		Entry("album", "album", &dbAlbum{Album: &model.Album{}}),
+		Entry("artist", "artist", &dbArtist{Artist: &model.Artist{}}),
+	)
+
+	It("detects a column added by a migration but not mapped", func() {
+		_, err := db.Db().WriteDB().Exec("alter table media_file add column fake_column varchar default ''")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(func() {
+			_, err := db.Db().WriteDB().Exec("alter table media_file drop column fake_column")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		schema, err = db.DumpSchema(db.Db().ReadDB())
+		Expect(err).ToNot(HaveOccurred())
+		missing, _ := drift("media_file", &model.MediaFile{})
+		Expect(missing).To(ConsistOf("fake_column"))
+	})
+})
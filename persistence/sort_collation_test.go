@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unicode sort collation", func() {
+	var ctx context.Context
+	var mr model.MediaFileRepository
+
+	put := func(id, title, orderTitle, sortTitle string) {
+		Expect(mr.Put(&model.MediaFile{ID: id, LibraryID: 1, Title: title, OrderTitle: orderTitle,
+			SortTitle: sortTitle, Path: "uc/" + id + ".mp3"})).To(Succeed())
+	}
+
+	titleOrder := func(repo model.MediaFileRepository) []string {
+		res, err := repo.GetAll(model.QueryOptions{Sort: "title",
+			Filters: squirrel.Like{"media_file.id": "uc-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		var ids []string
+		for _, mf := range res {
+			ids = append(ids, mf.ID)
+		}
+		return ids
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+
+		put("uc-1", "apple", "apple", "")
+		put("uc-2", "Banana", "banana", "")
+		put("uc-3", "Édith", "édith", "")
+		put("uc-4", "Zebra", "zebra", "")
+		put("uc-5", "日本語", "日本語", "")
+	})
+
+	AfterEach(func() {
+		helper := mr.(*mediaFileRepository)
+		_, err := helper.executeSQL(squirrel.Delete("media_file").Where(squirrel.Like{"id": "uc-%"}))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("orders accented and CJK titles where a human expects them, not byte-wise", func() {
+		// Byte-wise, "édith" (0xC3...) would come after "zebra", and "日本語" is incidental
+		Expect(titleOrder(mr)).To(Equal([]string{"uc-1", "uc-2", "uc-3", "uc-4", "uc-5"}))
+	})
+
+	It("still lets sort tags win when PreferSortTags is set", func() {
+		conf.Server.PreferSortTags = true
+		DeferCleanup(func() { conf.Server.PreferSortTags = false })
+
+		put("uc-6", "Zulu", "zulu", "antelope")
+
+		// The sort mappings are baked in at construction time
+		repo := NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+		Expect(titleOrder(repo)).To(Equal([]string{"uc-6", "uc-1", "uc-2", "uc-3", "uc-4", "uc-5"}))
+	})
+})
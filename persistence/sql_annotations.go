@@ -98,6 +98,15 @@ func (r sqlRepository) IncPlayCount(itemID string, ts time.Time) error {
 	return err
 }
 
+func (r sqlRepository) countOrphanAnnotations() (int64, error) {
+	sel := Select("count(*) as count").From(annotationTable).
+		Where(Eq{"item_type": r.tableName}).
+		Where("item_id not in (select id from " + r.tableName + ")")
+	var res struct{ Count int64 }
+	err := r.queryOne(sel, &res)
+	return res.Count, err
+}
+
 func (r sqlRepository) cleanAnnotations() error {
 	del := Delete(annotationTable).Where(Eq{"item_type": r.tableName}).Where("item_id not in (select id from " + r.tableName + ")")
 	c, err := r.executeSQL(del)
@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CleanOrphanAnnotations", func() {
+	var ds model.DataStore
+	var ctx context.Context
+	var helper sqlRepository
+	const annUser = "orphan-ann-user"
+
+	seedAnnotation := func(itemID, itemType string) {
+		ins := squirrel.Insert(annotationTable).
+			Columns("ann_id", "user_id", "item_id", "item_type", "play_count").
+			Values(uuid.NewString(), annUser, itemID, itemType, 1)
+		_, err := helper.executeSQL(ins)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	countAnnotations := func(itemID string) int64 {
+		sel := squirrel.Select("count(*) as count").From(annotationTable).
+			Where(squirrel.Eq{"item_id": itemID})
+		var res struct{ Count int64 }
+		Expect(helper.queryOne(sel, &res)).To(Succeed())
+		return res.Count
+	}
+
+	BeforeEach(func() {
+		ctx = log.NewContext(context.TODO())
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+
+		// Flush any orphans left behind by other specs, so the report counts are deterministic
+		_, err := ds.CleanOrphanAnnotations(ctx, true)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		del := squirrel.Delete(annotationTable).Where(squirrel.Eq{"user_id": annUser})
+		_, err := helper.executeSQL(del)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("reports orphan counts per item type, without deleting anything", func() {
+		seedAnnotation("no-such-track", "media_file")
+		seedAnnotation("no-such-album", "album")
+		seedAnnotation("no-such-artist-1", "artist")
+		seedAnnotation("no-such-artist-2", "artist")
+
+		report, err := ds.CleanOrphanAnnotations(ctx, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report).To(Equal(map[string]int64{"media_file": 1, "album": 1, "artist": 2}))
+
+		Expect(countAnnotations("no-such-track")).To(Equal(int64(1)))
+	})
+
+	It("deletes only the orphans when run with the fix flag", func() {
+		seedAnnotation("no-such-track", "media_file")
+		seedAnnotation(songDayInALife.ID, "media_file")
+
+		report, err := ds.CleanOrphanAnnotations(ctx, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report).To(Equal(map[string]int64{"media_file": 1, "album": 0, "artist": 0}))
+
+		Expect(countAnnotations("no-such-track")).To(Equal(int64(0)))
+		Expect(countAnnotations(songDayInALife.ID)).To(Equal(int64(1)))
+	})
+})
@@ -2,10 +2,12 @@ package persistence
 
 import (
 	"context"
+	"crypto/md5"
 	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,6 +26,15 @@ type sqlRepository struct {
 	tableName    string
 	db           dbx.Builder
 	sortMappings map[string]string
+	// hashContent enables the skip-unchanged optimization in put: the table gets a
+	// content_hash column fingerprinting the last written values, and writes that would
+	// store the exact same data are skipped. Used by the tables the scanner rewrites on
+	// every scan, so an unchanged library produces no UPDATEs (and no updated_at churn)
+	hashContent bool
+	// libraryFilter returns the predicate restricting rows to the libraries the given user
+	// was granted access to. Set by the repositories of library-scoped tables; hooked into
+	// newSelect, so no query on those tables can forget it
+	libraryFilter func(userID string) Sqlizer
 }
 
 const invalidUserId = "-1"
@@ -52,7 +63,30 @@ func (r sqlRepository) newSelect(options ...model.QueryOptions) SelectBuilder {
 	sq := Select().From(r.tableName)
 	sq = r.applyOptions(sq, options...)
 	sq = r.applyFilters(sq, options...)
-	return sq
+	return r.applyLibraryFilter(sq)
+}
+
+// applyLibraryFilter restricts the query to the libraries visible to the ctx user. Admins
+// and internal (user-less) contexts, like the scanner's, see everything. A user with no
+// grants sees an empty library, not an error
+func (r sqlRepository) applyLibraryFilter(sq SelectBuilder) SelectBuilder {
+	if r.libraryFilter == nil {
+		return sq
+	}
+	user, ok := request.UserFrom(r.ctx)
+	// An empty user ID is a placeholder (ex: auth.WithAdminUser before any user exists),
+	// not a real account, so it is treated as an internal context
+	if !ok || user.IsAdmin || user.ID == "" {
+		return sq
+	}
+	return sq.Where(r.libraryFilter(user.ID))
+}
+
+// libraryIDFilter builds the library restriction for tables with a library_id column
+func libraryIDFilter(tableName string) func(userID string) Sqlizer {
+	return func(userID string) Sqlizer {
+		return Expr(tableName+".library_id in (select library_id from user_library where user_id = ?)", userID)
+	}
 }
 
 func (r sqlRepository) applyOptions(sq SelectBuilder, options ...model.QueryOptions) SelectBuilder {
@@ -99,18 +133,17 @@ func (r sqlRepository) buildSortOrder(sort, order string) string {
 	var newSort []string
 	parts := strings.FieldsFunc(sort, splitFunc(','))
 	for _, p := range parts {
+		// Only the trailing asc/desc is a direction; anything else (ex: a collate clause)
+		// stays attached to the field expression
 		f := strings.FieldsFunc(p, splitFunc(' '))
-		newField := []string{f[0]}
-		if len(f) == 1 {
-			newField = append(newField, order)
-		} else {
-			if f[1] == "asc" {
-				newField = append(newField, order)
-			} else {
-				newField = append(newField, reverseOrder)
+		dir := order
+		if last := f[len(f)-1]; last == "asc" || last == "desc" {
+			if last == "desc" {
+				dir = reverseOrder
 			}
+			f = f[:len(f)-1]
 		}
-		newSort = append(newSort, strings.Join(newField, " "))
+		newSort = append(newSort, strings.Join(append(f, dir), " "))
 	}
 	return strings.Join(newSort, ", ")
 }
@@ -292,6 +325,17 @@ func (r sqlRepository) put(id string, m interface{}, colsToUpdate ...string) (ne
 		}
 
 		delete(updateValues, "created_at")
+		if r.hashContent {
+			hash := contentHash(updateValues)
+			unchanged, err := r.unchangedSince(id, hash)
+			if err != nil {
+				return "", err
+			}
+			if unchanged {
+				return id, nil
+			}
+			updateValues["content_hash"] = hash
+		}
 		update := Update(r.tableName).Where(Eq{"id": id}).SetMap(updateValues)
 		count, err := r.executeSQL(update)
 		if err != nil {
@@ -306,11 +350,56 @@ func (r sqlRepository) put(id string, m interface{}, colsToUpdate ...string) (ne
 		id = uuid.NewString()
 		values["id"] = id
 	}
+	if r.hashContent {
+		values["content_hash"] = contentHash(values)
+	}
 	insert := Insert(r.tableName).SetMap(values)
 	_, err = r.executeSQL(insert)
 	return id, err
 }
 
+// contentHashSkipCols lists the mapped columns left out of the content hash: timestamps are
+// restamped on every scan even when the underlying file did not change, and would defeat the
+// skip-unchanged check. Artist stats are owned by RefreshStats, which recomputes them with a
+// raw statement, so the hash must not vouch for them either
+var contentHashSkipCols = map[string]struct{}{
+	"content_hash": {},
+	"created_at":   {},
+	"updated_at":   {},
+	"birth_time":   {},
+	"stats":        {},
+}
+
+// contentHash fingerprints the mapped values of a row, so the persist stage can detect writes
+// that would store exactly what is already there
+func contentHash(values map[string]interface{}) string {
+	cols := make([]string, 0, len(values))
+	for col := range values {
+		if _, skip := contentHashSkipCols[col]; !skip {
+			cols = append(cols, col)
+		}
+	}
+	sort.Strings(cols)
+	h := md5.New()
+	for _, col := range cols {
+		_, _ = fmt.Fprintf(h, "%s=%v\x00", col, values[col])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// unchangedSince reports whether the stored row's fingerprint matches the given one. Code paths
+// that modify rows with raw statements, bypassing put, must clear content_hash so a stale
+// fingerprint can never suppress a legitimate write
+func (r sqlRepository) unchangedSince(id, hash string) (bool, error) {
+	sel := Select("content_hash").From(r.tableName).Where(Eq{"id": id})
+	var res struct{ ContentHash string }
+	err := r.queryOne(sel, &res)
+	if errors.Is(err, model.ErrNotFound) {
+		return false, nil
+	}
+	return res.ContentHash != "" && res.ContentHash == hash, err
+}
+
 func (r sqlRepository) delete(cond Sqlizer) error {
 	del := Delete(r.tableName).Where(cond)
 	_, err := r.executeSQL(del)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/model/request"
@@ -144,6 +145,22 @@ func (r sqlRepository) GetBookmarks() (model.Bookmarks, error) {
 	return resp, nil
 }
 
+// clearCompletedBookmarks removes bookmarks of tracks that were played past the
+// InProgressThreshold, so finished tracks don't linger in "continue listening" lists
+func (r sqlRepository) clearCompletedBookmarks() error {
+	del := Delete(bookmarkTable).Where(Eq{"item_type": r.tableName}).
+		Where(Expr("exists (select 1 from "+r.tableName+" where "+r.tableName+".id = item_id"+
+			" and position >= "+r.tableName+".duration_ms * ?)", conf.Server.InProgressThreshold))
+	c, err := r.executeSQL(del)
+	if err != nil {
+		return err
+	}
+	if c > 0 {
+		log.Debug(r.ctx, "Clean-up completed bookmarks", "totalDeleted", c)
+	}
+	return nil
+}
+
 func (r sqlRepository) cleanBookmarks() error {
 	del := Delete(bookmarkTable).Where(Eq{"item_type": r.tableName}).Where("item_id not in (select id from " + r.tableName + ")")
 	c, err := r.executeSQL(del)
@@ -16,7 +16,7 @@ var _ = Describe("sqlBookmarks", func() {
 
 	BeforeEach(func() {
 		ctx := log.NewContext(context.TODO())
-		ctx = request.WithUser(ctx, model.User{ID: "userid"})
+		ctx = request.WithUser(ctx, model.User{ID: "userid", IsAdmin: true})
 		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
 	})
 
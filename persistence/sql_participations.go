@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// updateParticipations persists the media_file_artists rows of the given media file, diffing
+// the artists (and roles) in the participations map against the existing rows: only added and
+// removed associations touch the table, instead of replacing all rows on every scan.
+// Participants without an ID are skipped.
+func (r sqlRepository) updateParticipations(id string, participations model.Participations) error {
+	key := func(artistID, role string) string { return artistID + "\x00" + role }
+
+	sel := Select("artist_id", "role").From("media_file_artists").Where(Eq{"media_file_id": id})
+	var existing []struct {
+		ArtistID string
+		Role     string
+	}
+	if err := r.queryAll(sel, &existing); err != nil {
+		return err
+	}
+	current := map[string]struct{}{}
+	for _, row := range existing {
+		current[key(row.ArtistID, row.Role)] = struct{}{}
+	}
+
+	wanted := map[string]struct{}{}
+	ins := Insert("media_file_artists").Columns("media_file_id", "artist_id", "role")
+	var addCount int
+	for role, artists := range participations {
+		for _, a := range artists {
+			if a.ID == "" {
+				continue
+			}
+			k := key(a.ID, role.String())
+			if _, ok := wanted[k]; ok {
+				continue
+			}
+			wanted[k] = struct{}{}
+			if _, ok := current[k]; !ok {
+				ins = ins.Values(id, a.ID, role.String())
+				addCount++
+			}
+		}
+	}
+
+	var removed Or
+	for _, row := range existing {
+		if _, ok := wanted[key(row.ArtistID, row.Role)]; !ok {
+			removed = append(removed, And{Eq{"artist_id": row.ArtistID}, Eq{"role": row.Role}})
+		}
+	}
+	if len(removed) > 0 {
+		del := Delete("media_file_artists").Where(And{Eq{"media_file_id": id}, removed})
+		if _, err := r.executeSQL(del); err != nil {
+			return err
+		}
+	}
+	if addCount == 0 {
+		return nil
+	}
+	_, err := r.executeSQL(ins)
+	return err
+}
+
+// cleanParticipations removes participation rows of media files that are no longer in the DB
+func (r sqlRepository) cleanParticipations() error {
+	del := Delete("media_file_artists").Where("media_file_id not in (select id from media_file)")
+	c, err := r.executeSQL(del)
+	if err != nil {
+		return err
+	}
+	if c > 0 {
+		log.Debug(r.ctx, "Clean-up participations", "totalDeleted", c)
+	}
+	return nil
+}
+
+// roleFilter matches artists participating in at least one media file with the given role
+func roleFilter(_ string, value interface{}) Sqlizer {
+	return Expr("exists (select 1 from media_file_artists mfa where mfa.artist_id = artist.id and mfa.role = ?)", value)
+}
@@ -13,6 +13,10 @@ type filterFunc = func(field string, value interface{}) Sqlizer
 
 type sqlRestful struct {
 	filterMappings map[string]filterFunc
+	// orFilterFields whitelists the filters that can be composed with OR semantics through
+	// the special "or" filter (ex: _filters={"or":{"starred":"true","has_rating":"true"}}).
+	// Filters not listed here are silently dropped from the composition
+	orFilterFields map[string]struct{}
 }
 
 func (r sqlRestful) parseRestFilters(options rest.QueryOptions) Sqlizer {
@@ -24,17 +28,90 @@ func (r sqlRestful) parseRestFilters(options rest.QueryOptions) Sqlizer {
 		if v == "" {
 			continue
 		}
-		if ff, ok := r.filterMappings[f]; ok {
-			filters = append(filters, ff(f, v))
-		} else if strings.HasSuffix(strings.ToLower(f), "id") {
-			filters = append(filters, eqFilter(f, v))
-		} else {
-			filters = append(filters, startsWithFilter(f, v))
+		if f == "or" {
+			if or := r.parseOrFilters(v); or != nil {
+				filters = append(filters, or)
+			}
+			continue
+		}
+		if filter := r.parseRestFilter(f, v); filter != nil {
+			filters = append(filters, filter)
 		}
 	}
 	return filters
 }
 
+func (r sqlRestful) parseRestFilter(f string, v interface{}) Sqlizer {
+	ff, mapped := r.filterMappings[f]
+	values := filterValueList(v)
+	switch {
+	case mapped && values != nil:
+		// A mapped filter given a list of values matches when any of the values match
+		return orCompose(values, func(value interface{}) Sqlizer { return ff(f, value) })
+	case mapped:
+		return ff(f, v)
+	case strings.HasSuffix(strings.ToLower(f), "id"):
+		// Lists are passed through as-is: squirrel renders Eq with a slice as an IN clause
+		return eqFilter(f, v)
+	case values != nil:
+		return orCompose(values, func(value interface{}) Sqlizer { return startsWithFilter(f, value) })
+	}
+	return startsWithFilter(f, v)
+}
+
+// parseOrFilters combines the given filters with OR instead of the default AND. Only filters
+// whitelisted in orFilterFields are accepted, so arbitrary predicates cannot be composed
+// through the public API
+func (r sqlRestful) parseOrFilters(v interface{}) Sqlizer {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	or := Or{}
+	for f, value := range m {
+		if _, ok := r.orFilterFields[f]; !ok || value == "" {
+			continue
+		}
+		if filter := r.parseRestFilter(f, value); filter != nil {
+			or = append(or, filter)
+		}
+	}
+	if len(or) == 0 {
+		return nil
+	}
+	return or
+}
+
+// filterValueList normalizes repeated query parameters ([]string) and JSON arrays
+// ([]interface{}) into a single slice. Returns nil for scalar values
+func filterValueList(v interface{}) []interface{} {
+	switch values := v.(type) {
+	case []interface{}:
+		return values
+	case []string:
+		list := make([]interface{}, len(values))
+		for i := range values {
+			list[i] = values[i]
+		}
+		return list
+	}
+	return nil
+}
+
+func orCompose(values []interface{}, apply func(value interface{}) Sqlizer) Sqlizer {
+	or := Or{}
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		or = append(or, apply(value))
+	}
+	if len(or) == 0 {
+		return nil
+	}
+	return or
+}
+
 func (r sqlRestful) parseRestOptions(options ...rest.QueryOptions) model.QueryOptions {
 	qo := model.QueryOptions{}
 	if len(options) > 0 {
@@ -46,6 +123,10 @@ func (r sqlRestful) parseRestOptions(options ...rest.QueryOptions) model.QueryOp
 			qo.Seed = seed
 			delete(options[0].Filters, "seed")
 		}
+		if includeEmpty, ok := options[0].Filters["include_empty"].(string); ok {
+			qo.IncludeEmpty = strings.EqualFold(includeEmpty, "true")
+			delete(options[0].Filters, "include_empty")
+		}
 		qo.Filters = r.parseRestFilters(options[0])
 	}
 	return qo
@@ -56,11 +137,23 @@ func eqFilter(field string, value interface{}) Sqlizer {
 }
 
 func startsWithFilter(field string, value interface{}) Sqlizer {
-	return Like{field: fmt.Sprintf("%s%%", value)}
+	return likeExpr(field, fmt.Sprintf("%s%%", escapeLike.Replace(fmt.Sprint(value))))
 }
 
 func containsFilter(field string, value interface{}) Sqlizer {
-	return Like{field: fmt.Sprintf("%%%s%%", value)}
+	return likeExpr(field, fmt.Sprintf("%%%s%%", escapeLike.Replace(fmt.Sprint(value))))
+}
+
+// Numeric columns follow the REST operator convention: a filter named `<column>_gt` or
+// `<column>_lt` (ex: bpm_gt=120&duration_lt=30) compares the column against the given value.
+// Both bounds can be combined in the same request to express a range
+
+func gtFilter(field string, value interface{}) Sqlizer {
+	return Gt{strings.TrimSuffix(field, "_gt"): value}
+}
+
+func ltFilter(field string, value interface{}) Sqlizer {
+	return Lt{strings.TrimSuffix(field, "_lt"): value}
 }
 
 func booleanFilter(field string, value interface{}) Sqlizer {
@@ -69,14 +162,14 @@ func booleanFilter(field string, value interface{}) Sqlizer {
 }
 
 func fullTextFilter(field string, value interface{}) Sqlizer {
-	return fullTextExpr(value.(string))
+	return fullTextExpr("full_text", value.(string))
 }
 
 func substringFilter(field string, value interface{}) Sqlizer {
 	parts := strings.Split(value.(string), " ")
 	filters := And{}
 	for _, part := range parts {
-		filters = append(filters, Like{field: "%" + part + "%"})
+		filters = append(filters, likeExpr(field, "%"+escapeLike.Replace(part)+"%"))
 	}
 	return filters
 }
@@ -1,8 +1,12 @@
 package persistence
 
 import (
+	"context"
+
 	"github.com/Masterminds/squirrel"
 	"github.com/deluan/rest"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -33,7 +37,17 @@ var _ = Describe("sqlRestful", func() {
 
 		It("returns a 'like' condition for other filters", func() {
 			options.Filters = map[string]interface{}{"name": "joe"}
-			Expect(r.parseRestFilters(options)).To(Equal(squirrel.And{squirrel.Like{"name": "joe%"}}))
+			sql, args, err := r.parseRestFilters(options).ToSql()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal(`(name LIKE ? ESCAPE '\')`))
+			Expect(args).To(ConsistOf("joe%"))
+		})
+
+		It("escapes LIKE wildcards in the filter value", func() {
+			options.Filters = map[string]interface{}{"name": "100%_true"}
+			_, args, err := r.parseRestFilters(options).ToSql()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(args).To(ConsistOf(`100\%\_true%`))
 		})
 
 		It("uses the custom filter", func() {
@@ -45,5 +59,75 @@ var _ = Describe("sqlRestful", func() {
 			options.Filters = map[string]interface{}{"test": 100}
 			Expect(r.parseRestFilters(options)).To(Equal(squirrel.And{squirrel.Gt{"test": 100}}))
 		})
+
+		It("ORs the values of a repeated mapped filter", func() {
+			r.filterMappings = map[string]filterFunc{"genre_id": eqFilter}
+			options.Filters = map[string]interface{}{"genre_id": []string{"gn-1", "gn-2"}}
+			sql, args, err := r.parseRestFilters(options).ToSql()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal("((genre_id = ? OR genre_id = ?))"))
+			Expect(args).To(ConsistOf("gn-1", "gn-2"))
+		})
+
+		It("ORs the values of a repeated unmapped filter", func() {
+			options.Filters = map[string]interface{}{"name": []string{"joe", "jane"}}
+			sql, args, err := r.parseRestFilters(options).ToSql()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal(`((name LIKE ? ESCAPE '\' OR name LIKE ? ESCAPE '\'))`))
+			Expect(args).To(ConsistOf("joe%", "jane%"))
+		})
+
+		Describe(`the special "or" filter`, func() {
+			BeforeEach(func() {
+				r.filterMappings = map[string]filterFunc{"starred": booleanFilter, "has_rating": hasRatingFilter}
+				r.orFilterFields = map[string]struct{}{"starred": {}, "has_rating": {}}
+			})
+
+			It("combines whitelisted filters with OR", func() {
+				options.Filters = map[string]interface{}{"or": map[string]interface{}{"starred": "true"}, "missing": "true"}
+				sql, args, err := r.parseRestFilters(options).ToSql()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sql).To(ContainSubstring("(starred = ?)"))
+				Expect(args).To(ContainElement(true))
+			})
+
+			It("drops filters that are not whitelisted", func() {
+				options.Filters = map[string]interface{}{"or": map[string]interface{}{"id": "123", "starred": "true"}}
+				sql, _, err := r.parseRestFilters(options).ToSql()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sql).To(Equal("((starred = ?))"))
+			})
+
+			It("is ignored when the value is not an object", func() {
+				options.Filters = map[string]interface{}{"or": "starred"}
+				Expect(r.parseRestFilters(options)).To(Equal(squirrel.And{}))
+			})
+		})
+	})
+
+	Describe("parseRestOptions", func() {
+		It("builds an IN-equivalent clause for repeated media file genres", func() {
+			ctx := log.NewContext(context.TODO())
+			repo := NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+			qo := repo.parseRestOptions(rest.QueryOptions{
+				Filters: map[string]interface{}{"genre_id": []string{"gn-1", "gn-2"}},
+			})
+			sql, args, err := qo.Filters.ToSql()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal("((genre_id = ? OR genre_id = ?))"))
+			Expect(args).To(ConsistOf("gn-1", "gn-2"))
+		})
+
+		It("composes starred OR has_rating for albums", func() {
+			ctx := log.NewContext(context.TODO())
+			repo := NewAlbumRepository(ctx, NewDBXBuilder(db.Db())).(*albumRepository)
+			qo := repo.parseRestOptions(rest.QueryOptions{
+				Filters: map[string]interface{}{"or": map[string]interface{}{"starred": "true", "has_rating": "true"}},
+			})
+			sql, _, err := qo.Filters.ToSql()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(ContainSubstring(" OR "))
+			Expect(sql).To(ContainSubstring("starred = ?"))
+		})
 	})
 })
@@ -1,28 +1,128 @@
 package persistence
 
 import (
+	"slices"
 	"strings"
+	"sync"
 
 	. "github.com/Masterminds/squirrel"
 	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/utils/str"
+	"github.com/pocketbase/dbx"
 )
 
 func getFullText(text ...string) string {
 	fullText := str.SanitizeStrings(text...)
-	return " " + fullText
+	// Store a folded variant next to each punctuated word, so "rem" matches "r.e.m."
+	words := strings.Fields(fullText)
+	for _, w := range words {
+		if folded := str.FoldPunctuation(w); folded != w && folded != "" {
+			words = append(words, folded)
+		}
+	}
+	slices.Sort(words)
+	words = slices.Compact(words)
+	return " " + strings.Join(words, " ")
 }
 
-func (r sqlRepository) doSearch(q string, offset, size int, results interface{}, orderBys ...string) error {
+// fts5Support caches whether each table's FTS5 index is usable, as a database created by an
+// FTS5-enabled build can later be opened by a build without it
+var fts5Support sync.Map
+
+func (r sqlRepository) ftsAvailable() bool {
+	if v, ok := fts5Support.Load(r.tableName); ok {
+		return v.(bool)
+	}
+	var count int64
+	err := r.db.NewQuery("select count(*) from " + r.tableName + "_fts").Row(&count)
+	fts5Support.Store(r.tableName, err == nil)
+	return err == nil
+}
+
+// updateFullTextIndex mirrors the row's full_text into the table's FTS5 index. The index is
+// kept in sync from here instead of DB triggers, so builds without FTS5 can still write to
+// the same database
+func (r sqlRepository) updateFullTextIndex(id string, fullText string) error {
+	if !r.ftsAvailable() {
+		return nil
+	}
+	ftsTable := r.tableName + "_fts"
+	_, err := r.db.NewQuery("delete from " + ftsTable + " where id = {:id}").
+		Bind(dbx.Params{"id": id}).Execute()
+	if err != nil {
+		return err
+	}
+	_, err = r.db.NewQuery("insert into " + ftsTable + " (id, full_text) values ({:id}, {:fullText})").
+		Bind(dbx.Params{"id": id, "fullText": fullText}).Execute()
+	return err
+}
+
+func (r sqlRepository) cleanFullTextIndex() error {
+	if !r.ftsAvailable() {
+		return nil
+	}
+	del := Delete(r.tableName + "_fts").Where("id not in (select id from " + r.tableName + ")")
+	c, err := r.executeSQL(del)
+	if err != nil {
+		return err
+	}
+	if c > 0 {
+		log.Debug(r.ctx, "Clean-up full-text index", "table", r.tableName, "totalDeleted", c)
+	}
+	return nil
+}
+
+// cleanSearchQuery normalizes the user query, returning an empty string when it is too short
+// to search for
+func cleanSearchQuery(q string) string {
 	q = strings.TrimSpace(q)
 	q = strings.TrimSuffix(q, "*")
 	if len(q) < 2 {
+		return ""
+	}
+	return q
+}
+
+func (r sqlRepository) doSearch(q string, offset, size int, results interface{}, orderBys ...string) error {
+	if q = cleanSearchQuery(q); q == "" {
 		return nil
 	}
+	sq := r.searchSelect(q, orderBys...).Limit(uint64(size)).Offset(uint64(offset))
+	return r.queryAll(sq, results, model.QueryOptions{Offset: offset})
+}
+
+// doSearchWithTotal is doSearch plus a companion count query with the same predicate, so
+// callers can report the total number of matches. Both queries are built by searchSelect,
+// keeping the predicates from drifting apart
+func (r sqlRepository) doSearchWithTotal(q string, offset, size int, results interface{}, orderBys ...string) (int64, error) {
+	if q = cleanSearchQuery(q); q == "" {
+		return 0, nil
+	}
+	total, err := r.count(r.searchSelect(q))
+	if err != nil {
+		return 0, err
+	}
+	sq := r.searchSelect(q, orderBys...).Limit(uint64(size)).Offset(uint64(offset))
+	return total, r.queryAll(sq, results, model.QueryOptions{Offset: offset})
+}
 
+// searchSelect builds the select with the search predicate and ordering applied, shared by the
+// page and count queries
+func (r sqlRepository) searchSelect(q string, orderBys ...string) SelectBuilder {
 	sq := r.newSelectWithAnnotation(r.tableName + ".id").Columns(r.tableName + ".*")
-	filter := fullTextExpr(q)
+	var filter Sqlizer
+	// The FTS5 index only knows about word prefixes, so when SearchFullString is enabled the
+	// query still has to scan full_text with the LIKE filters
+	if match := ftsQuery(q); match != "" && !conf.Server.SearchFullString && r.ftsAvailable() {
+		ftsTable := r.tableName + "_fts"
+		sq = sq.Join(ftsTable + " on " + ftsTable + ".id = " + r.tableName + ".id")
+		filter = Expr(ftsTable+" match ?", match)
+		orderBys = append(orderBys, ftsTable+".rank")
+	} else {
+		filter = fullTextExpr("full_text", q)
+	}
 	if filter != nil {
 		sq = sq.Where(filter)
 		if len(orderBys) > 0 {
@@ -33,12 +133,33 @@ func (r sqlRepository) doSearch(q string, offset, size int, results interface{},
 		// This is to speed up the results of `search3?query=""`, for OpenSubsonic
 		sq = sq.OrderBy("id")
 	}
-	sq = sq.Limit(uint64(size)).Offset(uint64(offset))
-	err := r.queryAll(sq, results, model.QueryOptions{Offset: offset})
-	return err
+	return sq
+}
+
+// escapeLike escapes the LIKE wildcards, so user provided values are always matched literally.
+// Expressions built with it must use likeExpr (or add an `ESCAPE '\'` clause themselves).
+var escapeLike = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func likeExpr(field string, pattern string) Sqlizer {
+	return Expr(field+` LIKE ? ESCAPE '\'`, pattern)
 }
 
-func fullTextExpr(value string) Sqlizer {
+// ftsQuery converts the user query to an FTS5 prefix match, applying the same normalization
+// used when indexing full_text. Words are separate terms, keeping the implicit AND semantics
+// of the LIKE filters
+func ftsQuery(value string) string {
+	q := str.SanitizeStrings(value)
+	if q == "" {
+		return ""
+	}
+	parts := strings.Split(q, " ")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(foldQueryWord(part), `"`, `""`) + `"*`
+	}
+	return strings.Join(parts, " ")
+}
+
+func fullTextExpr(field string, value string) Sqlizer {
 	q := str.SanitizeStrings(value)
 	if q == "" {
 		return nil
@@ -50,7 +171,17 @@ func fullTextExpr(value string) Sqlizer {
 	parts := strings.Split(q, " ")
 	filters := And{}
 	for _, part := range parts {
-		filters = append(filters, Like{"full_text": "%" + sep + part + "%"})
+		part = foldQueryWord(part)
+		filters = append(filters, likeExpr(field, "%"+sep+escapeLike.Replace(part)+"%"))
 	}
 	return filters
 }
+
+// foldQueryWord applies the same punctuation folding done by getFullText, so punctuated
+// queries ("r.e.m.") match the folded variant stored in full_text
+func foldQueryWord(part string) string {
+	if folded := str.FoldPunctuation(part); folded != "" {
+		return folded
+	}
+	return part
+}
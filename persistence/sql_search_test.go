@@ -1,6 +1,13 @@
 package persistence
 
 import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -10,5 +17,122 @@ var _ = Describe("sqlRepository", func() {
 		It("prefixes with a space", func() {
 			Expect(getFullText("legiao urbana")).To(Equal(" legiao urbana"))
 		})
+		It("stores a folded variant next to punctuated words", func() {
+			Expect(getFullText("R.E.M.")).To(Equal(" r.e.m. rem"))
+		})
+		It("leaves CJK text untouched", func() {
+			Expect(getFullText("残酷な天使のテーゼ")).To(Equal(" 残酷な天使のテーゼ"))
+		})
+	})
+
+	Describe("ftsQuery", func() {
+		It("quotes each word as a prefix term", func() {
+			Expect(ftsQuery("legiao urbana")).To(Equal(`"legiao"* "urbana"*`))
+		})
+		It("normalizes the query the same way full_text is indexed", func() {
+			Expect(ftsQuery("Legião")).To(Equal(`"legiao"*`))
+		})
+		It("returns empty for empty queries", func() {
+			Expect(ftsQuery("  ")).To(BeEmpty())
+		})
+	})
+
+	Describe("Search", func() {
+		var repo *mediaFileRepository
+
+		search := func(q string) []string {
+			res, err := repo.Search(q, 0, 100)
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			for _, mf := range res {
+				ids = append(ids, mf.ID)
+			}
+			return ids
+		}
+
+		BeforeEach(func() {
+			ctx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+			repo = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+			Expect(NewArtistRepository(ctx, NewDBXBuilder(db.Db())).Put(&model.Artist{ID: "fts-art", Name: "Brian Eno"})).To(Succeed())
+			producer := model.Participations{}
+			producer.Add(model.RoleProducer, model.Participant{ID: "fts-art", Name: "Brian Eno"})
+			for _, mf := range []model.MediaFile{
+				{LibraryID: 1, ID: "fts-1", Title: "Céu Azul", Path: "/fts/1.mp3"},
+				{LibraryID: 1, ID: "fts-2", Title: "Blue Sky", Path: "/fts/2.mp3"},
+				{LibraryID: 1, ID: "fts-3", Title: "Azul Profundo", Path: "/fts/3.mp3"},
+				{LibraryID: 1, ID: "fts-4", Title: "Eno Song", OrderTitle: "eno song", Path: "/fts/4.mp3"},
+				{LibraryID: 1, ID: "fts-5", Title: "Something", Artist: "Brian Eno", OrderArtistName: "brian eno", Path: "/fts/5.mp3"},
+				{LibraryID: 1, ID: "fts-6", Title: "Deep Blue", Participations: producer, Path: "/fts/6.mp3"},
+				{LibraryID: 1, ID: "fts-7", Title: "Losing My Religion", Artist: "R.E.M.", Path: "/fts/7.mp3"},
+			} {
+				Expect(repo.Put(&mf)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			for _, del := range []squirrel.DeleteBuilder{
+				squirrel.Delete("media_file_artists").Where(squirrel.Like{"media_file_id": "fts-%"}),
+				squirrel.Delete("media_file").Where(squirrel.Like{"id": "fts-%"}),
+				squirrel.Delete("artist").Where(squirrel.Like{"id": "fts-%"}),
+			} {
+				_, err := repo.executeSQL(del)
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+
+		It("matches word prefixes, ignoring case and accents", func() {
+			Expect(search("azul")).To(ConsistOf("fts-1", "fts-3"))
+			Expect(search("blu")).To(ConsistOf("fts-2", "fts-6"))
+		})
+
+		It("requires all words to match", func() {
+			Expect(search("azul céu")).To(ConsistOf("fts-1"))
+			Expect(search("azul sky")).To(BeEmpty())
+		})
+
+		It("ranks title matches first, then artist, then other participants", func() {
+			Expect(search("eno")).To(Equal([]string{"fts-4", "fts-5", "fts-6"}))
+		})
+
+		It("ignores punctuation in names and queries", func() {
+			Expect(search("rem")).To(ConsistOf("fts-7"))
+			Expect(search("r.e.m.")).To(ConsistOf("fts-7"))
+		})
+
+		It("restricts the search to the given role", func() {
+			Expect(search("producer:eno")).To(ConsistOf("fts-6"))
+			Expect(search("composer:eno")).To(BeEmpty())
+		})
+
+		It("returns the total number of matches along with the page", func() {
+			results, total, err := repo.SearchWithTotal("azul", 0, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(1))
+			Expect(total).To(Equal(int64(2)))
+		})
+
+		It("returns the total even when the offset is past the end", func() {
+			results, total, err := repo.SearchWithTotal("azul", 10, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(BeEmpty())
+			Expect(total).To(Equal(int64(2)))
+		})
+
+		It("counts role-qualified searches", func() {
+			results, total, err := repo.SearchWithTotal("producer:eno", 0, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(1))
+			Expect(total).To(Equal(int64(1)))
+		})
+
+		It("returns the same results from the FTS5 index and the LIKE scan", func() {
+			if !repo.ftsAvailable() {
+				Skip("FTS5 is not available in this build")
+			}
+			ftsResults := search("azul")
+			fts5Support.Store("media_file", false)
+			defer fts5Support.Delete("media_file")
+			Expect(search("azul")).To(ConsistOf(ftsResults))
+		})
 	})
 })
@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+)
+
+// updateTags persists the tags of the given item, keeping the tag table in sync. The links are
+// diffed against the existing rows, so only added and removed associations touch the table,
+// instead of replacing all rows on every scan. Unused tags are left behind, to be removed by
+// PurgeUnused during the scan garbage collection.
+func (r sqlRepository) updateTags(itemID string, tags model.Tags) error {
+	itemType := r.getTableName()
+	sel := Select("tag_id").From("item_tags").Where(And{Eq{"item_id": itemID}, Eq{"item_type": itemType}})
+	var existing []struct{ TagID string }
+	if err := r.queryAll(sel, &existing); err != nil {
+		return err
+	}
+	current := map[string]struct{}{}
+	for _, t := range existing {
+		current[t.TagID] = struct{}{}
+	}
+
+	var added model.Tags
+	wanted := map[string]struct{}{}
+	for _, t := range tags {
+		wanted[t.ID] = struct{}{}
+		if _, ok := current[t.ID]; !ok {
+			added = append(added, t)
+		}
+	}
+	var removed []string
+	for id := range current {
+		if _, ok := wanted[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+
+	if len(removed) > 0 {
+		del := Delete("item_tags").Where(And{Eq{"item_id": itemID}, Eq{"item_type": itemType},
+			Eq{"tag_id": removed}})
+		if _, err := r.executeSQL(del); err != nil {
+			return err
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	ins := Insert("tag").Columns("id", "tag_name", "tag_value").
+		Suffix("on conflict (id) do nothing")
+	links := Insert("item_tags").Columns("item_id", "item_type", "tag_id").
+		Suffix("on conflict (item_id, item_type, tag_id) do nothing")
+	for _, t := range added {
+		ins = ins.Values(t.ID, t.TagName, t.TagValue)
+		links = links.Values(itemID, itemType, t.ID)
+	}
+	if _, err := r.executeSQL(ins); err != nil {
+		return err
+	}
+	_, err := r.executeSQL(links)
+	return err
+}
+
+// tagIDFilter matches items carrying the given tag, through the item_tags link table
+func tagIDFilter(tableName string) func(string, interface{}) Sqlizer {
+	return func(_ string, value interface{}) Sqlizer {
+		return Expr("exists (select 1 from item_tags where item_id = "+tableName+".id and item_type = '"+tableName+"' and tag_id = ?)", value)
+	}
+}
+
+// cleanItemTags removes tag links pointing to items that no longer exist
+func (r sqlRepository) cleanItemTags() error {
+	itemType := r.getTableName()
+	del := Delete("item_tags").Where(And{
+		Eq{"item_type": itemType},
+		Expr("item_id not in (select id from " + itemType + ")"),
+	})
+	_, err := r.executeSQL(del)
+	return err
+}
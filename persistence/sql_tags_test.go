@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("updateTags and updateParticipations", func() {
+	var mr model.MediaFileRepository
+	var helper *mediaFileRepository
+
+	tagHappy := model.NewTag("mood", "Happy")
+	tagSad := model.NewTag("mood", "Sad")
+	tagGrouping := model.NewTag("grouping", "Classics")
+
+	tagLinks := func(id string) []string {
+		sel := squirrel.Select("tag_id").From("item_tags").
+			Where(squirrel.And{squirrel.Eq{"item_id": id}, squirrel.Eq{"item_type": "media_file"}})
+		var rows []struct{ TagID string }
+		Expect(helper.queryAll(sel, &rows)).To(Succeed())
+		var ids []string
+		for _, row := range rows {
+			ids = append(ids, row.TagID)
+		}
+		return ids
+	}
+
+	participationLinks := func(id string) []string {
+		sel := squirrel.Select("artist_id", "role").From("media_file_artists").
+			Where(squirrel.Eq{"media_file_id": id})
+		var rows []struct {
+			ArtistID string
+			Role     string
+		}
+		Expect(helper.queryAll(sel, &rows)).To(Succeed())
+		var links []string
+		for _, row := range rows {
+			links = append(links, row.Role+":"+row.ArtistID)
+		}
+		return links
+	}
+
+	var tr model.TagRepository
+
+	BeforeEach(func() {
+		ctx := log.NewContext(context.TODO())
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+		tr = NewTagRepository(ctx, NewDBXBuilder(db.Db()))
+		helper = mr.(*mediaFileRepository)
+	})
+
+	AfterEach(func() {
+		Expect(mr.Delete("df-1")).To(Succeed())
+		Expect(helper.cleanItemTags()).To(Succeed())
+		Expect(helper.cleanParticipations()).To(Succeed())
+		Expect(tr.PurgeUnused()).To(Succeed())
+	})
+
+	It("removes stale tag links when the tags change between scans", func() {
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "df-1", Path: P("/diffed/one.mp3"),
+			Tags: model.Tags{tagHappy, tagGrouping}})).To(Succeed())
+		Expect(tagLinks("df-1")).To(ConsistOf(tagHappy.ID, tagGrouping.ID))
+
+		// A "retagged" file replaces one value and keeps the other
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "df-1", Path: P("/diffed/one.mp3"),
+			Tags: model.Tags{tagSad, tagGrouping}})).To(Succeed())
+		Expect(tagLinks("df-1")).To(ConsistOf(tagSad.ID, tagGrouping.ID))
+
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "df-1", Path: P("/diffed/one.mp3")})).To(Succeed())
+		Expect(tagLinks("df-1")).To(BeEmpty())
+	})
+
+	It("removes stale participation links when the credits change between scans", func() {
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "df-1", Path: P("/diffed/one.mp3"),
+			Participations: model.Participations{
+				model.RoleComposer: []model.Participant{{ID: "pa-1", Name: "Composer"}},
+				model.RoleProducer: []model.Participant{{ID: "pa-2", Name: "Producer"}},
+			}})).To(Succeed())
+		Expect(participationLinks("df-1")).To(ConsistOf("composer:pa-1", "producer:pa-2"))
+
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "df-1", Path: P("/diffed/one.mp3"),
+			Participations: model.Participations{
+				model.RoleComposer: []model.Participant{{ID: "pa-1", Name: "Composer"}},
+			}})).To(Succeed())
+		Expect(participationLinks("df-1")).To(ConsistOf("composer:pa-1"))
+	})
+})
@@ -0,0 +1,213 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// MergeTagValues folds variant spellings of a tag value (ex: "Hip Hop", "hiphop") into a single
+// canonical one. Genres ("genre") live in their own tables, so they get their link tables and
+// the denormalized genre columns rewritten; all other tag names are merged through the
+// tag/item_tags tables. The applied mapping is recorded under consts.TagMappingsKey, so the
+// scanner keeps mapping the variants to the canonical value on future scans (see
+// model.TagMappings); without it, the next scan would re-create the variants from the file tags.
+func (s *SQLStore) MergeTagValues(ctx context.Context, tagName string, from []string, to string, dryRun bool) (int64, error) {
+	tagName = strings.ToLower(strings.TrimSpace(tagName))
+	to = strings.TrimSpace(to)
+	if tagName == "" || to == "" {
+		return 0, fmt.Errorf("merging tag values: tag name and target value are required")
+	}
+	var variants []string
+	for _, v := range from {
+		if v = strings.TrimSpace(v); v != "" && v != to {
+			variants = append(variants, v)
+		}
+	}
+	if len(variants) == 0 {
+		return 0, fmt.Errorf("merging tag values: no values to merge into %q", to)
+	}
+
+	var affected int64
+	err := s.WithTx(func(tx model.DataStore) error {
+		var err error
+		if tagName == "genre" {
+			affected, err = mergeGenreValues(ctx, tx, variants, to, dryRun)
+		} else {
+			affected, err = mergeItemTagValues(ctx, tx, tagName, variants, to, dryRun)
+		}
+		if err != nil || dryRun {
+			return err
+		}
+		return recordTagMapping(ctx, tx, tagName, variants, to)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !dryRun {
+		log.Info(ctx, "Merged tag values", "tag", tagName, "from", variants, "to", to, "affected", affected)
+	}
+	return affected, nil
+}
+
+// mergeGenreValues re-points the genre link tables and the denormalized genre columns on
+// media_file and album from the variant genre rows to the canonical one, then deletes the
+// variant rows
+func mergeGenreValues(ctx context.Context, tx model.DataStore, variants []string, to string, dryRun bool) (int64, error) {
+	repo := tx.Genre(ctx).(*genreRepository).sqlRepository
+
+	var rows []struct{ ID, Name string }
+	err := repo.queryAll(Select("id", "name").From("genre").Where(Eq{"name": variants}), &rows)
+	if err != nil {
+		return 0, err
+	}
+	var variantIDs []string
+	for _, r := range rows {
+		variantIDs = append(variantIDs, r.ID)
+	}
+
+	linkTables := []struct{ table, fk string }{
+		{"media_file_genres", "media_file_id"},
+		{"album_genres", "album_id"},
+		{"artist_genres", "artist_id"},
+	}
+	var affected int64
+	for _, lt := range linkTables {
+		count := Select("count(*) as count").From(lt.table).Where(Eq{"genre_id": variantIDs})
+		var res struct{ Count int64 }
+		if err := repo.queryOne(count, &res); err != nil {
+			return affected, err
+		}
+		affected += res.Count
+	}
+	for _, table := range []string{"media_file", "album"} {
+		count := Select("count(*) as count").From(table).Where(Eq{"genre": variants})
+		var res struct{ Count int64 }
+		if err := repo.queryOne(count, &res); err != nil {
+			return affected, err
+		}
+		affected += res.Count
+	}
+	if dryRun || len(variantIDs) == 0 {
+		return affected, nil
+	}
+
+	// Genre ids are not deterministic, so the canonical row is upserted by name
+	canonical := model.Genre{Name: to}
+	if err := tx.Genre(ctx).Put(&canonical); err != nil {
+		return affected, err
+	}
+	for _, lt := range linkTables {
+		// Items linked to both a variant and the canonical genre would end up with a duplicate
+		// link, so those variant links are dropped instead of re-pointed
+		dup := Delete(lt.table).Where(And{Eq{"genre_id": variantIDs},
+			Expr("exists (select 1 from "+lt.table+" dup where dup."+lt.fk+" = "+lt.table+"."+lt.fk+
+				" and dup.genre_id = ?)", canonical.ID)})
+		if _, err := repo.executeSQL(dup); err != nil {
+			return affected, err
+		}
+		upd := Update(lt.table).Set("genre_id", canonical.ID).Where(Eq{"genre_id": variantIDs})
+		if _, err := repo.executeSQL(upd); err != nil {
+			return affected, err
+		}
+	}
+	for _, table := range []string{"media_file", "album"} {
+		upd := Update(table).Set("genre", to).Where(Eq{"genre": variants})
+		if _, err := repo.executeSQL(upd); err != nil {
+			return affected, err
+		}
+	}
+	_, err = repo.executeSQL(Delete("genre").Where(Eq{"id": variantIDs}))
+	return affected, err
+}
+
+// mergeItemTagValues re-points item_tags rows from the variant tag ids to the canonical one.
+// Tag ids are derived from the lowercased value (see model.NewTag), so a variant differing from
+// the target only in case shares its row, which just gets its display value updated
+func mergeItemTagValues(ctx context.Context, tx model.DataStore, tagName string, variants []string, to string, dryRun bool) (int64, error) {
+	repo := tx.Tag(ctx).(*tagRepository).sqlRepository
+
+	canonical := model.NewTag(tagName, to)
+	var variantIDs []string
+	for _, v := range variants {
+		if id := model.NewTag(tagName, v).ID; id != canonical.ID {
+			variantIDs = append(variantIDs, id)
+		}
+	}
+
+	count := Select("count(*) as count").From("item_tags").Where(Eq{"tag_id": variantIDs})
+	var res struct{ Count int64 }
+	if err := repo.queryOne(count, &res); err != nil {
+		return 0, err
+	}
+	affected := res.Count
+	if dryRun {
+		return affected, nil
+	}
+
+	if err := tx.Tag(ctx).Put(&canonical); err != nil {
+		return affected, err
+	}
+	// Tag.Put keeps the first-seen casing, so the canonical casing is enforced explicitly
+	if _, err := repo.executeSQL(Update("tag").Set("tag_value", to).Where(Eq{"id": canonical.ID})); err != nil {
+		return affected, err
+	}
+	if len(variantIDs) == 0 {
+		return affected, nil
+	}
+	// Items carrying both a variant and the canonical tag would end up with a duplicate link,
+	// so those variant links are dropped instead of re-pointed
+	dup := Delete("item_tags").Where(And{Eq{"tag_id": variantIDs},
+		Expr("exists (select 1 from item_tags dup where dup.item_id = item_tags.item_id"+
+			" and dup.item_type = item_tags.item_type and dup.tag_id = ?)", canonical.ID)})
+	if _, err := repo.executeSQL(dup); err != nil {
+		return affected, err
+	}
+	upd := Update("item_tags").Set("tag_id", canonical.ID).Where(Eq{"tag_id": variantIDs})
+	if _, err := repo.executeSQL(upd); err != nil {
+		return affected, err
+	}
+	_, err := repo.executeSQL(Delete("tag").Where(Eq{"id": variantIDs}))
+	return affected, err
+}
+
+// recordTagMapping merges the applied variants into the mappings stored under
+// consts.TagMappingsKey. Mappings previously targeting one of the variants are re-pointed, so
+// chained merges keep resolving to the final value
+func recordTagMapping(ctx context.Context, tx model.DataStore, tagName string, variants []string, to string) error {
+	value, err := tx.Property(ctx).DefaultGet(consts.TagMappingsKey, "")
+	if err != nil {
+		return err
+	}
+	mappings := model.TagMappings{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &mappings); err != nil {
+			return err
+		}
+	}
+	if mappings[tagName] == nil {
+		mappings[tagName] = map[string]string{}
+	}
+	for _, v := range variants {
+		mappings[tagName][strings.ToLower(v)] = to
+	}
+	for from, target := range mappings[tagName] {
+		for _, v := range variants {
+			if strings.EqualFold(target, v) {
+				mappings[tagName][from] = to
+			}
+		}
+	}
+	delete(mappings[tagName], strings.ToLower(to))
+	data, err := json.Marshal(mappings)
+	if err != nil {
+		return err
+	}
+	return tx.Property(ctx).Put(consts.TagMappingsKey, string(data))
+}
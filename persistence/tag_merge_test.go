@@ -0,0 +1,174 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MergeTagValues", func() {
+	var ds model.DataStore
+	var ctx context.Context
+	var helper sqlRepository
+
+	countRows := func(table string, filters squirrel.Sqlizer) int64 {
+		sel := squirrel.Select("count(*) as count").From(table).Where(filters)
+		var res struct{ Count int64 }
+		Expect(helper.queryOne(sel, &res)).To(Succeed())
+		return res.Count
+	}
+
+	BeforeEach(func() {
+		ctx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db())).sqlRepository
+
+		DeferCleanup(func() {
+			for _, del := range []squirrel.DeleteBuilder{
+				squirrel.Delete("media_file_genres").Where(squirrel.Like{"media_file_id": "tm-%"}),
+				squirrel.Delete("album_genres").Where(squirrel.Like{"album_id": "tm-%"}),
+				squirrel.Delete("item_tags").Where(squirrel.Like{"item_id": "tm-%"}),
+				squirrel.Delete("media_file").Where(squirrel.Like{"id": "tm-%"}),
+				squirrel.Delete("album").Where(squirrel.Like{"id": "tm-%"}),
+				squirrel.Delete("genre").Where(squirrel.Like{"id": "tm-%"}),
+			} {
+				_, err := helper.executeSQL(del)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(ds.Tag(ctx).PurgeUnused()).To(Succeed())
+			Expect(ds.Property(ctx).Delete(consts.TagMappingsKey)).To(Succeed())
+		})
+	})
+
+	Describe("genres", func() {
+		var hip1, hip2, hip3 model.Genre
+
+		BeforeEach(func() {
+			hip1 = model.Genre{ID: "tm-g1", Name: "Hip Hop"}
+			hip2 = model.Genre{ID: "tm-g2", Name: "hiphop"}
+			hip3 = model.Genre{ID: "tm-g3", Name: "Hip-Hop"}
+			for _, g := range []*model.Genre{&hip1, &hip2, &hip3} {
+				Expect(ds.Genre(ctx).Put(g)).To(Succeed())
+			}
+			Expect(ds.MediaFile(ctx).Put(&model.MediaFile{ID: "tm-1", LibraryID: 1, Path: "/tm/1.mp3",
+				Genre: "Hip Hop", Genres: model.Genres{hip1}})).To(Succeed())
+			// Carries both a variant and the canonical genre, so re-pointing would duplicate the link
+			Expect(ds.MediaFile(ctx).Put(&model.MediaFile{ID: "tm-2", LibraryID: 1, Path: "/tm/2.mp3",
+				Genre: "hiphop", Genres: model.Genres{hip2, hip3}})).To(Succeed())
+			Expect(ds.Album(ctx).Put(&model.Album{ID: "tm-al", LibraryID: 1, Name: "tm album",
+				Genre: "Hip Hop", Genres: model.Genres{hip1}})).To(Succeed())
+		})
+
+		It("reports the affected rows without changing anything in dry-run mode", func() {
+			affected, err := ds.MergeTagValues(ctx, "genre", []string{"Hip Hop", "hiphop"}, "Hip-Hop", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(affected).To(Equal(int64(6))) // 3 links + 2 media_file.genre + 1 album.genre
+
+			mf, err := ds.MediaFile(ctx).Get("tm-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mf.Genre).To(Equal("Hip Hop"))
+			Expect(countRows("genre", squirrel.Like{"id": "tm-g%"})).To(Equal(int64(3)))
+			_, err = ds.Property(ctx).Get(consts.TagMappingsKey)
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+
+		It("folds the variants into the canonical genre and records the mapping", func() {
+			affected, err := ds.MergeTagValues(ctx, "genre", []string{"Hip Hop", "hiphop"}, "Hip-Hop", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(affected).To(Equal(int64(6)))
+
+			mf, err := ds.MediaFile(ctx).Get("tm-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mf.Genre).To(Equal("Hip-Hop"))
+			Expect(mf.Genres).To(Equal(model.Genres{hip3}))
+
+			// The duplicate link is dropped instead of re-pointed
+			mf, err = ds.MediaFile(ctx).Get("tm-2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mf.Genre).To(Equal("Hip-Hop"))
+			Expect(mf.Genres).To(Equal(model.Genres{hip3}))
+
+			album, err := ds.Album(ctx).Get("tm-al")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(album.Genre).To(Equal("Hip-Hop"))
+
+			Expect(countRows("genre", squirrel.Eq{"id": []string{"tm-g1", "tm-g2"}})).To(BeZero())
+			value, err := ds.Property(ctx).Get(consts.TagMappingsKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(MatchJSON(`{"genre":{"hip hop":"Hip-Hop","hiphop":"Hip-Hop"}}`))
+		})
+
+		It("is idempotent", func() {
+			_, err := ds.MergeTagValues(ctx, "genre", []string{"Hip Hop", "hiphop"}, "Hip-Hop", false)
+			Expect(err).ToNot(HaveOccurred())
+			affected, err := ds.MergeTagValues(ctx, "genre", []string{"Hip Hop", "hiphop"}, "Hip-Hop", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(affected).To(BeZero())
+
+			mf, err := ds.MediaFile(ctx).Get("tm-2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mf.Genres).To(Equal(model.Genres{hip3}))
+		})
+
+		It("re-points previously recorded mappings on chained merges", func() {
+			_, err := ds.MergeTagValues(ctx, "genre", []string{"hiphop"}, "Hip Hop", false)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = ds.MergeTagValues(ctx, "genre", []string{"Hip Hop"}, "Hip-Hop", false)
+			Expect(err).ToNot(HaveOccurred())
+
+			value, err := ds.Property(ctx).Get(consts.TagMappingsKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(MatchJSON(`{"genre":{"hip hop":"Hip-Hop","hiphop":"Hip-Hop"}}`))
+		})
+	})
+
+	Describe("item tags", func() {
+		var chill, variant model.Tag
+
+		BeforeEach(func() {
+			chill = model.NewTag("mood", "Chill")
+			variant = model.NewTag("mood", "chill-out")
+			Expect(ds.MediaFile(ctx).Put(&model.MediaFile{ID: "tm-3", LibraryID: 1, Path: "/tm/3.mp3",
+				Tags: model.Tags{variant}})).To(Succeed())
+			Expect(ds.MediaFile(ctx).Put(&model.MediaFile{ID: "tm-4", LibraryID: 1, Path: "/tm/4.mp3",
+				Tags: model.Tags{variant, chill}})).To(Succeed())
+		})
+
+		It("re-points the item links and deletes the variant tag row", func() {
+			affected, err := ds.MergeTagValues(ctx, "mood", []string{"chill-out"}, "Chill", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(affected).To(Equal(int64(2)))
+
+			Expect(countRows("item_tags", squirrel.Eq{"tag_id": variant.ID})).To(BeZero())
+			Expect(countRows("item_tags", squirrel.And{squirrel.Eq{"tag_id": chill.ID},
+				squirrel.Like{"item_id": "tm-%"}})).To(Equal(int64(2)))
+			Expect(countRows("tag", squirrel.Eq{"id": variant.ID})).To(BeZero())
+
+			value, err := ds.Property(ctx).Get(consts.TagMappingsKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(MatchJSON(`{"mood":{"chill-out":"Chill"}}`))
+		})
+
+		It("updates the stored casing when the variant shares the canonical tag id", func() {
+			_, err := ds.MergeTagValues(ctx, "mood", []string{"chill-out"}, "Chill-Out", false)
+			Expect(err).ToNot(HaveOccurred())
+
+			var row struct{ TagValue string }
+			sel := squirrel.Select("tag_value").From("tag").Where(squirrel.Eq{"id": variant.ID})
+			Expect(helper.queryOne(sel, &row)).To(Succeed())
+			Expect(row.TagValue).To(Equal("Chill-Out"))
+		})
+
+		It("refuses to merge a value into itself", func() {
+			_, err := ds.MergeTagValues(ctx, "mood", []string{"Chill"}, "Chill", false)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,114 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/deluan/rest"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/pocketbase/dbx"
+)
+
+type tagRepository struct {
+	sqlRepository
+	sqlRestful
+}
+
+func NewTagRepository(ctx context.Context, db dbx.Builder) model.TagRepository {
+	r := &tagRepository{}
+	r.ctx = ctx
+	r.db = db
+	r.tableName = "tag"
+	r.filterMappings = map[string]filterFunc{
+		"tag_name":  tagNameFilter,
+		"tag_value": startsWithFilter,
+	}
+	r.sortMappings = map[string]string{
+		"value": "tag_value",
+		"usage": "song_count",
+	}
+	return r
+}
+
+// tagNameFilter folds the given name to lowercase before matching, since tag names are stored
+// in their canonical (lowercase) form (see model.NewTag)
+func tagNameFilter(field string, value interface{}) Sqlizer {
+	return Eq{field: strings.ToLower(fmt.Sprint(value))}
+}
+
+// selectTag returns the tags with their usage counts, grouped by item type
+func (r *tagRepository) selectTag(opt ...model.QueryOptions) SelectBuilder {
+	return r.newSelect(opt...).Columns(
+		"tag.id",
+		"tag.tag_name",
+		"tag.tag_value",
+		"coalesce(a.album_count, 0) as album_count",
+		"coalesce(m.song_count, 0) as song_count",
+	).
+		LeftJoin(`(select it.tag_id, count(it.item_id) as album_count from item_tags it
+			where it.item_type = 'album' group by it.tag_id) a on a.tag_id = tag.id`).
+		LeftJoin(`(select it.tag_id, count(it.item_id) as song_count from item_tags it
+			where it.item_type = 'media_file' group by it.tag_id) m on m.tag_id = tag.id`)
+}
+
+func (r *tagRepository) GetAll(opt ...model.QueryOptions) (model.Tags, error) {
+	sq := r.selectTag(opt...)
+	res := model.Tags{}
+	err := r.queryAll(sq, &res)
+	return res, err
+}
+
+// Put is an Upsert operation: tag IDs are deterministic, derived from the name/value pair, so
+// inserting an existing tag is a no-op
+func (r *tagRepository) Put(t *model.Tag) error {
+	if t.ID == "" {
+		*t = model.NewTag(t.TagName, t.TagValue)
+	}
+	sq := Insert(r.tableName).Columns("id", "tag_name", "tag_value").
+		Values(t.ID, t.TagName, t.TagValue).
+		Suffix("on conflict (id) do nothing")
+	_, err := r.executeSQL(sq)
+	return err
+}
+
+// PurgeUnused removes tags that are no longer referenced by any item. It is called by the scan
+// garbage collection.
+func (r *tagRepository) PurgeUnused() error {
+	del := Delete(r.tableName).Where(`id not in (select tag_id from item_tags)`)
+	c, err := r.executeSQL(del)
+	if err == nil {
+		if c > 0 {
+			log.Debug(r.ctx, "Purged unused tags", "totalDeleted", c)
+		}
+	}
+	return err
+}
+
+func (r *tagRepository) Count(options ...rest.QueryOptions) (int64, error) {
+	return r.count(Select(), r.parseRestOptions(options...))
+}
+
+func (r *tagRepository) Read(id string) (interface{}, error) {
+	sel := r.selectTag().Where(Eq{"tag.id": id})
+	var res model.Tag
+	err := r.queryOne(sel, &res)
+	return &res, err
+}
+
+func (r *tagRepository) ReadAll(options ...rest.QueryOptions) (interface{}, error) {
+	return r.GetAll(r.parseRestOptions(options...))
+}
+
+func (r *tagRepository) EntityName() string {
+	return r.tableName
+}
+
+func (r *tagRepository) NewInstance() interface{} {
+	return &model.Tag{}
+}
+
+var _ model.TagRepository = (*tagRepository)(nil)
+var _ model.ResourceRepository = (*tagRepository)(nil)
@@ -0,0 +1,99 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/deluan/rest"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TagRepository", func() {
+	var repo model.TagRepository
+	var mr model.MediaFileRepository
+
+	tagHappy := model.NewTag("mood", "Happy")
+	tagChill := model.NewTag("mood", "Chill")
+	tagGrouping := model.NewTag("grouping", "Classics")
+
+	BeforeEach(func() {
+		ctx := log.NewContext(context.TODO())
+		repo = NewTagRepository(ctx, NewDBXBuilder(db.Db()))
+		mr = NewMediaFileRepository(ctx, NewDBXBuilder(db.Db()))
+
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "9101", Path: P("/tagged/one.mp3"),
+			Tags: model.Tags{tagHappy, tagGrouping}})).To(Succeed())
+		Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "9102", Path: P("/tagged/two.mp3"),
+			Tags: model.Tags{tagHappy, tagChill}})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, id := range []string{"9101", "9102"} {
+			Expect(mr.Delete(id)).To(Succeed())
+		}
+		r := mr.(*mediaFileRepository)
+		Expect(r.cleanItemTags()).To(Succeed())
+		Expect(repo.PurgeUnused()).To(Succeed())
+	})
+
+	Describe("GetAll", func() {
+		It("returns tags filtered by tag_name, with their usage counts", func() {
+			tags, err := repo.GetAll(model.QueryOptions{Filters: squirrel.Eq{"tag_name": "mood"}, Sort: "value"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tags).To(HaveLen(2))
+			Expect(tags[0].TagValue).To(Equal("Chill"))
+			Expect(tags[0].SongCount).To(Equal(1))
+			Expect(tags[1].TagValue).To(Equal("Happy"))
+			Expect(tags[1].SongCount).To(Equal(2))
+		})
+
+		It("orders by usage count", func() {
+			tags, err := repo.GetAll(model.QueryOptions{Filters: squirrel.Eq{"tag_name": "mood"},
+				Sort: "usage", Order: "desc"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tags[0].TagValue).To(Equal("Happy"))
+		})
+
+		It("paginates the result", func() {
+			tags, err := repo.GetAll(model.QueryOptions{Filters: squirrel.Eq{"tag_name": "mood"},
+				Sort: "value", Offset: 1, Max: 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tags).To(HaveLen(1))
+			Expect(tags[0].TagValue).To(Equal("Happy"))
+		})
+	})
+
+	Describe("ReadAll", func() {
+		It("filters by tag_name through the REST options", func() {
+			res, err := repo.(*tagRepository).ReadAll(rest.QueryOptions{Filters: map[string]interface{}{"tag_name": "grouping"}})
+			Expect(err).ToNot(HaveOccurred())
+			tags := res.(model.Tags)
+			Expect(tags).To(HaveLen(1))
+			Expect(tags[0].TagValue).To(Equal("Classics"))
+		})
+
+		It("folds the tag_name filter to the canonical lowercase form", func() {
+			res, err := repo.(*tagRepository).ReadAll(rest.QueryOptions{Filters: map[string]interface{}{"tag_name": "MOOD"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.(model.Tags)).To(HaveLen(2))
+		})
+	})
+
+	Describe("PurgeUnused", func() {
+		It("removes tags with no references left", func() {
+			Expect(mr.Delete("9101")).To(Succeed())
+			Expect(mr.(*mediaFileRepository).cleanItemTags()).To(Succeed())
+			Expect(repo.PurgeUnused()).To(Succeed())
+
+			tags, err := repo.GetAll(model.QueryOptions{Filters: squirrel.Eq{"tag_name": "grouping"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tags).To(BeEmpty())
+
+			Expect(mr.Put(&model.MediaFile{LibraryID: 1, ID: "9101", Path: P("/tagged/one.mp3")})).To(Succeed())
+		})
+	})
+})
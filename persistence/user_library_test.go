@@ -0,0 +1,127 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Per-library access control", func() {
+	var ds model.DataStore
+	var adminCtx, userCtx context.Context
+	var helper sqlRepository
+	const hiddenLib = 910
+
+	BeforeEach(func() {
+		adminCtx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "userid", IsAdmin: true})
+		userCtx = request.WithUser(log.NewContext(context.TODO()), model.User{ID: "ul-user"})
+		ds = New(db.Db())
+		helper = NewMediaFileRepository(adminCtx, NewDBXBuilder(db.Db())).sqlRepository
+
+		Expect(ds.User(adminCtx).Put(&model.User{ID: "ul-user", UserName: "ul-user"})).To(Succeed())
+		Expect(ds.Library(adminCtx).Put(&model.Library{ID: hiddenLib, Name: "ul-hidden", Path: "/ul-hidden"})).To(Succeed())
+
+		// One artist, album and track in the default library and in the hidden one
+		Expect(ds.Artist(adminCtx).Put(&model.Artist{ID: "ul-ar1", Name: "ul artist granted"})).To(Succeed())
+		Expect(ds.Artist(adminCtx).Put(&model.Artist{ID: "ul-ar2", Name: "ul artist hidden"})).To(Succeed())
+		Expect(ds.Library(adminCtx).AddArtist(1, "ul-ar1")).To(Succeed())
+		Expect(ds.Library(adminCtx).AddArtist(hiddenLib, "ul-ar2")).To(Succeed())
+		Expect(ds.Album(adminCtx).Put(&model.Album{ID: "ul-al1", LibraryID: 1, Name: "ulgranted album", SongCount: 1})).To(Succeed())
+		Expect(ds.Album(adminCtx).Put(&model.Album{ID: "ul-al2", LibraryID: hiddenLib, Name: "ulhidden album", SongCount: 1})).To(Succeed())
+		mf := model.MediaFile{ID: "ul-1", LibraryID: 1, AlbumID: "ul-al1", Title: "ulgranted song", Path: "ul/1.mp3"}
+		Expect(ds.MediaFile(adminCtx).Put(&mf)).To(Succeed())
+		mf = model.MediaFile{ID: "ul-2", LibraryID: hiddenLib, AlbumID: "ul-al2", Title: "ulhidden song", Path: "ul/2.mp3"}
+		Expect(ds.MediaFile(adminCtx).Put(&mf)).To(Succeed())
+
+		Expect(ds.Library(adminCtx).GrantAccess(1, "ul-user")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		for _, del := range []squirrel.DeleteBuilder{
+			squirrel.Delete("user_library").Where(squirrel.Eq{"user_id": "ul-user"}),
+			squirrel.Delete("media_file").Where(squirrel.Like{"id": "ul-%"}),
+			squirrel.Delete("album").Where(squirrel.Like{"id": "ul-%"}),
+			squirrel.Delete("library_artist").Where(squirrel.Like{"artist_id": "ul-%"}),
+			squirrel.Delete("artist").Where(squirrel.Like{"id": "ul-%"}),
+			squirrel.Delete("library").Where(squirrel.Eq{"id": hiddenLib}),
+			squirrel.Delete("user").Where(squirrel.Eq{"id": "ul-user"}),
+		} {
+			_, err := helper.executeSQL(del)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("hides content from libraries the user was not granted", func() {
+		mfs, err := ds.MediaFile(userCtx).GetAll(model.QueryOptions{Filters: squirrel.Like{"media_file.id": "ul-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		Expect(mfs[0].ID).To(Equal("ul-1"))
+
+		albums, err := ds.Album(userCtx).GetAll(model.QueryOptions{Filters: squirrel.Like{"album.id": "ul-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(albums).To(HaveLen(1))
+		Expect(albums[0].ID).To(Equal("ul-al1"))
+
+		artists, err := ds.Artist(userCtx).GetAll(model.QueryOptions{Filters: squirrel.Like{"artist.id": "ul-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(artists).To(HaveLen(1))
+		Expect(artists[0].ID).To(Equal("ul-ar1"))
+	})
+
+	It("does not return search results from the hidden library", func() {
+		mfs, err := ds.MediaFile(userCtx).Search("ulhidden", 0, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(BeEmpty())
+
+		mfs, err = ds.MediaFile(userCtx).Search("ulgranted", 0, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		Expect(mfs[0].ID).To(Equal("ul-1"))
+
+		albums, err := ds.Album(userCtx).Search("ulhidden", 0, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(albums).To(BeEmpty())
+	})
+
+	It("keeps the restriction under random sort", func() {
+		mfs, err := ds.MediaFile(userCtx).GetAll(model.QueryOptions{
+			Sort: "random", Filters: squirrel.Like{"media_file.id": "ul-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		Expect(mfs[0].ID).To(Equal("ul-1"))
+	})
+
+	It("shows an empty library to a user with no grants", func() {
+		noneCtx := request.WithUser(log.NewContext(context.TODO()), model.User{ID: "ul-nobody"})
+		mfs, err := ds.MediaFile(noneCtx).GetAll(model.QueryOptions{Filters: squirrel.Like{"media_file.id": "ul-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(BeEmpty())
+		count, err := ds.MediaFile(noneCtx).CountAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+
+	It("does not filter admins", func() {
+		mfs, err := ds.MediaFile(adminCtx).GetAll(model.QueryOptions{Filters: squirrel.Like{"media_file.id": "ul-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(2))
+	})
+
+	It("stops hiding the library once access is granted, and again after it is revoked", func() {
+		Expect(ds.Library(adminCtx).GrantAccess(hiddenLib, "ul-user")).To(Succeed())
+		mfs, err := ds.MediaFile(userCtx).GetAll(model.QueryOptions{Filters: squirrel.Like{"media_file.id": "ul-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(2))
+
+		Expect(ds.Library(adminCtx).RevokeAccess(hiddenLib, "ul-user")).To(Succeed())
+		mfs, err = ds.MediaFile(userCtx).GetAll(model.QueryOptions{Filters: squirrel.Like{"media_file.id": "ul-%"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+	})
+})
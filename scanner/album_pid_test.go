@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// perFileExtractor decorates another metadata.Extractor, replacing selected tags of individual
+// files, keyed by the file's base name. It stands in for files tagged differently from each
+// other, which the single fixture file cannot provide
+type perFileExtractor struct {
+	metadata.Extractor
+	overrides map[string]metadata.ParsedTags
+}
+
+func (e *perFileExtractor) Parse(files ...string) (map[string]metadata.ParsedTags, error) {
+	parsed, err := e.Extractor.Parse(files...)
+	if err != nil {
+		return nil, err
+	}
+	for path, tags := range parsed {
+		for name, values := range e.overrides[filepath.Base(path)] {
+			tags[name] = values
+		}
+	}
+	return parsed, nil
+}
+
+var _ = Describe("Scanner album re-grouping", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var s FolderScanner
+	var root, dir string
+	var progress chan uint32
+
+	theAlbums := func() model.Albums {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).ToNot(BeEmpty())
+		ids := map[string]struct{}{}
+		for _, mf := range mfs {
+			ids[mf.AlbumID] = struct{}{}
+		}
+		var albums model.Albums
+		for id := range ids {
+			al, err := ds.Album(ctx).Get(id)
+			Expect(err).ToNot(HaveOccurred())
+			albums = append(albums, *al)
+		}
+		return albums
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "album")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track1.mp3"), data, 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "track2.mp3"), data, 0600)).To(Succeed())
+
+		extractor := &perFileExtractor{Extractor: &taglib.Extractor{}, overrides: map[string]metadata.ParsedTags{
+			"track1.mp3": {"releasedate": []string{"2001-01-01"}, "title": []string{"One"}},
+			"track2.mp3": {"releasedate": []string{"2002-02-02"}, "title": []string{"Two"}},
+		}}
+		metadata.RegisterExtractor("perFile", extractor)
+		previousExtractor := conf.Server.Scanner.Extractor
+		previousGrouping := conf.Server.Scanner.GroupAlbumReleases
+		conf.Server.Scanner.Extractor = "perFile"
+		conf.Server.Scanner.GroupAlbumReleases = false
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previousExtractor
+			conf.Server.Scanner.GroupAlbumReleases = previousGrouping
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("keeps annotations when the releases are merged into one album", func() {
+		albums := theAlbums()
+		Expect(albums).To(HaveLen(2))
+		Expect(albums[0].PID).To(Equal(albums[1].PID))
+
+		// Star both releases and play them a few times
+		repo := ds.Album(ctx)
+		Expect(repo.SetStar(true, albums[0].ID)).To(Succeed())
+		time.Sleep(10 * time.Millisecond)
+		Expect(repo.SetStar(true, albums[1].ID)).To(Succeed())
+		Expect(repo.IncPlayCount(albums[0].ID, time.Now())).To(Succeed())
+		Expect(repo.IncPlayCount(albums[1].ID, time.Now())).To(Succeed())
+		Expect(repo.IncPlayCount(albums[1].ID, time.Now())).To(Succeed())
+		first, err := repo.Get(albums[0].ID)
+		Expect(err).ToNot(HaveOccurred())
+
+		conf.Server.Scanner.GroupAlbumReleases = true
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		merged := theAlbums()
+		Expect(merged).To(HaveLen(1))
+		Expect(merged[0].ID).ToNot(Or(Equal(albums[0].ID), Equal(albums[1].ID)))
+		Expect(merged[0].Starred).To(BeTrue())
+		Expect(merged[0].PlayCount).To(Equal(int64(3)))
+		// The earliest starred_at of the merged albums is kept
+		Expect(merged[0].StarredAt.UnixMilli()).To(Equal(first.StarredAt.UnixMilli()))
+	})
+
+	It("keeps the star when the album is split back into releases", func() {
+		conf.Server.Scanner.GroupAlbumReleases = true
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		albums := theAlbums()
+		Expect(albums).To(HaveLen(1))
+		Expect(ds.Album(ctx).SetStar(true, albums[0].ID)).To(Succeed())
+
+		conf.Server.Scanner.GroupAlbumReleases = false
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		split := theAlbums()
+		Expect(split).To(HaveLen(2))
+		for _, al := range split {
+			Expect(al.Starred).To(BeTrue(), al.Name)
+		}
+	})
+})
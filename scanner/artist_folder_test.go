@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scanner artist folder metadata", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var s FolderScanner
+	var root, artistDir, albumDir string
+	var progress chan uint32
+
+	const nfo = `<artist>
+	<name>Album Artist</name>
+	<biography>  An example biography.  </biography>
+	<genre>Post-Rock</genre>
+	<genre></genre>
+</artist>`
+
+	theArtist := func() model.Artist {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(albumDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).ToNot(BeEmpty())
+		artist, err := ds.Artist(ctx).Get(mfs[0].AlbumArtistID)
+		Expect(err).ToNot(HaveOccurred())
+		return *artist
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		// An artist folder: it directly contains the album folders of a single album artist,
+		// along with artist-level metadata files
+		root = GinkgoT().TempDir()
+		artistDir = filepath.Join(root, "Album Artist")
+		albumDir = filepath.Join(artistDir, "The Album")
+		Expect(os.MkdirAll(albumDir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(albumDir, "track.mp3"), data, 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(artistDir, "artist.jpg"), []byte("not a real image"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(artistDir, "artist.nfo"), []byte(nfo), 0600)).To(Succeed())
+
+		metadata.RegisterExtractor("plain", &taglib.Extractor{})
+		previousExtractor := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "plain"
+		conf.Server.Scanner.ArtistFolderMetadata = true
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previousExtractor
+			conf.Server.Scanner.ArtistFolderMetadata = false
+			_, _ = ds.MediaFile(ctx).DeleteByPath(albumDir)
+			_ = ds.GC(ctx, root)
+		})
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+	})
+
+	It("records the image path and parses the nfo fields into the artist", func() {
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		artist := theArtist()
+		Expect(artist.ImagePath).To(Equal(filepath.Join(artistDir, "artist.jpg")))
+		Expect(artist.NfoPath).To(Equal(filepath.Join(artistDir, "artist.nfo")))
+		Expect(artist.Biography).To(Equal("An example biography."))
+		var genres []string
+		for _, g := range artist.Genres {
+			genres = append(genres, g.Name)
+		}
+		Expect(genres).To(ContainElement("Post-Rock"))
+	})
+
+	It("picks up replaced metadata files on an incremental scan, via the folder modtime", func() {
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theArtist().Biography).To(Equal("An example biography."))
+
+		// Only the artist folder changes: the nfo is replaced after the last scan
+		lastScan := time.Now()
+		replaced := `<artist><biography>A rewritten biography.</biography></artist>`
+		nfoPath := filepath.Join(artistDir, "artist.nfo")
+		Expect(os.WriteFile(nfoPath, []byte(replaced), 0600)).To(Succeed())
+		future := time.Now().Add(time.Second)
+		Expect(os.Chtimes(nfoPath, future, future)).To(Succeed())
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root, LastScanAt: lastScan}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err = s.Scan(ctx, false, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theArtist().Biography).To(Equal("A rewritten biography."))
+	})
+
+	It("leaves the artist untouched when the detection is disabled", func() {
+		conf.Server.Scanner.ArtistFolderMetadata = false
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		artist := theArtist()
+		Expect(artist.ImagePath).To(BeEmpty())
+		Expect(artist.NfoPath).To(BeEmpty())
+		Expect(artist.Biography).To(BeEmpty())
+	})
+})
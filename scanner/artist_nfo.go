@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+// artistNfoFile is the conventional name of the artist metadata file (Kodi-style) looked for
+// in artist folders
+const artistNfoFile = "artist.nfo"
+
+// artistNfo holds the fields parsed from an artist.nfo file. Only the basic fields are
+// supported; everything else in the file is ignored
+type artistNfo struct {
+	Biography string   `xml:"biography"`
+	Genres    []string `xml:"genre"`
+}
+
+// loadArtistNfo reads and parses the artist.nfo file at the given path
+func loadArtistNfo(path string) (*artistNfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var nfo artistNfo
+	if err := xml.Unmarshal(data, &nfo); err != nil {
+		return nil, err
+	}
+	nfo.Biography = strings.TrimSpace(nfo.Biography)
+	genres := nfo.Genres[:0]
+	for _, g := range nfo.Genres {
+		if g = strings.TrimSpace(g); g != "" {
+			genres = append(genres, g)
+		}
+	}
+	nfo.Genres = genres
+	return &nfo, nil
+}
+
+// isArtistImageFile reports whether the file name is an artist image (ex: artist.jpg)
+func isArtistImageFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.EqualFold(base, "artist") && model.IsImageFile(name)
+}
@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scanner audio type filters", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var s FolderScanner
+	var root, dir string
+	var progress chan uint32
+
+	theTrack := func() model.MediaFile {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		return mfs[0]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "artist")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), data, 0600)).To(Succeed())
+
+		metadata.RegisterExtractor("plain", &taglib.Extractor{})
+		previousExtractor := conf.Server.Scanner.Extractor
+		previousExcluded := conf.Server.Scanner.ExcludedAudioTypes
+		conf.Server.Scanner.Extractor = "plain"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previousExtractor
+			conf.Server.Scanner.ExcludedAudioTypes = previousExcluded
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+	})
+
+	It("marks tracks excluded by a new denylist entry as missing, and restores them when it is removed", func() {
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Missing).To(BeFalse())
+
+		// The format is denylisted after the first import: the track is no longer seen on disk,
+		// so the next full scan flags it as missing
+		conf.Server.Scanner.ExcludedAudioTypes = []string{"mp3"}
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Missing).To(BeTrue())
+
+		// Removing the rule brings the track back on the next scan
+		conf.Server.Scanner.ExcludedAudioTypes = nil
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Missing).To(BeFalse())
+	})
+})
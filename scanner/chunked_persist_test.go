@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// chunkExtractor synthesizes tags for any file, so tests can exercise folders with tens of
+// thousands of tracks without paying for real metadata extraction
+type chunkExtractor struct{}
+
+func (chunkExtractor) Parse(files ...string) (map[string]metadata.ParsedTags, error) {
+	result := map[string]metadata.ParsedTags{}
+	for _, file := range files {
+		result[file] = metadata.ParsedTags{
+			"title":  []string{filepath.Base(file)},
+			"artist": []string{"Chunk Artist"},
+			"album":  []string{"Chunk Album"},
+		}
+	}
+	return result, nil
+}
+func (chunkExtractor) CustomMappings() metadata.ParsedTags { return metadata.ParsedTags{} }
+func (chunkExtractor) Version() string                     { return "1" }
+
+var _ = Describe("Chunked track persistence", func() {
+	var ctx context.Context
+	var s *TagScanner
+	var ref *refresher
+	var root string
+
+	makeFiles := func(n int) []string {
+		files := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			path := filepath.Join(root, fmt.Sprintf("track-%05d.mp3", i))
+			Expect(os.WriteFile(path, nil, 0600)).To(Succeed())
+			files = append(files, path)
+		}
+		return files
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		root = GinkgoT().TempDir()
+		metadata.RegisterExtractor("chunks", chunkExtractor{})
+
+		lib := model.Library{ID: 1, Path: root, Extractor: "chunks"}
+		s = &TagScanner{
+			lib:      lib,
+			cnt:      &counters{},
+			tagUsage: map[string]int64{},
+			metrics:  getScanMetrics(),
+			mapper:   NewMediaFileMapper(root, nil, nil),
+		}
+		ref = newRefresher(&noopCacheWarmer{}, lib, dirMap{})
+	})
+
+	It("streams one chunk at a time to the persist stage", func() {
+		const numFiles = 20_000
+		files := makeFiles(numFiles)
+
+		var chunkSizes []int
+		count, err := s.loadTracksToUpdate(ctx, ref, root, map[string]model.MediaFile{}, files,
+			func(chunk model.MediaFiles) error {
+				chunkSizes = append(chunkSizes, len(chunk))
+				return nil
+			})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(numFiles))
+
+		// The persist stage never sees more than one batch worth of tracks at a time
+		Expect(chunkSizes).To(HaveLen(numFiles / filesBatchSize))
+		for _, size := range chunkSizes {
+			Expect(size).To(BeNumerically("<=", filesBatchSize))
+		}
+	})
+
+	It("stops loading chunks when the persist stage fails", func() {
+		files := makeFiles(3 * filesBatchSize)
+
+		boom := errors.New("disk full")
+		calls := 0
+		count, err := s.loadTracksToUpdate(ctx, ref, root, map[string]model.MediaFile{}, files,
+			func(chunk model.MediaFiles) error {
+				calls++
+				if calls == 2 {
+					return boom
+				}
+				return nil
+			})
+		Expect(err).To(MatchError(boom))
+		Expect(calls).To(Equal(2))
+		Expect(count).To(Equal(filesBatchSize)) // only the successfully persisted chunk is counted
+	})
+})
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"context"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// selectCoverArtPath picks the album cover among the image files recorded by the scan,
+// evaluating the CoverArtPriority patterns in order. Unusable candidates (zero-byte files,
+// animated GIFs) are skipped. It also returns the size and modification time of the file
+// behind the chosen source; persisted with the album, they let the artwork reader build cache
+// keys without stating the file on every request. Returns empty when nothing matches; the
+// artwork reader then falls back to evaluating the priority list at serving time
+func selectCoverArtPath(ctx context.Context, a *model.Album) (string, int64, time.Time) {
+	for _, pattern := range strings.Split(strings.ToLower(conf.Server.CoverArtPriority), ",") {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "embedded":
+			if a.EmbedArtPath != "" {
+				if fi, err := os.Stat(a.EmbedArtPath); err == nil {
+					return a.EmbedArtPath, fi.Size(), fi.ModTime()
+				}
+				return a.EmbedArtPath, 0, time.Time{}
+			}
+		case pattern == "external":
+			// Agent-provided images are not files; they are only reachable at serving time
+		default:
+			for _, file := range strings.Split(a.ImageFiles, consts.Zwsp) {
+				if file == "" {
+					continue
+				}
+				_, name := filepath.Split(file)
+				if match, err := filepath.Match(pattern, strings.ToLower(name)); err != nil || !match {
+					continue
+				}
+				fi, reason := isUsableCoverArt(file)
+				if fi == nil {
+					log.Debug(ctx, "Skipping unusable cover art candidate", "file", file, "reason", reason)
+					continue
+				}
+				return file, fi.Size(), fi.ModTime()
+			}
+		}
+	}
+	return "", 0, time.Time{}
+}
+
+// isUsableCoverArt rejects candidates that would render as broken or moving covers:
+// zero-byte files and animated GIFs, returning nil and the reason. Usable candidates return
+// their FileInfo, so the caller can record the file's size and modification time
+func isUsableCoverArt(path string) (os.FileInfo, string) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, "stat error: " + err.Error()
+	}
+	if fi.Size() == 0 {
+		return nil, "empty file"
+	}
+	if strings.EqualFold(filepath.Ext(path), ".gif") {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, "open error: " + err.Error()
+		}
+		defer f.Close()
+		g, err := gif.DecodeAll(f)
+		if err != nil {
+			return nil, "invalid gif: " + err.Error()
+		}
+		if len(g.Image) > 1 {
+			return nil, "animated gif"
+		}
+	}
+	return fi, ""
+}
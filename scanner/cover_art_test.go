@@ -0,0 +1,171 @@
+package scanner
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func writeGif(path string, frames int) {
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black, color.White}))
+		g.Delay = append(g.Delay, 10)
+	}
+	f, err := os.Create(path)
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+	Expect(gif.EncodeAll(f, g)).To(Succeed())
+}
+
+var _ = Describe("selectCoverArtPath", func() {
+	var ctx context.Context
+	var dir string
+	var album model.Album
+
+	imageFiles := func(names ...string) string {
+		var files []string
+		for _, name := range names {
+			files = append(files, filepath.Join(dir, name))
+		}
+		return strings.Join(files, consts.Zwsp)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+		previous := conf.Server.CoverArtPriority
+		conf.Server.CoverArtPriority = "cover.*, folder.*, front.*, embedded"
+		DeferCleanup(func() {
+			conf.Server.CoverArtPriority = previous
+		})
+	})
+
+	It("picks the candidates in priority order", func() {
+		for _, name := range []string{"cover.jpg", "folder.png", "front.jpeg"} {
+			Expect(os.WriteFile(filepath.Join(dir, name), []byte("img"), 0600)).To(Succeed())
+		}
+		album = model.Album{ImageFiles: imageFiles("front.jpeg", "folder.png", "cover.jpg")}
+		path, size, modTime := selectCoverArtPath(ctx, &album)
+		Expect(path).To(Equal(filepath.Join(dir, "cover.jpg")))
+		Expect(size).To(Equal(int64(3)))
+		Expect(modTime).ToNot(BeZero())
+	})
+
+	It("skips zero-byte images", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "cover.jpg"), nil, 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "folder.png"), []byte("img"), 0600)).To(Succeed())
+		album = model.Album{ImageFiles: imageFiles("cover.jpg", "folder.png")}
+		path, _, _ := selectCoverArtPath(ctx, &album)
+		Expect(path).To(Equal(filepath.Join(dir, "folder.png")))
+	})
+
+	It("skips animated GIFs, but accepts static ones", func() {
+		writeGif(filepath.Join(dir, "cover.gif"), 2)
+		writeGif(filepath.Join(dir, "folder.gif"), 1)
+		album = model.Album{ImageFiles: imageFiles("cover.gif", "folder.gif")}
+		path, _, _ := selectCoverArtPath(ctx, &album)
+		Expect(path).To(Equal(filepath.Join(dir, "folder.gif")))
+	})
+
+	It("falls back to the embedded art when no file matches", func() {
+		album = model.Album{EmbedArtPath: filepath.Join(dir, "track.mp3")}
+		path, _, _ := selectCoverArtPath(ctx, &album)
+		Expect(path).To(Equal(filepath.Join(dir, "track.mp3")))
+	})
+
+	It("returns empty when there is no usable source", func() {
+		album = model.Album{}
+		path, size, modTime := selectCoverArtPath(ctx, &album)
+		Expect(path).To(BeEmpty())
+		Expect(size).To(BeZero())
+		Expect(modTime).To(BeZero())
+	})
+})
+
+var _ = Describe("Scanner cover art selection", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var s FolderScanner
+	var root, dir string
+	var progress chan uint32
+
+	theAlbum := func() *model.Album {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).ToNot(BeEmpty())
+		al, err := ds.Album(ctx).Get(mfs[0].AlbumID)
+		Expect(err).ToNot(HaveOccurred())
+		return al
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "album")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), data, 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "cover.jpg"), []byte("img"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "folder.png"), []byte("img"), 0600)).To(Succeed())
+
+		metadata.RegisterExtractor("plain", &taglib.Extractor{})
+		previousExtractor := conf.Server.Scanner.Extractor
+		previousPriority := conf.Server.CoverArtPriority
+		conf.Server.Scanner.Extractor = "plain"
+		conf.Server.CoverArtPriority = "cover.*, folder.*, front.*, embedded"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previousExtractor
+			conf.Server.CoverArtPriority = previousPriority
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+	})
+
+	It("stores the winning source on the album, with its size and modification time", func() {
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		al := theAlbum()
+		Expect(al.CoverArtPath).To(Equal(filepath.Join(dir, "cover.jpg")))
+		Expect(al.CoverArtSize).To(Equal(int64(3)))
+		fi, err := os.Stat(al.CoverArtPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(al.CoverArtUpdatedAt.Unix()).To(Equal(fi.ModTime().Unix()))
+	})
+
+	It("keeps a manual override through a rescan", func() {
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		// The user overrides the selection, locking it against scanner updates
+		al := theAlbum()
+		al.CoverArtPath = filepath.Join(dir, "folder.png")
+		al.LockedFields = al.LockedFields.Lock("coverArt")
+		Expect(ds.Album(ctx).Put(al)).To(Succeed())
+
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theAlbum().CoverArtPath).To(Equal(filepath.Join(dir, "folder.png")))
+	})
+})
@@ -0,0 +1,106 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// overrideExtractor decorates another metadata.Extractor, replacing selected tags with fixed
+// values. It stands in for retagging the files between scans, which the suite cannot do.
+type overrideExtractor struct {
+	metadata.Extractor
+	overrides metadata.ParsedTags
+}
+
+func (e *overrideExtractor) Parse(files ...string) (map[string]metadata.ParsedTags, error) {
+	parsed, err := e.Extractor.Parse(files...)
+	if err != nil {
+		return nil, err
+	}
+	for _, tags := range parsed {
+		for name, values := range e.overrides {
+			tags[name] = values
+		}
+	}
+	return parsed, nil
+}
+
+var _ = Describe("Scanner field locks", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var s FolderScanner
+	var dir string
+	var extractor *overrideExtractor
+	var progress chan uint32
+
+	theTrack := func() model.MediaFile {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		return mfs[0]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root := GinkgoT().TempDir()
+		dir = filepath.Join(root, "artist")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), data, 0600)).To(Succeed())
+
+		extractor = &overrideExtractor{Extractor: &taglib.Extractor{}}
+		metadata.RegisterExtractor("override", extractor)
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "override"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+	})
+
+	It("keeps locked fields through rescans, until they are unlocked", func() {
+		extractor.overrides = metadata.ParsedTags{"artist": []string{"Tagged Artist"}}
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Artist).To(Equal("Tagged Artist"))
+
+		// Lock the artist, then "retag" the file
+		mf := theTrack()
+		mf.LockedFields = mf.LockedFields.Lock("artist")
+		Expect(ds.MediaFile(ctx).Put(&mf)).To(Succeed())
+		extractor.overrides = metadata.ParsedTags{"artist": []string{"Renamed Artist"}}
+
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Artist).To(Equal("Tagged Artist"))
+		Expect(s.(*TagScanner).cnt.locked).To(Equal(int64(1)))
+
+		// Unlocking the field lets the next scan restore the tag value
+		mf = theTrack()
+		mf.LockedFields = mf.LockedFields.Unlock("artist")
+		Expect(ds.MediaFile(ctx).Put(&mf)).To(Succeed())
+
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Artist).To(Equal("Renamed Artist"))
+		Expect(s.(*TagScanner).cnt.locked).To(BeZero())
+	})
+})
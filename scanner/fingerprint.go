@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner/metadata"
+)
+
+// Fingerprinter computes an acoustic fingerprint (ex: Chromaprint) for a media file. When one is
+// registered, the scanner fingerprints every file missing both artist and title tags, queueing
+// the track for external identification.
+type Fingerprinter interface {
+	Fingerprint(ctx context.Context, path string) (string, error)
+}
+
+var fingerprinter Fingerprinter
+
+// RegisterFingerprinter sets the Fingerprinter used by the scanner. Passing nil disables
+// fingerprinting.
+func RegisterFingerprinter(f Fingerprinter) {
+	fingerprinter = f
+}
+
+// fingerprintIfNeeded computes and stores the acoustic fingerprint for files missing both artist
+// and title tags. Fingerprinting errors are logged, but don't abort the scan.
+func fingerprintIfNeeded(ctx context.Context, md metadata.Tags, mf *model.MediaFile) {
+	if fingerprinter == nil || md.Title() != "" || md.Artist() != "" {
+		return
+	}
+	fp, err := fingerprinter.Fingerprint(ctx, mf.Path)
+	if err != nil {
+		log.Warn(ctx, "Error fingerprinting file", "path", mf.Path, err)
+		return
+	}
+	mf.Fingerprint = fp
+}
+
+// IdentificationQueue exposes the fingerprinted tracks still waiting for external identification
+// (ex: an AcoustID lookup, which is out of the scanner's scope), and applies the identified tags
+// back, re-running the metadata mapping.
+type IdentificationQueue struct {
+	ds model.DataStore
+}
+
+func NewIdentificationQueue(ds model.DataStore) *IdentificationQueue {
+	return &IdentificationQueue{ds: ds}
+}
+
+// ListUnidentified returns the fingerprinted tracks that still have no artist
+func (q *IdentificationQueue) ListUnidentified(ctx context.Context) (model.MediaFiles, error) {
+	return q.ds.MediaFile(ctx).GetAll(model.QueryOptions{
+		Filters: squirrel.And{squirrel.NotEq{"fingerprint": ""}, squirrel.Eq{"artist": ""}},
+	})
+}
+
+// ApplyIdentification updates the track with the tags returned by an external identification,
+// re-running the metadata mapping over them
+func (q *IdentificationQueue) ApplyIdentification(ctx context.Context, id string, tags metadata.ParsedTags) error {
+	repo := q.ds.MediaFile(ctx)
+	mf, err := repo.Get(id)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(mf.Path)
+	if err != nil {
+		return fmt.Errorf("identifying track %s: %w", id, err)
+	}
+	md := metadata.NewTag(mf.Path, info, tags)
+	mapper := NewMediaFileMapper(conf.Server.MusicFolder, q.ds.Genre(ctx), loadTagMappings(ctx, q.ds))
+	identified := mapper.ToMediaFile(md)
+	identified.ID = mf.ID
+	identified.LibraryID = mf.LibraryID
+	identified.CreatedAt = mf.CreatedAt
+	identified.Fingerprint = mf.Fingerprint
+	return repo.Put(&identified)
+}
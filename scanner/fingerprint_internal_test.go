@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeFingerprinter struct {
+	calls []string
+}
+
+func (f *fakeFingerprinter) Fingerprint(_ context.Context, path string) (string, error) {
+	f.calls = append(f.calls, path)
+	return "CHROMAPRINT-FAKE", nil
+}
+
+var _ = Describe("Fingerprinting", func() {
+	var ctx context.Context
+	var fake *fakeFingerprinter
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = &fakeFingerprinter{}
+		RegisterFingerprinter(fake)
+		DeferCleanup(func() { RegisterFingerprinter(nil) })
+	})
+
+	Describe("fingerprintIfNeeded", func() {
+		It("fingerprints files missing both artist and title", func() {
+			md := metadata.NewTag("/music/unknown.mp3", nil, metadata.ParsedTags{})
+			mf := model.MediaFile{Path: "/music/unknown.mp3"}
+			fingerprintIfNeeded(ctx, md, &mf)
+			Expect(mf.Fingerprint).To(Equal("CHROMAPRINT-FAKE"))
+			Expect(fake.calls).To(ConsistOf("/music/unknown.mp3"))
+		})
+
+		It("skips files with an artist or title tag", func() {
+			md := metadata.NewTag("/music/tagged.mp3", nil, metadata.ParsedTags{"title": []string{"A Title"}})
+			mf := model.MediaFile{Path: "/music/tagged.mp3"}
+			fingerprintIfNeeded(ctx, md, &mf)
+			Expect(mf.Fingerprint).To(BeEmpty())
+			Expect(fake.calls).To(BeEmpty())
+		})
+
+		It("does nothing when no fingerprinter is registered", func() {
+			RegisterFingerprinter(nil)
+			md := metadata.NewTag("/music/unknown.mp3", nil, metadata.ParsedTags{})
+			mf := model.MediaFile{Path: "/music/unknown.mp3"}
+			fingerprintIfNeeded(ctx, md, &mf)
+			Expect(mf.Fingerprint).To(BeEmpty())
+		})
+	})
+
+	Describe("IdentificationQueue", func() {
+		It("drives a full identify-apply cycle", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "unknown.mp3")
+			Expect(os.WriteFile(path, []byte("not really audio"), 0600)).To(Succeed())
+
+			ds := &tests.MockDataStore{}
+			repo := ds.MediaFile(ctx).(*tests.MockMediaFileRepo)
+			repo.SetData(model.MediaFiles{{ID: "fp-1", LibraryID: 1, Path: path,
+				Title: "unknown", Fingerprint: "CHROMAPRINT-FAKE"}})
+
+			queue := NewIdentificationQueue(ds)
+			pending, err := queue.ListUnidentified(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pending).To(HaveLen(1))
+			Expect(pending[0].ID).To(Equal("fp-1"))
+
+			err = queue.ApplyIdentification(ctx, "fp-1", metadata.ParsedTags{
+				"title":  []string{"Now Identified"},
+				"artist": []string{"The Identified Artist"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			identified, err := repo.Get("fp-1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(identified.Title).To(Equal("Now Identified"))
+			Expect(identified.Artist).To(Equal("The Identified Artist"))
+			Expect(identified.Fingerprint).To(Equal("CHROMAPRINT-FAKE"))
+		})
+
+		It("fails when the track cannot be found", func() {
+			queue := NewIdentificationQueue(&tests.MockDataStore{})
+			err := queue.ApplyIdentification(ctx, "666", metadata.ParsedTags{})
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// countingExtractor decorates another metadata.Extractor, counting the files handed to Parse,
+// so the specs can assert which scans read tags at all
+type countingExtractor struct {
+	metadata.Extractor
+	parsed int
+}
+
+func (e *countingExtractor) Parse(files ...string) (map[string]metadata.ParsedTags, error) {
+	e.parsed += len(files)
+	return e.Extractor.Parse(files...)
+}
+
+// These specs cover the per-folder content fingerprint (see dirStats.fingerprint): folders
+// whose stored fingerprint still matches are skipped without reading any tags or loading their
+// tracks from the DB, while any content change is caught even when the modification times give
+// nothing away (ex: files restored from a backup with their original timestamps).
+var _ = Describe("Folder fingerprints", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var root, dirA, dirB string
+	var progress chan uint32
+	var extractor *countingExtractor
+	var m *scanMetrics
+
+	copyFixture := func(destPath string) {
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(destPath, data, 0600)).To(Succeed())
+	}
+
+	scan := func(full bool, lastScan time.Time) {
+		s := NewTagScanner(model.Library{ID: 1, Path: root, LastScanAt: lastScan}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err := s.Scan(ctx, full, progress)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+		m = getScanMetrics()
+
+		root = GinkgoT().TempDir()
+		dirA = filepath.Join(root, "artist a")
+		dirB = filepath.Join(root, "artist b")
+		Expect(os.Mkdir(dirA, 0700)).To(Succeed())
+		Expect(os.Mkdir(dirB, 0700)).To(Succeed())
+		copyFixture(filepath.Join(dirA, "track1.mp3"))
+		copyFixture(filepath.Join(dirA, "track2.mp3"))
+		copyFixture(filepath.Join(dirB, "track3.mp3"))
+
+		extractor = &countingExtractor{Extractor: &taglib.Extractor{}}
+		metadata.RegisterExtractor("counting", extractor)
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "counting"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dirA)
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dirB)
+			_ = ds.GC(ctx, root)
+		})
+	})
+
+	It("skips unchanged folders without reading tags or loading their tracks", func() {
+		scan(true, time.Time{})
+		Expect(extractor.parsed).To(Equal(3))
+		processed := testutil.ToFloat64(m.foldersProcessed.WithLabelValues(root))
+
+		// A stale LastScanAt makes the mtime heuristic consider every folder changed; the
+		// stored fingerprints still recognize them as untouched
+		extractor.parsed = 0
+		scan(false, time.Now().Add(-time.Hour))
+		Expect(extractor.parsed).To(BeZero())
+		Expect(testutil.ToFloat64(m.foldersProcessed.WithLabelValues(root))).To(Equal(processed))
+	})
+
+	It("catches added files even when no modification time gives them away", func() {
+		scan(true, time.Time{})
+
+		past := time.Now().Add(-2 * time.Hour)
+		path := filepath.Join(dirA, "track4.mp3")
+		copyFixture(path)
+		Expect(os.Chtimes(path, past, past)).To(Succeed())
+		Expect(os.Chtimes(dirA, past, past)).To(Succeed())
+
+		extractor.parsed = 0
+		scan(false, time.Now())
+		// Only the new file is read; the unchanged tracks are skipped by the per-file check
+		Expect(extractor.parsed).To(Equal(1))
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dirA)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(3))
+	})
+
+	It("catches deleted files even when the folder's mtime does not change", func() {
+		scan(true, time.Time{})
+
+		past := time.Now().Add(-2 * time.Hour)
+		Expect(os.Remove(filepath.Join(dirA, "track2.mp3"))).To(Succeed())
+		Expect(os.Chtimes(dirA, past, past)).To(Succeed())
+
+		extractor.parsed = 0
+		scan(false, time.Now())
+		Expect(extractor.parsed).To(BeZero())
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dirA)
+		Expect(err).ToNot(HaveOccurred())
+		missing := 0
+		for _, mf := range mfs {
+			if mf.Missing {
+				missing++
+			}
+		}
+		Expect(missing).To(Equal(1))
+	})
+})
@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pocketbase/dbx"
+)
+
+// The scanner persists each folder's tracks, tags and album/artist roll-ups in a single
+// transaction (see processChangedDir). This simulates a failure injected after the media_file
+// insert, and asserts the rollback leaves no half-written rows behind
+var _ = Describe("Folder persistence transaction", func() {
+	var ctx context.Context
+	var ds model.DataStore
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+	})
+
+	It("leaves no orphan tag rows when the transaction fails after the tracks are written", func() {
+		tag := model.NewTag("mood", "Chaotic")
+		mf := model.MediaFile{LibraryID: 1, ID: "txr-1", Path: "/txr/track.mp3", Tags: model.Tags{tag}}
+
+		boom := errors.New("boom")
+		err := ds.WithTx(func(tx model.DataStore) error {
+			if err := tx.MediaFile(ctx).PutAll(model.MediaFiles{mf}); err != nil {
+				return err
+			}
+			return boom
+		})
+		Expect(err).To(MatchError(boom))
+
+		_, err = ds.MediaFile(ctx).Get("txr-1")
+		Expect(err).To(MatchError(model.ErrNotFound))
+
+		conn := dbx.NewFromDB(db.Db().ReadDB(), db.Driver)
+		var count int
+		Expect(conn.NewQuery("select count(*) from item_tags where item_id = 'txr-1'").Row(&count)).To(Succeed())
+		Expect(count).To(BeZero())
+	})
+})
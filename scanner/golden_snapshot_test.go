@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	_ "github.com/navidrome/navidrome/scanner/metadata/taglib"
+	"github.com/navidrome/navidrome/tests"
+	"github.com/navidrome/navidrome/utils/slice"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+// MatchSnapshot compares the (serialized) actual value against a golden file stored in the
+// .snapshots folder. To regenerate the goldens after an intentional scanner change, run the
+// suite with UPDATE_SNAPSHOTS=true.
+func MatchSnapshot() types.GomegaMatcher {
+	// The suite runs from the project root (see tests.Init), so the subdirectory needs to be explicit
+	c := cupaloy.New(cupaloy.FailOnUpdate(false), cupaloy.SnapshotSubdirectory("scanner/.snapshots"))
+	return &snapshotMatcher{c}
+}
+
+type snapshotMatcher struct {
+	c *cupaloy.Config
+}
+
+func (matcher snapshotMatcher) Match(actual interface{}) (success bool, err error) {
+	actualJson := strings.TrimSpace(string(actual.([]byte)))
+	err = matcher.c.SnapshotWithName(CurrentSpecReport().FullText(), actualJson)
+	success = err == nil
+	return
+}
+
+func (matcher snapshotMatcher) FailureMessage(_ interface{}) (message string) {
+	return "Expected to match saved snapshot\n"
+}
+
+func (matcher snapshotMatcher) NegatedFailureMessage(_ interface{}) (message string) {
+	return "Expected to not match saved snapshot\n"
+}
+
+// maskVolatileFields zeroes columns that depend on the environment the suite runs on (file
+// timestamps), so the snapshots are stable across checkouts.
+func maskVolatileFields(mf model.MediaFile) model.MediaFile {
+	mf.CreatedAt = time.Time{}
+	mf.UpdatedAt = time.Time{}
+	mf.BirthTime = time.Time{}
+	// Genre IDs are assigned by the repository, not the mapper, so they are not part of what
+	// this snapshot covers
+	for i := range mf.Genres {
+		mf.Genres[i].ID = ""
+	}
+	// The order of multiple lyrics tags coming from the extractor is not deterministic
+	var lyrics model.LyricList
+	if err := json.Unmarshal([]byte(mf.Lyrics), &lyrics); err == nil {
+		sort.Slice(lyrics, func(i, j int) bool {
+			a, _ := json.Marshal(lyrics[i])
+			b, _ := json.Marshal(lyrics[j])
+			return string(a) < string(b)
+		})
+		normalized, _ := json.Marshal(lyrics)
+		mf.Lyrics = string(normalized)
+	}
+	return mf
+}
+
+var _ = Describe("Scanner golden snapshots", func() {
+	It("maps the fixture files to the same media files and albums", func() {
+		files, err := loadAllAudioFiles("tests/fixtures")
+		Expect(err).ToNot(HaveOccurred())
+
+		var paths []string
+		for path := range files {
+			paths = append(paths, path)
+		}
+		mds, err := metadata.Extract(paths...)
+		Expect(err).ToNot(HaveOccurred())
+
+		// The mapper gets the mocked genre repository directly: newCachedGenreRepository is a
+		// process-wide singleton, and binding it to a mock would leak into the scan specs
+		ds := &tests.MockDataStore{}
+		mapper := NewMediaFileMapper("tests/fixtures", ds.Genre(context.Background()), nil)
+		var mfs model.MediaFiles
+		for _, md := range mds {
+			mfs = append(mfs, maskVolatileFields(mapper.ToMediaFile(md)))
+		}
+		sort.Slice(mfs, func(i, j int) bool { return mfs[i].Path < mfs[j].Path })
+
+		albums := slice.Group(mfs, func(mf model.MediaFile) string { return mf.AlbumID })
+		var albumIDs []string
+		for id := range albums {
+			albumIDs = append(albumIDs, id)
+		}
+		sort.Strings(albumIDs)
+		albumNames := slice.Map(albumIDs, func(id string) string {
+			mf := albums[id][0]
+			return mf.AlbumArtist + " - " + mf.Album
+		})
+
+		snapshot := map[string]any{
+			"mediaFiles": mfs,
+			"albums":     albumNames,
+		}
+		Expect(json.MarshalIndent(snapshot, "", "  ")).To(MatchSnapshot())
+	})
+})
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scanner ISRC mapping", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var s FolderScanner
+	var dir string
+	var extractor *overrideExtractor
+	var progress chan uint32
+
+	theTrack := func() model.MediaFile {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		return mfs[0]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root := GinkgoT().TempDir()
+		dir = filepath.Join(root, "artist")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), data, 0600)).To(Succeed())
+
+		extractor = &overrideExtractor{Extractor: &taglib.Extractor{}}
+		metadata.RegisterExtractor("override", extractor)
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "override"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+	})
+
+	It("persists the first valid ISRC, normalized, and all raw values in the tag table", func() {
+		extractor.overrides = metadata.ParsedTags{"isrc": []string{"bad-isrc-value", "us-rc1-76-00129"}}
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(theTrack().Isrc).To(Equal("USRC17600129"))
+
+		// The raw values, including the invalid one, are kept in the generic tag table
+		tags, err := ds.Tag(ctx).GetAll(model.QueryOptions{Filters: squirrel.Eq{"tag_name": "isrc"}})
+		Expect(err).ToNot(HaveOccurred())
+		var values []string
+		for _, t := range tags {
+			values = append(values, t.TagValue)
+		}
+		Expect(values).To(ContainElements("bad-isrc-value", "us-rc1-76-00129"))
+	})
+
+	It("leaves the isrc column empty when no tagged value is valid", func() {
+		extractor.overrides = metadata.ParsedTags{"isrc": []string{"bad-isrc-value"}}
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Isrc).To(BeEmpty())
+	})
+})
@@ -1,14 +1,19 @@
 package scanner
 
 import (
+	"context"
 	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/scanner/metadata"
 	"github.com/navidrome/navidrome/utils/str"
@@ -17,15 +22,37 @@ import (
 type MediaFileMapper struct {
 	rootFolder string
 	genres     model.GenreRepository
+	mappings   model.TagMappings
 }
 
-func NewMediaFileMapper(rootFolder string, genres model.GenreRepository) *MediaFileMapper {
+func NewMediaFileMapper(rootFolder string, genres model.GenreRepository, mappings model.TagMappings) *MediaFileMapper {
 	return &MediaFileMapper{
 		rootFolder: rootFolder,
 		genres:     genres,
+		mappings:   mappings,
 	}
 }
 
+// loadTagMappings loads the tag value merges recorded by DataStore.MergeTagValues, so the
+// mapper keeps folding the merged variants into their canonical value instead of re-creating
+// them from the file tags
+func loadTagMappings(ctx context.Context, ds model.DataStore) model.TagMappings {
+	value, err := ds.Property(ctx).DefaultGet(consts.TagMappingsKey, "")
+	if err != nil {
+		log.Error(ctx, "Error loading tag mappings from the DB", err)
+		return nil
+	}
+	if value == "" {
+		return nil
+	}
+	var mappings model.TagMappings
+	if err := json.Unmarshal([]byte(value), &mappings); err != nil {
+		log.Error(ctx, "Error parsing tag mappings", err)
+		return nil
+	}
+	return mappings
+}
+
 // TODO Move most of these mapping functions to setters in the model.MediaFile
 func (s MediaFileMapper) ToMediaFile(md metadata.Tags) model.MediaFile {
 	mf := &model.MediaFile{}
@@ -34,22 +61,27 @@ func (s MediaFileMapper) ToMediaFile(md metadata.Tags) model.MediaFile {
 	mf.Title = s.mapTrackTitle(md)
 	mf.Album = md.Album()
 	mf.AlbumID = s.albumID(md, mf.ReleaseDate)
+	mf.AlbumPID = s.albumPID(md)
 	mf.Album = s.mapAlbumName(md)
 	mf.ArtistID = s.artistID(md)
 	mf.Artist = s.mapArtistName(md)
 	mf.AlbumArtistID = s.albumArtistID(md)
 	mf.AlbumArtist = s.mapAlbumArtistName(md)
 	mf.Genre, mf.Genres = s.mapGenres(md.Genres())
+	mf.Tags = s.mapTags(md)
 	mf.Compilation = md.Compilation()
-	mf.TrackNumber, _ = md.TrackNumber()
+	mf.TrackNumber, mf.TotalTracks = md.TrackNumber()
 	mf.DiscNumber, _ = md.DiscNumber()
 	mf.DiscSubtitle = md.DiscSubtitle()
-	mf.Duration = md.Duration()
+	mf.DurationMs = md.DurationMs()
 	mf.BitRate = md.BitRate()
 	mf.SampleRate = md.SampleRate()
+	mf.BitDepth = md.BitDepth()
 	mf.Channels = md.Channels()
 	mf.Path = md.FilePath()
+	mf.FolderID = model.FolderID(filepath.Dir(md.FilePath()))
 	mf.Suffix = md.Suffix()
+	mf.Codec = md.Codec()
 	mf.Size = md.Size()
 	mf.HasCoverArt = md.HasPicture()
 	mf.SortTitle = md.SortTitle()
@@ -61,9 +93,11 @@ func (s MediaFileMapper) ToMediaFile(md metadata.Tags) model.MediaFile {
 	mf.OrderArtistName = str.SanitizeFieldForSortingNoArticle(mf.Artist)
 	mf.OrderAlbumArtistName = str.SanitizeFieldForSortingNoArticle(mf.AlbumArtist)
 	mf.CatalogNum = md.CatalogNum()
+	mf.Isrc = s.mapISRC(md)
 	mf.MbzRecordingID = md.MbzRecordingID()
 	mf.MbzReleaseTrackID = md.MbzReleaseTrackID()
 	mf.MbzAlbumID = md.MbzAlbumID()
+	mf.MbzWorkID = md.MbzWorkID()
 	mf.MbzArtistID = md.MbzArtistID()
 	mf.MbzAlbumArtistID = md.MbzAlbumArtistID()
 	mf.MbzAlbumType = md.MbzAlbumType()
@@ -72,10 +106,18 @@ func (s MediaFileMapper) ToMediaFile(md metadata.Tags) model.MediaFile {
 	mf.RgAlbumPeak = md.RGAlbumPeak()
 	mf.RgTrackGain = md.RGTrackGain()
 	mf.RgTrackPeak = md.RGTrackPeak()
+	mf.Participations = s.mapParticipations(md)
 	mf.Comment = str.SanitizeText(md.Comment())
 	mf.Lyrics = md.Lyrics()
 	mf.Bpm = md.Bpm()
-	mf.CreatedAt = md.BirthTime()
+	mf.InitialKey = mapKey(md.Key())
+	mf.BirthTime = md.BirthTime()
+	if mf.BirthTime.IsZero() {
+		// Filesystem without birth time support: fall back to the import time, like before
+		mf.CreatedAt = time.Now()
+	} else {
+		mf.CreatedAt = mf.BirthTime
+	}
 	mf.UpdatedAt = md.ModificationTime()
 
 	return *mf
@@ -104,10 +146,53 @@ func (s MediaFileMapper) mapAlbumArtistName(md metadata.Tags) string {
 }
 
 func (s MediaFileMapper) mapArtistName(md metadata.Tags) string {
-	if md.Artist() != "" {
+	switch {
+	case md.Artist() != "":
 		return md.Artist()
+	case conf.Server.Scanner.ArtistFallback == "albumartist" && md.AlbumArtist() != "":
+		// Files tagged only with ALBUMARTIST would otherwise be fragmented under [Unknown Artist]
+		return md.AlbumArtist()
+	default:
+		return consts.UnknownArtist
 	}
-	return consts.UnknownArtist
+}
+
+// mapParticipations collects all artists participating in the track, indexed by their roles.
+// Any tag whose name matches a known role (or one registered in Scanner.AdditionalRoles, ex:
+// TXXX:ORCHESTRA) is mapped to the corresponding role.
+func (s MediaFileMapper) mapParticipations(md metadata.Tags) model.Participations {
+	participations := model.Participations{}
+	artist := newParticipant(s.mapArtistName(md))
+	artist.SortName = md.SortArtist()
+	artist.MbzArtistID = md.MbzArtistID()
+	participations.Add(model.RoleArtist, artist)
+	albumArtist := newParticipant(s.mapAlbumArtistName(md))
+	albumArtist.SortName = md.SortAlbumArtist()
+	albumArtist.MbzArtistID = md.MbzAlbumArtistID()
+	participations.Add(model.RoleAlbumArtist, albumArtist)
+	for name, values := range md.Tags {
+		role := model.RoleFromString(name)
+		if role == model.RoleInvalid || role == model.RoleArtist || role == model.RoleAlbumArtist {
+			continue
+		}
+		sortNames := md.SortTagsFor(name)
+		for i, value := range values {
+			p := newParticipant(value)
+			if i < len(sortNames) {
+				p.SortName = sortNames[i]
+			}
+			participations.Add(role, p)
+		}
+	}
+	return participations
+}
+
+func newParticipant(name string) model.Participant {
+	return model.Participant{ID: artistIDFromName(name), Name: name}
+}
+
+func artistIDFromName(name string) string {
+	return model.ParticipantID(name)
 }
 
 func (s MediaFileMapper) mapAlbumName(md metadata.Tags) string {
@@ -118,12 +203,21 @@ func (s MediaFileMapper) mapAlbumName(md metadata.Tags) string {
 	return name
 }
 
+// embeddedIDRx matches the IDs written by Scanner.WriteTrackID (an md5 hex digest). Values in
+// any other shape are ignored, so a corrupted or hand-edited tag cannot inject arbitrary row IDs
+var embeddedIDRx = regexp.MustCompile("^[0-9a-f]{32}$")
+
+// trackID prefers the stable ID embedded in the file tags by a previous import (see
+// Scanner.WriteTrackID), falling back to a hash of the file path
 func (s MediaFileMapper) trackID(md metadata.Tags) string {
+	if id := md.NavidromeID(); embeddedIDRx.MatchString(id) {
+		return id
+	}
 	return fmt.Sprintf("%x", md5.Sum([]byte(md.FilePath())))
 }
 
 func (s MediaFileMapper) albumID(md metadata.Tags, releaseDate string) string {
-	albumPath := strings.ToLower(fmt.Sprintf("%s\\%s", s.mapAlbumArtistName(md), s.mapAlbumName(md)))
+	albumPath := s.albumBasePath(md)
 	if !conf.Server.Scanner.GroupAlbumReleases {
 		if len(releaseDate) != 0 {
 			albumPath = fmt.Sprintf("%s\\%s", albumPath, releaseDate)
@@ -132,12 +226,28 @@ func (s MediaFileMapper) albumID(md metadata.Tags, releaseDate string) string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(albumPath)))
 }
 
+// albumPID is a persistent album identifier: the albumID computed without the release date,
+// so it does not change when GroupAlbumReleases is toggled
+func (s MediaFileMapper) albumPID(md metadata.Tags) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(s.albumBasePath(md))))
+}
+
+func (s MediaFileMapper) albumBasePath(md metadata.Tags) string {
+	albumPath := strings.ToLower(fmt.Sprintf("%s\\%s", s.mapAlbumArtistName(md), s.mapAlbumName(md)))
+	if md.AlbumArtist() == "" && !md.Compilation() {
+		// Without an album artist tag, the fallback chain would split a multi-artist album into
+		// one album per track artist, so group by folder instead
+		albumPath = strings.ToLower(fmt.Sprintf("%s\\%s", filepath.Dir(md.FilePath()), s.mapAlbumName(md)))
+	}
+	return albumPath
+}
+
 func (s MediaFileMapper) artistID(md metadata.Tags) string {
-	return fmt.Sprintf("%x", md5.Sum([]byte(strings.ToLower(s.mapArtistName(md)))))
+	return artistIDFromName(s.mapArtistName(md))
 }
 
 func (s MediaFileMapper) albumArtistID(md metadata.Tags) string {
-	return fmt.Sprintf("%x", md5.Sum([]byte(strings.ToLower(s.mapAlbumArtistName(md)))))
+	return artistIDFromName(s.mapAlbumArtistName(md))
 }
 
 func (s MediaFileMapper) mapGenres(genres []string) (string, model.Genres) {
@@ -149,7 +259,7 @@ func (s MediaFileMapper) mapGenres(genres []string) (string, model.Genres) {
 			return strings.ContainsRune(conf.Server.Scanner.GenreSeparators, r)
 		})
 		for j := range gs {
-			g := strings.TrimSpace(gs[j])
+			g := s.mappings.Canonical("genre", strings.TrimSpace(gs[j]))
 			key := strings.ToLower(g)
 			if _, ok := unique[key]; ok {
 				continue
@@ -169,6 +279,74 @@ func (s MediaFileMapper) mapGenres(genres []string) (string, model.Genres) {
 	return result[0].Name, result
 }
 
+// itemTagNames are the raw tags persisted to the tag tables, so items can be browsed by them
+// through the native API
+var itemTagNames = []string{"mood", "grouping", "language", "isrc"}
+
+// mapISRC returns the first valid ISRC among the tagged values, normalized. Invalid values are
+// not persisted to the isrc column, but remain available raw in the tag table
+func (s MediaFileMapper) mapISRC(md metadata.Tags) string {
+	for _, value := range md.ISRCs() {
+		if isrc, ok := model.NormalizeISRC(value); ok {
+			return isrc
+		}
+	}
+	return ""
+}
+
+func (s MediaFileMapper) mapTags(md metadata.Tags) model.Tags {
+	var tags model.Tags
+	for _, name := range itemTagNames {
+		for _, value := range md.Tags[name] {
+			tags = append(tags, model.NewTag(name, s.mappings.Canonical(name, value)))
+		}
+	}
+	// Keep the key as tagged, since mapKey normalizes enharmonic spellings
+	if key := md.Key(); key != "" {
+		tags = append(tags, model.NewTag("key", key))
+	}
+	return tags
+}
+
+// sharpToFlat maps enharmonic sharp spellings to the flat spelling used as canonical notation
+var sharpToFlat = map[string]string{
+	"c#": "Db", "d#": "Eb", "f#": "Gb", "g#": "Ab", "a#": "Bb",
+	"e#": "F", "b#": "C", "cb": "B", "fb": "E",
+}
+
+// mapKey normalizes a musical key to a canonical notation: note name with flats for accidentals,
+// and a "m" suffix for minor keys (ex: "g# minor" => "Abm"). Unparseable values are kept as-is.
+func mapKey(key string) string {
+	k := strings.ToLower(strings.TrimSpace(key))
+	if k == "" {
+		return ""
+	}
+	note := k[:1]
+	rest := k[1:]
+	if strings.HasPrefix(rest, "#") || strings.HasPrefix(rest, "b") {
+		note += rest[:1]
+		rest = rest[1:]
+	}
+	if note[0] < 'a' || note[0] > 'g' {
+		return key
+	}
+	var mode string
+	switch strings.TrimSpace(rest) {
+	case "m", "min", "minor":
+		mode = "m"
+	case "", "maj", "major":
+		mode = ""
+	default:
+		return key
+	}
+	if flat, ok := sharpToFlat[note]; ok {
+		note = flat
+	} else {
+		note = strings.ToUpper(note[:1]) + note[1:]
+	}
+	return note + mode
+}
+
 func (s MediaFileMapper) mapDates(md metadata.Tags) (year int, date string,
 	originalYear int, originalDate string,
 	releaseYear int, releaseDate string) {
@@ -2,7 +2,12 @@ package scanner
 
 import (
 	"context"
+	"crypto/md5"
+	"fmt"
 
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/conf/configtest"
+	"github.com/navidrome/navidrome/consts"
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/scanner/metadata"
 	"github.com/navidrome/navidrome/tests"
@@ -15,7 +20,7 @@ var _ = Describe("mapping", func() {
 		var mapper *MediaFileMapper
 		Describe("mapTrackTitle", func() {
 			BeforeEach(func() {
-				mapper = NewMediaFileMapper("/music", nil)
+				mapper = NewMediaFileMapper("/music", nil, nil)
 			})
 			It("returns the Title when it is available", func() {
 				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil, metadata.ParsedTags{"title": []string{"This is not a love song"}})
@@ -27,6 +32,152 @@ var _ = Describe("mapping", func() {
 			})
 		})
 
+		Describe("trackID", func() {
+			BeforeEach(func() {
+				mapper = NewMediaFileMapper("/music", nil, nil)
+			})
+			It("hashes the file path when the file carries no embedded ID", func() {
+				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil, metadata.ParsedTags{})
+				expected := fmt.Sprintf("%x", md5.Sum([]byte("/music/artist/album01/Song.mp3")))
+				Expect(mapper.trackID(md)).To(Equal(expected))
+			})
+			It("prefers the ID embedded by Scanner.WriteTrackID", func() {
+				embedded := "0123456789abcdef0123456789abcdef"
+				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil,
+					metadata.ParsedTags{"navidrome_id": []string{embedded}})
+				Expect(mapper.trackID(md)).To(Equal(embedded))
+			})
+			It("ignores embedded values that were not written by the scanner", func() {
+				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil,
+					metadata.ParsedTags{"navidrome_id": []string{"not-a-scanner-id"}})
+				expected := fmt.Sprintf("%x", md5.Sum([]byte("/music/artist/album01/Song.mp3")))
+				Expect(mapper.trackID(md)).To(Equal(expected))
+			})
+		})
+
+		Describe("mapArtistName/mapAlbumArtistName", func() {
+			BeforeEach(func() {
+				DeferCleanup(configtest.SetupConfig())
+				mapper = NewMediaFileMapper("/music", nil, nil)
+			})
+
+			newTags := func(tags map[string]string) metadata.Tags {
+				parsed := metadata.ParsedTags{}
+				for k, v := range tags {
+					parsed[k] = []string{v}
+				}
+				return metadata.NewTag("/music/artist/album01/Song.mp3", nil, parsed)
+			}
+
+			DescribeTable("maps the fallback chain",
+				func(tags map[string]string, expectedArtist, expectedAlbumArtist string) {
+					md := newTags(tags)
+					Expect(mapper.mapArtistName(md)).To(Equal(expectedArtist))
+					Expect(mapper.mapAlbumArtistName(md)).To(Equal(expectedAlbumArtist))
+				},
+				Entry("both artist and albumartist present",
+					map[string]string{"artist": "Joe", "albumartist": "Band"}, "Joe", "Band"),
+				Entry("only artist present",
+					map[string]string{"artist": "Joe"}, "Joe", "Joe"),
+				Entry("no artist tags at all",
+					map[string]string{}, consts.UnknownArtist, consts.UnknownArtist),
+				Entry("only albumartist present",
+					map[string]string{"albumartist": "Band"}, consts.UnknownArtist, "Band"),
+				Entry("compilation without albumartist",
+					map[string]string{"artist": "Joe", "compilation": "1"}, "Joe", consts.VariousArtists),
+			)
+
+			Context("when Scanner.ArtistFallback is set to 'albumartist'", func() {
+				BeforeEach(func() {
+					conf.Server.Scanner.ArtistFallback = "albumartist"
+				})
+
+				It("uses the album artist for tracks missing the artist tag", func() {
+					md := newTags(map[string]string{"albumartist": "Band"})
+					Expect(mapper.mapArtistName(md)).To(Equal("Band"))
+					Expect(mapper.mapAlbumArtistName(md)).To(Equal("Band"))
+				})
+
+				It("still falls back to Unknown Artist when both are missing", func() {
+					md := newTags(map[string]string{})
+					Expect(mapper.mapArtistName(md)).To(Equal(consts.UnknownArtist))
+				})
+			})
+		})
+
+		Describe("mapParticipations", func() {
+			BeforeEach(func() {
+				DeferCleanup(configtest.SetupConfig())
+				mapper = NewMediaFileMapper("/music", nil, nil)
+			})
+
+			It("maps known role tags into participations", func() {
+				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil, metadata.ParsedTags{
+					"artist":   []string{"Joe"},
+					"composer": []string{"J.S. Bach"},
+				})
+				p := mapper.mapParticipations(md)
+				Expect(p[model.RoleArtist]).To(ConsistOf(newParticipant("Joe")))
+				Expect(p[model.RoleComposer]).To(ConsistOf(newParticipant("J.S. Bach")))
+			})
+
+			It("fills the sort names of secondary roles", func() {
+				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil, metadata.ParsedTags{
+					"artist":        []string{"Joe"},
+					"conductor":     []string{"Herbert von Karajan"},
+					"conductorsort": []string{"Karajan, Herbert von"},
+					"lyricist":      []string{"Bernie Taupin", "Elton John"},
+					"sort_lyricist": []string{"Taupin, Bernie", "John, Elton"},
+				})
+				p := mapper.mapParticipations(md)
+				Expect(p[model.RoleConductor]).To(HaveLen(1))
+				Expect(p[model.RoleConductor][0].SortName).To(Equal("Karajan, Herbert von"))
+				// Multi-valued sort tags are matched positionally with the role's artists
+				Expect(p[model.RoleLyricist]).To(HaveLen(2))
+				Expect(p[model.RoleLyricist][0].SortName).To(Equal("Taupin, Bernie"))
+				Expect(p[model.RoleLyricist][1].SortName).To(Equal("John, Elton"))
+			})
+
+			It("fills the sort name and MBID of the artist and album artist", func() {
+				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil, metadata.ParsedTags{
+					"artist":               []string{"The Beatles"},
+					"artistsort":           []string{"Beatles, The"},
+					"musicbrainz_artistid": []string{"b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d"},
+				})
+				p := mapper.mapParticipations(md)
+				Expect(p[model.RoleArtist]).To(HaveLen(1))
+				Expect(p[model.RoleArtist][0].SortName).To(Equal("Beatles, The"))
+				Expect(p[model.RoleArtist][0].MbzArtistID).To(Equal("b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d"))
+			})
+
+			It("maps custom roles registered through the DB allowlist", func() {
+				model.SetCustomRoles([]string{"choir"})
+				DeferCleanup(func() { model.SetCustomRoles(nil) })
+				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil, metadata.ParsedTags{
+					"artist": []string{"Joe"},
+					"choir":  []string{"Some Choir"},
+				})
+				p := mapper.mapParticipations(md)
+				Expect(p[model.RoleFromString("choir")]).To(ConsistOf(newParticipant("Some Choir")))
+
+				model.SetCustomRoles(nil)
+				p = mapper.mapParticipations(md)
+				Expect(p).To(HaveLen(2)) // Only artist and albumartist
+			})
+
+			It("maps custom roles registered in Scanner.AdditionalRoles", func() {
+				conf.Server.Scanner.AdditionalRoles = []string{"orchestra"}
+				md := metadata.NewTag("/music/artist/album01/Song.mp3", nil, metadata.ParsedTags{
+					"artist":    []string{"Joe"},
+					"orchestra": []string{"Some Orchestra"},
+					"choir":     []string{"Some Choir"},
+				})
+				p := mapper.mapParticipations(md)
+				Expect(p[model.RoleFromString("orchestra")]).To(ConsistOf(newParticipant("Some Orchestra")))
+				Expect(p).ToNot(HaveKey(model.RoleFromString("choir")))
+			})
+		})
+
 		Describe("mapGenres", func() {
 			var gr model.GenreRepository
 			var ctx context.Context
@@ -36,7 +187,7 @@ var _ = Describe("mapping", func() {
 				ds := &tests.MockDataStore{}
 				gr = ds.Genre(ctx)
 				gr = newCachedGenreRepository(ctx, gr)
-				mapper = NewMediaFileMapper("/", gr)
+				mapper = NewMediaFileMapper("/", gr, nil)
 			})
 
 			It("returns empty if no genres are available", func() {
@@ -73,12 +224,85 @@ var _ = Describe("mapping", func() {
 				Expect(gs).To(HaveLen(1))
 				Expect(gs[0].Name).To(Equal("New Wave"))
 			})
+
+			It("folds merged variants into their canonical value", func() {
+				// Mappings recorded by DataStore.MergeTagValues, so a rescan does not re-create
+				// the variants from the file tags
+				mapper = NewMediaFileMapper("/", gr, model.TagMappings{
+					"genre": {"hiphop": "Hip-Hop", "hip hop": "Hip-Hop"},
+				})
+				g, gs := mapper.mapGenres([]string{"hiphop", "Hip Hop", "Rock"})
+				Expect(g).To(Equal("Hip-Hop"))
+				Expect(gs).To(HaveLen(2))
+				Expect(gs[0].Name).To(Equal("Hip-Hop"))
+				Expect(gs[1].Name).To(Equal("Rock"))
+			})
+		})
+
+		Describe("mapTags", func() {
+			It("folds merged variants into their canonical value", func() {
+				mapper = NewMediaFileMapper("/music", nil, model.TagMappings{
+					"mood": {"chill-out": "Chill"},
+				})
+				md := metadata.NewTag("/music/a.mp3", nil, metadata.ParsedTags{"mood": []string{"chill-out"}})
+				Expect(mapper.mapTags(md)).To(ContainElement(model.NewTag("mood", "Chill")))
+			})
+		})
+
+		Describe("albumID", func() {
+			BeforeEach(func() {
+				DeferCleanup(configtest.SetupConfig())
+				mapper = NewMediaFileMapper("/music", nil, nil)
+			})
+
+			newTags := func(filePath string, tags map[string]string) metadata.Tags {
+				parsed := metadata.ParsedTags{}
+				for k, v := range tags {
+					parsed[k] = []string{v}
+				}
+				return metadata.NewTag(filePath, nil, parsed)
+			}
+
+			It("groups tracks without an album artist by folder", func() {
+				md1 := newTags("/music/sampler/01.mp3", map[string]string{"album": "Sampler", "artist": "Joe"})
+				md2 := newTags("/music/sampler/02.mp3", map[string]string{"album": "Sampler", "artist": "Jane"})
+				Expect(mapper.albumID(md1, "")).To(Equal(mapper.albumID(md2, "")))
+			})
+
+			It("keeps grouping by album artist when the tag is present", func() {
+				md1 := newTags("/music/a/01.mp3", map[string]string{"album": "Album", "albumartist": "Band", "artist": "Joe"})
+				md2 := newTags("/music/b/02.mp3", map[string]string{"album": "Album", "albumartist": "Band", "artist": "Jane"})
+				Expect(mapper.albumID(md1, "")).To(Equal(mapper.albumID(md2, "")))
+			})
+
+			It("does not merge same-named albums from different folders", func() {
+				md1 := newTags("/music/joe/greatest hits/01.mp3", map[string]string{"album": "Greatest Hits", "artist": "Joe"})
+				md2 := newTags("/music/jane/greatest hits/01.mp3", map[string]string{"album": "Greatest Hits", "artist": "Jane"})
+				Expect(mapper.albumID(md1, "")).ToNot(Equal(mapper.albumID(md2, "")))
+			})
+		})
+
+		Describe("mapKey", func() {
+			DescribeTable("normalizes keys to the canonical notation",
+				func(key, expected string) {
+					Expect(mapKey(key)).To(Equal(expected))
+				},
+				Entry("empty", "", ""),
+				Entry("natural major", "C", "C"),
+				Entry("lowercase", "c", "C"),
+				Entry("natural minor", "Am", "Am"),
+				Entry("sharp converted to flat", "G#m", "Abm"),
+				Entry("flat kept as-is", "Abm", "Abm"),
+				Entry("long mode names", "g# minor", "Abm"),
+				Entry("explicit major", "F# major", "Gb"),
+				Entry("unparseable value kept as-is", "Dorian", "Dorian"),
+			)
 		})
 
 		Describe("mapDates", func() {
 			var md metadata.Tags
 			BeforeEach(func() {
-				mapper = NewMediaFileMapper("/", nil)
+				mapper = NewMediaFileMapper("/", nil, nil)
 			})
 			Context("when all date fields are provided", func() {
 				BeforeEach(func() {
@@ -0,0 +1,70 @@
+// Package chaostest provides a metadata.Extractor decorator that injects reproducible faults
+// into scans: random latency, seeded per-operation failures and mid-scan mutation hooks. It is
+// meant to be used in tests only, to exercise the scanner's error handling and reconciliation
+// paths under adverse conditions.
+package chaostest
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/scanner/metadata"
+)
+
+// ChaosExtractor wraps another metadata.Extractor. All fault toggles can be changed between
+// scans (ex: to follow a chaotic scan with a clean one), but not while a scan is running.
+type ChaosExtractor struct {
+	// MaxLatency adds a random delay of up to this duration to each Parse call
+	MaxLatency time.Duration
+	// FailureProbability is the chance (0.0 to 1.0) of each Parse call failing with an error
+	FailureProbability float64
+	// AfterNParses triggers OnParse once, right before the Nth Parse call is processed
+	AfterNParses int
+	// OnParse is a hook that can mutate the music folder, simulating files changing mid-scan
+	OnParse func()
+
+	wrapped metadata.Extractor
+	rnd     *rand.Rand
+	parses  int
+	mu      sync.Mutex
+}
+
+// NewChaosExtractor decorates the given extractor. The seed makes the sequence of injected
+// latencies and failures reproducible across runs.
+func NewChaosExtractor(wrapped metadata.Extractor, seed int64) *ChaosExtractor {
+	return &ChaosExtractor{
+		wrapped: wrapped,
+		rnd:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (e *ChaosExtractor) Parse(files ...string) (map[string]metadata.ParsedTags, error) {
+	e.mu.Lock()
+	var latency time.Duration
+	if e.MaxLatency > 0 {
+		latency = time.Duration(e.rnd.Int63n(int64(e.MaxLatency)))
+	}
+	fail := e.rnd.Float64() < e.FailureProbability
+	e.parses++
+	fireHook := e.parses == e.AfterNParses && e.OnParse != nil
+	e.mu.Unlock()
+
+	time.Sleep(latency)
+	if fireHook {
+		e.OnParse()
+	}
+	if fail {
+		return nil, fmt.Errorf("chaos: injected failure parsing %d file(s)", len(files))
+	}
+	return e.wrapped.Parse(files...)
+}
+
+func (e *ChaosExtractor) CustomMappings() metadata.ParsedTags {
+	return e.wrapped.CustomMappings()
+}
+
+func (e *ChaosExtractor) Version() string {
+	return e.wrapped.Version() + "+chaos"
+}
@@ -0,0 +1,82 @@
+package chaostest
+
+import (
+	"github.com/navidrome/navidrome/scanner/metadata"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeExtractor struct {
+	parses int
+}
+
+func (e *fakeExtractor) Parse(files ...string) (map[string]metadata.ParsedTags, error) {
+	e.parses++
+	result := map[string]metadata.ParsedTags{}
+	for _, f := range files {
+		result[f] = metadata.ParsedTags{"title": {f}}
+	}
+	return result, nil
+}
+
+func (e *fakeExtractor) CustomMappings() metadata.ParsedTags { return nil }
+
+func (e *fakeExtractor) Version() string { return "fake" }
+
+var _ = Describe("ChaosExtractor", func() {
+	var wrapped *fakeExtractor
+
+	BeforeEach(func() {
+		wrapped = &fakeExtractor{}
+	})
+
+	It("delegates to the wrapped extractor when no faults are configured", func() {
+		sut := NewChaosExtractor(wrapped, 1)
+		tags, err := sut.Parse("test.mp3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tags).To(HaveKey("test.mp3"))
+		Expect(sut.Version()).To(Equal("fake+chaos"))
+	})
+
+	It("injects failures with the configured probability", func() {
+		sut := NewChaosExtractor(wrapped, 1)
+		sut.FailureProbability = 0.5
+		failures := 0
+		for i := 0; i < 100; i++ {
+			if _, err := sut.Parse("test.mp3"); err != nil {
+				failures++
+			}
+		}
+		Expect(failures).To(BeNumerically("~", 50, 15))
+		Expect(wrapped.parses).To(Equal(100 - failures))
+	})
+
+	It("produces the same failure sequence for the same seed", func() {
+		failuresFor := func(seed int64) []bool {
+			sut := NewChaosExtractor(&fakeExtractor{}, seed)
+			sut.FailureProbability = 0.5
+			var failures []bool
+			for i := 0; i < 20; i++ {
+				_, err := sut.Parse("test.mp3")
+				failures = append(failures, err != nil)
+			}
+			return failures
+		}
+		Expect(failuresFor(42)).To(Equal(failuresFor(42)))
+		Expect(failuresFor(42)).ToNot(Equal(failuresFor(1)))
+	})
+
+	It("invokes the mutation hook once, after the configured number of calls", func() {
+		sut := NewChaosExtractor(wrapped, 1)
+		calls := 0
+		sut.AfterNParses = 3
+		sut.OnParse = func() { calls++ }
+		for i := 0; i < 5; i++ {
+			_, _ = sut.Parse("test.mp3")
+			if i < 2 {
+				Expect(calls).To(Equal(0))
+			}
+		}
+		Expect(calls).To(Equal(1))
+	})
+})
@@ -0,0 +1,108 @@
+package metadata_test
+
+import (
+	"errors"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// flakyExtractor fails (or returns nothing) for selected paths and records every path it
+// was asked to parse, so the specs can assert the fallback doesn't double the cost for
+// files the primary handles fine
+type flakyExtractor struct {
+	id       string
+	failOn   map[string]bool
+	failAll  bool
+	received *[]string
+}
+
+func (e flakyExtractor) Parse(files ...string) (map[string]metadata.ParsedTags, error) {
+	*e.received = append(*e.received, files...)
+	if e.failAll {
+		return nil, errors.New("boom")
+	}
+	result := map[string]metadata.ParsedTags{}
+	for _, file := range files {
+		if e.failOn[file] {
+			continue
+		}
+		result[file] = metadata.ParsedTags{"title": []string{"parsed by " + e.id}}
+	}
+	return result, nil
+}
+
+func (e flakyExtractor) CustomMappings() metadata.ParsedTags { return nil }
+func (e flakyExtractor) Version() string                     { return "fake" }
+
+var _ = Describe("Extractor fallback chain", func() {
+	const good = "tests/fixtures/test.mp3"
+	const bad = "tests/fixtures/test.ogg"
+
+	var primaryCalls, fallbackCalls []string
+
+	register := func(primary, fallback flakyExtractor) {
+		metadata.RegisterExtractor("stub-primary", primary)
+		metadata.RegisterExtractor("stub-fallback", fallback)
+	}
+
+	BeforeEach(func() {
+		primaryCalls, fallbackCalls = nil, nil
+		conf.Server.Scanner.ExtractorFallback = "stub-fallback"
+		DeferCleanup(func() { conf.Server.Scanner.ExtractorFallback = "" })
+	})
+
+	It("retries only the failed paths with the fallback", func() {
+		register(
+			flakyExtractor{id: "stub-primary", failOn: map[string]bool{bad: true}, received: &primaryCalls},
+			flakyExtractor{id: "stub-fallback", received: &fallbackCalls},
+		)
+
+		mds, err := metadata.ExtractWith("stub-primary", good, bad)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mds).To(HaveLen(2))
+		Expect(mds[good].Title()).To(Equal("parsed by stub-primary"))
+		Expect(mds[bad].Title()).To(Equal("parsed by stub-fallback"))
+
+		Expect(primaryCalls).To(ConsistOf(good, bad))
+		Expect(fallbackCalls).To(ConsistOf(bad))
+	})
+
+	It("annotates each result with the extractor that produced it", func() {
+		register(
+			flakyExtractor{id: "stub-primary", failOn: map[string]bool{bad: true}, received: &primaryCalls},
+			flakyExtractor{id: "stub-fallback", received: &fallbackCalls},
+		)
+
+		mds, err := metadata.ExtractWith("stub-primary", good, bad)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mds[good].Extractor()).To(Equal("stub-primary"))
+		Expect(mds[bad].Extractor()).To(Equal("stub-fallback"))
+	})
+
+	It("retries everything when the primary errors out", func() {
+		register(
+			flakyExtractor{id: "stub-primary", failAll: true, received: &primaryCalls},
+			flakyExtractor{id: "stub-fallback", received: &fallbackCalls},
+		)
+
+		mds, err := metadata.ExtractWith("stub-primary", good, bad)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mds).To(HaveLen(2))
+		Expect(fallbackCalls).To(ConsistOf(good, bad))
+	})
+
+	It("propagates the primary error when no fallback is configured", func() {
+		conf.Server.Scanner.ExtractorFallback = ""
+		register(
+			flakyExtractor{id: "stub-primary", failAll: true, received: &primaryCalls},
+			flakyExtractor{id: "stub-fallback", received: &fallbackCalls},
+		)
+
+		_, err := metadata.ExtractWith("stub-primary", good, bad)
+		Expect(err).To(MatchError("boom"))
+		Expect(fallbackCalls).To(BeEmpty())
+	})
+})
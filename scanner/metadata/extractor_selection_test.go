@@ -0,0 +1,60 @@
+package metadata_test
+
+import (
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeExtractor tags every parsed file with its own id, so the specs can tell which
+// extractor handled a given library
+type fakeExtractor struct {
+	id string
+}
+
+func (e fakeExtractor) Parse(files ...string) (map[string]metadata.ParsedTags, error) {
+	result := map[string]metadata.ParsedTags{}
+	for _, file := range files {
+		result[file] = metadata.ParsedTags{"title": []string{"parsed by " + e.id}}
+	}
+	return result, nil
+}
+
+func (e fakeExtractor) CustomMappings() metadata.ParsedTags { return nil }
+func (e fakeExtractor) Version() string                     { return "fake" }
+
+var _ = Describe("ExtractWith", func() {
+	BeforeEach(func() {
+		metadata.RegisterExtractor("fake-a", fakeExtractor{id: "fake-a"})
+		metadata.RegisterExtractor("fake-b", fakeExtractor{id: "fake-b"})
+	})
+
+	titleFrom := func(extractorID string) string {
+		mds, err := metadata.ExtractWith(extractorID, "tests/fixtures/test.mp3")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mds).To(HaveLen(1))
+		return mds["tests/fixtures/test.mp3"].Title()
+	}
+
+	It("uses the extractor each library asks for", func() {
+		libs := []model.Library{
+			{ID: 1, Extractor: "fake-a"},
+			{ID: 2, Extractor: "fake-b"},
+		}
+		Expect(titleFrom(libs[0].Extractor)).To(Equal("parsed by fake-a"))
+		Expect(titleFrom(libs[1].Extractor)).To(Equal("parsed by fake-b"))
+	})
+
+	It("falls back to the global option for libraries without an override", func() {
+		conf.Server.Scanner.Extractor = "fake-b"
+		DeferCleanup(func() { conf.Server.Scanner.Extractor = "taglib" })
+
+		Expect(titleFrom("")).To(Equal("parsed by fake-b"))
+	})
+
+	It("lists the registered extractors", func() {
+		Expect(metadata.ExtractorNames()).To(ContainElements("fake-a", "fake-b", "taglib", "ffmpeg"))
+	})
+})
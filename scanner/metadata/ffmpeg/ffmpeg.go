@@ -167,6 +167,7 @@ func (e *Extractor) parseInfo(info string) map[string][]string {
 
 		match = audioStreamRx.FindStringSubmatch(line)
 		if len(match) > 0 {
+			tags["codec"] = []string{e.parseCodec(match[1])}
 			tags["samplerate"] = []string{match[2]}
 			tags["channels"] = []string{e.parseChannels(match[3])}
 		}
@@ -190,6 +191,13 @@ func (e *Extractor) parseDuration(tag string) string {
 	return strconv.FormatFloat(d.Sub(zeroTime).Seconds(), 'f', 2, 32)
 }
 
+// parseCodec extracts the codec name from the stream description, which may carry profile and
+// fourcc details (ex: "aac (LC) (mp4a / 0x6134706D)")
+func (e *Extractor) parseCodec(tag string) string {
+	codec, _, _ := strings.Cut(tag, " ")
+	return codec
+}
+
 func (e *Extractor) parseChannels(tag string) string {
 	switch tag {
 	case "mono":
@@ -192,6 +192,24 @@ Input #0, wav, from '/Users/deluan/Music/Music/Media/_/multichannel/Nums_7dot1_2
 			Expect(md).To(HaveKeyWithValue("samplerate", []string{"48000"}))
 		})
 
+		It("parse codec from the stream", func() {
+			const output = `
+Input #0, mov,mp4,m4a,3gp,3g2,mj2, from '/Users/deluan/Music/iTunes/iTunes Media/Music/Song.m4a':
+  Duration: 00:00:01.02, start: 0.000000, bitrate: 1371 kb/s
+    Stream #0:0(eng): Audio: alac (alac / 0x63616C61), 44100 Hz, stereo, s16p, 706 kb/s (default)`
+			md, _ := e.extractMetadata("tests/fixtures/test.mp3", output)
+			Expect(md).To(HaveKeyWithValue("codec", []string{"alac"}))
+		})
+
+		It("parse codec from the stream, dropping profile details", func() {
+			const output = `
+Input #0, mov,mp4,m4a,3gp,3g2,mj2, from '/Users/deluan/Music/iTunes/iTunes Media/Music/Song.m4a':
+  Duration: 00:00:01.02, start: 0.000000, bitrate: 1371 kb/s
+    Stream #0:0(eng): Audio: aac (LC) (mp4a / 0x6134706D), 44100 Hz, stereo, fltp, 262 kb/s (default)`
+			md, _ := e.extractMetadata("tests/fixtures/test.mp3", output)
+			Expect(md).To(HaveKeyWithValue("codec", []string{"aac"}))
+		})
+
 		It("parses stream level tags", func() {
 			const output = `
 Input #0, ogg, from './01-02 Drive (Teku).opus':
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -25,59 +26,151 @@ type Extractor interface {
 	Version() string
 }
 
+// Writer is an optional capability of an Extractor: embedding the Navidrome track ID into a
+// file as a NAVIDROME_ID tag, so the track keeps its identity across renames and tag edits
+// (see Scanner.WriteTrackID). Implementations must never overwrite an ID already present
+type Writer interface {
+	WriteTrackID(filePath, id string) error
+}
+
 var extractors = map[string]Extractor{}
 
 func RegisterExtractor(id string, parser Extractor) {
 	extractors[id] = parser
 }
 
+// WriterFor returns the named extractor (or the configured default, when the name is empty)
+// as a Writer, when it implements the capability
+func WriterFor(extractorID string) (Writer, bool) {
+	if extractorID == "" {
+		extractorID = conf.Server.Scanner.Extractor
+	}
+	w, ok := extractors[extractorID].(Writer)
+	return w, ok
+}
+
 func LogExtractors() {
 	for id, p := range extractors {
 		log.Debug("Registered metadata extractor", "id", id, "version", p.Version())
 	}
 }
 
+// ExtractorNames returns the ids of all registered extractors, sorted, for use in
+// validation error messages and logs
+func ExtractorNames() []string {
+	names := make([]string, 0, len(extractors))
+	for id := range extractors {
+		names = append(names, id)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Extract parses the given files with the extractor configured in Scanner.Extractor
 func Extract(files ...string) (map[string]Tags, error) {
-	p, ok := extractors[conf.Server.Scanner.Extractor]
+	return ExtractWith("", files...)
+}
+
+// ExtractWith parses the given files with the named extractor. An empty name (libraries
+// without a per-library override) selects the global Scanner.Extractor option
+func ExtractWith(extractorID string, files ...string) (map[string]Tags, error) {
+	if extractorID == "" {
+		extractorID = conf.Server.Scanner.Extractor
+	}
+	p, ok := extractors[extractorID]
 	if !ok {
-		log.Warn("Invalid 'Scanner.Extractor' option. Using default", "requested", conf.Server.Scanner.Extractor,
-			"validOptions", "ffmpeg,taglib", "default", consts.DefaultScannerExtractor)
-		p = extractors[consts.DefaultScannerExtractor]
+		log.Warn("Invalid extractor option. Using default", "requested", extractorID,
+			"validOptions", strings.Join(ExtractorNames(), ","), "default", consts.DefaultScannerExtractor)
+		extractorID = consts.DefaultScannerExtractor
+		p = extractors[extractorID]
+	}
+	fallbackID := conf.Server.Scanner.ExtractorFallback
+	fb, hasFallback := extractors[fallbackID]
+	if fallbackID != "" && !hasFallback {
+		log.Warn("Invalid 'Scanner.ExtractorFallback' option. Ignoring", "requested", fallbackID,
+			"validOptions", strings.Join(ExtractorNames(), ","))
 	}
+	hasFallback = hasFallback && fallbackID != extractorID
 
 	extractedTags, err := p.Parse(files...)
 	if err != nil {
-		return nil, err
+		if !hasFallback {
+			return nil, err
+		}
+		log.Warn("Extractor failed, retrying all files with the fallback", "extractor", extractorID,
+			"fallback", fallbackID, err)
+		extractedTags = map[string]ParsedTags{}
+	}
+
+	// Retry only the files the primary extractor could not parse, so files that parse
+	// fine don't pay for a second extraction
+	fallbackTags := map[string]ParsedTags{}
+	if hasFallback {
+		var failed []string
+		for _, filePath := range files {
+			if len(extractedTags[filePath]) == 0 {
+				failed = append(failed, filePath)
+			}
+		}
+		if len(failed) > 0 {
+			fallbackTags, err = fb.Parse(failed...)
+			if err != nil {
+				log.Warn("Fallback extractor failed", "fallback", fallbackID, "files", len(failed), err)
+				fallbackTags = map[string]ParsedTags{}
+			}
+		}
 	}
 
 	result := map[string]Tags{}
-	for filePath, tags := range extractedTags {
+	for _, filePath := range files {
+		tags, found := extractedTags[filePath]
+		producedBy, mappings := extractorID, p.CustomMappings()
+		if len(tags) == 0 {
+			if fbTags, ok := fallbackTags[filePath]; ok && len(fbTags) > 0 {
+				log.Debug("Fallback extractor parsed file the primary could not", "filePath", filePath,
+					"extractor", extractorID, "fallback", fallbackID)
+				tags, found = fbTags, true
+				producedBy, mappings = fallbackID, fb.CustomMappings()
+			}
+		}
+		if !found {
+			continue
+		}
 		fileInfo, err := os.Stat(filePath)
 		if err != nil {
 			log.Warn("Error stating file. Skipping", "filePath", filePath, err)
 			continue
 		}
 
-		tags = tags.Map(p.CustomMappings())
-		result[filePath] = NewTag(filePath, fileInfo, tags)
+		tags = tags.Map(mappings)
+		t := NewTag(filePath, fileInfo, tags)
+		t.extractor = producedBy
+		result[filePath] = t
 	}
 
 	return result, nil
 }
 
 func NewTag(filePath string, fileInfo os.FileInfo, tags ParsedTags) Tags {
+	// Fold tag names to their canonical form first, so values tagged under different
+	// names/cases for the same tag are merged (and de-duplicated) under a single name
+	folded := ParsedTags{}
 	for t, values := range tags {
+		name := CanonicalTagName(t)
+		folded[name] = append(folded[name], values...)
+	}
+	for t, values := range folded {
 		values = removeDuplicatesAndEmpty(values)
 		if len(values) == 0 {
-			delete(tags, t)
+			delete(folded, t)
 			continue
 		}
-		tags[t] = values
+		folded[t] = values
 	}
 	return Tags{
 		filePath: filePath,
 		fileInfo: fileInfo,
-		Tags:     tags,
+		Tags:     folded,
 	}
 }
 
@@ -117,11 +210,15 @@ func (p ParsedTags) Map(customMappings ParsedTags) ParsedTags {
 }
 
 type Tags struct {
-	filePath string
-	fileInfo os.FileInfo
-	Tags     ParsedTags
+	filePath  string
+	fileInfo  os.FileInfo
+	extractor string
+	Tags      ParsedTags
 }
 
+// Extractor returns the id of the extractor that produced these tags, for logging
+func (t Tags) Extractor() string { return t.extractor }
+
 // Common tags
 
 func (t Tags) Title() string  { return t.getFirstTagValue("title", "sort_name", "titlesort") }
@@ -130,10 +227,30 @@ func (t Tags) Artist() string { return t.getFirstTagValue("artist", "sort_artist
 func (t Tags) AlbumArtist() string {
 	return t.getFirstTagValue("album_artist", "album artist", "albumartist")
 }
-func (t Tags) SortTitle() string           { return t.getSortTag("tsot", "title", "name") }
-func (t Tags) SortAlbum() string           { return t.getSortTag("tsoa", "album") }
-func (t Tags) SortArtist() string          { return t.getSortTag("tsop", "artist") }
-func (t Tags) SortAlbumArtist() string     { return t.getSortTag("tso2", "albumartist", "album_artist") }
+func (t Tags) SortTitle() string       { return t.getSortTag("tsot", "title", "name") }
+func (t Tags) SortAlbum() string       { return t.getSortTag("tsoa", "album") }
+func (t Tags) SortArtist() string      { return t.getSortTag("tsop", "artist") }
+func (t Tags) SortAlbumArtist() string { return t.getSortTag("tso2", "albumartist", "album_artist") }
+
+// SortTagsFor returns the sort names tagged for the given role (ex: conductorsort,
+// sort_lyricist), in tag order, so they can be matched positionally with the role's artists.
+// Composer additionally accepts the id3 TSOC frame.
+func (t Tags) SortTagsFor(role string) []string {
+	formats := []string{"sort%s", "sort_%s", "sort-%s", "%ssort", "%s_sort", "%s-sort"}
+	var all []string
+	if role == "composer" {
+		all = append(all, "tsoc")
+	}
+	for _, format := range formats {
+		all = append(all, fmt.Sprintf(format, role))
+	}
+	for _, tag := range all {
+		if values, ok := t.Tags[tag]; ok {
+			return values
+		}
+	}
+	return nil
+}
 func (t Tags) Genres() []string            { return t.getAllTagValues("genre") }
 func (t Tags) Date() (int, string)         { return t.getDate("date") }
 func (t Tags) OriginalDate() (int, string) { return t.getDate("originaldate") }
@@ -146,9 +263,20 @@ func (t Tags) DiscSubtitle() string {
 	return t.getFirstTagValue("tsst", "discsubtitle", "setsubtitle")
 }
 func (t Tags) CatalogNum() string { return t.getFirstTagValue("catalognumber") }
-func (t Tags) Bpm() int           { return (int)(math.Round(t.getFloat("tbpm", "bpm", "fbpm"))) }
+func (t Tags) ISRCs() []string    { return t.getAllTagValues("isrc") }
+func (t Tags) Key() string        { return t.getFirstTagValue("initialkey", "tkey", "key") }
 func (t Tags) HasPicture() bool   { return t.getFirstTagValue("has_picture") != "" }
 
+func (t Tags) Bpm() int {
+	// Some taggers write BPM as a float, and some locales use a decimal comma
+	tag := strings.Replace(t.getFirstTagValue("tbpm", "bpm", "fbpm"), ",", ".", 1)
+	value, err := strconv.ParseFloat(tag, 64)
+	if err != nil {
+		return 0
+	}
+	return int(math.Round(value))
+}
+
 // MusicBrainz Identifiers
 
 func (t Tags) MbzReleaseTrackID() string {
@@ -159,6 +287,7 @@ func (t Tags) MbzRecordingID() string {
 	return t.getMbzID("musicbrainz_trackid", "musicbrainz track id")
 }
 func (t Tags) MbzAlbumID() string { return t.getMbzID("musicbrainz_albumid", "musicbrainz album id") }
+func (t Tags) MbzWorkID() string  { return t.getMbzID("musicbrainz_workid", "musicbrainz work id") }
 func (t Tags) MbzArtistID() string {
 	return t.getMbzID("musicbrainz_artistid", "musicbrainz artist id")
 }
@@ -185,19 +314,41 @@ func (t Tags) RGTrackPeak() float64 { return t.getPeakValue("replaygain_track_pe
 
 // File properties
 
-func (t Tags) Duration() float32           { return float32(t.getFloat("duration")) }
-func (t Tags) SampleRate() int             { return t.getInt("samplerate") }
+func (t Tags) Duration() float32 { return float32(t.getFloat("duration")) }
+
+// DurationMs returns the track length in integer milliseconds, preferring the exact value
+// reported by the extractor over the rounded float seconds fallback
+func (t Tags) DurationMs() int64 {
+	if ms := t.getInt("lengthinmilliseconds"); ms > 0 {
+		return int64(ms)
+	}
+	return int64(math.Round(t.getFloat("duration") * 1000))
+}
+func (t Tags) SampleRate() int { return t.getInt("samplerate") }
+
+// Codec returns the actual audio codec inside the container, when the extractor can tell. It
+// matters for containers that hold more than one codec (ex: .m4a carries both AAC and ALAC);
+// an empty value means callers should fall back to the file suffix
+func (t Tags) Codec() string               { return strings.ToLower(t.getFirstTagValue("codec")) }
+func (t Tags) BitDepth() int               { return t.getInt("bitdepth") }
 func (t Tags) BitRate() int                { return t.getInt("bitrate") }
 func (t Tags) Channels() int               { return t.getInt("channels") }
 func (t Tags) ModificationTime() time.Time { return t.fileInfo.ModTime() }
 func (t Tags) Size() int64                 { return t.fileInfo.Size() }
 func (t Tags) FilePath() string            { return t.filePath }
-func (t Tags) Suffix() string              { return strings.ToLower(strings.TrimPrefix(path.Ext(t.filePath), ".")) }
+
+// NavidromeID returns the track ID embedded in the file by a previous import (see
+// Scanner.WriteTrackID), or empty when the file does not carry one
+func (t Tags) NavidromeID() string { return t.getFirstTagValue("navidrome_id") }
+func (t Tags) Suffix() string      { return strings.ToLower(strings.TrimPrefix(path.Ext(t.filePath), ".")) }
+
+// BirthTime returns the time the file was created on disk, or the zero time on filesystems
+// that don't track it, so callers can tell the two cases apart
 func (t Tags) BirthTime() time.Time {
 	if ts := times.Get(t.fileInfo); ts.HasBirthTime() {
 		return ts.BirthTime()
 	}
-	return time.Now()
+	return time.Time{}
 }
 
 func (t Tags) Lyrics() string {
@@ -214,23 +365,31 @@ func (t Tags) Lyrics() string {
 		lyricList = append(lyricList, *lyrics)
 	}
 
-	for tag, value := range t.Tags {
+	// Tags is a map, so the lyrics-* keys must be sorted to keep the serialized list (and
+	// with it the stored row) stable across scans
+	var lyricTags []string
+	for tag := range t.Tags {
 		if strings.HasPrefix(tag, "lyrics-") {
-			language := strings.TrimSpace(strings.TrimPrefix(tag, "lyrics-"))
+			lyricTags = append(lyricTags, tag)
+		}
+	}
+	sort.Strings(lyricTags)
 
-			if language == "" {
-				language = "xxx"
-			}
+	for _, tag := range lyricTags {
+		language := strings.TrimSpace(strings.TrimPrefix(tag, "lyrics-"))
 
-			for _, text := range value {
-				lyrics, err := model.ToLyrics(language, text)
-				if err != nil {
-					log.Warn("Unexpected failure occurred when parsing lyrics", "file", t.filePath, "error", err)
-					continue
-				}
+		if language == "" {
+			language = "xxx"
+		}
 
-				lyricList = append(lyricList, *lyrics)
+		for _, text := range t.Tags[tag] {
+			lyrics, err := model.ToLyrics(language, text)
+			if err != nil {
+				log.Warn("Unexpected failure occurred when parsing lyrics", "file", t.filePath, "error", err)
+				continue
 			}
+
+			lyricList = append(lyricList, *lyrics)
 		}
 	}
 
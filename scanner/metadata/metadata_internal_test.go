@@ -68,6 +68,53 @@ var _ = Describe("Tags", func() {
 		})
 	})
 
+	Describe("SortTagsFor", func() {
+		It("returns all values of the role's sort tag", func() {
+			md := &Tags{}
+			md.Tags = map[string][]string{
+				"conductorsort": {"Karajan, Herbert von"},
+				"lyricist_sort": {"Taupin, Bernie", "John, Elton"},
+			}
+
+			Expect(md.SortTagsFor("conductor")).To(Equal([]string{"Karajan, Herbert von"}))
+			Expect(md.SortTagsFor("lyricist")).To(Equal([]string{"Taupin, Bernie", "John, Elton"}))
+			Expect(md.SortTagsFor("arranger")).To(BeNil())
+		})
+		It("accepts the id3 TSOC frame for composers", func() {
+			md := &Tags{}
+			md.Tags = map[string][]string{
+				"tsoc": {"Beethoven, Ludwig van"},
+			}
+
+			Expect(md.SortTagsFor("composer")).To(Equal([]string{"Beethoven, Ludwig van"}))
+		})
+	})
+
+	Describe("CanonicalTagName", func() {
+		DescribeTable("folds case and the known aliases",
+			func(name string, expected string) {
+				Expect(CanonicalTagName(name)).To(Equal(expected))
+			},
+			Entry(nil, "mood", "mood"),
+			Entry(nil, "Mood", "mood"),
+			Entry(nil, "TMOO", "mood"),
+			Entry(nil, "Content Group", "grouping"),
+			Entry(nil, "custom-tag", "custom-tag"),
+		)
+
+		It("merges values tagged under different names/cases of the same tag", func() {
+			md := NewTag("/music/artist/album01/Song.mp3", nil, ParsedTags{
+				"Genre": []string{"Electronic"},
+				"TMOO":  []string{"happy"},
+				"mood":  []string{"happy", "sad"},
+			})
+			Expect(md.Tags).ToNot(HaveKey("Genre"))
+			Expect(md.Tags).ToNot(HaveKey("TMOO"))
+			Expect(md.Genres()).To(Equal([]string{"Electronic"}))
+			Expect(md.Tags["mood"]).To(ConsistOf("happy", "sad"))
+		})
+	})
+
 	Describe("removeDuplicatesAndEmpty", func() {
 		It("removes duplicates", func() {
 			md := NewTag("/music/artist/album01/Song.mp3", nil, ParsedTags{
@@ -100,6 +147,13 @@ var _ = Describe("Tags", func() {
 		It("rounds a floating point fBPM tag", func() {
 			Expect(t.Bpm()).To(Equal(142))
 		})
+
+		It("accepts a decimal comma", func() {
+			t = &Tags{Tags: map[string][]string{
+				"tbpm": []string{"141,7"},
+			}}
+			Expect(t.Bpm()).To(Equal(142))
+		})
 	})
 
 	Describe("ReplayGain", func() {
@@ -112,6 +166,8 @@ var _ = Describe("Tags", func() {
 			},
 			Entry("0", "0", 0.0),
 			Entry("1.2dB", "1.2dB", 1.2),
+			Entry("-7.25 dB", "-7.25 dB", -7.25),
+			Entry("+2.3dB", "+2.3dB", 2.3),
 			Entry("Infinity", "Infinity", 0.0),
 			Entry("Invalid value", "INVALID VALUE", 0.0),
 		)
@@ -0,0 +1,28 @@
+package metadata
+
+import "strings"
+
+// tagAliases maps the names different container formats use for the same tag to its canonical
+// (lowercase) name. ID3 frame names and their spelled-out variants all fold into the
+// vorbis-style name the rest of the code references, so a library mixing formats doesn't end up
+// with the same tag fragmented under several names. Note that TCON is deliberately absent: its
+// raw value is a numeric genre code, which the extractors already decode into "genre"
+var tagAliases = map[string]string{
+	"tmoo":          "mood",
+	"tit1":          "grouping",
+	"contentgroup":  "grouping",
+	"content group": "grouping",
+	"grp1":          "grouping",
+	"tlan":          "language",
+	"tsrc":          "isrc",
+}
+
+// CanonicalTagName returns the canonical name for the given tag name: lowercase, with the known
+// per-format aliases folded (ex: TMOO, Mood and mood all map to "mood")
+func CanonicalTagName(name string) string {
+	name = strings.ToLower(name)
+	if canonical, ok := tagAliases[name]; ok {
+		return canonical
+	}
+	return name
+}
@@ -38,6 +38,11 @@ func (e *Extractor) Version() string {
 	return Version()
 }
 
+// WriteTrackID implements the optional metadata.Writer capability (see Scanner.WriteTrackID)
+func (e *Extractor) WriteTrackID(filePath, id string) error {
+	return WriteTrackID(filePath, id)
+}
+
 func (e *Extractor) extractMetadata(filePath string) (metadata.ParsedTags, error) {
 	tags, err := Read(filePath)
 	if err != nil {
@@ -3,6 +3,7 @@ package taglib
 import (
 	"io/fs"
 	"os"
+	"path/filepath"
 
 	"github.com/navidrome/navidrome/scanner/metadata"
 	"github.com/navidrome/navidrome/utils"
@@ -172,6 +173,47 @@ var _ = Describe("Extractor", func() {
 			Entry("correctly parses aiff tags", "test.aiff", "1.00", "1", "44100", "2.00 dB", "0.124972", "2.00 dB", "0.124972", true),
 		)
 
+		DescribeTable("MP4 codec detection",
+			func(file, codec string) {
+				mds, err := e.Parse("tests/fixtures/" + file)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mds).To(HaveLen(1))
+				Expect(mds["tests/fixtures/"+file]).To(HaveKeyWithValue("codec", []string{codec}))
+			},
+			Entry("reports aac for AAC streams", "test.m4a", "aac"),
+			Entry("reports alac for ALAC streams", "test_alac.m4a", "alac"),
+		)
+
+		Describe("WriteTrackID", func() {
+			var testFile string
+
+			BeforeEach(func() {
+				testFile = filepath.Join(GinkgoT().TempDir(), "test.mp3")
+				data, err := os.ReadFile("tests/fixtures/test.mp3")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(os.WriteFile(testFile, data, 0600)).To(Succeed())
+			})
+
+			It("embeds the ID and reads it back", func() {
+				Expect(e.WriteTrackID(testFile, "0123456789abcdef0123456789abcdef")).To(Succeed())
+				mds, err := e.Parse(testFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mds[testFile]).To(HaveKeyWithValue("navidrome_id", []string{"0123456789abcdef0123456789abcdef"}))
+			})
+
+			It("never overwrites an ID already embedded in the file", func() {
+				Expect(e.WriteTrackID(testFile, "0123456789abcdef0123456789abcdef")).To(Succeed())
+				Expect(e.WriteTrackID(testFile, "ffffffffffffffffffffffffffffffff")).To(Succeed())
+				mds, err := e.Parse(testFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mds[testFile]).To(HaveKeyWithValue("navidrome_id", []string{"0123456789abcdef0123456789abcdef"}))
+			})
+
+			It("returns an error for files it cannot parse", func() {
+				Expect(e.WriteTrackID("tests/fixtures/itunes-library.xml", "0123456789abcdef0123456789abcdef")).ToNot(Succeed())
+			})
+		})
+
 		// Skip these tests when running as root
 		Context("Access Forbidden", func() {
 			var accessForbiddenFile string
@@ -47,6 +47,35 @@ int taglib_read(const FILENAME_CHAR_T *filename, unsigned long id) {
   go_map_put_int(id, (char *)"channels", props->channels());
   go_map_put_int(id, (char *)"samplerate", props->sampleRate());
 
+  // Bit depth is not part of the generic AudioProperties, only of some format-specific ones
+  int bitDepth = 0;
+  if (const auto *flacProps{dynamic_cast<const TagLib::FLAC::Properties *>(props)}) {
+    bitDepth = flacProps->bitsPerSample();
+  } else if (const auto *mp4Props{dynamic_cast<const TagLib::MP4::Properties *>(props)}) {
+    bitDepth = mp4Props->bitsPerSample();
+    // The MP4 container is ambiguous: the same .m4a suffix holds both AAC and ALAC streams,
+    // and the difference matters for streaming decisions
+    switch (mp4Props->codec()) {
+    case TagLib::MP4::Properties::AAC:
+      go_map_put_str(id, (char *)"codec", (char *)"aac");
+      break;
+    case TagLib::MP4::Properties::ALAC:
+      go_map_put_str(id, (char *)"codec", (char *)"alac");
+      break;
+    default:
+      break;
+    }
+  } else if (const auto *wavProps{dynamic_cast<const TagLib::RIFF::WAV::Properties *>(props)}) {
+    bitDepth = wavProps->bitsPerSample();
+  } else if (const auto *aiffProps{dynamic_cast<const TagLib::RIFF::AIFF::Properties *>(props)}) {
+    bitDepth = aiffProps->bitsPerSample();
+  } else if (const auto *asfProps{dynamic_cast<const TagLib::ASF::Properties *>(props)}) {
+    bitDepth = asfProps->bitsPerSample();
+  }
+  if (bitDepth > 0) {
+    go_map_put_int(id, (char *)"bitdepth", bitDepth);
+  }
+
   // Create a map to collect all the tags
   TagLib::PropertyMap tags = f.file()->properties();
 
@@ -196,6 +225,30 @@ int taglib_read(const FILENAME_CHAR_T *filename, unsigned long id) {
   return 0;
 }
 
+// Embed the Navidrome track ID into the file as a NAVIDROME_ID tag. Files already carrying
+// the tag are left untouched (returns TAGLIB_ID_ALREADY_SET), so an ID written by a previous
+// import can never be overwritten
+int taglib_write_track_id(const FILENAME_CHAR_T *filename, const char *track_id) {
+  TagLib::FileRef f(filename);
+
+  if (f.isNull() || !f.file()->isValid()) {
+    return TAGLIB_ERR_PARSE;
+  }
+
+  TagLib::PropertyMap props = f.file()->properties();
+  if (props.contains("NAVIDROME_ID")) {
+    return TAGLIB_ID_ALREADY_SET;
+  }
+
+  props.insert("NAVIDROME_ID", TagLib::String(track_id, TagLib::String::UTF8));
+  f.file()->setProperties(props);
+  if (!f.file()->save()) {
+    return TAGLIB_ERR_SAVE;
+  }
+
+  return 0;
+}
+
 // Detect if the file has cover art. Returns 1 if the file has cover art, 0 otherwise.
 char has_cover(const TagLib::FileRef f) {
   char hasCover = 0;
@@ -72,6 +72,35 @@ func Read(filename string) (tags map[string][]string, err error) {
 	return m, nil
 }
 
+// WriteTrackID embeds the given track ID into the file as a NAVIDROME_ID tag. Writing is a
+// no-op when the file already carries an ID, so re-imports can call it unconditionally
+func WriteTrackID(filename string, trackID string) (err error) {
+	// Do not crash on failures in the C code/library
+	debug.SetPanicOnFault(true)
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("TagLib: recovered from panic when writing track ID", "file", filename, "error", r)
+			err = fmt.Errorf("TagLib: recovered from panic: %s", r)
+		}
+	}()
+
+	fp := getFilename(filename)
+	defer C.free(unsafe.Pointer(fp))
+	tp := C.CString(trackID)
+	defer C.free(unsafe.Pointer(tp))
+
+	log.Trace("TagLib: writing track ID", "filename", filename, "trackId", trackID)
+	switch C.taglib_write_track_id(fp, tp) {
+	case C.TAGLIB_ERR_PARSE:
+		return fmt.Errorf("cannot parse file media file")
+	case C.TAGLIB_ERR_SAVE:
+		return fmt.Errorf("cannot save file")
+	case C.TAGLIB_ID_ALREADY_SET:
+		log.Trace("TagLib: file already has a track ID. Skipping", "filename", filename)
+	}
+	return nil
+}
+
 var lock sync.RWMutex
 var allMaps = make(map[uint32]map[string][]string)
 var mapsNextID uint32
@@ -1,5 +1,8 @@
 #define TAGLIB_ERR_PARSE -1
 #define TAGLIB_ERR_AUDIO_PROPS -2
+#define TAGLIB_ERR_SAVE -3
+
+#define TAGLIB_ID_ALREADY_SET 1
 
 #ifdef __cplusplus
 extern "C" {
@@ -17,6 +20,7 @@ extern void go_map_put_int(unsigned long id, char *key, int val);
 extern void go_map_put_lyrics(unsigned long id, char *lang, char *val);
 extern void go_map_put_lyric_line(unsigned long id, char *lang, char *text, int time);
 int taglib_read(const FILENAME_CHAR_T *filename, unsigned long id);
+int taglib_write_track_id(const FILENAME_CHAR_T *filename, const char *track_id);
 char* taglib_version();
 
 #ifdef __cplusplus
@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"sync"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// scanMetrics instruments the scanner, labeled by library path. The collectors are always
+// created (incrementing unregistered collectors is cheap), but only exposed on /metrics
+// when Prometheus.Enabled is set
+type scanMetrics struct {
+	foldersProcessed *prometheus.CounterVec
+	filesImported    *prometheus.CounterVec
+	filesSkipped     *prometheus.CounterVec
+	extractionErrors *prometheus.CounterVec
+	tracksMissing    *prometheus.CounterVec
+	folderDuration   *prometheus.HistogramVec
+	extractDuration  *prometheus.HistogramVec
+}
+
+var (
+	scanMetricsInstance *scanMetrics
+	scanMetricsOnce     sync.Once
+)
+
+func getScanMetrics() *scanMetrics {
+	scanMetricsOnce.Do(func() {
+		scanMetricsInstance = newScanMetrics()
+		if conf.Server.Prometheus.Enabled {
+			scanMetricsInstance.register()
+		}
+	})
+	return scanMetricsInstance
+}
+
+func newScanMetrics() *scanMetrics {
+	libLabel := []string{"library"}
+	return &scanMetrics{
+		foldersProcessed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "scanner_folders_processed",
+				Help: "Total folders with changes processed by the scanner, per library",
+			}, libLabel),
+		filesImported: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "scanner_files_imported",
+				Help: "Total files added or updated by the scanner, per library",
+			}, libLabel),
+		filesSkipped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "scanner_files_skipped",
+				Help: "Total unchanged files skipped by the scanner, per library",
+			}, libLabel),
+		extractionErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "scanner_extraction_errors",
+				Help: "Total files whose metadata could not be extracted, per library",
+			}, libLabel),
+		tracksMissing: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "scanner_tracks_missing",
+				Help: "Total tracks marked missing by the scanner, per library",
+			}, libLabel),
+		folderDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "scanner_folder_duration_seconds",
+				Help: "Time spent processing each changed folder, per library",
+			}, libLabel),
+		extractDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "scanner_extraction_batch_duration_seconds",
+				Help: "Latency of each metadata extraction batch, per library",
+			}, libLabel),
+	}
+}
+
+func (m *scanMetrics) register() {
+	collectors := map[string]prometheus.Collector{
+		"scanner_folders_processed":                 m.foldersProcessed,
+		"scanner_files_imported":                    m.filesImported,
+		"scanner_files_skipped":                     m.filesSkipped,
+		"scanner_extraction_errors":                 m.extractionErrors,
+		"scanner_tracks_missing":                    m.tracksMissing,
+		"scanner_folder_duration_seconds":           m.folderDuration,
+		"scanner_extraction_batch_duration_seconds": m.extractDuration,
+	}
+	for name, collector := range collectors {
+		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
+			log.Error("Unable to register scanner metrics", "metric", name, err)
+		}
+	}
+}
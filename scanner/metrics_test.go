@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Scanner metrics", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var root, dir string
+	var progress chan uint32
+	var m *scanMetrics
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+		m = getScanMetrics()
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "album")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track1.mp3"), data, 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "track2.mp3"), data, 0600)).To(Succeed())
+
+		metadata.RegisterExtractor("plain", &taglib.Extractor{})
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "plain"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+	})
+
+	// The library label is the unique temp folder of each spec, so the counters always
+	// start at zero, even with the rest of the suite incrementing the same vectors
+	It("counts processed folders and imported, skipped and missing files", func() {
+		s := NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.ToFloat64(m.foldersProcessed.WithLabelValues(root))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(m.filesImported.WithLabelValues(root))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(m.filesSkipped.WithLabelValues(root))).To(Equal(0.0))
+
+		// An incremental scan with one new file skips the two unchanged ones
+		lastScan := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		data, _ := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(os.WriteFile(filepath.Join(dir, "track3.mp3"), data, 0600)).To(Succeed())
+		s = NewTagScanner(model.Library{ID: 1, Path: root, LastScanAt: lastScan}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err = s.Scan(ctx, false, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.ToFloat64(m.filesImported.WithLabelValues(root))).To(Equal(3.0))
+		Expect(testutil.ToFloat64(m.filesSkipped.WithLabelValues(root))).To(Equal(2.0))
+
+		// Deleting a file marks its track as missing
+		lastScan = time.Now()
+		time.Sleep(10 * time.Millisecond)
+		Expect(os.Remove(filepath.Join(dir, "track3.mp3"))).To(Succeed())
+		s = NewTagScanner(model.Library{ID: 1, Path: root, LastScanAt: lastScan}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err = s.Scan(ctx, false, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.ToFloat64(m.tracksMissing.WithLabelValues(root))).To(Equal(1.0))
+	})
+
+	It("observes the extraction batch latency", func() {
+		before := testutil.CollectAndCount(m.extractDuration)
+		s := NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testutil.CollectAndCount(m.extractDuration)).To(BeNumerically(">", before))
+	})
+})
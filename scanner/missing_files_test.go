@@ -0,0 +1,156 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scanner missing files", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var s FolderScanner
+	var root, dir string
+	var progress chan uint32
+
+	fixture := func() []byte {
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		return data
+	}
+
+	theTrack := func() model.MediaFile {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		return mfs[0]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "artist")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), fixture(), 0600)).To(Succeed())
+
+		metadata.RegisterExtractor("plain", &taglib.Extractor{})
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "plain"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+	})
+
+	It("flags tracks that disappear from disk and clears the flag when they come back", func() {
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Missing).To(BeFalse())
+
+		// The track vanishes from disk: the row stays in the DB, flagged as missing
+		Expect(os.Remove(filepath.Join(dir, "track.mp3"))).To(Succeed())
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		mf := theTrack()
+		Expect(mf.Missing).To(BeTrue())
+		Expect(s.(*TagScanner).cnt.missing).To(Equal(int64(1)))
+
+		al, err := ds.Album(ctx).Get(mf.AlbumID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(al.Missing).To(BeTrue())
+
+		// The file reappears: the flag is cleared on the next scan
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), fixture(), 0600)).To(Succeed())
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Missing).To(BeFalse())
+
+		al, err = ds.Album(ctx).Get(mf.AlbumID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(al.Missing).To(BeFalse())
+	})
+
+	It("clears the flag of a returning file whose modification time did not change", func() {
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		info, err := os.Stat(filepath.Join(dir, "track.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		modTime := info.ModTime()
+
+		lastScan := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		Expect(os.Remove(filepath.Join(dir, "track.mp3"))).To(Succeed())
+		s = NewTagScanner(model.Library{ID: 1, Path: root, LastScanAt: lastScan}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err = s.Scan(ctx, false, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Missing).To(BeTrue())
+
+		// The file is restored with its original modification time, which is older than the
+		// track's row in the DB: the flag must still be cleared by an incremental scan
+		lastScan = time.Now()
+		time.Sleep(10 * time.Millisecond)
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), fixture(), 0600)).To(Succeed())
+		Expect(os.Chtimes(filepath.Join(dir, "track.mp3"), modTime, modTime)).To(Succeed())
+		s = NewTagScanner(model.Library{ID: 1, Path: root, LastScanAt: lastScan}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err = s.Scan(ctx, false, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Missing).To(BeFalse())
+	})
+
+	It("does not re-mark tracks that are already missing", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "track2.mp3"), fixture(), 0600)).To(Succeed())
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.Remove(filepath.Join(dir, "track2.mp3"))).To(Succeed())
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.(*TagScanner).cnt.missing).To(Equal(int64(1)))
+
+		// Scanning the folder again must not count (and churn) the same track as missing once more
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.(*TagScanner).cnt.missing).To(Equal(int64(0)))
+		mf, err := ds.MediaFile(ctx).FindByPath(filepath.Join(dir, "track2.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mf.Missing).To(BeTrue())
+	})
+
+	It("un-flags returning user-edited tracks without overwriting their edits", func() {
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		mf := theTrack()
+		mf.Title = "My Edited Title"
+		mf.UserEdited = true
+		Expect(ds.MediaFile(ctx).Put(&mf)).To(Succeed())
+
+		Expect(os.Remove(filepath.Join(dir, "track.mp3"))).To(Succeed())
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theTrack().Missing).To(BeTrue())
+
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), fixture(), 0600)).To(Succeed())
+		_, _, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		mf = theTrack()
+		Expect(mf.Missing).To(BeFalse())
+		Expect(mf.Title).To(Equal("My Edited Title"))
+	})
+})
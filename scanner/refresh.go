@@ -0,0 +1,226 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/core/auth"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/server/events"
+	"github.com/navidrome/navidrome/utils/slice"
+)
+
+// RefreshItemsResult carries the outcome of a RefreshItems call: the tracks that were re-read
+// and persisted, and a message for each item that could not be refreshed. Items fail
+// individually, so one unreadable file does not abort the rest of the batch
+type RefreshItemsResult struct {
+	Updated model.MediaFiles  `json:"updated"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+// RefreshItems re-reads the tags of the given media files (or of all tracks of the given
+// albums) straight from disk, and refreshes the owning album and artist roll-ups. Unlike a
+// scan, it ignores the files' modification times, so tag edits that don't touch the mtime
+// (or that were made before the last scan checkpoint) are still picked up
+func (s *scanner) RefreshItems(ctx context.Context, ids ...string) (*RefreshItemsResult, error) {
+	ctx = auth.WithAdminUser(ctx, s.ds)
+	// Sync the folder list, so files can be matched to libraries added at runtime
+	s.loadFolders()
+
+	if !isScanning.TryLock() {
+		log.Debug(ctx, "Scanner already running, ignoring request for tag refresh.")
+		return nil, ErrAlreadyScanning
+	}
+	defer isScanning.Unlock()
+
+	result := &RefreshItemsResult{Errors: map[string]string{}}
+	byLib := map[string]model.MediaFiles{}
+	for _, mf := range s.resolveItems(ctx, ids, result) {
+		lib, ok := s.libraryFor(mf.Path)
+		if !ok {
+			result.Errors[mf.ID] = "file is not in any library"
+			continue
+		}
+		byLib[lib.Path] = append(byLib[lib.Path], mf)
+	}
+
+	for path, files := range byLib {
+		if err := s.refreshLibraryItems(ctx, s.libs[path], files, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(result.Updated) > 0 {
+		// Don't use real context, forcing a refresh in all open windows, including the one that triggered the refresh
+		s.broker.SendMessage(context.Background(), &events.RefreshResource{})
+	}
+	return result, nil
+}
+
+// resolveItems maps the given IDs to media files: each ID is looked up as a media file first,
+// and as an album (resolving to all its non-missing tracks) if no file matches. Lookup
+// failures are recorded in the result, keyed by the requested ID
+func (s *scanner) resolveItems(ctx context.Context, ids []string, result *RefreshItemsResult) model.MediaFiles {
+	repo := s.ds.MediaFile(ctx)
+	var files model.MediaFiles
+	seen := map[string]struct{}{}
+	add := func(mf model.MediaFile) {
+		if _, ok := seen[mf.ID]; !ok {
+			seen[mf.ID] = struct{}{}
+			files = append(files, mf)
+		}
+	}
+	for _, id := range ids {
+		mf, err := repo.Get(id)
+		if err == nil {
+			if mf.Missing {
+				result.Errors[id] = "file is missing from the music folder"
+				continue
+			}
+			add(*mf)
+			continue
+		}
+		if !errors.Is(err, model.ErrNotFound) {
+			result.Errors[id] = err.Error()
+			continue
+		}
+		mfs, err := repo.GetAll(model.QueryOptions{Filters: squirrel.Eq{"album_id": id}})
+		if err != nil {
+			result.Errors[id] = err.Error()
+			continue
+		}
+		if len(mfs) == 0 {
+			result.Errors[id] = "no media file or album found with this ID"
+			continue
+		}
+		for _, mf := range mfs {
+			if !mf.Missing {
+				add(mf)
+			}
+		}
+	}
+	return files
+}
+
+// libraryFor returns the library whose path contains the given file
+func (s *scanner) libraryFor(path string) (model.Library, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, lib := range s.libs {
+		root := filepath.Clean(lib.Path)
+		if strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return lib, true
+		}
+	}
+	return model.Library{}, false
+}
+
+// refreshLibraryItems re-reads and persists the given files of a single library, reusing the
+// scan machinery: the same metadata extraction and mapping, annotation and field lock
+// preservation, and the refresher for the album/artist roll-ups
+func (s *scanner) refreshLibraryItems(ctx context.Context, lib model.Library, files model.MediaFiles, result *RefreshItemsResult) error {
+	genres := newCachedGenreRepository(ctx, s.ds.Genre(ctx))
+	mapper := NewMediaFileMapper(lib.Path, genres, loadTagMappings(ctx, s.ds))
+	refresher := newRefresher(s.cacheWarmer, lib, s.statDirs(ctx, lib, files))
+
+	var batch model.MediaFiles
+	for _, chunk := range slice.BreakUp(files, filesBatchSize) {
+		paths := make([]string, len(chunk))
+		for i, mf := range chunk {
+			paths[i] = mf.Path
+		}
+		mds, err := metadata.ExtractWith(lib.Extractor, paths...)
+		if err != nil {
+			for _, mf := range chunk {
+				result.Errors[mf.ID] = err.Error()
+			}
+			continue
+		}
+		for _, cur := range chunk {
+			md, ok := mds[cur.Path]
+			if !ok {
+				result.Errors[cur.ID] = "could not read tags from file"
+				continue
+			}
+			// Tracks edited through the bulk edit API take precedence over the file tags
+			if cur.UserEdited {
+				result.Errors[cur.ID] = "track was edited through the API, so file tags are ignored"
+				continue
+			}
+			n := mapper.ToMediaFile(md)
+			fingerprintIfNeeded(ctx, md, &n)
+			n.Annotations = cur.Annotations
+			// Locked fields keep their DB values, regardless of what is in the file tags
+			if suppressed := n.ApplyFieldLocks(cur); suppressed > 0 {
+				log.Debug(ctx, "Suppressed updates to locked fields", "path", n.Path, "lockedFields", cur.LockedFields)
+			}
+			n.LibraryID = lib.ID
+			batch = append(batch, n)
+			refresher.accumulate(n)
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := s.ds.WithTx(func(tx model.DataStore) error {
+		if err := tx.MediaFile(ctx).PutAll(batch); err != nil {
+			return err
+		}
+		return refresher.flush(ctx, tx)
+	})
+	if err != nil {
+		return err
+	}
+	result.Updated = append(result.Updated, batch...)
+	return nil
+}
+
+// statDirs loads the dirStats of every folder contributing tracks to the affected albums,
+// plus their parent folders, so the image file roll-ups and the artist folder metadata stay
+// intact when the refresher re-aggregates the albums and artists
+func (s *scanner) statDirs(ctx context.Context, lib model.Library, files model.MediaFiles) dirMap {
+	dirs := files.Dirs()
+	albumIDs := map[string]struct{}{}
+	for _, mf := range files {
+		albumIDs[mf.AlbumID] = struct{}{}
+	}
+	// Multi-folder albums may hold tracks in folders not covered by the refreshed files
+	for id := range albumIDs {
+		folders, err := s.ds.Album(ctx).GetFolders(id)
+		if err != nil {
+			log.Warn(ctx, "Error loading album folders", "albumId", id, err)
+			continue
+		}
+		for _, f := range folders {
+			dirs = append(dirs, f.Path)
+		}
+	}
+
+	dm := dirMap{}
+	root := filepath.Clean(lib.Path)
+	add := func(dir string) {
+		if _, ok := dm[dir]; ok {
+			return
+		}
+		_, stats, err := loadDir(ctx, root, dir)
+		if err != nil {
+			return
+		}
+		stats.Path = dir
+		dm[dir] = *stats
+	}
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		add(dir)
+		if parent := filepath.Dir(dir); dir != root && parent != dir {
+			add(parent)
+		}
+	}
+	return dm
+}
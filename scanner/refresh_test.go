@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	"github.com/navidrome/navidrome/server/events"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scanner RefreshItems", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var sc *scanner
+	var root, dir string
+	var extractor *overrideExtractor
+	var progress chan uint32
+
+	theTrack := func() model.MediaFile {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		return mfs[0]
+	}
+	theAlbum := func() *model.Album {
+		al, err := ds.Album(ctx).Get(theTrack().AlbumID)
+		Expect(err).ToNot(HaveOccurred())
+		return al
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "album")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), data, 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "cover.jpg"), []byte("img"), 0600)).To(Succeed())
+
+		extractor = &overrideExtractor{Extractor: &taglib.Extractor{}}
+		metadata.RegisterExtractor("override", extractor)
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "override"
+
+		// RefreshItems resolves libraries from the DB, so point the default library to the
+		// test folder for the duration of the spec
+		originalLib, err := ds.Library(ctx).Get(model.DefaultLibraryID)
+		Expect(err).ToNot(HaveOccurred())
+		lib := *originalLib
+		lib.Path = root
+		Expect(ds.Library(ctx).Put(&lib)).To(Succeed())
+
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			Expect(ds.Library(ctx).Put(originalLib)).To(Succeed())
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		extractor.overrides = metadata.ParsedTags{"comment": []string{"Original comment"}}
+		ts := NewTagScanner(model.Library{ID: model.DefaultLibraryID, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err = ts.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		sc = &scanner{
+			ds:          ds,
+			pls:         core.NewPlaylists(ds),
+			broker:      events.GetBroker(),
+			folders:     map[string]FolderScanner{},
+			libs:        map[string]model.Library{},
+			status:      map[string]*scanStatus{},
+			lock:        &sync.RWMutex{},
+			cacheWarmer: &noopCacheWarmer{},
+		}
+	})
+
+	It("re-reads a file's tags even when its modification time is unchanged", func() {
+		// "Retag" the file without touching it on disk, so a regular scan would not pick it up
+		extractor.overrides = metadata.ParsedTags{"comment": []string{"Fixed comment"}}
+
+		res, err := sc.RefreshItems(ctx, theTrack().ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.Errors).To(BeEmpty())
+		Expect(res.Updated).To(HaveLen(1))
+		Expect(res.Updated[0].Comment).To(Equal("Fixed comment"))
+
+		Expect(theTrack().Comment).To(Equal("Fixed comment"))
+		// The album roll-up is refreshed as well, without losing its image files
+		Expect(theAlbum().Comment).To(Equal("Fixed comment"))
+		Expect(theAlbum().ImageFiles).To(ContainSubstring("cover.jpg"))
+	})
+
+	It("resolves album IDs to all their tracks", func() {
+		extractor.overrides = metadata.ParsedTags{"comment": []string{"Fixed comment"}}
+
+		res, err := sc.RefreshItems(ctx, theTrack().AlbumID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.Errors).To(BeEmpty())
+		Expect(res.Updated).To(HaveLen(1))
+		Expect(theTrack().Comment).To(Equal("Fixed comment"))
+	})
+
+	It("reports failed items individually, without failing the batch", func() {
+		extractor.overrides = metadata.ParsedTags{"comment": []string{"Fixed comment"}}
+
+		res, err := sc.RefreshItems(ctx, "not-a-valid-id", theTrack().ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.Updated).To(HaveLen(1))
+		Expect(res.Errors).To(HaveKey("not-a-valid-id"))
+	})
+
+	It("does not overwrite tracks edited through the API", func() {
+		mf := theTrack()
+		mf.Comment = "Edited comment"
+		mf.UserEdited = true
+		Expect(ds.MediaFile(ctx).Put(&mf)).To(Succeed())
+		extractor.overrides = metadata.ParsedTags{"comment": []string{"Fixed comment"}}
+
+		res, err := sc.RefreshItems(ctx, mf.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.Updated).To(BeEmpty())
+		Expect(res.Errors).To(HaveKey(mf.ID))
+		Expect(theTrack().Comment).To(Equal("Edited comment"))
+	})
+})
@@ -4,15 +4,19 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/deluan/sanitize"
+	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/consts"
 	"github.com/navidrome/navidrome/core/artwork"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/utils/slice"
+	"github.com/navidrome/navidrome/utils/str"
 	"golang.org/x/exp/maps"
 )
 
@@ -22,22 +26,22 @@ import (
 //
 // The actual mappings happen in MediaFiles.ToAlbum() and Albums.ToAlbumArtist()
 type refresher struct {
-	ds          model.DataStore
-	lib         model.Library
-	album       map[string]struct{}
-	artist      map[string]struct{}
-	dirMap      dirMap
-	cacheWarmer artwork.CacheWarmer
+	lib          model.Library
+	album        map[string]struct{}
+	artist       map[string]struct{}
+	participants map[string]model.Participant
+	dirMap       dirMap
+	cacheWarmer  artwork.CacheWarmer
 }
 
-func newRefresher(ds model.DataStore, cw artwork.CacheWarmer, lib model.Library, dirMap dirMap) *refresher {
+func newRefresher(cw artwork.CacheWarmer, lib model.Library, dirMap dirMap) *refresher {
 	return &refresher{
-		ds:          ds,
-		lib:         lib,
-		album:       map[string]struct{}{},
-		artist:      map[string]struct{}{},
-		dirMap:      dirMap,
-		cacheWarmer: cw,
+		lib:          lib,
+		album:        map[string]struct{}{},
+		artist:       map[string]struct{}{},
+		participants: map[string]model.Participant{},
+		dirMap:       dirMap,
+		cacheWarmer:  cw,
 	}
 }
 
@@ -48,25 +52,93 @@ func (r *refresher) accumulate(mf model.MediaFile) {
 	if mf.AlbumArtistID != "" {
 		r.artist[mf.AlbumArtistID] = struct{}{}
 	}
+	for _, artists := range mf.Participations {
+		for _, a := range artists {
+			if a.ID == "" {
+				continue
+			}
+			// When name variants fold into the same ID (see model.ParticipantID), prefer the
+			// variant with diacritics as display name
+			if cur, ok := r.participants[a.ID]; ok && hasDiacritics(cur.Name) {
+				continue
+			}
+			r.participants[a.ID] = a
+		}
+	}
+}
+
+// accumulateArtist schedules an artist refresh by ID, used when a folder carrying artist
+// metadata files changes without any of the artist's tracks changing
+func (r *refresher) accumulateArtist(id string) {
+	r.artist[id] = struct{}{}
 }
 
-func (r *refresher) flush(ctx context.Context) error {
-	err := r.flushMap(ctx, r.album, "album", r.refreshAlbums)
+// flush writes the accumulated album, artist and participant refreshes through the given
+// DataStore, which can be a transaction, so a folder's roll-ups commit (or roll back) together
+// with its tracks
+func (r *refresher) flush(ctx context.Context, ds model.DataStore) error {
+	err := r.flushMap(ctx, ds, r.album, "album", r.refreshAlbums)
 	if err != nil {
 		return err
 	}
 	r.album = map[string]struct{}{}
-	err = r.flushMap(ctx, r.artist, "artist", r.refreshArtists)
+	err = r.flushMap(ctx, ds, r.artist, "artist", r.refreshArtists)
 	if err != nil {
 		return err
 	}
 	r.artist = map[string]struct{}{}
+	err = r.refreshParticipants(ctx, ds)
+	if err != nil {
+		return err
+	}
+	r.participants = map[string]model.Participant{}
+	return nil
+}
+
+// refreshParticipants creates artist records for participants not in the DB yet (ex: an artist
+// only tagged as composer), and recomputes the per-role counters of all accumulated participants
+func (r *refresher) refreshParticipants(ctx context.Context, ds model.DataStore) error {
+	if len(r.participants) == 0 {
+		return nil
+	}
+	repo := ds.Artist(ctx)
+	ids := maps.Keys(r.participants)
+	for id, participant := range r.participants {
+		exists, err := repo.Exists(id)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		a := model.Artist{
+			ID:              id,
+			Name:            participant.Name,
+			SortArtistName:  participant.SortName,
+			MbzArtistID:     participant.MbzArtistID,
+			OrderArtistName: str.SanitizeFieldForSortingNoArticle(participant.Name),
+		}
+		if err := repo.Put(&a); err != nil {
+			return err
+		}
+	}
+	chunks := slice.BreakUp(ids, 100)
+	for _, chunk := range chunks {
+		if err := repo.RefreshStats(chunk...); err != nil {
+			log.Error(ctx, "Error refreshing artist stats", err)
+			return err
+		}
+	}
 	return nil
 }
 
-type refreshCallbackFunc = func(ctx context.Context, ids ...string) error
+func hasDiacritics(name string) bool {
+	return sanitize.Accents(name) != name
+}
+
+type refreshCallbackFunc = func(ctx context.Context, ds model.DataStore, ids ...string) error
 
-func (r *refresher) flushMap(ctx context.Context, m map[string]struct{}, entity string, refresh refreshCallbackFunc) error {
+func (r *refresher) flushMap(ctx context.Context, ds model.DataStore, m map[string]struct{}, entity string, refresh refreshCallbackFunc) error {
 	if len(m) == 0 {
 		return nil
 	}
@@ -74,7 +146,7 @@ func (r *refresher) flushMap(ctx context.Context, m map[string]struct{}, entity
 	ids := maps.Keys(m)
 	chunks := slice.BreakUp(ids, 100)
 	for _, chunk := range chunks {
-		err := refresh(ctx, chunk...)
+		err := refresh(ctx, ds, chunk...)
 		if err != nil {
 			log.Error(ctx, fmt.Sprintf("Error writing %ss to the DB", entity), err)
 			return err
@@ -83,8 +155,8 @@ func (r *refresher) flushMap(ctx context.Context, m map[string]struct{}, entity
 	return nil
 }
 
-func (r *refresher) refreshAlbums(ctx context.Context, ids ...string) error {
-	mfs, err := r.ds.MediaFile(ctx).GetAll(model.QueryOptions{Filters: squirrel.Eq{"album_id": ids}})
+func (r *refresher) refreshAlbums(ctx context.Context, ds model.DataStore, ids ...string) error {
+	mfs, err := ds.MediaFile(ctx).GetAll(model.QueryOptions{Filters: squirrel.Eq{"album_id": ids}})
 	if err != nil {
 		return err
 	}
@@ -92,17 +164,38 @@ func (r *refresher) refreshAlbums(ctx context.Context, ids ...string) error {
 		return nil
 	}
 
-	repo := r.ds.Album(ctx)
+	repo := ds.Album(ctx)
+	current, err := repo.GetAll(model.QueryOptions{Filters: squirrel.Eq{"album.id": ids}})
+	if err != nil {
+		return err
+	}
+	currentAlbums := map[string]model.Album{}
+	for _, al := range current {
+		currentAlbums[al.ID] = al
+	}
 	grouped := slice.Group(mfs, func(m model.MediaFile) string { return m.AlbumID })
 	for _, group := range grouped {
 		songs := model.MediaFiles(group)
 		a := songs.ToAlbum()
+		a.Missing = allMissing(songs)
 		var updatedAt time.Time
 		a.ImageFiles, updatedAt = r.getImageFiles(songs.Dirs())
 		if updatedAt.After(a.UpdatedAt) {
 			a.UpdatedAt = updatedAt
 		}
+		a.CoverArtPath, a.CoverArtSize, a.CoverArtUpdatedAt = selectCoverArtPath(ctx, &a)
+		// Locked fields keep their DB values, regardless of what was aggregated from the tracks
+		if cur, ok := currentAlbums[a.ID]; ok {
+			a.ApplyFieldLocks(cur)
+		}
 		a.LibraryID = r.lib.ID
+		// An album ID not in the DB yet may be a re-grouped version of existing albums (ex:
+		// after toggling GroupAlbumReleases): carry the user annotations over before it lands
+		if _, ok := currentAlbums[a.ID]; !ok && a.PID != "" {
+			if err := repo.ReconcileAnnotations(a.PID, a.ID); err != nil {
+				log.Error(ctx, "Error reconciling album annotations", "album", a.Name, "pid", a.PID, err)
+			}
+		}
 		err := repo.Put(&a)
 		if err != nil {
 			return err
@@ -112,6 +205,16 @@ func (r *refresher) refreshAlbums(ctx context.Context, ids ...string) error {
 	return nil
 }
 
+// allMissing reports whether every track of the album is flagged as missing from disk
+func allMissing(songs model.MediaFiles) bool {
+	for _, mf := range songs {
+		if !mf.Missing {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *refresher) getImageFiles(dirs []string) (string, time.Time) {
 	var imageFiles []string
 	var updatedAt time.Time
@@ -127,8 +230,8 @@ func (r *refresher) getImageFiles(dirs []string) (string, time.Time) {
 	return strings.Join(imageFiles, consts.Zwsp), updatedAt
 }
 
-func (r *refresher) refreshArtists(ctx context.Context, ids ...string) error {
-	albums, err := r.ds.Album(ctx).GetAll(model.QueryOptions{Filters: squirrel.Eq{"album_artist_id": ids}})
+func (r *refresher) refreshArtists(ctx context.Context, ds model.DataStore, ids ...string) error {
+	albums, err := ds.Album(ctx).GetAll(model.QueryOptions{Filters: squirrel.Eq{"album_artist_id": ids}})
 	if err != nil {
 		return err
 	}
@@ -136,8 +239,8 @@ func (r *refresher) refreshArtists(ctx context.Context, ids ...string) error {
 		return nil
 	}
 
-	repo := r.ds.Artist(ctx)
-	libRepo := r.ds.Library(ctx)
+	repo := ds.Artist(ctx)
+	libRepo := ds.Library(ctx)
 	grouped := slice.Group(albums, func(al model.Album) string { return al.AlbumArtistID })
 	for _, group := range grouped {
 		a := model.Albums(group).ToAlbumArtist()
@@ -146,7 +249,15 @@ func (r *refresher) refreshArtists(ctx context.Context, ids ...string) error {
 		a.ExternalInfoUpdatedAt = &time.Time{}
 
 		// Do not remove old metadata
-		err := repo.Put(&a, "album_count", "genres", "external_info_updated_at", "mbz_artist_id", "name", "order_artist_name", "size", "sort_artist_name", "song_count")
+		cols := []string{"album_count", "genres", "external_info_updated_at", "image_path", "mbz_artist_id",
+			"name", "nfo_path", "order_artist_name", "size", "sort_artist_name", "song_count"}
+		if conf.Server.Scanner.ArtistFolderMetadata {
+			r.applyArtistFolderMetadata(ctx, ds, &a, group)
+			if a.NfoPath != "" {
+				cols = append(cols, "biography")
+			}
+		}
+		err := repo.Put(&a, cols...)
 		if err != nil {
 			return err
 		}
@@ -160,3 +271,89 @@ func (r *refresher) refreshArtists(ctx context.Context, ids ...string) error {
 	}
 	return nil
 }
+
+// applyArtistFolderMetadata looks for artist-level metadata files in the artist's folder - the
+// folder directly containing the artist's album folders - and applies them to the artist: an
+// artist.* image file is recorded in ImagePath, and biography/genres are parsed from an
+// artist.nfo file. These take precedence over metadata from external agents
+func (r *refresher) applyArtistFolderMetadata(ctx context.Context, ds model.DataStore, a *model.Artist, albums model.Albums) {
+	folder := r.artistFolderOf(albums)
+	if folder == "" {
+		return
+	}
+	stats, ok := r.dirMap[folder]
+	if !ok || !stats.hasArtistMetadata() {
+		return
+	}
+	// Only folders holding the album folders of a single album artist are considered
+	// artist folders
+	ids, err := ds.Album(ctx).GetAlbumArtistIDsInFolder(folder)
+	if err != nil {
+		log.Error(ctx, "Error checking artist folder", "folder", folder, "artist", a.Name, err)
+		return
+	}
+	if len(ids) != 1 || ids[0] != a.ID {
+		return
+	}
+	for _, img := range stats.Images {
+		if isArtistImageFile(img) {
+			a.ImagePath = filepath.Join(folder, img)
+			break
+		}
+	}
+	if !stats.HasArtistNfo {
+		return
+	}
+	nfoPath := filepath.Join(folder, artistNfoFile)
+	nfo, err := loadArtistNfo(nfoPath)
+	if err != nil {
+		log.Warn(ctx, "Error parsing artist.nfo file", "path", nfoPath, err)
+		return
+	}
+	a.NfoPath = nfoPath
+	if nfo.Biography != "" {
+		a.Biography = nfo.Biography
+	}
+	r.mergeNfoGenres(ctx, ds, a, nfo.Genres)
+}
+
+// artistFolderOf returns the folder directly containing the given albums' folders, following
+// the same heuristic the artwork reader uses: the longest common prefix of all album paths.
+// Returns empty when the albums don't share a folder below the library root
+func (r *refresher) artistFolderOf(albums model.Albums) string {
+	var paths []string
+	for _, al := range albums {
+		for _, p := range strings.Split(al.Paths, consts.Zwsp) {
+			if p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	folder := str.LongestCommonPrefix(paths)
+	if folder == "" {
+		return ""
+	}
+	if !strings.HasSuffix(folder, string(filepath.Separator)) {
+		folder, _ = filepath.Split(folder)
+	}
+	folder = filepath.Clean(folder)
+	if folder == filepath.Clean(r.lib.Path) {
+		return ""
+	}
+	return folder
+}
+
+// mergeNfoGenres appends the genres listed in the artist.nfo to the ones aggregated from the
+// artist's albums, creating genre records as needed
+func (r *refresher) mergeNfoGenres(ctx context.Context, ds model.DataStore, a *model.Artist, names []string) {
+	for _, name := range names {
+		g := model.Genre{Name: name}
+		if err := ds.Genre(ctx).Put(&g); err != nil {
+			log.Error(ctx, "Error adding genre from artist.nfo", "genre", name, err)
+			continue
+		}
+		if !slices.ContainsFunc(a.Genres, func(cur model.Genre) bool { return cur.ID == g.ID }) {
+			a.Genres = append(a.Genres, g)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	"github.com/navidrome/navidrome/server/events"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scan locking", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var sc *scanner
+	var root, dir string
+
+	putClaim := func(instanceID string, lastSeen time.Time) {
+		data, err := json.Marshal(scanClaim{InstanceID: instanceID, StartedAt: lastSeen, LastSeen: lastSeen})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ds.Property(ctx).Put(consts.ScanClaimKey, string(data))).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "album")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track.mp3"), data, 0600)).To(Succeed())
+
+		metadata.RegisterExtractor("plain", &taglib.Extractor{})
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "plain"
+
+		// RescanAll resolves libraries from the DB, so point the default library to the test
+		// folder for the duration of the spec
+		originalLib, err := ds.Library(ctx).Get(model.DefaultLibraryID)
+		Expect(err).ToNot(HaveOccurred())
+		lib := *originalLib
+		lib.Path = root
+		Expect(ds.Library(ctx).Put(&lib)).To(Succeed())
+
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			_ = ds.Property(ctx).Delete(consts.ScanClaimKey)
+			Expect(ds.Library(ctx).Put(originalLib)).To(Succeed())
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		sc = &scanner{
+			ds:          ds,
+			pls:         core.NewPlaylists(ds),
+			broker:      events.GetBroker(),
+			folders:     map[string]FolderScanner{},
+			libs:        map[string]model.Library{},
+			status:      map[string]*scanStatus{},
+			lock:        &sync.RWMutex{},
+			cacheWarmer: &noopCacheWarmer{},
+		}
+	})
+
+	It("rejects a rescan while another instance holds a fresh claim", func() {
+		putClaim("another-instance", time.Now())
+		Expect(sc.RescanAll(ctx, true)).To(MatchError(ErrAlreadyScanning))
+	})
+
+	It("takes over a stale claim left by a crashed instance, and releases it when done", func() {
+		putClaim("crashed-instance", time.Now().Add(-scanClaimTimeout-time.Minute))
+		Expect(sc.RescanAll(ctx, true)).To(Succeed())
+
+		value, err := ds.Property(ctx).DefaultGet(consts.ScanClaimKey, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(BeEmpty())
+	})
+
+	It("lets concurrent rescans run one at a time, keeping the data consistent", func() {
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+				errs[i] = sc.RescanAll(ctx, true)
+			}(i)
+		}
+		wg.Wait()
+
+		// The second caller either finds the scanner busy or runs after the first finished;
+		// either way the library ends up imported exactly once
+		for _, err := range errs {
+			if err != nil {
+				Expect(err).To(MatchError(ErrAlreadyScanning))
+			}
+		}
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+	})
+
+	It("reports since when the scan in progress started", func() {
+		Expect(sc.RescanAll(ctx, true)).To(Succeed())
+		status, err := sc.Status(root)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.Scanning).To(BeFalse())
+		Expect(status.ScanningSince).To(BeZero())
+
+		start := time.Now()
+		sc.setStatusStart(root, start)
+		status, err = sc.Status(root)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.Scanning).To(BeTrue())
+		Expect(status.ScanningSince).To(Equal(start))
+		sc.setStatusEnd(root, start)
+	})
+})
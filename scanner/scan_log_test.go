@@ -0,0 +1,92 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	"github.com/navidrome/navidrome/server/events"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scan history", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var sc *scanner
+	var root, dir string
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "album")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track1.mp3"), data, 0600)).To(Succeed())
+
+		metadata.RegisterExtractor("plain", &taglib.Extractor{})
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "plain"
+
+		// RescanAll resolves libraries from the DB, so point the default library to the test
+		// folder for the duration of the spec
+		originalLib, err := ds.Library(ctx).Get(model.DefaultLibraryID)
+		Expect(err).ToNot(HaveOccurred())
+		lib := *originalLib
+		lib.Path = root
+		Expect(ds.Library(ctx).Put(&lib)).To(Succeed())
+
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			Expect(ds.Library(ctx).Put(originalLib)).To(Succeed())
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+
+		sc = &scanner{
+			ds:          ds,
+			pls:         core.NewPlaylists(ds),
+			broker:      events.GetBroker(),
+			folders:     map[string]FolderScanner{},
+			libs:        map[string]model.Library{},
+			status:      map[string]*scanStatus{},
+			lock:        &sync.RWMutex{},
+			cacheWarmer: &noopCacheWarmer{},
+		}
+	})
+
+	It("records one history row per scan, with the scan counters", func() {
+		Expect(sc.RescanAll(ctx, true)).To(Succeed())
+
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "track2.mp3"), data, 0600)).To(Succeed())
+		Expect(sc.RescanAll(ctx, true)).To(Succeed())
+
+		entries, err := ds.ScanLog(ctx).GetAll(model.QueryOptions{
+			Filters: squirrel.Eq{"library_path": root}, Sort: "start_time", Order: "asc"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+
+		Expect(entries[0].FullScan).To(BeTrue())
+		Expect(entries[0].Added).To(Equal(int64(1)))
+		Expect(entries[0].Error).To(BeEmpty())
+		Expect(entries[0].DurationMs).To(BeNumerically(">=", 0))
+
+		// The second (full) scan revisits the first file and imports the new one
+		Expect(entries[1].Added).To(Equal(int64(2)))
+		Expect(entries[1].Missing).To(Equal(int64(0)))
+	})
+})
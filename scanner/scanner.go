@@ -2,11 +2,15 @@ package scanner
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/navidrome/navidrome/consts"
 	"github.com/navidrome/navidrome/core"
 	"github.com/navidrome/navidrome/core/artwork"
 	"github.com/navidrome/navidrome/log"
@@ -18,6 +22,9 @@ import (
 type Scanner interface {
 	RescanAll(ctx context.Context, fullRescan bool) error
 	Status(library string) (*StatusInfo, error)
+	// RefreshItems re-reads the tags of the given media files or albums from disk,
+	// bypassing the modification time checks a scan would apply
+	RefreshItems(ctx context.Context, ids ...string) (*RefreshItemsResult, error)
 }
 
 type StatusInfo struct {
@@ -26,6 +33,8 @@ type StatusInfo struct {
 	LastScan    time.Time
 	Count       uint32
 	FolderCount uint32
+	// ScanningSince is when the scan currently in progress started; zero when not scanning
+	ScanningSince time.Time
 }
 
 var (
@@ -34,12 +43,42 @@ var (
 )
 
 type FolderScanner interface {
-	// Scan process finds any changes after `lastModifiedSince` and returns the number of changes found
-	Scan(ctx context.Context, fullRescan bool, progress chan uint32) (int64, error)
+	// Scan process finds any changes after `lastModifiedSince` and returns the number of changes
+	// found, and whether the scan was cut short by the Scanner.TimeBudget option
+	Scan(ctx context.Context, fullRescan bool, progress chan uint32) (int64, bool, error)
 }
 
+// progressUpdateInterval is the minimum interval between scan progress events sent to clients,
+// per library. Phase transitions (scan started/finished) are always sent.
+const progressUpdateInterval = time.Second
+
 var isScanning sync.Mutex
 
+// scanInstanceID identifies this process in the cross-instance scan claim
+var scanInstanceID = uuid.NewString()
+
+// scanClaimTimeout is how long a scan claim stays valid without being refreshed. Claims older
+// than this are assumed to be left over by a crashed instance and can be taken over
+const scanClaimTimeout = 30 * time.Minute
+
+// scanClaim is stored in the properties table while a scan runs, so multiple Navidrome
+// instances sharing the same DB don't scan concurrently. LastSeen is refreshed as the scan
+// moves from one library to the next
+type scanClaim struct {
+	InstanceID string    `json:"instanceId"`
+	StartedAt  time.Time `json:"startedAt"`
+	LastSeen   time.Time `json:"lastSeen"`
+}
+
+// IsScanning reports whether a scan is currently in progress
+func IsScanning() bool {
+	if !isScanning.TryLock() {
+		return true
+	}
+	isScanning.Unlock()
+	return false
+}
+
 type scanner struct {
 	once        sync.Once
 	folders     map[string]FolderScanner
@@ -57,6 +96,7 @@ type scanStatus struct {
 	fileCount   uint32
 	folderCount uint32
 	lastUpdate  time.Time
+	startTime   time.Time
 }
 
 func GetInstance(ds model.DataStore, playlists core.Playlists, cacheWarmer artwork.CacheWarmer, broker events.Broker) Scanner {
@@ -86,7 +126,7 @@ func (s *scanner) rescan(ctx context.Context, library string, fullRescan bool) e
 		return fmt.Errorf("folder %s not a valid library path", library)
 	}
 
-	s.setStatusStart(library)
+	s.setStatusStart(library, start)
 	defer s.setStatusEnd(library, start)
 
 	if fullRescan {
@@ -98,7 +138,7 @@ func (s *scanner) rescan(ctx context.Context, library string, fullRescan bool) e
 	progress, cancel := s.startProgressTracker(library)
 	defer cancel()
 
-	changeCount, err := folderScanner.Scan(ctx, fullRescan, progress)
+	changeCount, partial, err := folderScanner.Scan(ctx, fullRescan, progress)
 	if err != nil {
 		log.Error("Error scanning Library", "folder", library, err)
 	}
@@ -110,25 +150,84 @@ func (s *scanner) rescan(ctx context.Context, library string, fullRescan bool) e
 		s.broker.SendMessage(context.Background(), &events.RefreshResource{})
 	}
 
-	s.updateLastModifiedSince(ctx, library, start)
+	// Partial scans keep the previous checkpoint, so the next run picks up the remaining folders
+	if !partial {
+		s.updateLastModifiedSince(ctx, library, start)
+	}
+	s.recordPartialScan(ctx, library, partial)
+	s.saveScanLog(ctx, library, start, fullRescan, partial, folderScanner, err)
 	return err
 }
 
+// saveScanLog stores a summary row of the finished pass, so the scan history can be inspected
+// through the native API. The counters come from the folder scanner, when available
+func (s *scanner) saveScanLog(ctx context.Context, library string, start time.Time, fullRescan, partial bool,
+	folderScanner FolderScanner, scanErr error) {
+	entry := &model.ScanLog{
+		LibraryPath: library,
+		StartTime:   start,
+		DurationMs:  time.Since(start).Milliseconds(),
+		FullScan:    fullRescan,
+		Partial:     partial,
+	}
+	if ts, ok := folderScanner.(*TagScanner); ok && ts.cnt != nil {
+		entry.Added = ts.cnt.added
+		entry.Updated = ts.cnt.updated
+		entry.Missing = ts.cnt.missing
+		entry.Playlists = ts.cnt.playlists
+	}
+	if scanErr != nil {
+		entry.Error = scanErr.Error()
+	}
+	if err := s.ds.ScanLog(ctx).Add(entry); err != nil {
+		log.Error(ctx, "Error saving scan history entry", "folder", library, err)
+	}
+}
+
+// recordPartialScan keeps track of libraries whose last scan was cut short by the time budget,
+// so the scan history reflects that a full pass is still pending
+func (s *scanner) recordPartialScan(ctx context.Context, library string, partial bool) {
+	props := s.ds.Property(ctx)
+	var pending []string
+	if value, err := props.DefaultGet(consts.PartialScanKey, ""); err == nil && value != "" {
+		if err := json.Unmarshal([]byte(value), &pending); err != nil {
+			log.Warn(ctx, "Could not parse partial scan history. Discarding it", err)
+			pending = nil
+		}
+	}
+	if partial {
+		if !slices.Contains(pending, library) {
+			pending = append(pending, library)
+		}
+	} else {
+		pending = slices.DeleteFunc(pending, func(l string) bool { return l == library })
+	}
+	value, err := json.Marshal(pending)
+	if err == nil {
+		err = props.Put(consts.PartialScanKey, string(value))
+	}
+	if err != nil {
+		log.Error(ctx, "Error saving partial scan history", err)
+	}
+}
+
 func (s *scanner) startProgressTracker(library string) (chan uint32, context.CancelFunc) {
 	// Must be a new context (not the one passed to the scan method) to allow broadcasting the scan status to all clients
 	ctx, cancel := context.WithCancel(context.Background())
 	progress := make(chan uint32, 100)
 	go func() {
-		s.broker.SendMessage(ctx, &events.ScanStatus{Scanning: true, Count: 0, FolderCount: 0})
+		s.broker.SendMessage(ctx, &events.ScanStatus{Scanning: true, Count: 0, FolderCount: 0, Library: library})
 		defer func() {
 			if status, ok := s.getStatus(library); ok {
 				s.broker.SendMessage(ctx, &events.ScanStatus{
 					Scanning:    false,
 					Count:       int64(status.fileCount),
 					FolderCount: int64(status.folderCount),
+					Library:     library,
 				})
 			}
 		}()
+		var lastUpdate time.Time
 		for {
 			select {
 			case <-ctx.Done():
@@ -138,10 +237,16 @@ func (s *scanner) startProgressTracker(library string) (chan uint32, context.Can
 					continue
 				}
 				totalFolders, totalFiles := s.incStatusCounter(library, count)
+				// Throttle intermediate updates, so slow clients are not flooded with events
+				if time.Since(lastUpdate) < progressUpdateInterval {
+					continue
+				}
+				lastUpdate = time.Now()
 				s.broker.SendMessage(ctx, &events.ScanStatus{
 					Scanning:    true,
 					Count:       int64(totalFiles),
 					FolderCount: int64(totalFolders),
+					Library:     library,
 				})
 			}
 		}
@@ -168,13 +273,14 @@ func (s *scanner) incStatusCounter(folder string, numFiles uint32) (totalFolders
 	return
 }
 
-func (s *scanner) setStatusStart(folder string) {
+func (s *scanner) setStatusStart(folder string, start time.Time) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	if status, ok := s.status[folder]; ok {
 		status.active = true
 		status.fileCount = 0
 		status.folderCount = 0
+		status.startTime = start
 	}
 }
 
@@ -189,7 +295,8 @@ func (s *scanner) setStatusEnd(folder string, lastUpdate time.Time) {
 
 func (s *scanner) RescanAll(ctx context.Context, fullRescan bool) error {
 	ctx = context.WithoutCancel(ctx)
-	s.once.Do(s.loadFolders)
+	// Sync the folder list on every run, so libraries added or removed at runtime are picked up
+	s.loadFolders()
 
 	if !isScanning.TryLock() {
 		log.Debug(ctx, "Scanner already running, ignoring request for rescan.")
@@ -197,8 +304,20 @@ func (s *scanner) RescanAll(ctx context.Context, fullRescan bool) error {
 	}
 	defer isScanning.Unlock()
 
+	// The in-process lock does not protect against other instances sharing the same DB, so a
+	// claim is also written to the DB for the duration of the scan
+	claim, err := s.acquireScanClaim(ctx)
+	if err != nil {
+		if errors.Is(err, ErrAlreadyScanning) {
+			log.Debug(ctx, "Another instance is scanning, ignoring request for rescan.")
+		}
+		return err
+	}
+	defer s.releaseScanClaim(ctx)
+
 	var hasError bool
 	for folder := range s.folders {
+		s.refreshScanClaim(ctx, claim)
 		err := s.rescan(ctx, folder, fullRescan)
 		hasError = hasError || err != nil
 	}
@@ -211,19 +330,71 @@ func (s *scanner) RescanAll(ctx context.Context, fullRescan bool) error {
 	return nil
 }
 
+// acquireScanClaim registers this instance as the one scanning, returning ErrAlreadyScanning
+// when another instance holds a fresh claim. Stale claims (not refreshed within
+// scanClaimTimeout, ex: after a crash) and malformed ones are taken over
+func (s *scanner) acquireScanClaim(ctx context.Context) (*scanClaim, error) {
+	var claim *scanClaim
+	err := s.ds.WithTx(func(tx model.DataStore) error {
+		props := tx.Property(ctx)
+		value, err := props.DefaultGet(consts.ScanClaimKey, "")
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			var current scanClaim
+			if err := json.Unmarshal([]byte(value), &current); err == nil &&
+				current.InstanceID != scanInstanceID && time.Since(current.LastSeen) < scanClaimTimeout {
+				return ErrAlreadyScanning
+			}
+		}
+		now := time.Now()
+		claim = &scanClaim{InstanceID: scanInstanceID, StartedAt: now, LastSeen: now}
+		data, err := json.Marshal(claim)
+		if err != nil {
+			return err
+		}
+		return props.Put(consts.ScanClaimKey, string(data))
+	})
+	return claim, err
+}
+
+// refreshScanClaim bumps the claim's LastSeen, so long multi-library scans are not mistaken
+// for crashed ones
+func (s *scanner) refreshScanClaim(ctx context.Context, claim *scanClaim) {
+	claim.LastSeen = time.Now()
+	data, err := json.Marshal(claim)
+	if err == nil {
+		err = s.ds.Property(ctx).Put(consts.ScanClaimKey, string(data))
+	}
+	if err != nil {
+		log.Warn(ctx, "Error refreshing scan claim", err)
+	}
+}
+
+func (s *scanner) releaseScanClaim(ctx context.Context) {
+	if err := s.ds.Property(ctx).Delete(consts.ScanClaimKey); err != nil {
+		log.Warn(ctx, "Error releasing scan claim", err)
+	}
+}
+
 func (s *scanner) Status(library string) (*StatusInfo, error) {
 	s.once.Do(s.loadFolders)
 	status, ok := s.getStatus(library)
 	if !ok {
 		return nil, errors.New("library not found")
 	}
-	return &StatusInfo{
+	info := &StatusInfo{
 		Library:     library,
 		Scanning:    status.active,
 		LastScan:    status.lastUpdate,
 		Count:       status.fileCount,
 		FolderCount: status.folderCount,
-	}, nil
+	}
+	if status.active {
+		info.ScanningSince = status.startTime
+	}
+	return info, nil
 }
 
 func (s *scanner) updateLastModifiedSince(ctx context.Context, folder string, t time.Time) {
@@ -236,10 +407,21 @@ func (s *scanner) updateLastModifiedSince(ctx context.Context, folder string, t
 	s.libs[folder] = lib
 }
 
+// loadFolders syncs the in-memory folder list with the library table. It can be called
+// repeatedly: folders already configured keep their scanner and status, new libraries are
+// added and removed ones are dropped
 func (s *scanner) loadFolders() {
 	ctx := context.TODO()
 	libs, _ := s.ds.Library(ctx).GetAll()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	current := map[string]struct{}{}
 	for _, lib := range libs {
+		current[lib.Path] = struct{}{}
+		if _, ok := s.folders[lib.Path]; ok {
+			s.libs[lib.Path] = lib
+			continue
+		}
 		log.Info("Configuring Media Folder", "name", lib.Name, "path", lib.Path)
 		s.folders[lib.Path] = s.newScanner(lib)
 		s.libs[lib.Path] = lib
@@ -250,6 +432,14 @@ func (s *scanner) loadFolders() {
 			lastUpdate:  lib.LastScanAt,
 		}
 	}
+	for path := range s.folders {
+		if _, ok := current[path]; !ok {
+			log.Info("Removing Media Folder", "path", path)
+			delete(s.folders, path)
+			delete(s.libs, path)
+			delete(s.status, path)
+		}
+	}
 }
 
 func (s *scanner) newScanner(f model.Library) FolderScanner {
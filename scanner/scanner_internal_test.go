@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/navidrome/navidrome/server/events"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeBroker struct {
+	sync.Mutex
+	messages []events.Event
+}
+
+func (b *fakeBroker) SendMessage(_ context.Context, event events.Event) {
+	b.Lock()
+	defer b.Unlock()
+	b.messages = append(b.messages, event)
+}
+
+func (b *fakeBroker) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+func (b *fakeBroker) scanStatuses() []*events.ScanStatus {
+	b.Lock()
+	defer b.Unlock()
+	var statuses []*events.ScanStatus
+	for _, m := range b.messages {
+		if s, ok := m.(*events.ScanStatus); ok {
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses
+}
+
+var _ = Describe("scanner", func() {
+	Describe("startProgressTracker", func() {
+		const library = "/music"
+		var s *scanner
+		var broker *fakeBroker
+
+		BeforeEach(func() {
+			broker = &fakeBroker{}
+			s = &scanner{
+				broker: broker,
+				lock:   &sync.RWMutex{},
+				status: map[string]*scanStatus{library: {}},
+			}
+		})
+
+		It("throttles intermediate updates, but always sends start and completion", func() {
+			progress, cancel := s.startProgressTracker(library)
+			for i := 0; i < 10; i++ {
+				progress <- 1
+			}
+			// Wait for the updates to be processed before ending the scan
+			Eventually(func() uint32 {
+				status, _ := s.getStatus(library)
+				return status.fileCount
+			}).Should(Equal(uint32(10)))
+			cancel()
+
+			Eventually(func() []*events.ScanStatus {
+				return broker.scanStatuses()
+			}).Should(WithTransform(func(statuses []*events.ScanStatus) bool {
+				return len(statuses) >= 2 && !statuses[len(statuses)-1].Scanning
+			}, BeTrue()))
+
+			statuses := broker.scanStatuses()
+			Expect(statuses[0].Scanning).To(BeTrue())
+			final := statuses[len(statuses)-1]
+			Expect(final.Scanning).To(BeFalse())
+			Expect(final.Count).To(Equal(int64(10)))
+			Expect(final.FolderCount).To(Equal(int64(10)))
+			Expect(final.Library).To(Equal(library))
+			// All 10 updates arrived within the throttle interval, so at most one was broadcast
+			Expect(len(statuses)).To(BeNumerically("<=", 3))
+		})
+	})
+})
@@ -0,0 +1,124 @@
+package scanner
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/chaostest"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// This test runs a real scan over a temporary music folder with a chaotic metadata extractor:
+// random latency, injected failures and files changing in the middle of the scan. Whatever
+// state that leaves behind, a follow-up clean full scan must reconcile the DB with the final
+// contents of the folder.
+var _ = Describe("Scanner resilience", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var chaos *chaostest.ChaosExtractor
+	var root, dirA, dirB string
+
+	copyFixture := func(destPath string) {
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(destPath, data, 0600)).To(Succeed())
+	}
+
+	filesOnDisk := func() []string {
+		var paths []string
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				paths = append(paths, path)
+			}
+			return err
+		})
+		Expect(err).ToNot(HaveOccurred())
+		sort.Strings(paths)
+		return paths
+	}
+
+	// Tracks that disappear from disk stay in the DB flagged as missing, so only the
+	// non-missing rows are expected to mirror the folder contents
+	filesInDB := func() []string {
+		var paths []string
+		for _, dir := range []string{dirA, dirB} {
+			mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+			Expect(err).ToNot(HaveOccurred())
+			for _, mf := range mfs {
+				if !mf.Missing {
+					paths = append(paths, mf.Path)
+				}
+			}
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+
+		root = GinkgoT().TempDir()
+		dirA = filepath.Join(root, "artist a")
+		dirB = filepath.Join(root, "artist b")
+		Expect(os.Mkdir(dirA, 0700)).To(Succeed())
+		Expect(os.Mkdir(dirB, 0700)).To(Succeed())
+		copyFixture(filepath.Join(dirA, "track1.mp3"))
+		copyFixture(filepath.Join(dirA, "track2.mp3"))
+		copyFixture(filepath.Join(dirB, "track3.mp3"))
+
+		chaos = chaostest.NewChaosExtractor(&taglib.Extractor{}, 1234)
+		metadata.RegisterExtractor("chaos", chaos)
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "chaos"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dirA)
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dirB)
+			_ = ds.GC(ctx, root)
+		})
+	})
+
+	It("reconciles the DB with the folder after a chaotic scan", func() {
+		lib := model.Library{ID: 1, Path: root}
+		s := NewTagScanner(lib, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		progress := make(chan uint32, 100)
+
+		chaos.MaxLatency = 2 * time.Millisecond
+		chaos.FailureProbability = 0.3
+		chaos.AfterNParses = 1
+		chaos.OnParse = func() {
+			// Simulate the folder changing mid-scan
+			Expect(os.Remove(filepath.Join(dirA, "track2.mp3"))).To(Succeed())
+			copyFixture(filepath.Join(dirB, "track4.mp3"))
+		}
+
+		// Run a few scans under chaos. Failures are contained per folder, so these may leave
+		// the DB in any intermediate state
+		for i := 0; i < 3; i++ {
+			_, _, _ = s.Scan(ctx, true, progress)
+		}
+
+		// A clean full scan must bring the DB back in sync with the folder
+		chaos.MaxLatency = 0
+		chaos.FailureProbability = 0
+		chaos.OnParse = nil
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(filesInDB()).To(Equal(filesOnDisk()))
+		Expect(filesOnDisk()).To(HaveLen(3))
+	})
+})
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"encoding/json"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -9,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/consts"
 	"github.com/navidrome/navidrome/core"
 	"github.com/navidrome/navidrome/core/artwork"
 	"github.com/navidrome/navidrome/core/auth"
@@ -31,6 +34,9 @@ type TagScanner struct {
 	cnt         *counters
 	mapper      *MediaFileMapper
 	cacheWarmer artwork.CacheWarmer
+	tagUsage    map[string]int64
+	metrics     *scanMetrics
+	tagWriter   metadata.Writer
 }
 
 func NewTagScanner(lib model.Library, ds model.DataStore, playlists core.Playlists, cacheWarmer artwork.CacheWarmer) FolderScanner {
@@ -39,6 +45,7 @@ func NewTagScanner(lib model.Library, ds model.DataStore, playlists core.Playlis
 		plsSync:     newPlaylistImporter(ds, playlists, cacheWarmer, lib.Path),
 		ds:          ds,
 		cacheWarmer: cacheWarmer,
+		metrics:     getScanMetrics(),
 	}
 	metadata.LogExtractors()
 
@@ -50,11 +57,12 @@ type dirMap map[string]dirStats
 type counters struct {
 	added     int64
 	updated   int64
-	deleted   int64
+	missing   int64
 	playlists int64
+	locked    int64
 }
 
-func (cnt *counters) total() int64 { return cnt.added + cnt.updated + cnt.deleted }
+func (cnt *counters) total() int64 { return cnt.added + cnt.updated + cnt.missing }
 
 const (
 	// filesBatchSize used for batching file metadata extraction
@@ -77,9 +85,14 @@ const (
 // - If the playlist is not in the DB, import it, setting sync = true
 // - If the playlist is in the DB and sync == true, import it, or else skip it
 // Delete all empty albums, delete all empty artists, clean-up playlists
-func (s *TagScanner) Scan(ctx context.Context, fullScan bool, progress chan uint32) (int64, error) {
+func (s *TagScanner) Scan(ctx context.Context, fullScan bool, progress chan uint32) (int64, bool, error) {
 	ctx = auth.WithAdminUser(ctx, s.ds)
 	start := time.Now()
+	var deadline time.Time
+	if conf.Server.Scanner.TimeBudget > 0 {
+		deadline = start.Add(conf.Server.Scanner.TimeBudget)
+	}
+	partial := false
 
 	// Special case: if LastScanAt is zero, re-import all files
 	fullScan = fullScan || s.lib.LastScanAt.IsZero()
@@ -87,24 +100,60 @@ func (s *TagScanner) Scan(ctx context.Context, fullScan bool, progress chan uint
 	// If the media folder is empty (no music and no subfolders), abort to avoid deleting all data from DB
 	empty, err := isDirEmpty(ctx, s.lib.Path)
 	if err != nil {
-		return 0, err
+		return 0, false, err
 	}
 	if empty && !fullScan {
 		log.Error(ctx, "Media Folder is empty. Aborting scan.", "folder", s.lib.Path)
-		return 0, nil
+		return 0, false, nil
 	}
 
 	allDBDirs, err := s.getDBDirTree(ctx)
 	if err != nil {
-		return 0, err
+		return 0, false, err
+	}
+	dbFingerprints, err := s.getDBFolderFingerprints(ctx)
+	if err != nil {
+		return 0, false, err
 	}
 
 	allFSDirs := dirMap{}
 	var changedDirs []string
 	s.cnt = &counters{}
+	s.tagUsage = map[string]int64{}
+	if err := s.loadTagAllowlist(ctx); err != nil {
+		log.Error(ctx, "Error loading tag allowlist from the DB", err)
+	}
+	if fullScan {
+		// Fold pre-existing duplicate artists before their rows are refreshed with the
+		// normalized IDs. No-op unless Scanner.NormalizeArtistNames is enabled
+		if merged, err := s.ds.MergeDuplicateArtists(ctx); err != nil {
+			log.Error(ctx, "Error merging duplicate artists", err)
+		} else if merged > 0 {
+			log.Info(ctx, "Merged artists with duplicate names", "count", merged)
+		}
+		// Fold folder rows whose paths normalize to the same ID (ex: NFD/NFC variants),
+		// before the tree is rebuilt for this scan
+		if merged, err := s.ds.MergeDuplicateFolders(ctx); err != nil {
+			log.Error(ctx, "Error merging duplicate folders", err)
+		} else if merged > 0 {
+			log.Info(ctx, "Merged folders with duplicate paths", "count", merged)
+		}
+	}
 	genres := newCachedGenreRepository(ctx, s.ds.Genre(ctx))
-	s.mapper = NewMediaFileMapper(s.lib.Path, genres)
-	refresher := newRefresher(s.ds, s.cacheWarmer, s.lib, allFSDirs)
+	s.mapper = NewMediaFileMapper(s.lib.Path, genres, loadTagMappings(ctx, s.ds))
+	s.tagWriter = nil
+	if conf.Server.Scanner.WriteTrackID && !conf.Server.Scanner.WriteTrackIDDryRun {
+		var ok bool
+		if s.tagWriter, ok = metadata.WriterFor(s.lib.Extractor); !ok {
+			extractorID := s.lib.Extractor
+			if extractorID == "" {
+				extractorID = conf.Server.Scanner.Extractor
+			}
+			log.Warn(ctx, "Scanner.WriteTrackID is enabled, but the extractor cannot write tags. Ignoring",
+				"extractor", extractorID)
+		}
+	}
+	refresher := newRefresher(s.cacheWarmer, s.lib, allFSDirs)
 
 	log.Trace(ctx, "Loading directory tree from music folder", "folder", s.lib.Path)
 	foldersFound, walkerError := walkDirTree(ctx, s.lib.Path)
@@ -116,13 +165,22 @@ func (s *TagScanner) Scan(ctx context.Context, fullScan bool, progress chan uint
 			progress <- folderStats.AudioFilesCount
 			allFSDirs[folderStats.Path] = folderStats
 
-			if s.folderHasChanged(folderStats, allDBDirs, s.lib.LastScanAt) || fullScan {
+			if fullScan || s.folderNeedsProcessing(folderStats, allDBDirs, dbFingerprints) {
+				// Once the time budget is exhausted, remaining folders are left for the next run
+				if partial {
+					continue
+				}
 				changedDirs = append(changedDirs, folderStats.Path)
 				log.Debug("Processing changed folder", "dir", folderStats.Path)
 				err := s.processChangedDir(walkCtx, refresher, fullScan, folderStats.Path)
 				if err != nil {
 					log.Error("Error updating folder in the DB", "dir", folderStats.Path, err)
 				}
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					partial = true
+					log.Warn(ctx, "Scan time budget exhausted. Postponing the remaining folders to the next run",
+						"folder", s.lib.Path, "budget", conf.Server.Scanner.TimeBudget)
+				}
 			}
 		}
 		return nil
@@ -137,17 +195,22 @@ func (s *TagScanner) Scan(ctx context.Context, fullScan bool, progress chan uint
 	})
 	// Wait for all goroutines to finish, and check if an error occurred
 	if err := g.Wait(); err != nil {
-		return 0, err
+		return 0, false, err
 	}
 
-	deletedDirs := s.getDeletedDirs(ctx, allFSDirs, allDBDirs)
+	// On partial runs the folder tree was not fully traversed, so skip the destructive
+	// reconciliation phases: folders not seen yet would be wrongly considered deleted
+	var deletedDirs []string
+	if !partial {
+		deletedDirs = s.getDeletedDirs(ctx, allFSDirs, allDBDirs)
+	}
 	if len(deletedDirs)+len(changedDirs) == 0 {
 		log.Debug(ctx, "No changes found in Music Folder", "folder", s.lib.Path, "elapsed", time.Since(start))
-		return 0, nil
+		return 0, false, nil
 	}
 
 	for _, dir := range deletedDirs {
-		err := s.processDeletedDir(ctx, refresher, dir)
+		err := s.processDeletedDir(ctx, dir)
 		if err != nil {
 			log.Error("Error removing deleted folder from DB", "dir", dir, err)
 		}
@@ -172,15 +235,36 @@ func (s *TagScanner) Scan(ctx context.Context, fullScan bool, progress chan uint
 		log.Debug("Playlist auto-import is disabled")
 	}
 
-	err = s.ds.GC(log.NewContext(ctx), s.lib.Path)
+	if !partial {
+		err = s.ds.GC(log.NewContext(ctx), s.lib.Path)
+		if fullScan && conf.Server.Scanner.PurgeOrphanAnnotations {
+			report, cleanErr := s.ds.CleanOrphanAnnotations(log.NewContext(ctx), true)
+			if cleanErr != nil {
+				log.Error(ctx, "Error removing orphan annotations", cleanErr)
+			} else {
+				log.Info(ctx, "Removed orphan annotations", "counts", report)
+			}
+		}
+	}
+	s.saveTagUsage(ctx, fullScan && !partial)
+
+	// Post-scan duplicate report: a single grouped query, so the main pipeline is never
+	// slowed down. The result is served live by the /api/duplicates endpoint
+	if dups, dupErr := s.ds.MediaFile(ctx).FindDuplicates(); dupErr != nil {
+		log.Error(ctx, "Error detecting duplicate files", dupErr)
+	} else if len(dups) > 0 {
+		log.Info(ctx, "Found tracks that seem to be imported more than once", "groups", len(dups))
+	}
+
 	log.Info("Finished processing Music Folder", "folder", s.lib.Path, "elapsed", time.Since(start),
-		"added", s.cnt.added, "updated", s.cnt.updated, "deleted", s.cnt.deleted, "playlistsImported", s.cnt.playlists)
+		"added", s.cnt.added, "updated", s.cnt.updated, "markedMissing", s.cnt.missing, "playlistsImported", s.cnt.playlists,
+		"lockedFieldUpdatesSuppressed", s.cnt.locked, "partial", partial)
 
-	return s.cnt.total(), err
+	return s.cnt.total(), partial, err
 }
 
 func isDirEmpty(ctx context.Context, dir string) (bool, error) {
-	children, stats, err := loadDir(ctx, dir)
+	children, stats, err := loadDir(ctx, dir, dir)
 	if err != nil {
 		return false, err
 	}
@@ -205,6 +289,34 @@ func (s *TagScanner) getDBDirTree(ctx context.Context) (map[string]struct{}, err
 	return resp, nil
 }
 
+// getDBFolderFingerprints loads the content fingerprint of every folder in the library, as
+// recorded by the last scan that processed it
+func (s *TagScanner) getDBFolderFingerprints(ctx context.Context) (map[string]string, error) {
+	folders, err := s.ds.Folder(ctx).GetAll(model.QueryOptions{Filters: squirrel.Eq{"library_id": s.lib.ID}})
+	if err != nil {
+		return nil, err
+	}
+	resp := make(map[string]string, len(folders))
+	for _, f := range folders {
+		if f.Fingerprint != "" {
+			resp[f.Path] = f.Fingerprint
+		}
+	}
+	return resp, nil
+}
+
+// folderNeedsProcessing decides whether the folder's tracks must be reconciled with the DB. A
+// fingerprint recorded by a previous scan is authoritative: when it still matches, the folder is
+// skipped without its tracks ever being loaded, and any difference (files added, removed or
+// touched) forces processing, even when the modification times don't give the change away.
+// Folders scanned before fingerprints existed fall back to the modification time heuristic
+func (s *TagScanner) folderNeedsProcessing(folder dirStats, dbDirs map[string]struct{}, fingerprints map[string]string) bool {
+	if fp := fingerprints[folder.Path]; fp != "" {
+		return folder.fingerprint() != fp
+	}
+	return s.folderHasChanged(folder, dbDirs, s.lib.LastScanAt)
+}
+
 func (s *TagScanner) folderHasChanged(folder dirStats, dbDirs map[string]struct{}, lastModified time.Time) bool {
 	_, inDB := dbDirs[folder.Path]
 	// If is a new folder with at least one song OR it was modified after lastModified
@@ -227,35 +339,47 @@ func (s *TagScanner) getDeletedDirs(ctx context.Context, fsDirs dirMap, dbDirs m
 	return deleted
 }
 
-func (s *TagScanner) processDeletedDir(ctx context.Context, refresher *refresher, dir string) error {
+func (s *TagScanner) processDeletedDir(ctx context.Context, dir string) error {
 	start := time.Now()
 
-	mfs, err := s.ds.MediaFile(ctx).FindAllByPath(dir)
-	if err != nil {
-		return err
-	}
-
-	c, err := s.ds.MediaFile(ctx).DeleteByPath(dir)
+	var c int64
+	err := s.ds.WithTx(func(tx model.DataStore) error {
+		var err error
+		c, err = tx.MediaFile(ctx).MarkMissingByPath(dir)
+		if err != nil {
+			return err
+		}
+		// The album_folders association records how many tracks this folder contributed to
+		// each album, so song counts can be adjusted in place instead of reloading and
+		// re-aggregating every affected album (they may span folders that still exist)
+		return tx.Album(ctx).ReduceSongCountByFolder(model.FolderID(dir))
+	})
 	if err != nil {
 		return err
 	}
-	s.cnt.deleted += c
+	s.cnt.missing += c
+	s.metrics.tracksMissing.WithLabelValues(s.lib.Path).Add(float64(c))
 
-	for _, t := range mfs {
-		refresher.accumulate(t)
-	}
-
-	err = refresher.flush(ctx)
-	log.Info(ctx, "Finished processing deleted folder", "dir", dir, "purged", len(mfs), "elapsed", time.Since(start))
-	return err
+	log.Info(ctx, "Finished processing deleted folder", "dir", dir, "markedMissing", c, "elapsed", time.Since(start))
+	return nil
 }
 
 func (s *TagScanner) processChangedDir(ctx context.Context, refresher *refresher, fullScan bool, dir string) error {
 	start := time.Now()
 
-	// Load folder's current tracks from DB into a map
+	// Keep the folder tree in sync, so folder-based filters can resolve the parent chain. The
+	// content fingerprint stored with it lets the next scans skip the folder while it matches
+	folder := model.Folder{ID: model.FolderID(dir), LibraryID: s.lib.ID, Path: dir,
+		Fingerprint: refresher.dirMap[dir].fingerprint()}
+	if dir != s.lib.Path {
+		folder.ParentID = model.FolderID(filepath.Dir(dir))
+	}
+
+	// Load folder's current tracks from DB into a map, excluding the ones already flagged
+	// missing: re-marking those on every scan would needlessly churn their rows and the
+	// album/artist roll-ups
 	currentTracks := map[string]model.MediaFile{}
-	ct, err := s.ds.MediaFile(ctx).FindAllByPath(dir)
+	ct, err := s.ds.MediaFile(ctx).FindAllByPath(dir, model.QueryOptions{Filters: squirrel.Eq{"missing": false}})
 	if err != nil {
 		return err
 	}
@@ -263,15 +387,39 @@ func (s *TagScanner) processChangedDir(ctx context.Context, refresher *refresher
 		currentTracks[t.Path] = t
 	}
 
+	// The missing tracks are loaded separately, only to detect files that came back
+	missingTracks := map[string]model.MediaFile{}
+	mt, err := s.ds.MediaFile(ctx).FindAllByPath(dir, model.QueryOptions{Filters: squirrel.Eq{"missing": true}})
+	if err != nil {
+		return err
+	}
+	for _, t := range mt {
+		missingTracks[t.Path] = t
+	}
+
 	// Load track list from the folder
 	files, err := loadAllAudioFiles(dir)
 	if err != nil {
 		return err
 	}
 
-	// If no files to process, return
+	// If no files to process, check for artist-level metadata before returning: folders
+	// without audio can still carry artist.jpg/artist.nfo files, and replacing one must
+	// refresh the artists whose albums live below the folder
 	if len(files)+len(currentTracks) == 0 {
-		return nil
+		if !conf.Server.Scanner.ArtistFolderMetadata || !refresher.dirMap[dir].hasArtistMetadata() {
+			return nil
+		}
+		ids, err := s.ds.Album(ctx).GetAlbumArtistIDsInFolder(dir)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		for _, id := range ids {
+			refresher.accumulateArtist(id)
+		}
+		return s.ds.WithTx(func(tx model.DataStore) error {
+			return refresher.flush(ctx, tx)
+		})
 	}
 
 	orphanTracks := map[string]model.MediaFile{}
@@ -282,7 +430,21 @@ func (s *TagScanner) processChangedDir(ctx context.Context, refresher *refresher
 	// If track from folder is newer than the one in DB, select for update/insert in DB
 	log.Trace(ctx, "Processing changed folder", "dir", dir, "tracksInDB", len(currentTracks), "tracksInFolder", len(files))
 	var filesToUpdate []string
+	var restoredIDs []string
 	for filePath, entry := range files {
+		if c, wasMissing := missingTracks[filePath]; wasMissing {
+			// The file came back. Its modification time may predate the track being marked
+			// missing, so reprocess it unconditionally to clear the flag. User-edited tracks
+			// skip tag reprocessing, so they are un-flagged directly instead
+			if c.UserEdited {
+				restoredIDs = append(restoredIDs, c.ID)
+			} else {
+				filesToUpdate = append(filesToUpdate, filePath)
+			}
+			s.cnt.updated++
+			refresher.accumulate(c)
+			continue
+		}
 		c, inDB := currentTracks[filePath]
 		if !inDB || fullScan {
 			filesToUpdate = append(filesToUpdate, filePath)
@@ -303,107 +465,258 @@ func (s *TagScanner) processChangedDir(ctx context.Context, refresher *refresher
 		refresher.accumulate(c)
 
 		// Only leaves in orphanTracks the ones not found in the folder. After this loop any remaining orphanTracks
-		// are considered gone from the music folder and will be deleted from DB
+		// are considered gone from the music folder and will be marked as missing
 		delete(orphanTracks, filePath)
 	}
 
-	numUpdatedTracks := 0
-	numPurgedTracks := 0
+	// Make the restored tracks visible to loadTracksToUpdate, so their annotations and
+	// locked fields are carried over when they are reprocessed
+	for path, t := range missingTracks {
+		if _, ok := files[path]; ok {
+			currentTracks[path] = t
+		}
+	}
 
+	var updated int
+	importedIDs := map[string]struct{}{}
 	if len(filesToUpdate) > 0 {
-		numUpdatedTracks, err = s.addOrUpdateTracksInDB(ctx, refresher, dir, currentTracks, filesToUpdate)
+		// Chunks are streamed to the DB as they are extracted, instead of accumulating the
+		// whole folder first: a flat folder with tens of thousands of files would otherwise
+		// hold every track and its tags in memory before the first row is written
+		updated, err = s.loadTracksToUpdate(ctx, refresher, dir, currentTracks, filesToUpdate,
+			func(chunk model.MediaFiles) error {
+				for _, t := range chunk {
+					importedIDs[t.ID] = struct{}{}
+				}
+				return s.ds.WithTx(func(tx model.DataStore) error {
+					return tx.MediaFile(ctx).PutAll(chunk)
+				})
+			})
 		if err != nil {
 			return err
 		}
 	}
 
+	var orphanIDs []string
 	if len(orphanTracks) > 0 {
-		numPurgedTracks, err = s.deleteOrphanSongs(ctx, refresher, dir, orphanTracks)
-		if err != nil {
-			return err
+		log.Debug(ctx, "Marking orphan tracks as missing", "dir", dir, "numTracks", len(orphanTracks))
+		// Remaining tracks from DB that are not in the folder are flagged as missing. They keep
+		// their annotations and playlist entries until permanently deleted through the API
+		for _, ct := range orphanTracks {
+			// A file carrying this track's embedded ID (see Scanner.WriteTrackID) was just
+			// imported under another path: the track was renamed, not deleted
+			if _, renamed := importedIDs[ct.ID]; renamed {
+				continue
+			}
+			orphanIDs = append(orphanIDs, ct.ID)
+			refresher.accumulate(ct)
 		}
 	}
 
-	err = refresher.flush(ctx)
-	log.Info(ctx, "Finished processing changed folder", "dir", dir, "updated", numUpdatedTracks,
-		"deleted", numPurgedTracks, "elapsed", time.Since(start))
-	return err
-}
-
-func (s *TagScanner) deleteOrphanSongs(
-	ctx context.Context,
-	refresher *refresher,
-	dir string,
-	tracksToDelete map[string]model.MediaFile,
-) (int, error) {
-	numPurgedTracks := 0
-
-	log.Debug(ctx, "Deleting orphan tracks from DB", "dir", dir, "numTracks", len(tracksToDelete))
-	// Remaining tracks from DB that are not in the folder are deleted
-	for _, ct := range tracksToDelete {
-		numPurgedTracks++
-		refresher.accumulate(ct)
-		if err := s.ds.MediaFile(ctx).Delete(ct.ID); err != nil {
-			return 0, err
+	// The missing/restored flags and the album/artist roll-ups are deferred until every chunk
+	// of the folder is in: an error while loading chunks returns above, so a half-processed
+	// folder never marks tracks missing, and is picked up again by the next scan
+	err = s.ds.WithTx(func(tx model.DataStore) error {
+		if err := tx.Folder(ctx).Put(&folder); err != nil {
+			return err
+		}
+		if len(orphanIDs) > 0 {
+			if err := tx.MediaFile(ctx).MarkMissing(true, orphanIDs...); err != nil {
+				return err
+			}
 		}
-		s.cnt.deleted++
+		if len(restoredIDs) > 0 {
+			if err := tx.MediaFile(ctx).MarkMissing(false, restoredIDs...); err != nil {
+				return err
+			}
+		}
+		return refresher.flush(ctx, tx)
+	})
+	if err != nil {
+		return err
 	}
-	return numPurgedTracks, nil
+	s.cnt.missing += int64(len(orphanIDs))
+
+	s.metrics.foldersProcessed.WithLabelValues(s.lib.Path).Inc()
+	s.metrics.folderDuration.WithLabelValues(s.lib.Path).Observe(time.Since(start).Seconds())
+	s.metrics.filesImported.WithLabelValues(s.lib.Path).Add(float64(updated))
+	s.metrics.filesSkipped.WithLabelValues(s.lib.Path).Add(float64(len(files) - len(filesToUpdate)))
+	s.metrics.tracksMissing.WithLabelValues(s.lib.Path).Add(float64(len(orphanIDs)))
+
+	log.Info(ctx, "Finished processing changed folder", "dir", dir, "updated", updated,
+		"markedMissing", len(orphanIDs), "elapsed", time.Since(start))
+	return nil
 }
 
-func (s *TagScanner) addOrUpdateTracksInDB(
+// loadTracksToUpdate extracts the metadata of the given files, maps them to MediaFiles and hands
+// them to the persist callback one chunk at a time, returning the number of tracks persisted.
+// Only one chunk is held in memory at any moment, keeping the scan's footprint bounded no matter
+// how many files a single folder has
+func (s *TagScanner) loadTracksToUpdate(
 	ctx context.Context,
 	refresher *refresher,
 	dir string,
 	currentTracks map[string]model.MediaFile,
 	filesToUpdate []string,
+	persist func(chunk model.MediaFiles) error,
 ) (int, error) {
-	numUpdatedTracks := 0
-
-	log.Trace(ctx, "Updating mediaFiles in DB", "dir", dir, "numFiles", len(filesToUpdate))
-	// Break the file list in chunks to avoid calling ffmpeg with too many parameters
+	log.Trace(ctx, "Loading tracks metadata", "dir", dir, "numFiles", len(filesToUpdate))
+	// Break the file list in chunks, also to avoid calling ffmpeg with too many parameters
 	chunks := slice.BreakUp(filesToUpdate, filesBatchSize)
+	count := 0
 	for _, chunk := range chunks {
 		// Load tracks Metadata from the folder
-		newTracks, err := s.loadTracks(chunk)
+		newTracks, err := s.loadTracks(ctx, chunk)
 		if err != nil {
-			return 0, err
+			return count, err
 		}
 
-		// If track from folder is newer than the one in DB, update/insert in DB
-		log.Trace(ctx, "Updating mediaFiles in DB", "dir", dir, "files", chunk, "numFiles", len(chunk))
+		batch := make(model.MediaFiles, 0, len(newTracks))
+
 		for i := range newTracks {
 			n := newTracks[i]
+			// Quarantine rows failing validation (skip them with a warning), so one garbage
+			// file does not abort the folder or end up in the DB
+			if err := n.Normalize(); err != nil {
+				log.Warn(ctx, "Skipping invalid media file", "path", n.Path, err)
+				continue
+			}
 			// Keep current annotations if the track is in the DB
 			if t, ok := currentTracks[n.Path]; ok {
+				// Tracks edited through the bulk edit API take precedence over the file tags
+				if t.UserEdited {
+					log.Debug(ctx, "Skipping user-edited track", "path", n.Path)
+					refresher.accumulate(t)
+					continue
+				}
 				n.Annotations = t.Annotations
+				// Locked fields keep their DB values, regardless of what is in the file tags
+				if suppressed := n.ApplyFieldLocks(t); suppressed > 0 {
+					log.Debug(ctx, "Suppressed updates to locked fields", "path", n.Path, "lockedFields", t.LockedFields)
+					s.cnt.locked += int64(suppressed)
+				}
 			}
 			n.LibraryID = s.lib.ID
-			err := s.ds.MediaFile(ctx).Put(&n)
-			if err != nil {
-				return 0, err
-			}
+			batch = append(batch, n)
 			refresher.accumulate(n)
-			numUpdatedTracks++
+		}
+
+		if len(batch) > 0 {
+			if err := persist(batch); err != nil {
+				return count, err
+			}
+			count += len(batch)
 		}
 	}
-	return numUpdatedTracks, nil
+	return count, nil
 }
 
-func (s *TagScanner) loadTracks(filePaths []string) (model.MediaFiles, error) {
-	mds, err := metadata.Extract(filePaths...)
+func (s *TagScanner) loadTracks(ctx context.Context, filePaths []string) (model.MediaFiles, error) {
+	start := time.Now()
+	mds, err := metadata.ExtractWith(s.lib.Extractor, filePaths...)
+	s.metrics.extractDuration.WithLabelValues(s.lib.Path).Observe(time.Since(start).Seconds())
 	if err != nil {
+		s.metrics.extractionErrors.WithLabelValues(s.lib.Path).Add(float64(len(filePaths)))
 		return nil, err
 	}
+	// Files dropped from the result could not be parsed by the extractor
+	if failed := len(filePaths) - len(mds); failed > 0 {
+		s.metrics.extractionErrors.WithLabelValues(s.lib.Path).Add(float64(failed))
+	}
 
 	var mfs model.MediaFiles
 	for _, md := range mds {
+		for name := range md.Tags {
+			s.tagUsage[name]++
+		}
 		mf := s.mapper.ToMediaFile(md)
+		fingerprintIfNeeded(ctx, md, &mf)
+		if conf.Server.Scanner.WriteTrackID {
+			s.embedTrackID(ctx, md, &mf)
+		}
 		mfs = append(mfs, mf)
 	}
 	return mfs, nil
 }
 
+// embedTrackID writes the track ID into files imported without one (see Scanner.WriteTrackID).
+// Files are only ever touched right here, while being imported: never during a scan that just
+// reads them, and never when they already carry an ID
+func (s *TagScanner) embedTrackID(ctx context.Context, md metadata.Tags, mf *model.MediaFile) {
+	if md.NavidromeID() != "" {
+		return
+	}
+	if conf.Server.Scanner.WriteTrackIDDryRun {
+		log.Info(ctx, "Would embed track ID (dry-run)", "path", mf.Path, "id", mf.ID)
+		return
+	}
+	if s.tagWriter == nil {
+		return
+	}
+	if err := s.tagWriter.WriteTrackID(mf.Path, mf.ID); err != nil {
+		log.Warn(ctx, "Could not embed track ID", "path", mf.Path, "id", mf.ID, err)
+		return
+	}
+	// The write itself changed the file. Keep the row in sync with the on-disk state, so the
+	// next scan does not immediately flag the file as modified
+	if info, err := os.Stat(mf.Path); err == nil {
+		mf.Size = info.Size()
+		mf.UpdatedAt = info.ModTime()
+	}
+	log.Debug(ctx, "Embedded track ID", "path", mf.Path, "id", mf.ID)
+}
+
+// loadTagAllowlist applies the tag allowlist stored in the DB (managed through the native API),
+// which takes precedence over the Scanner.AdditionalRoles configuration
+func (s *TagScanner) loadTagAllowlist(ctx context.Context) error {
+	value, err := s.ds.Property(ctx).DefaultGet(consts.TagAllowlistKey, "")
+	if err != nil {
+		return err
+	}
+	var roles []string
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &roles); err != nil {
+			return err
+		}
+	}
+	// The allowlist is matched against canonical tag names, so entries added in any
+	// case/spelling still match what the extractors report
+	for i, role := range roles {
+		roles[i] = metadata.CanonicalTagName(role)
+	}
+	model.SetCustomRoles(roles)
+	return nil
+}
+
+// saveTagUsage records the tag names (and counts) seen during this scan, including ones not
+// persisted, so the native API can report all tags present in the library. Full scans revisit
+// every file, so their counts replace the stored report instead of being merged into it.
+func (s *TagScanner) saveTagUsage(ctx context.Context, fullScan bool) {
+	if len(s.tagUsage) == 0 {
+		return
+	}
+	props := s.ds.Property(ctx)
+	usage := map[string]int64{}
+	if !fullScan {
+		if value, err := props.DefaultGet(consts.TagUsageKey, ""); err == nil && value != "" {
+			if err := json.Unmarshal([]byte(value), &usage); err != nil {
+				log.Warn(ctx, "Could not parse stored tag usage report. Discarding it", err)
+				usage = map[string]int64{}
+			}
+		}
+	}
+	for name, count := range s.tagUsage {
+		usage[name] += count
+	}
+	value, err := json.Marshal(usage)
+	if err == nil {
+		err = props.Put(consts.TagUsageKey, string(value))
+	}
+	if err != nil {
+		log.Error(ctx, "Error saving tag usage report", err)
+	}
+}
+
 func loadAllAudioFiles(dirPath string) (map[string]fs.DirEntry, error) {
 	files, err := fs.ReadDir(os.DirFS(dirPath), ".")
 	if err != nil {
@@ -10,10 +10,11 @@ var _ = Describe("TagScanner", func() {
 		It("return all audio files from the folder", func() {
 			files, err := loadAllAudioFiles("tests/fixtures")
 			Expect(err).ToNot(HaveOccurred())
-			Expect(files).To(HaveLen(12))
+			Expect(files).To(HaveLen(13))
 			Expect(files).To(HaveKey("tests/fixtures/test.aiff"))
 			Expect(files).To(HaveKey("tests/fixtures/test.flac"))
 			Expect(files).To(HaveKey("tests/fixtures/test.m4a"))
+			Expect(files).To(HaveKey("tests/fixtures/test_alac.m4a"))
 			Expect(files).To(HaveKey("tests/fixtures/test.mp3"))
 			Expect(files).To(HaveKey("tests/fixtures/test.tak"))
 			Expect(files).To(HaveKey("tests/fixtures/test.ogg"))
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scanner time budget", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var s FolderScanner
+	var root, dirA, dirB string
+	var progress chan uint32
+
+	copyFixture := func(destPath string) {
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(destPath, data, 0600)).To(Succeed())
+	}
+
+	trackIn := func(dir string) model.MediaFile {
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		return mfs[0]
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root = GinkgoT().TempDir()
+		dirA = filepath.Join(root, "artist a")
+		dirB = filepath.Join(root, "artist b")
+		Expect(os.Mkdir(dirA, 0700)).To(Succeed())
+		Expect(os.Mkdir(dirB, 0700)).To(Succeed())
+		copyFixture(filepath.Join(dirA, "track1.mp3"))
+		copyFixture(filepath.Join(dirB, "track2.mp3"))
+
+		metadata.RegisterExtractor("budget", &taglib.Extractor{})
+		previous := conf.Server.Scanner.Extractor
+		conf.Server.Scanner.Extractor = "budget"
+		DeferCleanup(func() {
+			conf.Server.Scanner.Extractor = previous
+			conf.Server.Scanner.TimeBudget = 0
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dirA)
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dirB)
+			_ = ds.GC(ctx, root)
+		})
+
+		s = NewTagScanner(model.Library{ID: 1, Path: root}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+	})
+
+	It("stops after the budget, skips reconciliation, and completes on the next run", func() {
+		// A complete pass imports everything
+		_, partial, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(partial).To(BeFalse())
+		Expect(trackIn(dirA).Missing).To(BeFalse())
+		Expect(trackIn(dirB).Missing).To(BeFalse())
+
+		// A folder disappears, but the next pass runs out of budget right away
+		Expect(os.RemoveAll(dirB)).To(Succeed())
+		conf.Server.Scanner.TimeBudget = time.Nanosecond
+		_, partial, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(partial).To(BeTrue())
+
+		// The partial run must not mark anything missing: the deleted folder check needs a
+		// full traversal to be reliable
+		Expect(trackIn(dirB).Missing).To(BeFalse())
+
+		// With the budget lifted, the next run completes and reconciles
+		conf.Server.Scanner.TimeBudget = 0
+		_, partial, err = s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(partial).To(BeFalse())
+		Expect(trackIn(dirA).Missing).To(BeFalse())
+		Expect(trackIn(dirB).Missing).To(BeTrue())
+	})
+})
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/navidrome/navidrome/scanner/metadata/taglib"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// These specs cover the opt-in Scanner.WriteTrackID mode: the first import embeds the track ID
+// into the file as a NAVIDROME_ID tag, and the embedded ID then takes precedence over the
+// path-derived one, so a renamed file keeps its row (and with it annotations and playlist
+// entries) instead of being re-imported as a new track.
+var _ = Describe("Track ID write-back", func() {
+	var ctx context.Context
+	var ds model.DataStore
+	var root, dir, trackPath string
+	var progress chan uint32
+
+	parseFile := func(path string) map[string][]string {
+		mds, err := (&taglib.Extractor{}).Parse(path)
+		Expect(err).ToNot(HaveOccurred())
+		return mds[path]
+	}
+
+	scan := func() {
+		s := NewTagScanner(model.Library{ID: 1, Path: root, LastScanAt: time.Now()}, ds, core.NewPlaylists(ds), &noopCacheWarmer{})
+		_, _, err := s.Scan(ctx, true, progress)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ds = persistence.New(db.Db())
+		progress = make(chan uint32, 100)
+
+		root = GinkgoT().TempDir()
+		dir = filepath.Join(root, "artist")
+		trackPath = filepath.Join(dir, "track1.mp3")
+		Expect(os.Mkdir(dir, 0700)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join("tests", "fixtures", "test.mp3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(trackPath, data, 0600)).To(Succeed())
+
+		prevWrite := conf.Server.Scanner.WriteTrackID
+		prevDryRun := conf.Server.Scanner.WriteTrackIDDryRun
+		conf.Server.Scanner.WriteTrackID = true
+		DeferCleanup(func() {
+			conf.Server.Scanner.WriteTrackID = prevWrite
+			conf.Server.Scanner.WriteTrackIDDryRun = prevDryRun
+			_, _ = ds.MediaFile(ctx).DeleteByPath(dir)
+			_ = ds.GC(ctx, root)
+		})
+	})
+
+	It("embeds the track ID into the file on first import", func() {
+		scan()
+
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		Expect(parseFile(trackPath)).To(HaveKeyWithValue("navidrome_id", []string{mfs[0].ID}))
+	})
+
+	It("keeps the track identity when the file is renamed", func() {
+		scan()
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		id := mfs[0].ID
+
+		newPath := filepath.Join(dir, "renamed.mp3")
+		Expect(os.Rename(trackPath, newPath)).To(Succeed())
+		scan()
+
+		mf, err := ds.MediaFile(ctx).Get(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mf.Path).To(Equal(newPath))
+		Expect(mf.Missing).To(BeFalse())
+		mfs, err = ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+	})
+
+	It("does not modify files in dry-run mode", func() {
+		conf.Server.Scanner.WriteTrackIDDryRun = true
+		scan()
+
+		mfs, err := ds.MediaFile(ctx).FindAllByPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mfs).To(HaveLen(1))
+		Expect(parseFile(trackPath)).ToNot(HaveKey("navidrome_id"))
+	})
+})
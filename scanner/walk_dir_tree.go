@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/consts"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
@@ -22,17 +24,41 @@ type (
 		Images          []string
 		ImagesUpdatedAt time.Time
 		HasPlaylist     bool
+		HasArtistNfo    bool
 		AudioFilesCount uint32
+		FilesSize       int64
+		FilesUpdatedAt  time.Time
 	}
 )
 
+// hasArtistMetadata reports whether the folder carries artist-level metadata files (an
+// artist.* image or an artist.nfo)
+func (s dirStats) hasArtistMetadata() bool {
+	if s.HasArtistNfo {
+		return true
+	}
+	for _, img := range s.Images {
+		if isArtistImageFile(img) {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprint summarizes the folder contents: audio file count, newest file modification time
+// and total file size. The folder's own mtime is left out on purpose, as it is bumped by
+// operations (ex: temporary files coming and going) that don't change the library contents
+func (s dirStats) fingerprint() string {
+	return fmt.Sprintf("%d|%d|%d", s.AudioFilesCount, s.FilesUpdatedAt.UnixMilli(), s.FilesSize)
+}
+
 func walkDirTree(ctx context.Context, rootFolder string) (<-chan dirStats, <-chan error) {
 	results := make(chan dirStats)
 	errC := make(chan error)
 	go func() {
 		defer close(results)
 		defer close(errC)
-		err := walkFolder(ctx, rootFolder, rootFolder, results)
+		err := walkFolder(ctx, rootFolder, rootFolder, map[string]struct{}{}, results)
 		if err != nil {
 			log.Error(ctx, "There were errors reading directories from filesystem", "path", rootFolder, err)
 			errC <- err
@@ -42,13 +68,27 @@ func walkDirTree(ctx context.Context, rootFolder string) (<-chan dirStats, <-cha
 	return results, errC
 }
 
-func walkFolder(ctx context.Context, rootPath string, currentFolder string, results chan<- dirStats) error {
-	children, stats, err := loadDir(ctx, currentFolder)
+// walkFolder processes currentFolder and recurses into its subfolders. ancestors holds the
+// resolved real path of every folder in the current descent, so a symlink looping back up the
+// tree is detected and skipped instead of recursing forever. Results are reported under the
+// logical (link) path, matching the layout the user configured
+func walkFolder(ctx context.Context, rootPath string, currentFolder string, ancestors map[string]struct{}, results chan<- dirStats) error {
+	realPath, err := filepath.EvalSymlinks(currentFolder)
+	if err == nil {
+		if _, ok := ancestors[realPath]; ok {
+			log.Warn(ctx, "Skipping symlink loop", "path", currentFolder, "target", realPath)
+			return nil
+		}
+		ancestors[realPath] = struct{}{}
+		defer delete(ancestors, realPath)
+	}
+
+	children, stats, err := loadDir(ctx, rootPath, currentFolder)
 	if err != nil {
 		return err
 	}
 	for _, c := range children {
-		err := walkFolder(ctx, rootPath, c, results)
+		err := walkFolder(ctx, rootPath, c, ancestors, results)
 		if err != nil {
 			return err
 		}
@@ -63,7 +103,7 @@ func walkFolder(ctx context.Context, rootPath string, currentFolder string, resu
 	return nil
 }
 
-func loadDir(ctx context.Context, dirPath string) ([]string, *dirStats, error) {
+func loadDir(ctx context.Context, rootPath string, dirPath string) ([]string, *dirStats, error) {
 	var children []string
 	stats := &dirStats{}
 
@@ -88,6 +128,9 @@ func loadDir(ctx context.Context, dirPath string) ([]string, *dirStats, error) {
 			log.Error(ctx, "Invalid symlink", "dir", filepath.Join(dirPath, entry.Name()), err)
 			continue
 		}
+		if isDir && entry.Type()&os.ModeSymlink != 0 && !shouldFollowSymlink(ctx, rootPath, dirPath, entry) {
+			continue
+		}
 		if isDir && !isDirIgnored(dirPath, entry) && isDirReadable(ctx, dirPath, entry) {
 			children = append(children, filepath.Join(dirPath, entry.Name()))
 		} else {
@@ -99,6 +142,10 @@ func loadDir(ctx context.Context, dirPath string) ([]string, *dirStats, error) {
 			if fileInfo.ModTime().After(stats.ModTime) {
 				stats.ModTime = fileInfo.ModTime()
 			}
+			if fileInfo.ModTime().After(stats.FilesUpdatedAt) {
+				stats.FilesUpdatedAt = fileInfo.ModTime()
+			}
+			stats.FilesSize += fileInfo.Size()
 			switch {
 			case model.IsAudioFile(entry.Name()):
 				stats.AudioFilesCount++
@@ -109,6 +156,8 @@ func loadDir(ctx context.Context, dirPath string) ([]string, *dirStats, error) {
 				if fileInfo.ModTime().After(stats.ImagesUpdatedAt) {
 					stats.ImagesUpdatedAt = fileInfo.ModTime()
 				}
+			case strings.EqualFold(entry.Name(), artistNfoFile):
+				stats.HasArtistNfo = true
 			}
 		}
 	}
@@ -160,6 +209,33 @@ func isDirOrSymlinkToDir(baseDir string, dirEnt fs.DirEntry) (bool, error) {
 	return fileInfo.IsDir(), nil
 }
 
+// shouldFollowSymlink applies the Scanner symlink options to a symlinked folder: links are
+// not followed at all when FollowSymlinks is off, and links pointing outside the library root
+// are only followed when FollowExternalSymlinks is on
+func shouldFollowSymlink(ctx context.Context, rootPath string, baseDir string, dirEnt fs.DirEntry) bool {
+	if !conf.Server.Scanner.FollowSymlinks {
+		return false
+	}
+	if conf.Server.Scanner.FollowExternalSymlinks {
+		return true
+	}
+	linkPath := filepath.Join(baseDir, dirEnt.Name())
+	target, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		return false
+	}
+	realRoot, err := filepath.EvalSymlinks(rootPath)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(realRoot, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		log.Debug(ctx, "Not following symlink pointing outside the library", "link", linkPath, "target", target)
+		return false
+	}
+	return true
+}
+
 // isDirIgnored returns true if the directory represented by dirEnt contains an
 // `ignore` file (named after skipScanFile)
 func isDirIgnored(baseDir string, dirEnt fs.DirEntry) bool {
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"testing/fstest"
 
+	"github.com/navidrome/navidrome/conf"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
@@ -34,7 +35,7 @@ var _ = Describe("walk_dir_tree", func() {
 			Expect(collected[baseDir]).To(MatchFields(IgnoreExtras, Fields{
 				"Images":          BeEmpty(),
 				"HasPlaylist":     BeFalse(),
-				"AudioFilesCount": BeNumerically("==", 13),
+				"AudioFilesCount": BeNumerically("==", 14),
 			}))
 			Expect(collected[filepath.Join(baseDir, "artist", "an-album")]).To(MatchFields(IgnoreExtras, Fields{
 				"Images":          ConsistOf("cover.jpg", "front.png", "artist.png"),
@@ -47,6 +48,59 @@ var _ = Describe("walk_dir_tree", func() {
 		})
 	})
 
+	Describe("symlink handling", func() {
+		var root string
+
+		collect := func() dirMap {
+			collected := dirMap{}
+			results, errC := walkDirTree(context.Background(), root)
+			for stats := range results {
+				collected[stats.Path] = stats
+			}
+			Consistently(errC).ShouldNot(Receive())
+			return collected
+		}
+
+		BeforeEach(func() {
+			root = GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(root, "artist", "album"), 0700)).To(Succeed())
+		})
+
+		It("skips a symlink that loops back up the tree, instead of walking forever", func() {
+			Expect(os.Symlink(root, filepath.Join(root, "artist", "loop"))).To(Succeed())
+
+			collected := collect()
+			Expect(collected).To(HaveKey(filepath.Join(root, "artist", "album")))
+			Expect(collected).ToNot(HaveKey(filepath.Join(root, "artist", "loop")))
+		})
+
+		It("does not follow symlinked folders when FollowSymlinks is off", func() {
+			previous := conf.Server.Scanner.FollowSymlinks
+			conf.Server.Scanner.FollowSymlinks = false
+			DeferCleanup(func() { conf.Server.Scanner.FollowSymlinks = previous })
+			Expect(os.Symlink(filepath.Join(root, "artist", "album"), filepath.Join(root, "linked"))).To(Succeed())
+
+			collected := collect()
+			Expect(collected).To(HaveKey(filepath.Join(root, "artist", "album")))
+			Expect(collected).ToNot(HaveKey(filepath.Join(root, "linked")))
+		})
+
+		It("only follows symlinks pointing outside the library root when FollowExternalSymlinks is on", func() {
+			external := GinkgoT().TempDir()
+			Expect(os.Symlink(external, filepath.Join(root, "shared"))).To(Succeed())
+
+			previous := conf.Server.Scanner.FollowExternalSymlinks
+			DeferCleanup(func() { conf.Server.Scanner.FollowExternalSymlinks = previous })
+
+			conf.Server.Scanner.FollowExternalSymlinks = false
+			Expect(collect()).ToNot(HaveKey(filepath.Join(root, "shared")))
+
+			// When followed, the folder is reported under the logical (link) path
+			conf.Server.Scanner.FollowExternalSymlinks = true
+			Expect(collect()).To(HaveKey(filepath.Join(root, "shared")))
+		})
+	})
+
 	Describe("isDirOrSymlinkToDir", func() {
 		It("returns true for normal dirs", func() {
 			dirEntry := getDirEntry("tests", "fixtures")
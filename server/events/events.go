@@ -27,11 +27,21 @@ func (e *baseEvent) Data(evt Event) string {
 
 type ScanStatus struct {
 	baseEvent
-	Scanning    bool  `json:"scanning"`
-	Count       int64 `json:"count"`
-	FolderCount int64 `json:"folderCount"`
+	Scanning    bool   `json:"scanning"`
+	Count       int64  `json:"count"`
+	FolderCount int64  `json:"folderCount"`
+	Library     string `json:"library,omitempty"`
 }
 
+// prioritizedEvent marks events that should not be silently dropped when a client's queue is
+// full. The oldest queued message is discarded to make room, so slow clients always end up with
+// the most recent status (ex: the final "scan done" event), without ever blocking the sender.
+type prioritizedEvent interface {
+	prioritized()
+}
+
+func (s *ScanStatus) prioritized() {}
+
 type KeepAlive struct {
 	baseEvent
 	TS int64 `json:"ts"`
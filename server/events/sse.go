@@ -29,10 +29,11 @@ const (
 
 type (
 	message struct {
-		id        uint64
-		event     string
-		data      string
-		senderCtx context.Context
+		id          uint64
+		event       string
+		data        string
+		senderCtx   context.Context
+		prioritized bool
 	}
 	messageChan chan message
 	clientsChan chan client
@@ -88,6 +89,7 @@ func (b *broker) prepareMessage(ctx context.Context, event Event) message {
 	msg.data = event.Data(event)
 	msg.event = event.Name(event)
 	msg.senderCtx = ctx
+	_, msg.prioritized = event.(prioritizedEvent)
 	return msg
 }
 
@@ -263,9 +265,23 @@ func (b *broker) listen() {
 func sendOrDrop(client client, msg message) {
 	select {
 	case client.msgC <- msg:
+		return
 	default:
-		if log.IsGreaterOrEqualTo(log.LevelTrace) {
-			log.Trace("Event dropped because client's channel is full", "event", msg, "client", client.String())
+	}
+	if msg.prioritized {
+		// Discard the oldest queued message to make room, so slow clients still get the latest
+		// prioritized event without blocking the broker
+		select {
+		case <-client.msgC:
+		default:
+		}
+		select {
+		case client.msgC <- msg:
+			return
+		default:
 		}
 	}
+	if log.IsGreaterOrEqualTo(log.LevelTrace) {
+		log.Trace("Event dropped because client's channel is full", "event", msg, "client", client.String())
+	}
 }
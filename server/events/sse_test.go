@@ -58,4 +58,37 @@ var _ = Describe("Broker", func() {
 			})
 		})
 	})
+
+	Describe("prepareMessage", func() {
+		It("marks ScanStatus events as prioritized", func() {
+			msg := b.prepareMessage(context.Background(), &ScanStatus{})
+			Expect(msg.prioritized).To(BeTrue())
+		})
+		It("does not mark other events as prioritized", func() {
+			msg := b.prepareMessage(context.Background(), &KeepAlive{})
+			Expect(msg.prioritized).To(BeFalse())
+		})
+	})
+
+	Describe("sendOrDrop", func() {
+		var c client
+
+		BeforeEach(func() {
+			c = client{msgC: make(chan message, 1)}
+		})
+
+		It("drops regular events when the client's queue is full", func() {
+			sendOrDrop(c, message{id: 1})
+			sendOrDrop(c, message{id: 2})
+			Expect((<-c.msgC).id).To(Equal(uint64(1)))
+			Expect(c.msgC).To(BeEmpty())
+		})
+
+		It("replaces the oldest queued event with a prioritized one", func() {
+			sendOrDrop(c, message{id: 1})
+			sendOrDrop(c, message{id: 2, prioritized: true})
+			Expect((<-c.msgC).id).To(Equal(uint64(2)))
+			Expect(c.msgC).To(BeEmpty())
+		})
+	})
 })
@@ -0,0 +1,89 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// setAlbumCoverArt overrides the cover selected for the album by the scanner. The path must
+// be one of the album's image files or its embedded art path, and the override is kept
+// through re-scans by locking the coverArt field.
+func setAlbumCoverArt(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := chi.URLParam(r, "id")
+		var payload struct {
+			Path string `json:"path"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Path == "" {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+		al, err := ds.Album(ctx).Get(id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, "Album not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error retrieving album", "id", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !albumHasImage(al, payload.Path) {
+			http.Error(w, "path is not one of the album images", http.StatusBadRequest)
+			return
+		}
+		al.CoverArtPath = payload.Path
+		al.LockedFields = al.LockedFields.Lock("coverArt")
+		if err := ds.Album(ctx).Put(al); err != nil {
+			log.Error(ctx, "Error saving album cover art override", "id", id, "path", payload.Path, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+// removeAlbumCoverArt drops the cover art override: the field is unlocked and the selection
+// is recomputed by the next scan.
+func removeAlbumCoverArt(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := chi.URLParam(r, "id")
+		al, err := ds.Album(ctx).Get(id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, "Album not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error retrieving album", "id", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		al.CoverArtPath = ""
+		al.LockedFields = al.LockedFields.Unlock("coverArt")
+		if err := ds.Album(ctx).Put(al); err != nil {
+			log.Error(ctx, "Error removing album cover art override", "id", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+// albumHasImage reports whether the given path is one of the album's known image sources,
+// so the override can never point outside the album's own files
+func albumHasImage(al *model.Album, path string) bool {
+	if path == al.EmbedArtPath && path != "" {
+		return true
+	}
+	return slices.Contains(strings.Split(al.ImageFiles, consts.Zwsp), path)
+}
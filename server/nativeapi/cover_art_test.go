@@ -0,0 +1,65 @@
+package nativeapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cover art override endpoints", func() {
+	var ds *tests.MockDataStore
+	var router chi.Router
+
+	BeforeEach(func() {
+		ds = &tests.MockDataStore{}
+		ds.MockedAlbum = tests.CreateMockAlbumRepo()
+		ds.MockedAlbum.(*tests.MockAlbumRepo).SetData(model.Albums{
+			{ID: "1", Name: "Album", EmbedArtPath: "/music/album/track.mp3",
+				ImageFiles: strings.Join([]string{"/music/album/cover.jpg", "/music/album/folder.png"}, consts.Zwsp)},
+		})
+		router = chi.NewRouter()
+		router.Put("/album/{id}/coverArt", setAlbumCoverArt(ds))
+		router.Delete("/album/{id}/coverArt", removeAlbumCoverArt(ds))
+	})
+
+	call := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	It("sets and locks the override, and removes it again", func() {
+		Expect(call("PUT", "/album/1/coverArt", `{"path":"/music/album/folder.png"}`)).To(Equal(http.StatusOK))
+		al, err := ds.Album(context.Background()).Get("1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(al.CoverArtPath).To(Equal("/music/album/folder.png"))
+		Expect(al.LockedFields.IsLocked("coverArt")).To(BeTrue())
+
+		Expect(call("DELETE", "/album/1/coverArt", "")).To(Equal(http.StatusOK))
+		al, err = ds.Album(context.Background()).Get("1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(al.CoverArtPath).To(BeEmpty())
+		Expect(al.LockedFields.IsLocked("coverArt")).To(BeFalse())
+	})
+
+	It("accepts the embedded art path", func() {
+		Expect(call("PUT", "/album/1/coverArt", `{"path":"/music/album/track.mp3"}`)).To(Equal(http.StatusOK))
+	})
+
+	It("rejects paths that are not album images", func() {
+		Expect(call("PUT", "/album/1/coverArt", `{"path":"/etc/passwd"}`)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns 404 for unknown albums", func() {
+		Expect(call("PUT", "/album/missing/coverArt", `{"path":"/music/album/cover.jpg"}`)).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,31 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// listDuplicates reports tracks that appear to have been imported more than once, grouped by
+// recording, with the full entries (paths, bitrates, formats) so the admin can pick which
+// copy to delete
+func listDuplicates(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		groups, err := ds.MediaFile(ctx).FindDuplicates()
+		if err != nil {
+			log.Error(ctx, "Error detecting duplicate files", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if groups == nil {
+			groups = model.DuplicateGroups{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(groups); err != nil {
+			log.Error(ctx, "Error sending duplicates list", err)
+		}
+	}
+}
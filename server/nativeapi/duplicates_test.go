@@ -0,0 +1,59 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Duplicates endpoint", func() {
+	var ds *tests.MockDataStore
+	var router chi.Router
+
+	BeforeEach(func() {
+		ds = &tests.MockDataStore{}
+		ds.MockedMediaFile = tests.CreateMockMediaFileRepo()
+		ds.MockedMediaFile.(*tests.MockMediaFileRepo).SetData(model.MediaFiles{
+			{ID: "1", Path: "music/rips/mp3/taxman.mp3", Suffix: "mp3", BitRate: 320,
+				AlbumArtist: "The Beatles", Album: "Revolver", TrackNumber: 1, Title: "Taxman"},
+			{ID: "2", Path: "music/rips/flac/taxman.flac", Suffix: "flac", BitRate: 1000,
+				AlbumArtist: "The Beatles", Album: "Revolver", TrackNumber: 1, Title: "Taxman"},
+			{ID: "3", Path: "music/rips/mp3/rigby.mp3", Suffix: "mp3", BitRate: 320,
+				AlbumArtist: "The Beatles", Album: "Revolver", TrackNumber: 2, Title: "Eleanor Rigby"},
+		})
+		router = chi.NewRouter()
+		router.Get("/duplicates", listDuplicates(ds))
+	})
+
+	It("lists duplicate groups with both copies", func() {
+		req := httptest.NewRequest("GET", "/duplicates", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var groups model.DuplicateGroups
+		Expect(json.Unmarshal(w.Body.Bytes(), &groups)).To(Succeed())
+		Expect(groups).To(HaveLen(1))
+		Expect(groups[0].Title).To(Equal("Taxman"))
+		Expect(groups[0].Entries).To(HaveLen(2))
+		Expect(groups[0].Entries[0].Path).To(Equal("music/rips/flac/taxman.flac"))
+		Expect(groups[0].Entries[1].Path).To(Equal("music/rips/mp3/taxman.mp3"))
+	})
+
+	It("returns an empty list when there are no duplicates", func() {
+		ds.MockedMediaFile.(*tests.MockMediaFileRepo).SetData(model.MediaFiles{
+			{ID: "3", Path: "music/rips/mp3/rigby.mp3", Title: "Eleanor Rigby"},
+		})
+		req := httptest.NewRequest("GET", "/duplicates", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(MatchJSON("[]"))
+	})
+})
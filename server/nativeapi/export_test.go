@@ -0,0 +1,43 @@
+package nativeapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Export endpoint", func() {
+	var ds *tests.MockDataStore
+	var router chi.Router
+
+	BeforeEach(func() {
+		ds = &tests.MockDataStore{}
+		ds.MockedMediaFile = tests.CreateMockMediaFileRepo()
+		ds.MockedMediaFile.(*tests.MockMediaFileRepo).SetData(model.MediaFiles{
+			{ID: "2", Path: "music/two.mp3", Title: "Two", Suffix: "mp3", BitRate: 320},
+			{ID: "1", Path: "music/one.flac", Title: "One, Part \"1\"", Suffix: "flac", BitRate: 1000},
+		})
+		router = chi.NewRouter()
+		router.Get("/export/songs", exportSongsCSV(ds))
+	})
+
+	It("streams all tracks as CSV, in id order", func() {
+		req := httptest.NewRequest("GET", "/export/songs", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("text/csv"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(HavePrefix("id,path,title"))
+		Expect(lines[1]).To(ContainSubstring(`1,music/one.flac,"One, Part ""1"""`))
+		Expect(lines[2]).To(ContainSubstring("2,music/two.mp3,Two"))
+	})
+})
@@ -0,0 +1,87 @@
+package nativeapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// lockSongField and unlockSongField toggle a field lock on a track. Locked fields keep their
+// DB values on subsequent scans, regardless of the file tags; unlocking a field lets the next
+// scan restore the value from the tags.
+func lockSongField(ds model.DataStore) http.HandlerFunc   { return setSongFieldLock(ds, true) }
+func unlockSongField(ds model.DataStore) http.HandlerFunc { return setSongFieldLock(ds, false) }
+
+func setSongFieldLock(ds model.DataStore, lock bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := chi.URLParam(r, "id")
+		field := chi.URLParam(r, "field")
+		if !model.IsLockableMediaFileField(field) {
+			http.Error(w, "field is not lockable: "+field, http.StatusBadRequest)
+			return
+		}
+		mf, err := ds.MediaFile(ctx).Get(id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, "Track not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error retrieving track", "id", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if lock {
+			mf.LockedFields = mf.LockedFields.Lock(field)
+		} else {
+			mf.LockedFields = mf.LockedFields.Unlock(field)
+		}
+		if err := ds.MediaFile(ctx).Put(mf); err != nil {
+			log.Error(ctx, "Error saving track field locks", "id", id, "field", field, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+// lockAlbumField and unlockAlbumField are the album counterparts: locked album fields keep
+// their values when the album is re-aggregated from its tracks.
+func lockAlbumField(ds model.DataStore) http.HandlerFunc   { return setAlbumFieldLock(ds, true) }
+func unlockAlbumField(ds model.DataStore) http.HandlerFunc { return setAlbumFieldLock(ds, false) }
+
+func setAlbumFieldLock(ds model.DataStore, lock bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := chi.URLParam(r, "id")
+		field := chi.URLParam(r, "field")
+		if !model.IsLockableAlbumField(field) {
+			http.Error(w, "field is not lockable: "+field, http.StatusBadRequest)
+			return
+		}
+		al, err := ds.Album(ctx).Get(id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, "Album not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error retrieving album", "id", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if lock {
+			al.LockedFields = al.LockedFields.Lock(field)
+		} else {
+			al.LockedFields = al.LockedFields.Unlock(field)
+		}
+		if err := ds.Album(ctx).Put(al); err != nil {
+			log.Error(ctx, "Error saving album field locks", "id", id, "field", field, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
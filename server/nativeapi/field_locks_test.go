@@ -0,0 +1,56 @@
+package nativeapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Field lock endpoints", func() {
+	var ds *tests.MockDataStore
+	var router chi.Router
+
+	BeforeEach(func() {
+		ds = &tests.MockDataStore{}
+		ds.MockedMediaFile = tests.CreateMockMediaFileRepo()
+		ds.MockedMediaFile.(*tests.MockMediaFileRepo).SetData(model.MediaFiles{
+			{ID: "1", Title: "Song", Artist: "Artist"},
+		})
+		router = chi.NewRouter()
+		router.Put("/song/{id}/lock/{field}", lockSongField(ds))
+		router.Delete("/song/{id}/lock/{field}", unlockSongField(ds))
+	})
+
+	call := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	It("locks and unlocks a field on a track", func() {
+		Expect(call("PUT", "/song/1/lock/artist")).To(Equal(http.StatusOK))
+		mf, err := ds.MediaFile(context.Background()).Get("1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mf.LockedFields.IsLocked("artist")).To(BeTrue())
+
+		Expect(call("DELETE", "/song/1/lock/artist")).To(Equal(http.StatusOK))
+		mf, err = ds.MediaFile(context.Background()).Get("1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mf.LockedFields.IsLocked("artist")).To(BeFalse())
+	})
+
+	It("rejects fields that are not lockable", func() {
+		Expect(call("PUT", "/song/1/lock/path")).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns 404 for unknown tracks", func() {
+		Expect(call("PUT", "/song/missing/lock/artist")).To(Equal(http.StatusNotFound))
+	})
+})
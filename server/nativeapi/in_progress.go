@@ -0,0 +1,57 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+const defaultInProgressLimit = 10
+
+type inProgressResponse struct {
+	Songs  model.MediaFiles `json:"songs"`
+	Albums model.Albums     `json:"albums"`
+}
+
+// inProgress returns the user's "continue listening" items: tracks with a bookmark before the
+// completion threshold, and albums where the track played last is not the album's final one.
+// The number of items of each kind can be capped with the `limit` query param.
+func inProgress(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		limit := defaultInProgressLimit
+		if p := r.URL.Query().Get("limit"); p != "" {
+			var err error
+			if limit, err = strconv.Atoi(p); err != nil || limit < 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+		songs, err := ds.MediaFile(ctx).GetInProgress(limit)
+		if err != nil {
+			log.Error(ctx, "Error retrieving in-progress tracks", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		albums, err := ds.Album(ctx).GetInProgress(limit)
+		if err != nil {
+			log.Error(ctx, "Error retrieving in-progress albums", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp := inProgressResponse{Songs: songs, Albums: albums}
+		if resp.Songs == nil {
+			resp.Songs = model.MediaFiles{}
+		}
+		if resp.Albums == nil {
+			resp.Albums = model.Albums{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Error(ctx, "Error sending in-progress items", err)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package nativeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/core/storage"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner"
+)
+
+type libraryPayload struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Extractor string `json:"extractor,omitempty"`
+}
+
+func (n *Router) addLibraryRoute(r chi.Router) {
+	rescan := func(ctx context.Context) error { return n.scanner.RescanAll(ctx, false) }
+	r.Route("/library", func(r chi.Router) {
+		r.Use(adminOnly)
+		r.Get("/", listLibraries(n.ds))
+		r.Post("/", createLibrary(n.ds, rescan))
+		r.Route("/{id}", func(r chi.Router) {
+			r.Put("/", updateLibrary(n.ds))
+			r.Delete("/", deleteLibrary(n.ds, scanner.IsScanning))
+			r.Post("/relocate", relocateLibrary(n.ds, scanner.IsScanning, rescan))
+			r.Route("/access/{userId}", func(r chi.Router) {
+				r.Put("/", grantLibraryAccess(n.ds))
+				r.Delete("/", revokeLibraryAccess(n.ds))
+			})
+		})
+	})
+}
+
+// listLibraries returns all configured libraries
+func listLibraries(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		libs, err := ds.Library(ctx).GetAll()
+		if err != nil {
+			log.Error(ctx, "Error reading libraries", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(libs); err != nil {
+			log.Error(ctx, "Error sending libraries list", err)
+		}
+	}
+}
+
+// validateLibraryPath resolves the storage backend for the path and checks the root is
+// reachable, so an unregistered scheme or a typo does not surface only at the next scan
+func validateLibraryPath(path string) error {
+	fsys, err := storage.For(path)
+	if err != nil {
+		return err
+	}
+	if _, err := fs.Stat(fsys, "."); err != nil {
+		return fmt.Errorf("library path %q is not accessible: %w", path, err)
+	}
+	return nil
+}
+
+func decodeLibraryPayload(r *http.Request) (libraryPayload, error) {
+	var payload libraryPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return payload, err
+	}
+	if payload.Name == "" || payload.Path == "" {
+		return payload, errors.New("both name and path are required")
+	}
+	if err := validateLibraryPath(payload.Path); err != nil {
+		return payload, err
+	}
+	return payload, nil
+}
+
+// createLibrary adds a new library and triggers a scan to import its contents. The scan
+// runs in the background, as importing a large library can take a long time
+func createLibrary(ds model.DataStore, rescan func(ctx context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		payload, err := decodeLibraryPayload(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lib := model.Library{Name: payload.Name, Path: payload.Path, Extractor: payload.Extractor}
+		if err := ds.Library(ctx).Put(&lib); err != nil {
+			log.Error(ctx, "Error creating library", "name", payload.Name, "path", payload.Path, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		go func() {
+			// Not the request context: the scan outlives the request
+			if err := rescan(context.Background()); err != nil {
+				log.Error("Error scanning new library", "name", payload.Name, "path", payload.Path, err)
+			}
+		}()
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+func updateLibrary(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lib, err := ds.Library(ctx).Get(id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, "library not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		payload, err := decodeLibraryPayload(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lib.Name = payload.Name
+		lib.Path = payload.Path
+		lib.Extractor = payload.Extractor
+		if err := ds.Library(ctx).Put(lib); err != nil {
+			log.Error(ctx, "Error updating library", "id", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+// libraryAccessParams validates the library and user referenced by an access grant
+// request, so grants are never created for ids that don't exist
+func libraryAccessParams(ds model.DataStore, r *http.Request) (int, string, error) {
+	ctx := r.Context()
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, "", err
+	}
+	if _, err := ds.Library(ctx).Get(id); err != nil {
+		return 0, "", err
+	}
+	userID := chi.URLParam(r, "userId")
+	if _, err := ds.User(ctx).Get(userID); err != nil {
+		return 0, "", err
+	}
+	return id, userID, nil
+}
+
+func accessErrorStatus(err error) int {
+	if errors.Is(err, model.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// grantLibraryAccess makes the library visible to the user. Admins always see every
+// library, so grants are only meaningful for regular users
+func grantLibraryAccess(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, userID, err := libraryAccessParams(ds, r)
+		if err != nil {
+			http.Error(w, err.Error(), accessErrorStatus(err))
+			return
+		}
+		if err := ds.Library(ctx).GrantAccess(id, userID); err != nil {
+			log.Error(ctx, "Error granting library access", "id", id, "userId", userID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+func revokeLibraryAccess(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, userID, err := libraryAccessParams(ds, r)
+		if err != nil {
+			http.Error(w, err.Error(), accessErrorStatus(err))
+			return
+		}
+		if err := ds.Library(ctx).RevokeAccess(id, userID); err != nil {
+			log.Error(ctx, "Error revoking library access", "id", id, "userId", userID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+// relocateLibrary rewrites the library's records to a new path, for libraries moved on disk,
+// instead of re-importing them (which would lose annotations and play counts). It then
+// triggers an incremental scan: as the relocated rows match what the scanner derives from
+// the new paths, the scan only verifies what exists, without re-extracting unchanged tags.
+// Refused while a scan is running, as the scanner would be reading the rows mid-rewrite
+func relocateLibrary(ds model.DataStore, scanning func() bool, rescan func(ctx context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var payload struct {
+			Path string `json:"path"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if payload.Path == "" {
+			http.Error(w, "path is required", http.StatusBadRequest)
+			return
+		}
+		if err := validateLibraryPath(payload.Path); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if scanning() {
+			http.Error(w, "cannot relocate a library while a scan is in progress", http.StatusConflict)
+			return
+		}
+		count, err := ds.RelocateLibrary(ctx, id, payload.Path)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, "library not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error relocating library", "id", id, "path", payload.Path, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		go func() {
+			// Not the request context: the scan outlives the request
+			if err := rescan(context.Background()); err != nil {
+				log.Error("Error verifying relocated library", "id", id, "path", payload.Path, err)
+			}
+		}()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"response":"ok","relocated":%d}`, count)
+	}
+}
+
+// deleteLibrary removes a library and everything that belongs to it. Refused while a scan
+// is running, as the scanner would recreate rows of a library being deleted under it
+func deleteLibrary(ds model.DataStore, scanning func() bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if id == model.DefaultLibraryID {
+			http.Error(w, "the default music folder cannot be removed", http.StatusBadRequest)
+			return
+		}
+		if scanning() {
+			http.Error(w, "cannot remove a library while a scan is in progress", http.StatusConflict)
+			return
+		}
+		err = ds.DeleteLibrary(ctx, id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, "library not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error removing library", "id", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
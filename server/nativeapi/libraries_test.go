@@ -0,0 +1,252 @@
+package nativeapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing/fstest"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/core/storage"
+	"github.com/navidrome/navidrome/core/storage/storagetest"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// libTestDS records DeleteLibrary and RelocateLibrary calls, which MockDataStore just swallows
+type libTestDS struct {
+	*tests.MockDataStore
+	deletedID     int
+	deleteErr     error
+	relocatedID   int
+	relocatedPath string
+	relocateErr   error
+}
+
+func (ds *libTestDS) DeleteLibrary(_ context.Context, id int) error {
+	if ds.deleteErr != nil {
+		return ds.deleteErr
+	}
+	ds.deletedID = id
+	return nil
+}
+
+func (ds *libTestDS) RelocateLibrary(_ context.Context, id int, newPath string) (int64, error) {
+	if ds.relocateErr != nil {
+		return 0, ds.relocateErr
+	}
+	ds.relocatedID = id
+	ds.relocatedPath = newPath
+	return 1, nil
+}
+
+var _ = Describe("Library endpoints", func() {
+	var ds *libTestDS
+	var libRepo *tests.MockLibraryRepo
+	var router chi.Router
+	var scanning bool
+	var scanRequested chan struct{}
+
+	BeforeEach(func() {
+		fakeFS := storagetest.NewFakeFS()
+		fakeFS.SetFiles(fstest.MapFS{"music/placeholder.txt": &fstest.MapFile{Data: []byte("")}})
+		storage.Register("fake", func(_ *url.URL) (storage.MusicFS, error) { return fakeFS, nil })
+
+		ds = &libTestDS{MockDataStore: &tests.MockDataStore{}}
+		libRepo = &tests.MockLibraryRepo{}
+		libRepo.SetData(model.Libraries{{ID: 1, Name: "Music Library", Path: "/music"}})
+		ds.MockedLibrary = libRepo
+
+		scanning = false
+		scanRequested = make(chan struct{}, 1)
+		rescan := func(context.Context) error {
+			scanRequested <- struct{}{}
+			return nil
+		}
+
+		router = chi.NewRouter()
+		router.Get("/library", listLibraries(ds))
+		router.Post("/library", createLibrary(ds, rescan))
+		router.Put("/library/{id}", updateLibrary(ds))
+		router.Delete("/library/{id}", deleteLibrary(ds, func() bool { return scanning }))
+		router.Post("/library/{id}/relocate", relocateLibrary(ds, func() bool { return scanning }, rescan))
+	})
+
+	It("lists the configured libraries", func() {
+		req := httptest.NewRequest("GET", "/library", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(ContainSubstring("Music Library"))
+	})
+
+	Describe("create", func() {
+		It("creates a library on a registered scheme and triggers a scan", func() {
+			body := `{"name":"Fake Library","path":"fake:///music"}`
+			req := httptest.NewRequest("POST", "/library", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			libs, _ := libRepo.GetAll()
+			Expect(libs).To(HaveLen(2))
+			Eventually(scanRequested).Should(Receive())
+		})
+
+		It("rejects a path with an unregistered scheme", func() {
+			body := `{"name":"S3 Library","path":"s3://bucket/music"}`
+			req := httptest.NewRequest("POST", "/library", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("unsupported storage scheme"))
+		})
+
+		It("rejects a request without a name", func() {
+			req := httptest.NewRequest("POST", "/library", strings.NewReader(`{"path":"fake:///music"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("update", func() {
+		It("updates an existing library", func() {
+			body := `{"name":"Renamed","path":"fake:///music"}`
+			req := httptest.NewRequest("PUT", "/library/1", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			lib, err := libRepo.Get(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lib.Name).To(Equal("Renamed"))
+		})
+
+		It("returns 404 for an unknown library", func() {
+			body := `{"name":"Renamed","path":"fake:///music"}`
+			req := httptest.NewRequest("PUT", "/library/42", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("access", func() {
+		BeforeEach(func() {
+			userRepo := tests.CreateMockUserRepo()
+			Expect(userRepo.Put(&model.User{ID: "u1", UserName: "kid"})).To(Succeed())
+			ds.MockedUser = userRepo
+			router.Put("/library/{id}/access/{userId}", grantLibraryAccess(ds))
+			router.Delete("/library/{id}/access/{userId}", revokeLibraryAccess(ds))
+		})
+
+		It("grants and revokes access to a library", func() {
+			req := httptest.NewRequest("PUT", "/library/1/access/u1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(libRepo.Access[1]).To(ContainElement("u1"))
+
+			req = httptest.NewRequest("DELETE", "/library/1/access/u1", nil)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(libRepo.Access[1]).ToNot(ContainElement("u1"))
+		})
+
+		It("returns 404 for an unknown user", func() {
+			req := httptest.NewRequest("PUT", "/library/1/access/nope", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("returns 404 for an unknown library", func() {
+			req := httptest.NewRequest("PUT", "/library/42/access/u1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("relocate", func() {
+		It("relocates the library and triggers a verification scan", func() {
+			body := `{"path":"fake:///music"}`
+			req := httptest.NewRequest("POST", "/library/1/relocate", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring(`"relocated":1`))
+			Expect(ds.relocatedID).To(Equal(1))
+			Expect(ds.relocatedPath).To(Equal("fake:///music"))
+			Eventually(scanRequested).Should(Receive())
+		})
+
+		It("rejects a path with an unregistered scheme", func() {
+			body := `{"path":"bogus:///nowhere"}`
+			req := httptest.NewRequest("POST", "/library/1/relocate", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(ds.relocatedID).To(BeZero())
+		})
+
+		It("refuses to relocate while a scan is in progress", func() {
+			scanning = true
+			body := `{"path":"fake:///music"}`
+			req := httptest.NewRequest("POST", "/library/1/relocate", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			Expect(ds.relocatedID).To(BeZero())
+		})
+
+		It("returns 404 for an unknown library", func() {
+			ds.relocateErr = model.ErrNotFound
+			body := `{"path":"fake:///music"}`
+			req := httptest.NewRequest("POST", "/library/42/relocate", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("delete", func() {
+		It("removes the library", func() {
+			req := httptest.NewRequest("DELETE", "/library/2", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(ds.deletedID).To(Equal(2))
+		})
+
+		It("refuses to remove the default music folder", func() {
+			req := httptest.NewRequest("DELETE", "/library/1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("refuses to remove a library while a scan is in progress", func() {
+			scanning = true
+			req := httptest.NewRequest("DELETE", "/library/2", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			Expect(ds.deletedID).To(BeZero())
+		})
+
+		It("returns 404 for an unknown library", func() {
+			ds.deleteErr = model.ErrNotFound
+			req := httptest.NewRequest("DELETE", "/library/42", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,63 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/core/gain"
+	"github.com/navidrome/navidrome/log"
+)
+
+func (n *Router) addMaintenanceRoutes(r chi.Router) {
+	r.Route("/maintenance", func(r chi.Router) {
+		r.Use(adminOnly)
+		r.Route("/gainAnalysis", func(r chi.Router) {
+			r.Get("/", gainAnalysisStatus(n.gainAnalysis))
+			r.Post("/", startGainAnalysis(n.gainAnalysis))
+			r.Delete("/", stopGainAnalysis(n.gainAnalysis))
+		})
+	})
+}
+
+// gainAnalysisStatus reports whether the job is running and how many files were processed
+// and are still pending
+func gainAnalysisStatus(job gain.Analysis) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		status, err := job.Status(ctx)
+		if err != nil {
+			log.Error(ctx, "Error getting gain analysis status", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			log.Error(ctx, "Error sending gain analysis status", err)
+		}
+	}
+}
+
+func startGainAnalysis(job gain.Analysis) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if err := job.Start(ctx); err != nil {
+			if errors.Is(err, gain.ErrAlreadyRunning) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			log.Error(ctx, "Error starting gain analysis", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+func stopGainAnalysis(job gain.Analysis) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job.Stop()
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
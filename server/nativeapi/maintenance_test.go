@@ -0,0 +1,88 @@
+package nativeapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/core/gain"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeGainAnalysis struct {
+	started  bool
+	stopped  bool
+	startErr error
+	status   gain.Status
+}
+
+func (f *fakeGainAnalysis) Start(context.Context) error {
+	if f.startErr != nil {
+		return f.startErr
+	}
+	f.started = true
+	return nil
+}
+
+func (f *fakeGainAnalysis) Stop() { f.stopped = true }
+
+func (f *fakeGainAnalysis) Status(context.Context) (*gain.Status, error) {
+	return &f.status, nil
+}
+
+var _ = Describe("Maintenance endpoints", func() {
+	var job *fakeGainAnalysis
+	var router chi.Router
+
+	BeforeEach(func() {
+		job = &fakeGainAnalysis{}
+		router = chi.NewRouter()
+		router.Get("/maintenance/gainAnalysis", gainAnalysisStatus(job))
+		router.Post("/maintenance/gainAnalysis", startGainAnalysis(job))
+		router.Delete("/maintenance/gainAnalysis", stopGainAnalysis(job))
+	})
+
+	It("starts the gain analysis job", func() {
+		req := httptest.NewRequest("POST", "/maintenance/gainAnalysis", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(job.started).To(BeTrue())
+	})
+
+	It("returns 409 when the job is already running", func() {
+		job.startErr = gain.ErrAlreadyRunning
+		req := httptest.NewRequest("POST", "/maintenance/gainAnalysis", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusConflict))
+	})
+
+	It("stops the gain analysis job", func() {
+		req := httptest.NewRequest("DELETE", "/maintenance/gainAnalysis", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(job.stopped).To(BeTrue())
+	})
+
+	It("reports the job status", func() {
+		job.status = gain.Status{Running: true, Processed: 10, Remaining: 5}
+		req := httptest.NewRequest("GET", "/maintenance/gainAnalysis", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var status gain.Status
+		Expect(json.Unmarshal(w.Body.Bytes(), &status)).To(Succeed())
+		Expect(status.Running).To(BeTrue())
+		Expect(status.Processed).To(Equal(int64(10)))
+		Expect(status.Remaining).To(Equal(int64(5)))
+	})
+})
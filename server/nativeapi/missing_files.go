@@ -0,0 +1,76 @@
+package nativeapi
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"slices"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+type missingFolder struct {
+	Folder string           `json:"folder"`
+	Count  int              `json:"count"`
+	Files  model.MediaFiles `json:"files"`
+}
+
+// listMissingFiles reports all tracks flagged as missing by the scanner, grouped by the folder
+// they used to live in
+func listMissingFiles(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		mfs, err := ds.MediaFile(ctx).GetAll(model.QueryOptions{
+			Filters: squirrel.Eq{"missing": true},
+			Sort:    "path",
+		})
+		if err != nil {
+			log.Error(ctx, "Error reading missing files", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		byFolder := map[string]model.MediaFiles{}
+		for _, mf := range mfs {
+			dir := filepath.Dir(mf.Path)
+			byFolder[dir] = append(byFolder[dir], mf)
+		}
+		folders := make([]missingFolder, 0, len(byFolder))
+		for dir, files := range byFolder {
+			folders = append(folders, missingFolder{Folder: dir, Count: len(files), Files: files})
+		}
+		slices.SortFunc(folders, func(a, b missingFolder) int { return cmp.Compare(a.Folder, b.Folder) })
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(folders); err != nil {
+			log.Error(ctx, "Error sending missing files list", err)
+		}
+	}
+}
+
+// deleteMissingFiles permanently removes the selected missing tracks from the DB, together with
+// their annotations, playlist entries and tag links. Refused while a scan is running, as the
+// scanner may be about to clear the missing flag of a reappeared file.
+func deleteMissingFiles(ds model.DataStore, scanning func() bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var payload struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if scanning() {
+			http.Error(w, "cannot delete missing files while a scan is in progress", http.StatusConflict)
+			return
+		}
+		if err := ds.DeleteMissingFiles(ctx, payload.IDs); err != nil {
+			log.Error(ctx, "Error deleting missing files", "ids", payload.IDs, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
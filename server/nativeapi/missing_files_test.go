@@ -0,0 +1,65 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Missing files endpoints", func() {
+	var ds *tests.MockDataStore
+	var router chi.Router
+	var scanning bool
+
+	BeforeEach(func() {
+		ds = &tests.MockDataStore{}
+		ds.MockedMediaFile = tests.CreateMockMediaFileRepo()
+		ds.MockedMediaFile.(*tests.MockMediaFileRepo).SetData(model.MediaFiles{
+			{ID: "1", Path: "music/artist a/track1.mp3", Missing: true},
+			{ID: "2", Path: "music/artist a/track2.mp3", Missing: true},
+			{ID: "3", Path: "music/artist b/track3.mp3", Missing: true},
+		})
+		scanning = false
+		router = chi.NewRouter()
+		router.Get("/missingFiles", listMissingFiles(ds))
+		router.Post("/missingFiles/delete", deleteMissingFiles(ds, func() bool { return scanning }))
+	})
+
+	It("lists missing files grouped by folder", func() {
+		req := httptest.NewRequest("GET", "/missingFiles", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var folders []missingFolder
+		Expect(json.Unmarshal(w.Body.Bytes(), &folders)).To(Succeed())
+		Expect(folders).To(HaveLen(2))
+		Expect(folders[0].Folder).To(Equal("music/artist a"))
+		Expect(folders[0].Count).To(Equal(2))
+		Expect(folders[0].Files).To(HaveLen(2))
+		Expect(folders[1].Folder).To(Equal("music/artist b"))
+		Expect(folders[1].Count).To(Equal(1))
+	})
+
+	It("deletes the selected missing files", func() {
+		req := httptest.NewRequest("POST", "/missingFiles/delete", strings.NewReader(`{"ids":["1","2"]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("refuses to delete while a scan is in progress", func() {
+		scanning = true
+		req := httptest.NewRequest("POST", "/missingFiles/delete", strings.NewReader(`{"ids":["1"]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusConflict))
+	})
+})
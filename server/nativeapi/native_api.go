@@ -8,19 +8,25 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/core/gain"
 	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner"
 	"github.com/navidrome/navidrome/server"
 )
 
 type Router struct {
 	http.Handler
-	ds        model.DataStore
-	share     core.Share
-	playlists core.Playlists
+	ds           model.DataStore
+	share        core.Share
+	playlists    core.Playlists
+	edits        core.Edits
+	scanner      scanner.Scanner
+	gainAnalysis gain.Analysis
 }
 
-func New(ds model.DataStore, share core.Share, playlists core.Playlists) *Router {
-	r := &Router{ds: ds, share: share, playlists: playlists}
+func New(ds model.DataStore, share core.Share, playlists core.Playlists, edits core.Edits,
+	scanner scanner.Scanner, gainAnalysis gain.Analysis) *Router {
+	r := &Router{ds: ds, share: share, playlists: playlists, edits: edits, scanner: scanner, gainAnalysis: gainAnalysis}
 	r.Handler = r.routes()
 	return r
 }
@@ -36,8 +42,8 @@ func (n *Router) routes() http.Handler {
 		r.Use(server.Authenticator(n.ds))
 		r.Use(server.JWTRefresher)
 		n.R(r, "/user", model.User{}, true)
-		n.R(r, "/song", model.MediaFile{}, false)
-		n.R(r, "/album", model.Album{}, false)
+		n.addSongRoute(r)
+		n.addAlbumRoute(r)
 		n.R(r, "/artist", model.Artist{}, false)
 		n.R(r, "/genre", model.Genre{}, false)
 		n.R(r, "/player", model.Player{}, true)
@@ -47,8 +53,42 @@ func (n *Router) routes() http.Handler {
 			n.RX(r, "/share", n.share.NewRepository, true)
 		}
 
+		r.Get("/inProgress", inProgress(n.ds))
+		r.Route("/stats", func(r chi.Router) {
+			r.Get("/topArtists", topArtists(n.ds))
+			r.Get("/topSongs", topSongs(n.ds))
+			r.Get("/listeningTime", listeningTime(n.ds))
+			r.Get("/library", libraryStats(n.ds))
+		})
+
 		n.addPlaylistRoute(r)
 		n.addPlaylistTrackRoute(r)
+		n.addTagRoutes(r)
+
+		// Missing files listing and cleanup, for admins only
+		r.Route("/missingFiles", func(r chi.Router) {
+			r.Use(adminOnly)
+			r.Get("/", listMissingFiles(n.ds))
+			r.Post("/delete", deleteMissingFiles(n.ds, scanner.IsScanning))
+		})
+
+		// On-demand tag refresh for individual songs or albums, for admins only
+		r.With(adminOnly).Post("/refreshTags", refreshTags(n.scanner.RefreshItems))
+
+		// Duplicate rips report, for admins only
+		r.With(adminOnly).Get("/duplicates", listDuplicates(n.ds))
+
+		// Scan history, for admins only
+		r.With(adminOnly).Get("/scanLog", listScanLog(n.ds))
+
+		// Full library export, for admins only
+		r.With(adminOnly).Get("/export/songs", exportSongsCSV(n.ds))
+
+		// Library management, for admins only
+		n.addLibraryRoute(r)
+
+		// Maintenance jobs, for admins only
+		n.addMaintenanceRoutes(r)
 
 		// Keepalive endpoint to be used to keep the session valid (ex: while playing songs)
 		r.Get("/keepalive/*", func(w http.ResponseWriter, r *http.Request) {
@@ -83,6 +123,66 @@ func (n *Router) RX(r chi.Router, pathPrefix string, constructor rest.Repository
 	})
 }
 
+func (n *Router) addSongRoute(r chi.Router) {
+	constructor := func(ctx context.Context) rest.Repository {
+		return n.ds.Resource(ctx, model.MediaFile{})
+	}
+	r.Route("/song", func(r chi.Router) {
+		r.With(withRandomSeed).Get("/", rest.GetAll(constructor))
+		r.Post("/bulkEdit", bulkEdit(n.edits))
+		r.Route("/{id}", func(r chi.Router) {
+			r.Use(server.URLParamsMiddleware)
+			r.Get("/", rest.Get(constructor))
+			r.Get("/otherVersions", otherVersions(n.ds))
+			r.Put("/lock/{field}", lockSongField(n.ds))
+			r.Delete("/lock/{field}", unlockSongField(n.ds))
+		})
+	})
+}
+
+func (n *Router) addAlbumRoute(r chi.Router) {
+	constructor := func(ctx context.Context) rest.Repository {
+		return n.ds.Resource(ctx, model.Album{})
+	}
+	r.Route("/album", func(r chi.Router) {
+		r.With(withRandomSeed).Get("/", rest.GetAll(constructor))
+		r.Route("/{id}", func(r chi.Router) {
+			r.Use(server.URLParamsMiddleware)
+			r.Get("/", rest.Get(constructor))
+			r.Put("/lock/{field}", lockAlbumField(n.ds))
+			r.Delete("/lock/{field}", unlockAlbumField(n.ds))
+			r.Put("/coverArt", setAlbumCoverArt(n.ds))
+			r.Delete("/coverArt", removeAlbumCoverArt(n.ds))
+		})
+	})
+}
+
+func (n *Router) addTagRoutes(r chi.Router) {
+	constructor := func(ctx context.Context) rest.Repository {
+		return n.ds.Resource(ctx, model.Tag{})
+	}
+	r.Route("/tag", func(r chi.Router) {
+		// Tag browsing (ex: /tag?tag_name=mood)
+		r.Get("/", rest.GetAll(constructor))
+
+		// Tag name reporting and allowlist management, for admins only
+		r.Group(func(r chi.Router) {
+			r.Use(adminOnly)
+			r.Get("/names", listTags(n.ds))
+			r.Post("/merge", mergeTagValues(n.ds))
+			r.Route("/allowlist/{name}", func(r chi.Router) {
+				r.Put("/", addTagToAllowlist(n.ds))
+				r.Delete("/", removeTagFromAllowlist(n.ds))
+			})
+		})
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Use(server.URLParamsMiddleware)
+			r.Get("/", rest.Get(constructor))
+		})
+	})
+}
+
 func (n *Router) addPlaylistRoute(r chi.Router) {
 	constructor := func(ctx context.Context) rest.Repository {
 		return n.ds.Resource(ctx, model.Playlist{})
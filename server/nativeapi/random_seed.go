@@ -0,0 +1,33 @@
+package nativeapi
+
+import (
+	"net/http"
+
+	"github.com/navidrome/navidrome/utils/hasher"
+)
+
+// seedHeader carries the seed used for random sorting back to the client, so it can be echoed
+// in subsequent page requests
+const seedHeader = "X-Nd-Random-Seed"
+
+// withRandomSeed pins the shuffle order of `_sort=random` requests across pagination: when the
+// client does not pass a `seed` parameter, one is generated and injected into the request, so
+// every page request carrying the same seed sees the same order. The seed in effect is always
+// exposed in the response headers, for the client to echo back
+func withRandomSeed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("_sort") != "random" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		q := r.URL.Query()
+		seed := q.Get("seed")
+		if seed == "" {
+			seed = hasher.NewSeed()
+			q.Set("seed", seed)
+			r.URL.RawQuery = q.Encode()
+		}
+		w.Header().Set(seedHeader, seed)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,45 @@
+package nativeapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("withRandomSeed", func() {
+	var handler http.Handler
+	var seenSeed string
+
+	BeforeEach(func() {
+		seenSeed = ""
+		handler = withRandomSeed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seenSeed = r.URL.Query().Get("seed")
+		}))
+	})
+
+	It("generates a seed and exposes it in the response headers", func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/song?_sort=random", nil))
+
+		Expect(seenSeed).ToNot(BeEmpty())
+		Expect(w.Header().Get(seedHeader)).To(Equal(seenSeed))
+	})
+
+	It("keeps the seed echoed by the client", func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/song?_sort=random&seed=abc123", nil))
+
+		Expect(seenSeed).To(Equal("abc123"))
+		Expect(w.Header().Get(seedHeader)).To(Equal("abc123"))
+	})
+
+	It("does nothing for other sort orders", func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/song?_sort=title", nil))
+
+		Expect(seenSeed).To(BeEmpty())
+		Expect(w.Header().Get(seedHeader)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,43 @@
+package nativeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/scanner"
+)
+
+type refreshTagsFunc = func(ctx context.Context, ids ...string) (*scanner.RefreshItemsResult, error)
+
+// refreshTags re-reads the tags of the given media files or albums from disk, without waiting
+// for a scan, and returns the updated tracks so the UI can refresh in place. Items that could
+// not be refreshed are reported individually in the response
+func refreshTags(refresh refreshTagsFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var payload struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || len(payload.IDs) == 0 {
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+		result, err := refresh(ctx, payload.IDs...)
+		if errors.Is(err, scanner.ErrAlreadyScanning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error refreshing tags", "ids", payload.IDs, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Error(ctx, "Error sending tag refresh results", err)
+		}
+	}
+}
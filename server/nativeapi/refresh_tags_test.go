@@ -0,0 +1,66 @@
+package nativeapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/scanner"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("refreshTags endpoint", func() {
+	var router chi.Router
+	var received []string
+	var result *scanner.RefreshItemsResult
+	var refreshErr error
+
+	BeforeEach(func() {
+		received = nil
+		result = &scanner.RefreshItemsResult{
+			Updated: model.MediaFiles{{ID: "mf-1", Title: "Fixed Title"}},
+			Errors:  map[string]string{"bad-id": "no media file or album found with this ID"},
+		}
+		refreshErr = nil
+		refresh := func(_ context.Context, ids ...string) (*scanner.RefreshItemsResult, error) {
+			received = ids
+			return result, refreshErr
+		}
+		router = chi.NewRouter()
+		router.Post("/refreshTags", refreshTags(refresh))
+	})
+
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/refreshTags", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("passes the IDs along and returns the updated entities and per-item errors", func() {
+		w := call(`{"ids":["mf-1","bad-id"]}`)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(received).To(Equal([]string{"mf-1", "bad-id"}))
+
+		var resp scanner.RefreshItemsResult
+		Expect(json.Unmarshal(w.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Updated).To(HaveLen(1))
+		Expect(resp.Updated[0].Title).To(Equal("Fixed Title"))
+		Expect(resp.Errors).To(HaveKeyWithValue("bad-id", "no media file or album found with this ID"))
+	})
+
+	It("rejects requests without IDs", func() {
+		Expect(call(`{"ids":[]}`).Code).To(Equal(http.StatusBadRequest))
+		Expect(call(`not json`).Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns 409 when a scan is already running", func() {
+		refreshErr = scanner.ErrAlreadyScanning
+		Expect(call(`{"ids":["mf-1"]}`).Code).To(Equal(http.StatusConflict))
+	})
+})
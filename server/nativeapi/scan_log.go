@@ -0,0 +1,43 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// listScanLog returns the recorded scan history, most recent first, honoring the standard
+// _start/_end pagination parameters. The total is reported in the X-Total-Count header, so
+// the UI can paginate the same way it does for the rest resources
+func listScanLog(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		start, _ := strconv.Atoi(r.URL.Query().Get("_start"))
+		end, _ := strconv.Atoi(r.URL.Query().Get("_end"))
+		options := model.QueryOptions{Sort: "start_time", Order: "desc", Offset: start}
+		if end > start {
+			options.Max = end - start
+		}
+		repo := ds.ScanLog(ctx)
+		entries, err := repo.GetAll(options)
+		if err != nil {
+			log.Error(ctx, "Error reading scan history", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		total, err := repo.CountAll()
+		if err != nil {
+			log.Error(ctx, "Error counting scan history", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(entries); err != nil {
+			log.Error(ctx, "Error sending scan history", err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+type bulkEditPayload struct {
+	IDs    []string               `json:"ids"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// bulkEdit applies the same field changes to all selected tracks at once. The whole batch
+// either succeeds or is rolled back.
+func bulkEdit(edits core.Edits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var payload bulkEditPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(payload.IDs) == 0 || len(payload.Fields) == 0 {
+			http.Error(w, "ids and fields are required", http.StatusBadRequest)
+			return
+		}
+		err := edits.BulkEdit(ctx, payload.IDs, payload.Fields)
+		switch {
+		case errors.Is(err, core.ErrNotEditable):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, model.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			log.Error(ctx, "Error applying bulk edit", "ids", payload.IDs, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+// otherVersions returns the other recordings of the same MusicBrainz work present in the
+// library (live takes, remixes, covers...), excluding the requested track. Tracks without a
+// work ID simply produce an empty list.
+func otherVersions(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := chi.URLParam(r, "id")
+		mf, err := ds.MediaFile(ctx).Get(id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, "Track not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error retrieving track", "id", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		versions := model.MediaFiles{}
+		if mf.MbzWorkID != "" {
+			all, err := ds.MediaFile(ctx).GetByWorkID(mf.MbzWorkID)
+			if err != nil {
+				log.Error(ctx, "Error retrieving other versions", "id", id, "workId", mf.MbzWorkID, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			for _, v := range all {
+				if v.ID != mf.ID {
+					versions = append(versions, v)
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(versions); err != nil {
+			log.Error(ctx, "Error sending other versions", err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Song endpoints", func() {
+	var ds *tests.MockDataStore
+	var router chi.Router
+
+	BeforeEach(func() {
+		ds = &tests.MockDataStore{}
+		router = chi.NewRouter()
+		router.Get("/song/{id}/otherVersions", otherVersions(ds))
+	})
+
+	get := func(id string) (int, model.MediaFiles) {
+		req := httptest.NewRequest("GET", "/song/"+id+"/otherVersions", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var versions model.MediaFiles
+		if w.Code == http.StatusOK {
+			Expect(json.Unmarshal(w.Body.Bytes(), &versions)).To(Succeed())
+		}
+		return w.Code, versions
+	}
+
+	Describe("otherVersions", func() {
+		BeforeEach(func() {
+			ds.MockedMediaFile = tests.CreateMockMediaFileRepo()
+			ds.MockedMediaFile.(*tests.MockMediaFileRepo).SetData(model.MediaFiles{
+				{ID: "1", Title: "Song (Studio)", AlbumID: "al-1", Year: 1970, MbzWorkID: "work-1"},
+				{ID: "2", Title: "Song (Live)", AlbumID: "al-2", Year: 1972, MbzWorkID: "work-1"},
+				{ID: "3", Title: "Song (Remix)", AlbumID: "al-3", Year: 1999, MbzWorkID: "work-1"},
+				{ID: "4", Title: "Unrelated", AlbumID: "al-4", Year: 1980},
+			})
+		})
+
+		It("returns the other recordings of the same work, ordered by album year", func() {
+			code, versions := get("2")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(versions).To(HaveLen(2))
+			Expect(versions[0].ID).To(Equal("1"))
+			Expect(versions[1].ID).To(Equal("3"))
+		})
+
+		It("returns an empty list for tracks without a work ID", func() {
+			code, versions := get("4")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(versions).To(BeEmpty())
+		})
+
+		It("returns 404 for unknown tracks", func() {
+			code, _ := get("666")
+			Expect(code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
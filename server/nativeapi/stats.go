@@ -0,0 +1,114 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/utils/cache"
+)
+
+const defaultStatsLimit = 10
+
+// statsWindow parses the optional `from`, `to` and `limit` query params. Dates accept RFC3339
+// timestamps or plain dates (2006-01-02); the window defaults to the last 30 days.
+func statsWindow(r *http.Request) (from, to time.Time, limit int, err error) {
+	parseDate := func(s string, fallback time.Time) (time.Time, error) {
+		if s == "" {
+			return fallback, nil
+		}
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.DateOnly, s)
+	}
+	if to, err = parseDate(r.URL.Query().Get("to"), time.Now()); err != nil {
+		return from, to, limit, err
+	}
+	if from, err = parseDate(r.URL.Query().Get("from"), to.AddDate(0, 0, -30)); err != nil {
+		return from, to, limit, err
+	}
+	limit = defaultStatsLimit
+	if p := r.URL.Query().Get("limit"); p != "" {
+		if limit, err = strconv.Atoi(p); err != nil || limit < 1 {
+			return from, to, limit, err
+		}
+	}
+	return from, to, limit, nil
+}
+
+func statsHandler[T any](query func(ds model.DataStore, r *http.Request, from, to time.Time, limit int) (T, error)) func(model.DataStore) http.HandlerFunc {
+	return func(ds model.DataStore) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			from, to, limit, err := statsWindow(r)
+			if err != nil {
+				http.Error(w, "invalid from/to/limit", http.StatusBadRequest)
+				return
+			}
+			res, err := query(ds, r, from, to, limit)
+			if err != nil {
+				log.Error(ctx, "Error computing play statistics", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(res); err != nil {
+				log.Error(ctx, "Error sending play statistics", err)
+			}
+		}
+	}
+}
+
+var topArtists = statsHandler(func(ds model.DataStore, r *http.Request, from, to time.Time, limit int) (model.Artists, error) {
+	return ds.PlayEvent(r.Context()).GetTopArtists(from, to, limit)
+})
+
+var topSongs = statsHandler(func(ds model.DataStore, r *http.Request, from, to time.Time, limit int) (model.MediaFiles, error) {
+	return ds.PlayEvent(r.Context()).GetTopSongs(from, to, limit)
+})
+
+const libraryStatsTTL = 5 * time.Minute
+
+// libraryStatsCache keeps the library health aggregates for a few minutes: they are full-table
+// aggregates, and dashboards tend to poll them
+var libraryStatsCache = cache.NewSimpleCache[string, *model.LibraryStats]()
+
+func libraryStats(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		param := r.URL.Query().Get("libraryId")
+		if param == "" {
+			param = "1"
+		}
+		libID, err := strconv.Atoi(param)
+		if err != nil {
+			http.Error(w, "invalid libraryId", http.StatusBadRequest)
+			return
+		}
+		res, err := libraryStatsCache.GetWithLoader(param, func(string) (*model.LibraryStats, time.Duration, error) {
+			stats, err := ds.MediaFile(ctx).Stats(libID)
+			return stats, libraryStatsTTL, err
+		})
+		if err != nil {
+			log.Error(ctx, "Error computing library statistics", "libraryId", libID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			log.Error(ctx, "Error sending library statistics", err)
+		}
+	}
+}
+
+var listeningTime = statsHandler(func(ds model.DataStore, r *http.Request, from, to time.Time, _ int) (map[string]float64, error) {
+	total, err := ds.PlayEvent(r.Context()).GetListeningTime(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]float64{"listeningTime": total}, nil
+})
@@ -0,0 +1,182 @@
+package nativeapi
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+)
+
+type tagInfo struct {
+	Name    string `json:"name"`
+	Count   int64  `json:"count"`
+	Allowed bool   `json:"allowed"`
+}
+
+// getTagAllowlist returns the current tag allowlist: the one stored in the DB if present,
+// otherwise the Scanner.AdditionalRoles configuration
+func getTagAllowlist(ctx context.Context, ds model.DataStore) ([]string, error) {
+	value, err := ds.Property(ctx).DefaultGet(consts.TagAllowlistKey, "")
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return conf.Server.Scanner.AdditionalRoles, nil
+	}
+	var allowlist []string
+	if err := json.Unmarshal([]byte(value), &allowlist); err != nil {
+		return nil, err
+	}
+	return allowlist, nil
+}
+
+func saveTagAllowlist(ctx context.Context, ds model.DataStore, allowlist []string) error {
+	value, err := json.Marshal(allowlist)
+	if err != nil {
+		return err
+	}
+	return ds.Property(ctx).Put(consts.TagAllowlistKey, string(value))
+}
+
+func isTagAllowed(name string, allowlist []string) bool {
+	if model.RoleFromString(name) != model.RoleInvalid {
+		return true
+	}
+	return slices.ContainsFunc(allowlist, func(a string) bool {
+		return strings.EqualFold(a, name)
+	})
+}
+
+// listTags reports all tag names seen during scans (as recorded in the tag usage report),
+// including ones currently filtered out, together with their occurrence counts
+func listTags(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		allowlist, err := getTagAllowlist(ctx, ds)
+		if err != nil {
+			log.Error(ctx, "Error reading tag allowlist", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		usage := map[string]int64{}
+		if value, err := ds.Property(ctx).DefaultGet(consts.TagUsageKey, ""); err == nil && value != "" {
+			if err := json.Unmarshal([]byte(value), &usage); err != nil {
+				log.Error(ctx, "Error parsing tag usage report", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		tags := make([]tagInfo, 0, len(usage))
+		for name, count := range usage {
+			tags = append(tags, tagInfo{Name: name, Count: count, Allowed: isTagAllowed(name, allowlist)})
+		}
+		slices.SortFunc(tags, func(a, b tagInfo) int { return cmp.Compare(a.Name, b.Name) })
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(tags); err != nil {
+			log.Error(ctx, "Error sending tag list", err)
+		}
+	}
+}
+
+// addTagToAllowlist adds the tag name to the allowlist stored in the DB, seeding it from the
+// Scanner.AdditionalRoles configuration the first time. Takes effect on the next scan.
+func addTagToAllowlist(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		name := strings.ToLower(chi.URLParam(r, "name"))
+		allowlist, err := getTagAllowlist(ctx, ds)
+		if err != nil {
+			log.Error(ctx, "Error reading tag allowlist", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !slices.Contains(allowlist, name) {
+			allowlist = append(allowlist, name)
+		}
+		if err := saveTagAllowlist(ctx, ds, allowlist); err != nil {
+			log.Error(ctx, "Error saving tag allowlist", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+// removeTagFromAllowlist removes the tag name from the allowlist stored in the DB. Takes effect
+// on the next scan.
+func removeTagFromAllowlist(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		name := strings.ToLower(chi.URLParam(r, "name"))
+		allowlist, err := getTagAllowlist(ctx, ds)
+		if err != nil {
+			log.Error(ctx, "Error reading tag allowlist", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		allowlist = slices.DeleteFunc(allowlist, func(a string) bool {
+			return strings.EqualFold(a, name)
+		})
+		if err := saveTagAllowlist(ctx, ds, allowlist); err != nil {
+			log.Error(ctx, "Error saving tag allowlist", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok"}`))
+	}
+}
+
+type mergeTagsPayload struct {
+	TagName string   `json:"tagName"`
+	From    []string `json:"from"`
+	To      string   `json:"to"`
+	DryRun  bool     `json:"dryRun"`
+}
+
+// mergeTagValues folds variant tag (or genre) values into a canonical one, recording the
+// mapping so future scans keep applying it (see model.TagMappings). With dryRun, nothing is
+// changed and only the number of affected rows is reported
+func mergeTagValues(ds model.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var payload mergeTagsPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if payload.TagName == "" || payload.To == "" || len(payload.From) == 0 {
+			http.Error(w, "tagName, from and to are required", http.StatusBadRequest)
+			return
+		}
+		affected, err := ds.MergeTagValues(ctx, payload.TagName, payload.From, payload.To, payload.DryRun)
+		if err != nil {
+			log.Error(ctx, "Error merging tag values", "tag", payload.TagName, "to", payload.To, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"response":"ok","affected":%d,"dryRun":%v}`, affected, payload.DryRun)
+	}
+}
+
+// adminOnly rejects requests from non-admin users
+func adminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := request.UserFrom(r.Context())
+		if !ok || !u.IsAdmin {
+			http.Error(w, "Only admins can manage tags", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
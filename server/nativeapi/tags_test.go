@@ -0,0 +1,118 @@
+package nativeapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/conf/configtest"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tags endpoints", func() {
+	var ds *tests.MockDataStore
+	var router chi.Router
+
+	adminCtx := func(ctx context.Context) context.Context {
+		return request.WithUser(ctx, model.User{ID: "admin", IsAdmin: true})
+	}
+
+	BeforeEach(func() {
+		DeferCleanup(configtest.SetupConfig())
+		ds = &tests.MockDataStore{}
+		router = chi.NewRouter()
+		router.Route("/tag", func(r chi.Router) {
+			r.Use(adminOnly)
+			r.Get("/names", listTags(ds))
+			r.Post("/merge", mergeTagValues(ds))
+			r.Route("/allowlist/{name}", func(r chi.Router) {
+				r.Put("/", addTagToAllowlist(ds))
+				r.Delete("/", removeTagFromAllowlist(ds))
+			})
+		})
+	})
+
+	It("rejects non-admin users", func() {
+		req := httptest.NewRequest("GET", "/tag/names", nil)
+		req = req.WithContext(request.WithUser(req.Context(), model.User{ID: "regular"}))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("lists tags from the usage report, marking the allowed ones", func() {
+		props := ds.Property(context.TODO())
+		Expect(props.Put(consts.TagUsageKey, `{"composer":10,"orchestra":3}`)).To(Succeed())
+
+		req := httptest.NewRequest("GET", "/tag/names", nil)
+		req = req.WithContext(adminCtx(req.Context()))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var tags []tagInfo
+		Expect(json.Unmarshal(w.Body.Bytes(), &tags)).To(Succeed())
+		Expect(tags).To(Equal([]tagInfo{
+			{Name: "composer", Count: 10, Allowed: true},
+			{Name: "orchestra", Count: 3, Allowed: false},
+		}))
+	})
+
+	It("flips a tag from denied to allowed and back", func() {
+		req := httptest.NewRequest("PUT", "/tag/allowlist/orchestra", nil)
+		req = req.WithContext(adminCtx(req.Context()))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		allowlist, err := getTagAllowlist(context.TODO(), ds)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowlist).To(ContainElement("orchestra"))
+
+		req = httptest.NewRequest("DELETE", "/tag/allowlist/orchestra", nil)
+		req = req.WithContext(adminCtx(req.Context()))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		allowlist, err = getTagAllowlist(context.TODO(), ds)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowlist).ToNot(ContainElement("orchestra"))
+	})
+
+	Describe("merge", func() {
+		post := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/tag/merge", strings.NewReader(body))
+			req = req.WithContext(adminCtx(req.Context()))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		It("merges the given values and reports the affected rows", func() {
+			w := post(`{"tagName":"genre","from":["Hip Hop","hiphop"],"to":"Hip-Hop"}`)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(MatchJSON(`{"response":"ok","affected":0,"dryRun":false}`))
+		})
+
+		It("echoes the dry-run flag back", func() {
+			w := post(`{"tagName":"genre","from":["hiphop"],"to":"Hip-Hop","dryRun":true}`)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(MatchJSON(`{"response":"ok","affected":0,"dryRun":true}`))
+		})
+
+		It("rejects incomplete payloads", func() {
+			Expect(post(`{"tagName":"genre","to":"Hip-Hop"}`).Code).To(Equal(http.StatusBadRequest))
+			Expect(post(`{"from":["hiphop"],"to":"Hip-Hop"}`).Code).To(Equal(http.StatusBadRequest))
+			Expect(post(`not json`).Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
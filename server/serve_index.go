@@ -153,7 +153,7 @@ func addShareData(r *http.Request, data map[string]interface{}, shareInfo *model
 			Title:     mf.Title,
 			Artist:    mf.Artist,
 			Album:     mf.Album,
-			Duration:  mf.Duration,
+			Duration:  mf.Duration(),
 			UpdatedAt: mf.UpdatedAt,
 		}
 	})
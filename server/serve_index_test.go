@@ -454,20 +454,20 @@ var _ = Describe("addShareData", func() {
 				Downloadable: true,
 				Tracks: []model.MediaFile{
 					{
-						ID:        "track1",
-						Title:     "Track 1",
-						Artist:    "Artist 1",
-						Album:     "Album 1",
-						Duration:  100,
-						UpdatedAt: time.Date(2023, time.Month(3), 27, 0, 0, 0, 0, time.UTC),
+						ID:         "track1",
+						Title:      "Track 1",
+						Artist:     "Artist 1",
+						Album:      "Album 1",
+						DurationMs: 100000,
+						UpdatedAt:  time.Date(2023, time.Month(3), 27, 0, 0, 0, 0, time.UTC),
 					},
 					{
-						ID:        "track2",
-						Title:     "Track 2",
-						Artist:    "Artist 2",
-						Album:     "Album 2",
-						Duration:  200,
-						UpdatedAt: time.Date(2023, time.Month(3), 26, 0, 0, 0, 0, time.UTC),
+						ID:         "track2",
+						Title:      "Track 2",
+						Artist:     "Artist 2",
+						Album:      "Album 2",
+						DurationMs: 200000,
+						UpdatedAt:  time.Date(2023, time.Month(3), 26, 0, 0, 0, 0, time.UTC),
 					},
 				},
 				Contents: "Test contents",
@@ -496,7 +496,7 @@ var _ = Describe("addShareData", func() {
 				Expect(track.Title).To(Equal(shareInfo.Tracks[i].Title))
 				Expect(track.Artist).To(Equal(shareInfo.Tracks[i].Artist))
 				Expect(track.Album).To(Equal(shareInfo.Tracks[i].Album))
-				Expect(track.Duration).To(Equal(shareInfo.Tracks[i].Duration))
+				Expect(track.Duration).To(Equal(shareInfo.Tracks[i].Duration()))
 				Expect(track.UpdatedAt).To(Equal(shareInfo.Tracks[i].UpdatedAt))
 			}
 		})
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/server/subsonic/filter"
@@ -54,11 +55,38 @@ func (api *Router) getAlbumList(r *http.Request) (model.Albums, int64, error) {
 			return nil, 0, err
 		}
 		opts = filter.AlbumsByYear(fromYear, toYear)
+	case "byComposer":
+		composer, err := p.String("composer")
+		if err != nil {
+			return nil, 0, err
+		}
+		opts = filter.AlbumsByComposer(composer)
+	case "byMood":
+		mood, err := p.String("mood")
+		if err != nil {
+			return nil, 0, err
+		}
+		opts = filter.AlbumsByMood(mood)
+	case "byEra":
+		era, err := p.Int("era")
+		if err != nil {
+			return nil, 0, err
+		}
+		opts = filter.AlbumsByEra(era)
 	default:
 		log.Error(r, "albumList type not implemented", "type", typ)
 		return nil, 0, newError(responses.ErrorGeneric, "type '%s' not implemented", typ)
 	}
 
+	if libId, err := p.Int("musicFolderId"); err == nil {
+		scope := squirrel.Eq{"album.library_id": libId}
+		if opts.Filters == nil {
+			opts.Filters = scope
+		} else {
+			opts.Filters = squirrel.And{opts.Filters, scope}
+		}
+	}
+
 	opts.Offset = p.IntOr("offset", 0)
 	opts.Max = min(p.IntOr("size", 10), 500)
 	albums, err := api.ds.Album(r.Context()).GetAllWithoutGenres(opts)
@@ -65,6 +65,106 @@ var _ = Describe("Album Lists", func() {
 		})
 	})
 
+	Describe("GetAlbumList new types", func() {
+		BeforeEach(func() {
+			mockRepo.SetData(model.Albums{{ID: "1"}})
+		})
+
+		It("filters byComposer using the composer participations", func() {
+			r := newGetRequest("type=byComposer", "composer=Mozart")
+
+			_, err := router.GetAlbumList2(w, r)
+
+			Expect(err).ToNot(HaveOccurred())
+			sql, args, _ := mockRepo.Options.Filters.ToSql()
+			Expect(sql).To(ContainSubstring("media_file_artists"))
+			Expect(args).To(ContainElement(model.ParticipantID("Mozart")))
+		})
+
+		It("fails byComposer without a composer parameter", func() {
+			r := newGetRequest("type=byComposer")
+
+			_, err := router.GetAlbumList2(w, r)
+
+			Expect(err).To(MatchError(req.ErrMissingParam))
+		})
+
+		It("filters byGenre matching the genre name case-insensitively", func() {
+			r := newGetRequest("type=byGenre", "genre=ROCK")
+
+			_, err := router.GetAlbumList2(w, r)
+
+			Expect(err).ToNot(HaveOccurred())
+			sql, args, _ := mockRepo.Options.Filters.ToSql()
+			Expect(sql).To(ContainSubstring("genre.name = ? collate nocase"))
+			Expect(args).To(ContainElement("ROCK"))
+		})
+
+		It("filters byMood using the album-level mood tag", func() {
+			r := newGetRequest("type=byMood", "mood=Upbeat")
+
+			_, err := router.GetAlbumList2(w, r)
+
+			Expect(err).ToNot(HaveOccurred())
+			sql, args, _ := mockRepo.Options.Filters.ToSql()
+			Expect(sql).To(ContainSubstring("item_tags"))
+			Expect(args).To(ContainElement(model.NewTag("mood", "Upbeat").ID))
+		})
+
+		It("filters byEra using the decade of the original year", func() {
+			r := newGetRequest("type=byEra", "era=1994")
+
+			_, err := router.GetAlbumList2(w, r)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, args, _ := mockRepo.Options.Filters.ToSql()
+			Expect(args).To(ContainElement(1990))
+			Expect(args).To(ContainElement(1999))
+		})
+
+		It("scopes the list to the given musicFolderId", func() {
+			r := newGetRequest("type=newest", "musicFolderId=2")
+
+			_, err := router.GetAlbumList2(w, r)
+
+			Expect(err).ToNot(HaveOccurred())
+			sql, args, _ := mockRepo.Options.Filters.ToSql()
+			Expect(sql).To(ContainSubstring("album.library_id"))
+			Expect(args).To(ContainElement(2))
+		})
+
+		It("keeps failing on unknown types", func() {
+			r := newGetRequest("type=byShoeSize")
+
+			_, err := router.GetAlbumList2(w, r)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("GetSongsByGenre", func() {
+		var mockSongRepo *tests.MockMediaFileRepo
+
+		BeforeEach(func() {
+			mockSongRepo = ds.MediaFile(ctx).(*tests.MockMediaFileRepo)
+			mockSongRepo.SetData(model.MediaFiles{{ID: "1"}, {ID: "2"}})
+		})
+
+		It("matches the genre name case-insensitively and paginates", func() {
+			r := newGetRequest("genre=electronic", "offset=5", "count=10")
+
+			resp, err := router.GetSongsByGenre(r)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.SongsByGenre.Songs).To(HaveLen(2))
+			sql, args, _ := mockSongRepo.Options.Filters.ToSql()
+			Expect(sql).To(ContainSubstring("genre.name = ? collate nocase"))
+			Expect(args).To(ContainElement("electronic"))
+			Expect(mockSongRepo.Options.Offset).To(Equal(5))
+			Expect(mockSongRepo.Options.Max).To(Equal(10))
+		})
+	})
+
 	Describe("GetAlbumList2", func() {
 		It("should return list of the type specified", func() {
 			r := newGetRequest("type=newest", "offset=10", "size=20")
@@ -267,6 +267,8 @@ func mapToSubsonicError(err error) subError {
 		err = newError(responses.ErrorMissingParameter, err.Error())
 	case errors.Is(err, req.ErrInvalidParam):
 		err = newError(responses.ErrorGeneric, err.Error())
+	case errors.Is(err, model.ErrSourceMissing):
+		err = newError(responses.ErrorDataNotFound, "file is missing from the filesystem. A rescan may be needed")
 	case errors.Is(err, model.ErrNotFound):
 		err = newError(responses.ErrorDataNotFound, "data not found")
 	default:
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"path"
 	"time"
 
 	"github.com/navidrome/navidrome/conf"
@@ -27,7 +28,7 @@ func (api *Router) GetMusicFolders(r *http.Request) (*responses.Subsonic, error)
 	return response, nil
 }
 
-func (api *Router) getArtistIndex(r *http.Request, libId int, ifModifiedSince time.Time) (*responses.Indexes, error) {
+func (api *Router) getArtistIndex(r *http.Request, libId int, ifModifiedSince time.Time, roles ...string) (*responses.Indexes, error) {
 	ctx := r.Context()
 	lib, err := api.ds.Library(ctx).Get(libId)
 	if err != nil {
@@ -37,7 +38,7 @@ func (api *Router) getArtistIndex(r *http.Request, libId int, ifModifiedSince ti
 
 	var indexes model.ArtistIndexes
 	if lib.LastScanAt.After(ifModifiedSince) {
-		indexes, err = api.ds.Artist(ctx).GetIndex()
+		indexes, err = api.ds.Artist(ctx).GetIndex(roles...)
 		if err != nil {
 			log.Error(ctx, "Error retrieving Indexes", err)
 			return nil, err
@@ -75,7 +76,11 @@ func (api *Router) GetIndexes(r *http.Request) (*responses.Subsonic, error) {
 func (api *Router) GetArtists(r *http.Request) (*responses.Subsonic, error) {
 	p := req.Params(r)
 	musicFolderId := p.IntOr("musicFolderId", 1)
-	res, err := api.getArtistIndex(r, musicFolderId, time.Time{})
+	var roles []string
+	if role, err := p.String("role"); err == nil {
+		roles = append(roles, role)
+	}
+	res, err := api.getArtistIndex(r, musicFolderId, time.Time{}, roles...)
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +112,8 @@ func (api *Router) GetMusicDirectory(r *http.Request) (*responses.Subsonic, erro
 		dir, err = api.buildArtistDirectory(ctx, v)
 	case *model.Album:
 		dir, err = api.buildAlbumDirectory(ctx, v)
+	case *model.Folder:
+		dir, err = api.buildFolderDirectory(ctx, v)
 	default:
 		log.Error(r, "Requested ID of invalid type", "id", id, "entity", v)
 		return nil, newError(responses.ErrorDataNotFound, "Directory not found")
@@ -384,6 +391,31 @@ func (api *Router) buildArtist(r *http.Request, artist *model.Artist) (*response
 	return a, nil
 }
 
+// buildFolderDirectory serves a filesystem directory from the folder table: its sub-folders
+// (non-empty ones only), followed by the tracks directly inside it
+func (api *Router) buildFolderDirectory(ctx context.Context, folder *model.Folder) (*responses.Directory, error) {
+	dir := &responses.Directory{}
+	dir.Id = folder.ID
+	dir.Name = path.Base(folder.Path)
+	dir.Parent = folder.ParentID
+
+	children, err := api.ds.Folder(ctx).GetChildren(folder.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range children {
+		dir.Child = append(dir.Child, responses.Child{Id: f.ID, Parent: folder.ID,
+			Title: path.Base(f.Path), IsDir: true})
+	}
+
+	mfs, err := api.ds.MediaFile(ctx).GetAll(filter.SongsByFolder(folder.ID))
+	if err != nil {
+		return nil, err
+	}
+	dir.Child = append(dir.Child, childrenFromMediaFiles(ctx, mfs)...)
+	return dir, nil
+}
+
 func (api *Router) buildAlbumDirectory(ctx context.Context, album *model.Album) (*responses.Directory, error) {
 	dir := &responses.Directory{}
 	dir.Id = album.ID
@@ -43,10 +43,16 @@ func AlbumsByRating() Options {
 	return Options{Sort: "Rating", Order: "desc", Filters: squirrel.Gt{"rating": 0}}
 }
 
+// byGenre matches the genre display name case-insensitively: Subsonic clients send back the
+// string returned by getGenres, not an id, and some of them change its casing along the way
+func byGenre(genre string) squirrel.Sqlizer {
+	return squirrel.Expr("genre.name = ? collate nocase", genre)
+}
+
 func AlbumsByGenre(genre string) Options {
 	return Options{
 		Sort:    "genre.name asc, name asc",
-		Filters: squirrel.Eq{"genre.name": genre},
+		Filters: byGenre(genre),
 	}
 }
 
@@ -84,10 +90,50 @@ func AlbumsByYear(fromYear, toYear int) Options {
 	}
 }
 
+// AlbumsByComposer matches albums where the given composer participates in at least one track
+func AlbumsByComposer(composer string) Options {
+	return Options{
+		Sort: "name",
+		Filters: squirrel.Expr("exists (select 1 from media_file mf"+
+			" join media_file_artists mfa on mfa.media_file_id = mf.id"+
+			" where mf.album_id = album.id and mfa.role = 'composer' and mfa.artist_id = ?)",
+			model.ParticipantID(composer)),
+	}
+}
+
+// AlbumsByMood matches albums carrying the given album-level mood tag
+func AlbumsByMood(mood string) Options {
+	return Options{
+		Sort: "name",
+		Filters: squirrel.Expr("exists (select 1 from item_tags"+
+			" where item_id = album.id and item_type = 'album' and tag_id = ?)",
+			model.NewTag("mood", mood).ID),
+	}
+}
+
+// AlbumsByEra matches albums whose original year falls in the given decade (any year in the
+// decade can be passed, ex: 1994 selects 1990-1999)
+func AlbumsByEra(era int) Options {
+	decade := era - era%10
+	return Options{
+		Sort: "min_original_year, name",
+		Filters: squirrel.Or{
+			squirrel.And{
+				squirrel.GtOrEq{"min_original_year": decade},
+				squirrel.LtOrEq{"min_original_year": decade + 9},
+			},
+			squirrel.And{
+				squirrel.GtOrEq{"max_original_year": decade},
+				squirrel.LtOrEq{"max_original_year": decade + 9},
+			},
+		},
+	}
+}
+
 func SongsByGenre(genre string) Options {
 	return Options{
 		Sort:    "genre.name asc, title asc",
-		Filters: squirrel.Eq{"genre.name": genre},
+		Filters: byGenre(genre),
 	}
 }
 
@@ -98,13 +144,21 @@ func SongsByAlbum(albumId string) Options {
 	}
 }
 
+// SongsByFolder returns the non-missing tracks directly inside the given folder, in path order
+func SongsByFolder(folderId string) Options {
+	return Options{
+		Filters: squirrel.And{squirrel.Eq{"folder_id": folderId}, squirrel.Eq{"missing": false}},
+		Sort:    "path",
+	}
+}
+
 func SongsByRandom(genre string, fromYear, toYear int) Options {
 	options := Options{
 		Sort: "random",
 	}
 	ff := squirrel.And{}
 	if genre != "" {
-		ff = append(ff, squirrel.Eq{"genre.name": genre})
+		ff = append(ff, byGenre(genre))
 	}
 	if fromYear != 0 {
 		ff = append(ff, squirrel.GtOrEq{"year": fromYear})
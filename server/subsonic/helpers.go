@@ -4,11 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"mime"
 	"net/http"
 	"sort"
 	"strings"
 
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/conf/mime"
 	"github.com/navidrome/navidrome/consts"
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/model/request"
@@ -140,7 +141,7 @@ func childFromMediaFile(ctx context.Context, mf model.MediaFile) responses.Child
 	child.Genre = mf.Genre
 	child.Genres = buildItemGenres(mf.Genres)
 	child.Track = int32(mf.TrackNumber)
-	child.Duration = int32(mf.Duration)
+	child.Duration = int32(mf.Duration())
 	child.Size = mf.Size
 	child.Suffix = mf.Suffix
 	child.BitRate = int32(mf.BitRate)
@@ -177,17 +178,38 @@ func childFromMediaFile(ctx context.Context, mf model.MediaFile) responses.Child
 	child.Bpm = int32(mf.Bpm)
 	child.MediaType = responses.MediaTypeSong
 	child.MusicBrainzId = mf.MbzRecordingID
+	if mf.Isrc != "" {
+		child.Isrc = []string{mf.Isrc}
+	}
+	trackGain, trackPeak := mf.TrackGain()
 	child.ReplayGain = responses.ReplayGain{
-		TrackGain: mf.RgTrackGain,
+		TrackGain: trackGain,
 		AlbumGain: mf.RgAlbumGain,
-		TrackPeak: mf.RgTrackPeak,
+		TrackPeak: trackPeak,
 		AlbumPeak: mf.RgAlbumPeak,
 	}
 	child.ChannelCount = int32(mf.Channels)
 	child.SamplingRate = int32(mf.SampleRate)
+	child.Contributors, child.ContributorsTruncated = buildContributors(mf)
 	return child
 }
 
+// buildContributors flattens the track participations into the OpenSubsonic contributors list,
+// with roles in display order and the participant lists capped at Server.MaxContributors
+func buildContributors(mf model.MediaFile) ([]responses.Contributor, bool) {
+	participations, truncated := mf.Participations.Truncated(conf.Server.MaxContributors)
+	var contributors []responses.Contributor
+	for _, role := range participations.SortedRoles() {
+		for _, participant := range participations[role] {
+			contributors = append(contributors, responses.Contributor{
+				Role:   role.String(),
+				Artist: responses.ArtistID3{Id: participant.ID, Name: participant.Name},
+			})
+		}
+	}
+	return contributors, truncated
+}
+
 func fakePath(mf model.MediaFile) string {
 	filename := mapSlashToDash(mf.Title)
 	if mf.TrackNumber != 0 {
@@ -223,7 +245,7 @@ func childFromAlbum(_ context.Context, al model.Album) responses.Child {
 	child.Created = &al.CreatedAt
 	child.Parent = al.AlbumArtistID
 	child.ArtistId = al.AlbumArtistID
-	child.Duration = int32(al.Duration)
+	child.Duration = int32(al.Duration())
 	child.SongCount = int32(al.SongCount)
 	if al.Starred {
 		child.Starred = al.StarredAt
@@ -236,6 +258,10 @@ func childFromAlbum(_ context.Context, al model.Album) responses.Child {
 	child.SortName = al.SortAlbumName
 	child.MediaType = responses.MediaTypeAlbum
 	child.MusicBrainzId = al.MbzAlbumID
+	child.ReplayGain = responses.ReplayGain{
+		AlbumGain: al.RgAlbumGain,
+		AlbumPeak: al.RgAlbumPeak,
+	}
 	return child
 }
 
@@ -303,7 +329,7 @@ func buildAlbumID3(ctx context.Context, album model.Album) responses.AlbumID3 {
 	dir.ArtistId = album.AlbumArtistID
 	dir.CoverArt = album.CoverArtID().String()
 	dir.SongCount = int32(album.SongCount)
-	dir.Duration = int32(album.Duration)
+	dir.Duration = int32(album.Duration())
 	dir.PlayCount = album.PlayCount
 	if album.PlayCount > 0 {
 		dir.Played = album.PlayDate
@@ -324,6 +350,11 @@ func buildAlbumID3(ctx context.Context, album model.Album) responses.AlbumID3 {
 	dir.SortName = album.SortAlbumName
 	dir.OriginalReleaseDate = toItemDate(album.OriginalDate)
 	dir.ReleaseDate = toItemDate(album.ReleaseDate)
+	dir.MinBitDepth = int32(album.MinBitDepth)
+	dir.MaxBitDepth = int32(album.MaxBitDepth)
+	dir.MinSampleRate = int32(album.MinSampleRate)
+	dir.MaxSampleRate = int32(album.MaxSampleRate)
+	dir.ChannelCount = int32(album.Channels)
 	return dir
 }
 
@@ -37,6 +37,19 @@ var _ = Describe("helpers", func() {
 		})
 	})
 
+	Describe("buildAlbumID3", func() {
+		It("exposes the aggregated audio quality range", func() {
+			album := model.Album{ID: "1", Name: "album1", MinSampleRate: 44100, MaxSampleRate: 96000,
+				MinBitDepth: 16, MaxBitDepth: 24, Channels: 2}
+			dir := buildAlbumID3(context.Background(), album)
+			Expect(dir.MinSampleRate).To(Equal(int32(44100)))
+			Expect(dir.MaxSampleRate).To(Equal(int32(96000)))
+			Expect(dir.MinBitDepth).To(Equal(int32(16)))
+			Expect(dir.MaxBitDepth).To(Equal(int32(24)))
+			Expect(dir.ChannelCount).To(Equal(int32(2)))
+		})
+	})
+
 	Describe("buildDiscTitles", func() {
 		It("should return nil when album has no discs", func() {
 			album := model.Album{}
@@ -56,6 +69,20 @@ var _ = Describe("helpers", func() {
 			}
 			Expect(buildDiscSubtitles(context.Background(), album)).To(Equal(expected))
 		})
+
+		It("should keep discs without a subtitle in the list", func() {
+			album := model.Album{
+				Discs: map[int]string{
+					1: "",
+					2: "Live",
+				},
+			}
+			expected := responses.DiscTitles{
+				{Disc: 1, Title: ""},
+				{Disc: 2, Title: "Live"},
+			}
+			Expect(buildDiscSubtitles(context.Background(), album)).To(Equal(expected))
+		})
 	})
 
 	DescribeTable("toItemDate",
@@ -171,7 +171,7 @@ func (api *Router) buildPlaylist(p model.Playlist) *responses.Playlist {
 	pls.Comment = p.Comment
 	pls.SongCount = int32(p.SongCount)
 	pls.Owner = p.OwnerName
-	pls.Duration = int32(p.Duration)
+	pls.Duration = int32(p.Duration())
 	pls.Public = p.Public
 	pls.Created = p.CreatedAt
 	pls.CoverArt = p.CoverArtID().String()
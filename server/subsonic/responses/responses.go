@@ -155,16 +155,19 @@ type Child struct {
 	   <xs:attribute name="averageRating" type="sub:AverageRating" use="optional"/>  <!-- Added in 1.6.0 -->
 	*/
 	// OpenSubsonic extensions
-	Played        *time.Time `xml:"played,attr,omitempty"   json:"played,omitempty"`
-	Bpm           int32      `xml:"bpm,attr"                json:"bpm"`
-	Comment       string     `xml:"comment,attr"            json:"comment"`
-	SortName      string     `xml:"sortName,attr"           json:"sortName"`
-	MediaType     MediaType  `xml:"mediaType,attr"          json:"mediaType"`
-	MusicBrainzId string     `xml:"musicBrainzId,attr"      json:"musicBrainzId"`
-	Genres        ItemGenres `xml:"genres"                  json:"genres"`
-	ReplayGain    ReplayGain `xml:"replayGain"              json:"replayGain"`
-	ChannelCount  int32      `xml:"channelCount,attr"       json:"channelCount"`
-	SamplingRate  int32      `xml:"samplingRate,attr"       json:"samplingRate"`
+	Played                *time.Time    `xml:"played,attr,omitempty"   json:"played,omitempty"`
+	Bpm                   int32         `xml:"bpm,attr"                json:"bpm"`
+	Comment               string        `xml:"comment,attr"            json:"comment"`
+	SortName              string        `xml:"sortName,attr"           json:"sortName"`
+	MediaType             MediaType     `xml:"mediaType,attr"          json:"mediaType"`
+	MusicBrainzId         string        `xml:"musicBrainzId,attr"      json:"musicBrainzId"`
+	Isrc                  []string      `xml:"isrc,omitempty"          json:"isrc,omitempty"`
+	Genres                ItemGenres    `xml:"genres"                  json:"genres"`
+	ReplayGain            ReplayGain    `xml:"replayGain"              json:"replayGain"`
+	ChannelCount          int32         `xml:"channelCount,attr"       json:"channelCount"`
+	SamplingRate          int32         `xml:"samplingRate,attr"       json:"samplingRate"`
+	Contributors          []Contributor `xml:"contributors"                         json:"contributors,omitempty"`
+	ContributorsTruncated bool          `xml:"contributorsTruncated,attr,omitempty" json:"contributorsTruncated,omitempty"`
 }
 
 type Songs struct {
@@ -235,6 +238,14 @@ type AlbumID3 struct {
 	DiscTitles          DiscTitles `xml:"discTitles"            json:"discTitles"`
 	OriginalReleaseDate ItemDate   `xml:"originalReleaseDate"   json:"originalReleaseDate"`
 	ReleaseDate         ItemDate   `xml:"releaseDate"           json:"releaseDate"`
+
+	// Aggregated audio properties (albumAudioQuality extension). Min/max carry the full
+	// range on mixed-quality albums
+	MinBitDepth   int32 `xml:"minBitDepth,attr,omitempty"   json:"minBitDepth,omitempty"`
+	MaxBitDepth   int32 `xml:"maxBitDepth,attr,omitempty"   json:"maxBitDepth,omitempty"`
+	MinSampleRate int32 `xml:"minSampleRate,attr,omitempty" json:"minSampleRate,omitempty"`
+	MaxSampleRate int32 `xml:"maxSampleRate,attr,omitempty" json:"maxSampleRate,omitempty"`
+	ChannelCount  int32 `xml:"channelCount,attr,omitempty"  json:"channelCount,omitempty"`
 }
 
 type ArtistWithAlbumsID3 struct {
@@ -486,6 +497,12 @@ type ItemGenre struct {
 	Name string `xml:"name,attr" json:"name"`
 }
 
+// Contributor is an artist participating in a song with a specific role (OpenSubsonic).
+type Contributor struct {
+	Role   string    `xml:"role,attr" json:"role"`
+	Artist ArtistID3 `xml:"artist"    json:"artist"`
+}
+
 // ItemGenres holds a list of genres (OpenSubsonic). If it is null, it must be marshalled as an empty array.
 type ItemGenres []ItemGenre
 
@@ -149,6 +149,28 @@ var _ = Describe("Responses", func() {
 				response.Directory.Child = child
 			})
 
+			It("should match .XML", func() {
+				Expect(xml.MarshalIndent(response, "", "  ")).To(MatchSnapshot())
+			})
+			It("should match .JSON", func() {
+				Expect(json.MarshalIndent(response, "", "  ")).To(MatchSnapshot())
+			})
+		})
+		Context("with contributors", func() {
+			BeforeEach(func() {
+				response.Directory = &Directory{Id: "1", Name: "N"}
+				child := make([]Child, 1)
+				child[0] = Child{
+					Id: "1", Title: "title", IsDir: false,
+					Contributors: []Contributor{
+						{Role: "composer", Artist: ArtistID3{Id: "2", Name: "a composer"}},
+						{Role: "producer", Artist: ArtistID3{Id: "3", Name: "a producer"}},
+					},
+					ContributorsTruncated: true,
+				}
+				response.Directory.Child = child
+			})
+
 			It("should match .XML", func() {
 				Expect(xml.MarshalIndent(response, "", "  ")).To(MatchSnapshot())
 			})
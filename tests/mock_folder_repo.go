@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"github.com/navidrome/navidrome/model"
+)
+
+type MockFolderRepo struct {
+	model.FolderRepository
+	Error error
+	data  map[string]model.Folder
+}
+
+func (r *MockFolderRepo) init() {
+	if r.data == nil {
+		r.data = make(map[string]model.Folder)
+	}
+}
+
+func (r *MockFolderRepo) Get(id string) (*model.Folder, error) {
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	r.init()
+	if f, ok := r.data[id]; ok {
+		return &f, nil
+	}
+	return nil, model.ErrNotFound
+}
+
+func (r *MockFolderRepo) GetByPath(path string) (*model.Folder, error) {
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	r.init()
+	for _, f := range r.data {
+		if f.Path == path {
+			return &f, nil
+		}
+	}
+	return nil, model.ErrNotFound
+}
+
+func (r *MockFolderRepo) Put(f *model.Folder) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	r.init()
+	r.data[f.ID] = *f
+	return nil
+}
+
+func (r *MockFolderRepo) GetChildren(folderID string, _ ...model.QueryOptions) (model.Folders, error) {
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	r.init()
+	var children model.Folders
+	for _, f := range r.data {
+		if f.ParentID == folderID {
+			children = append(children, f)
+		}
+	}
+	return children, nil
+}
+
+func (r *MockFolderRepo) CountAll(...model.QueryOptions) (int64, error) {
+	if r.Error != nil {
+		return 0, r.Error
+	}
+	r.init()
+	return int64(len(r.data)), nil
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"slices"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+type MockLibraryRepo struct {
+	model.LibraryRepository
+	Error  error
+	Access map[int][]string
+	data   map[int]model.Library
+}
+
+func (r *MockLibraryRepo) init() {
+	if r.data == nil {
+		r.data = make(map[int]model.Library)
+	}
+}
+
+func (r *MockLibraryRepo) SetData(libs model.Libraries) {
+	r.data = make(map[int]model.Library)
+	for _, lib := range libs {
+		r.data[lib.ID] = lib
+	}
+}
+
+func (r *MockLibraryRepo) Get(id int) (*model.Library, error) {
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	r.init()
+	if lib, ok := r.data[id]; ok {
+		return &lib, nil
+	}
+	return nil, model.ErrNotFound
+}
+
+func (r *MockLibraryRepo) Put(lib *model.Library) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	r.init()
+	if lib.ID == 0 {
+		lib.ID = len(r.data) + 1
+	}
+	r.data[lib.ID] = *lib
+	return nil
+}
+
+func (r *MockLibraryRepo) GrantAccess(id int, userID string) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.Access == nil {
+		r.Access = make(map[int][]string)
+	}
+	if !slices.Contains(r.Access[id], userID) {
+		r.Access[id] = append(r.Access[id], userID)
+	}
+	return nil
+}
+
+func (r *MockLibraryRepo) RevokeAccess(id int, userID string) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	r.Access[id] = slices.DeleteFunc(r.Access[id], func(u string) bool { return u == userID })
+	return nil
+}
+
+func (r *MockLibraryRepo) GetAll(...model.QueryOptions) (model.Libraries, error) {
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	r.init()
+	var libs model.Libraries
+	for _, lib := range r.data {
+		libs = append(libs, lib)
+	}
+	return libs, nil
+}
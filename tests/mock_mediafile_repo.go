@@ -2,6 +2,7 @@ package tests
 
 import (
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,8 +20,9 @@ func CreateMockMediaFileRepo() *MockMediaFileRepo {
 
 type MockMediaFileRepo struct {
 	model.MediaFileRepository
-	data map[string]*model.MediaFile
-	err  bool
+	data    map[string]*model.MediaFile
+	err     bool
+	Options model.QueryOptions
 }
 
 func (m *MockMediaFileRepo) SetError(err bool) {
@@ -52,7 +54,10 @@ func (m *MockMediaFileRepo) Get(id string) (*model.MediaFile, error) {
 	return nil, model.ErrNotFound
 }
 
-func (m *MockMediaFileRepo) GetAll(...model.QueryOptions) (model.MediaFiles, error) {
+func (m *MockMediaFileRepo) GetAll(qo ...model.QueryOptions) (model.MediaFiles, error) {
+	if len(qo) > 0 {
+		m.Options = qo[0]
+	}
 	if m.err {
 		return nil, errors.New("error")
 	}
@@ -62,6 +67,25 @@ func (m *MockMediaFileRepo) GetAll(...model.QueryOptions) (model.MediaFiles, err
 	}), nil
 }
 
+func (m *MockMediaFileRepo) GetCursor(...model.QueryOptions) (<-chan model.MediaFile, <-chan error) {
+	results := make(chan model.MediaFile)
+	errC := make(chan error, 1)
+	go func() {
+		defer close(results)
+		defer close(errC)
+		if m.err {
+			errC <- errors.New("error")
+			return
+		}
+		ids := maps.Keys(m.data)
+		sort.Strings(ids)
+		for _, id := range ids {
+			results <- *m.data[id]
+		}
+	}()
+	return results, errC
+}
+
 func (m *MockMediaFileRepo) Put(mf *model.MediaFile) error {
 	if m.err {
 		return errors.New("error")
@@ -73,6 +97,15 @@ func (m *MockMediaFileRepo) Put(mf *model.MediaFile) error {
 	return nil
 }
 
+func (m *MockMediaFileRepo) PutAll(mfs model.MediaFiles) error {
+	for i := range mfs {
+		if err := m.Put(&mfs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *MockMediaFileRepo) IncPlayCount(id string, timestamp time.Time) error {
 	if m.err {
 		return errors.New("error")
@@ -85,6 +118,49 @@ func (m *MockMediaFileRepo) IncPlayCount(id string, timestamp time.Time) error {
 	return model.ErrNotFound
 }
 
+func (m *MockMediaFileRepo) GetByWorkID(workID string) (model.MediaFiles, error) {
+	if m.err {
+		return nil, errors.New("error")
+	}
+	var res model.MediaFiles
+	for _, mf := range m.data {
+		if mf.MbzWorkID == workID {
+			res = append(res, *mf)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Year < res[j].Year })
+	return res, nil
+}
+
+func (m *MockMediaFileRepo) FindDuplicates() (model.DuplicateGroups, error) {
+	if m.err {
+		return nil, errors.New("error")
+	}
+	type key struct {
+		albumArtist, album, title string
+		trackNumber               int
+	}
+	byKey := map[key]model.MediaFiles{}
+	for _, mf := range m.data {
+		if mf.Missing {
+			continue
+		}
+		k := key{mf.AlbumArtist, mf.Album, mf.Title, mf.TrackNumber}
+		byKey[k] = append(byKey[k], *mf)
+	}
+	var res model.DuplicateGroups
+	for k, entries := range byKey {
+		if len(entries) < 2 {
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+		res = append(res, model.DuplicateGroup{AlbumArtist: k.albumArtist, Album: k.album,
+			TrackNumber: k.trackNumber, Title: k.title, Entries: entries})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Title < res[j].Title })
+	return res, nil
+}
+
 func (m *MockMediaFileRepo) FindByAlbum(artistId string) (model.MediaFiles, error) {
 	if m.err {
 		return nil, errors.New("error")
@@ -101,4 +177,52 @@ func (m *MockMediaFileRepo) FindByAlbum(artistId string) (model.MediaFiles, erro
 	return res, nil
 }
 
+func (m *MockMediaFileRepo) missingGain() model.MediaFiles {
+	var res model.MediaFiles
+	for _, mf := range m.data {
+		if mf.Missing || mf.RgTrackGain != 0 || mf.RgTrackPeak != 0 ||
+			mf.RgTrackGainComputed != 0 || mf.RgTrackPeakComputed != 0 {
+			continue
+		}
+		res = append(res, *mf)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+func (m *MockMediaFileRepo) GetMissingGain(fromID string, limit int) (model.MediaFiles, error) {
+	if m.err {
+		return nil, errors.New("error")
+	}
+	var res model.MediaFiles
+	for _, mf := range m.missingGain() {
+		if mf.ID > fromID {
+			res = append(res, mf)
+		}
+		if len(res) == limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (m *MockMediaFileRepo) CountMissingGain() (int64, error) {
+	if m.err {
+		return 0, errors.New("error")
+	}
+	return int64(len(m.missingGain())), nil
+}
+
+func (m *MockMediaFileRepo) StoreComputedGain(id string, gain, peak float64) error {
+	if m.err {
+		return errors.New("error")
+	}
+	if mf, ok := m.data[id]; ok {
+		mf.RgTrackGainComputed = gain
+		mf.RgTrackPeakComputed = peak
+		return nil
+	}
+	return model.ErrNotFound
+}
+
 var _ model.MediaFileRepository = (*MockMediaFileRepo)(nil)
@@ -20,6 +20,9 @@ type MockDataStore struct {
 	MockedUserProps      model.UserPropsRepository
 	MockedScrobbleBuffer model.ScrobbleBufferRepository
 	MockedRadioBuffer    model.RadioRepository
+	MockedPlayEvent      model.PlayEventRepository
+	MockedFolder         model.FolderRepository
+	MockedLibrary        model.LibraryRepository
 }
 
 func (db *MockDataStore) Album(context.Context) model.AlbumRepository {
@@ -44,7 +47,21 @@ func (db *MockDataStore) MediaFile(context.Context) model.MediaFileRepository {
 }
 
 func (db *MockDataStore) Library(context.Context) model.LibraryRepository {
-	return struct{ model.LibraryRepository }{}
+	if db.MockedLibrary == nil {
+		db.MockedLibrary = &MockLibraryRepo{}
+	}
+	return db.MockedLibrary
+}
+
+func (db *MockDataStore) Tag(context.Context) model.TagRepository {
+	return struct{ model.TagRepository }{}
+}
+
+func (db *MockDataStore) Folder(context.Context) model.FolderRepository {
+	if db.MockedFolder == nil {
+		db.MockedFolder = &MockFolderRepo{}
+	}
+	return db.MockedFolder
 }
 
 func (db *MockDataStore) Genre(context.Context) model.GenreRepository {
@@ -65,6 +82,17 @@ func (db *MockDataStore) PlayQueue(context.Context) model.PlayQueueRepository {
 	return struct{ model.PlayQueueRepository }{}
 }
 
+func (db *MockDataStore) PlayEvent(context.Context) model.PlayEventRepository {
+	if db.MockedPlayEvent == nil {
+		db.MockedPlayEvent = CreateMockPlayEventRepo()
+	}
+	return db.MockedPlayEvent
+}
+
+func (db *MockDataStore) ScanLog(context.Context) model.ScanLogRepository {
+	return struct{ model.ScanLogRepository }{}
+}
+
 func (db *MockDataStore) UserProps(context.Context) model.UserPropsRepository {
 	if db.MockedUserProps == nil {
 		db.MockedUserProps = &MockedUserPropsRepo{}
@@ -132,3 +160,35 @@ func (db *MockDataStore) Resource(ctx context.Context, m interface{}) model.Reso
 func (db *MockDataStore) GC(ctx context.Context, rootFolder string) error {
 	return nil
 }
+
+func (db *MockDataStore) CleanOrphanAnnotations(ctx context.Context, fix bool) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
+func (db *MockDataStore) MergeDuplicateArtists(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (db *MockDataStore) MergeTagValues(ctx context.Context, tagName string, from []string, to string, dryRun bool) (int64, error) {
+	return 0, nil
+}
+
+func (db *MockDataStore) DeleteMissingFiles(ctx context.Context, ids []string) error {
+	return nil
+}
+
+func (db *MockDataStore) MergeDuplicateFolders(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (db *MockDataStore) DeleteLibrary(ctx context.Context, id int) error {
+	return nil
+}
+
+func (db *MockDataStore) ResolvePlaylistTracks(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (db *MockDataStore) RelocateLibrary(ctx context.Context, id int, newPath string) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,26 @@
+package tests
+
+import (
+	"errors"
+	"time"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+func CreateMockPlayEventRepo() *MockPlayEventRepo {
+	return &MockPlayEventRepo{}
+}
+
+type MockPlayEventRepo struct {
+	model.PlayEventRepository
+	Events []model.PlayEvent
+	Err    bool
+}
+
+func (m *MockPlayEventRepo) Record(mediaFileID string, playDate time.Time) error {
+	if m.Err {
+		return errors.New("error")
+	}
+	m.Events = append(m.Events, model.PlayEvent{MediaFileID: mediaFileID, PlayDate: playDate})
+	return nil
+}
@@ -19,6 +19,18 @@ type MockedUserRepo struct {
 	Data  map[string]*model.User
 }
 
+func (u *MockedUserRepo) Get(id string) (*model.User, error) {
+	if u.Error != nil {
+		return nil, u.Error
+	}
+	for _, usr := range u.Data {
+		if usr.ID == id {
+			return usr, nil
+		}
+	}
+	return nil, model.ErrNotFound
+}
+
 func (u *MockedUserRepo) CountAll(qo ...model.QueryOptions) (int64, error) {
 	if u.Error != nil {
 		return 0, u.Error
@@ -44,11 +44,16 @@ func (h *Hasher) Reseed(id string) {
 }
 
 func (h *Hasher) reseed(id string) string {
-	seed := strconv.FormatUint(random.Uint64(), 36)
+	seed := NewSeed()
 	h.seeds[id] = seed
 	return seed
 }
 
+// NewSeed returns a new random seed, in the same format used by Reseed
+func NewSeed() string {
+	return strconv.FormatUint(random.Uint64(), 36)
+}
+
 // HashFunc returns a function that hashes a string using the seed for the given id
 func (h *Hasher) HashFunc() func(id, str string) uint64 {
 	return func(id, str string) uint64 {
@@ -33,6 +33,14 @@ func SanitizeStrings(text ...string) string {
 	return strings.Join(fullText, " ")
 }
 
+var punctuationRegex = regexp.MustCompile(`[^\p{L}\p{N} ]`)
+
+// FoldPunctuation removes everything but letters, digits and spaces, so "R.E.M." can be
+// matched by "rem". Text without punctuation (including CJK) passes through untouched.
+func FoldPunctuation(text string) string {
+	return punctuationRegex.ReplaceAllString(text, "")
+}
+
 var policy = bluemonday.UGCPolicy()
 
 func SanitizeText(text string) string {